@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"mineServers/internal/models"
+)
+
+// AuditStore persists the audit log of the mutating API calls.
+type AuditStore interface {
+	CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) error
+	// ListAuditEntries returns the entries matching the filter, newest
+	// first.
+	ListAuditEntries(ctx context.Context, filter *AuditFilter) ([]models.AuditEntry, error)
+}
+
+// AuditFilter selects audit entries. Empty fields and zero bounds are
+// ignored; From is inclusive, To and Before, an entry ID, are exclusive.
+type AuditFilter struct {
+	Actor  string
+	Method string
+	Target string
+	Host   string
+	Result string
+	From   time.Time
+	To     time.Time
+	Before int64
+	Limit  int
+}
+
+const auditColumns = `id, time, actor, source_ip, method, route, path, target, host, payload, status, result, error, duration_ms`
+
+func (s *service) CreateAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	entry.Time = entry.Time.UTC()
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO audit_log (time, actor, source_ip, method, route, path, target, host, payload, status, result, error, duration_ms)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.Time, entry.Actor, entry.SourceIP, entry.Method, entry.Route, entry.Path, entry.Target, entry.Host,
+		string(entry.Payload), entry.Status, entry.Result, entry.Error, entry.DurationMs,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+
+	entry.ID, _ = res.LastInsertId()
+
+	return nil
+}
+
+func (s *service) ListAuditEntries(ctx context.Context, filter *AuditFilter) ([]models.AuditEntry, error) {
+	var from, to any
+	if !filter.From.IsZero() {
+		from = filter.From.UTC()
+	}
+	if !filter.To.IsZero() {
+		to = filter.To.UTC()
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+auditColumns+` FROM audit_log
+		WHERE (? = '' OR actor = ?) AND (? = '' OR method = ?) AND (? = '' OR target = ?) AND (? = '' OR host = ?)
+		AND (? = '' OR result = ?) AND (? IS NULL OR time >= ?) AND (? IS NULL OR time < ?) AND (? = 0 OR id < ?)
+		ORDER BY id DESC LIMIT ?`,
+		filter.Actor, filter.Actor, filter.Method, filter.Method, filter.Target, filter.Target, filter.Host, filter.Host,
+		filter.Result, filter.Result, from, from, to, to, filter.Before, filter.Before, filter.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var (
+			entry   models.AuditEntry
+			payload string
+		)
+		if err := rows.Scan(&entry.ID, &entry.Time, &entry.Actor, &entry.SourceIP, &entry.Method, &entry.Route, &entry.Path,
+			&entry.Target, &entry.Host, &payload, &entry.Status, &entry.Result, &entry.Error, &entry.DurationMs); err != nil {
+			return nil, err
+		}
+		if payload != "" {
+			entry.Payload = []byte(payload)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,43 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mineServers/internal/models"
+)
+
+func TestAuditStore_CreateAndFilter(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	start := time.Date(2026, 1, 1, 3, 0, 0, 0, time.UTC)
+	entries := []models.AuditEntry{
+		{Time: start, Actor: "alice", Method: "POST", Route: "/api/containers/:id/stop", Path: "/api/containers/mc/stop", Target: "mc", Status: 204, Result: "success"},
+		{Time: start.Add(time.Minute), Actor: "bob", Method: "DELETE", Route: "/api/containers/:id", Path: "/api/containers/mc", Target: "mc", Status: 409, Result: "failure", Error: "container is running"},
+		{Time: start.Add(2 * time.Minute), Actor: "alice", Method: "POST", Route: "/api/containers/", Path: "/api/containers/", Target: "proxy", Payload: []byte(`{"name":"proxy"}`), Status: 201, Result: "success"},
+	}
+	for i := range entries {
+		if err := db.CreateAuditEntry(ctx, &entries[i]); err != nil || entries[i].ID == 0 {
+			t.Fatalf("CreateAuditEntry() = %+v, %v", entries[i], err)
+		}
+	}
+
+	all, err := db.ListAuditEntries(ctx, &AuditFilter{Limit: 10})
+	if err != nil || len(all) != 3 || all[0].Target != "proxy" || string(all[0].Payload) != `{"name":"proxy"}` || !all[2].Time.Equal(start) {
+		t.Fatalf("ListAuditEntries() = %+v, %v", all, err)
+	}
+	failures, err := db.ListAuditEntries(ctx, &AuditFilter{Target: "mc", Result: "failure", Limit: 10})
+	if err != nil || len(failures) != 1 || failures[0].Actor != "bob" || failures[0].Error != "container is running" {
+		t.Fatalf("ListAuditEntries(failures) = %+v, %v", failures, err)
+	}
+	ranged, err := db.ListAuditEntries(ctx, &AuditFilter{Actor: "alice", From: start.Add(time.Second), To: start.Add(time.Hour), Limit: 10})
+	if err != nil || len(ranged) != 1 || ranged[0].Target != "proxy" {
+		t.Fatalf("ListAuditEntries(range) = %+v, %v", ranged, err)
+	}
+	page, err := db.ListAuditEntries(ctx, &AuditFilter{Before: all[0].ID, Limit: 1})
+	if err != nil || len(page) != 1 || page[0].ID != all[1].ID {
+		t.Fatalf("ListAuditEntries(before) = %+v, %v", page, err)
+	}
+}
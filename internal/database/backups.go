@@ -0,0 +1,274 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"mineServers/internal/models"
+)
+
+// BackupStore persists the records of volume backups and their schedules.
+// The archives themselves are kept on disk. Deleting a schedule keeps the
+// backups it made.
+type BackupStore interface {
+	CreateBackup(ctx context.Context, backup *models.Backup) error
+	// FinishBackup stores the outcome of a running backup.
+	FinishBackup(ctx context.Context, backup *models.Backup) error
+	GetBackup(ctx context.Context, id int64) (*models.Backup, error)
+	// ListBackups returns the backups of a host and container, given by
+	// name or ID, newest first. Empty filters match every backup.
+	ListBackups(ctx context.Context, host, container string) ([]models.Backup, error)
+	DeleteBackup(ctx context.Context, id int64) error
+
+	CreateBackupSchedule(ctx context.Context, schedule *models.BackupSchedule) error
+	GetBackupSchedule(ctx context.Context, id int64) (*models.BackupSchedule, error)
+	ListBackupSchedules(ctx context.Context) ([]models.BackupSchedule, error)
+	UpdateBackupSchedule(ctx context.Context, schedule *models.BackupSchedule) error
+	DeleteBackupSchedule(ctx context.Context, id int64) error
+	RecordBackupScheduleRun(ctx context.Context, id int64, at time.Time, status string) error
+}
+
+const backupColumns = `id, host, container_id, container_name, schedule_id, mount_type, source, destination, mode, file, size, checksum, duration_ms, status, error, created_at, finished_at`
+
+const backupScheduleColumns = `id, host, container, cron, timezone, mounts, mode, keep_last, keep_daily, disabled, last_run_at, last_status, created_at, updated_at`
+
+func (s *service) CreateBackup(ctx context.Context, backup *models.Backup) error {
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO backups (host, container_id, container_name, schedule_id, mount_type, source, destination, mode, file, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		backup.Host, backup.ContainerID, backup.ContainerName, backup.ScheduleID, backup.MountType, backup.Source,
+		backup.Destination, backup.Mode, backup.File, backup.Status, now,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+
+	backup.ID, _ = res.LastInsertId()
+	backup.CreatedAt = now
+
+	return nil
+}
+
+func (s *service) FinishBackup(ctx context.Context, backup *models.Backup) error {
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE backups SET size = ?, checksum = ?, duration_ms = ?, status = ?, error = ?, finished_at = ? WHERE id = ?`,
+		backup.Size, backup.Checksum, backup.DurationMs, backup.Status, backup.Error, now, backup.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	backup.FinishedAt = &now
+
+	return nil
+}
+
+func (s *service) GetBackup(ctx context.Context, id int64) (*models.Backup, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+backupColumns+` FROM backups WHERE id = ?`, id)
+
+	backup, err := scanBackup(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+
+	return backup, err
+}
+
+func (s *service) ListBackups(ctx context.Context, host, container string) ([]models.Backup, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+backupColumns+` FROM backups
+		WHERE (? = '' OR host = ?) AND (? = '' OR container_name = ? OR container_id = ?)
+		ORDER BY created_at DESC, id DESC`,
+		host, host, container, container, container,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	backups := []models.Backup{}
+	for rows.Next() {
+		backup, err := scanBackup(rows)
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, *backup)
+	}
+
+	return backups, rows.Err()
+}
+
+func (s *service) DeleteBackup(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM backups WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *service) CreateBackupSchedule(ctx context.Context, schedule *models.BackupSchedule) error {
+	mounts, err := json.Marshal(schedule.Mounts)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO backup_schedules (host, container, cron, timezone, mounts, mode, keep_last, keep_daily, disabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		schedule.Host, schedule.Container, schedule.Cron, schedule.Timezone, string(mounts), schedule.Mode,
+		schedule.Retention.KeepLast, schedule.Retention.KeepDaily, schedule.Disabled, now, now,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+
+	schedule.ID, _ = res.LastInsertId()
+	schedule.CreatedAt = now
+	schedule.UpdatedAt = now
+
+	return nil
+}
+
+func (s *service) GetBackupSchedule(ctx context.Context, id int64) (*models.BackupSchedule, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+backupScheduleColumns+` FROM backup_schedules WHERE id = ?`, id)
+
+	schedule, err := scanBackupSchedule(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+
+	return schedule, err
+}
+
+func (s *service) ListBackupSchedules(ctx context.Context) ([]models.BackupSchedule, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+backupScheduleColumns+` FROM backup_schedules ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := []models.BackupSchedule{}
+	for rows.Next() {
+		schedule, err := scanBackupSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, *schedule)
+	}
+
+	return schedules, rows.Err()
+}
+
+// UpdateBackupSchedule replaces the definition of the schedule with the
+// same ID. Its host and run history are kept.
+func (s *service) UpdateBackupSchedule(ctx context.Context, schedule *models.BackupSchedule) error {
+	mounts, err := json.Marshal(schedule.Mounts)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE backup_schedules SET container = ?, cron = ?, timezone = ?, mounts = ?, mode = ?, keep_last = ?, keep_daily = ?, disabled = ?, updated_at = ?
+		WHERE id = ?`,
+		schedule.Container, schedule.Cron, schedule.Timezone, string(mounts), schedule.Mode,
+		schedule.Retention.KeepLast, schedule.Retention.KeepDaily, schedule.Disabled, now, schedule.ID,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	schedule.UpdatedAt = now
+
+	return nil
+}
+
+func (s *service) DeleteBackupSchedule(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM backup_schedules WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *service) RecordBackupScheduleRun(ctx context.Context, id int64, at time.Time, status string) error {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE backup_schedules SET last_run_at = ?, last_status = ? WHERE id = ?`,
+		at.UTC(), status, id,
+	)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func scanBackup(row scanner) (*models.Backup, error) {
+	var (
+		backup     models.Backup
+		scheduleID sql.NullInt64
+		finishedAt sql.NullTime
+	)
+	if err := row.Scan(&backup.ID, &backup.Host, &backup.ContainerID, &backup.ContainerName, &scheduleID, &backup.MountType,
+		&backup.Source, &backup.Destination, &backup.Mode, &backup.File, &backup.Size, &backup.Checksum, &backup.DurationMs,
+		&backup.Status, &backup.Error, &backup.CreatedAt, &finishedAt); err != nil {
+		return nil, err
+	}
+
+	if scheduleID.Valid {
+		backup.ScheduleID = &scheduleID.Int64
+	}
+	if finishedAt.Valid {
+		backup.FinishedAt = &finishedAt.Time
+	}
+
+	return &backup, nil
+}
+
+func scanBackupSchedule(row scanner) (*models.BackupSchedule, error) {
+	var (
+		schedule  models.BackupSchedule
+		mounts    string
+		lastRunAt sql.NullTime
+	)
+	if err := row.Scan(&schedule.ID, &schedule.Host, &schedule.Container, &schedule.Cron, &schedule.Timezone, &mounts,
+		&schedule.Mode, &schedule.Retention.KeepLast, &schedule.Retention.KeepDaily, &schedule.Disabled, &lastRunAt,
+		&schedule.LastStatus, &schedule.CreatedAt, &schedule.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(mounts), &schedule.Mounts); err != nil {
+		return nil, err
+	}
+	if lastRunAt.Valid {
+		schedule.LastRunAt = &lastRunAt.Time
+	}
+
+	return &schedule, nil
+}
@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"mineServers/internal/models"
+)
+
+func TestBackupStore_RecordsAndSchedules(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	schedule := &models.BackupSchedule{Host: "local", BackupScheduleDefinition: models.BackupScheduleDefinition{
+		Container: "mc",
+		Cron:      "0 4 * * *",
+		Mounts:    []string{"/data"},
+		Mode:      "pause",
+		Retention: models.RetentionPolicy{KeepLast: 3, KeepDaily: 7},
+	}}
+	if err := db.CreateBackupSchedule(ctx, schedule); err != nil {
+		t.Fatalf("CreateBackupSchedule() error = %v", err)
+	}
+
+	backup := &models.Backup{Host: "local", ContainerID: "abc", ContainerName: "mc", ScheduleID: &schedule.ID, MountType: "volume",
+		Source: "mc-data", Destination: "/data", Mode: "pause", File: "local/mc/a.tar.gz", Status: "running"}
+	if err := db.CreateBackup(ctx, backup); err != nil {
+		t.Fatalf("CreateBackup() error = %v", err)
+	}
+	backup.Status, backup.Size, backup.Checksum, backup.DurationMs = "completed", 42, "sha256:00", 15
+	if err := db.FinishBackup(ctx, backup); err != nil {
+		t.Fatalf("FinishBackup() error = %v", err)
+	}
+
+	got, err := db.GetBackup(ctx, backup.ID)
+	if err != nil {
+		t.Fatalf("GetBackup() error = %v", err)
+	}
+	if got.Status != "completed" || got.Size != 42 || got.FinishedAt == nil || got.ScheduleID == nil || *got.ScheduleID != schedule.ID {
+		t.Errorf("unexpected backup %+v", got)
+	}
+	if backups, err := db.ListBackups(ctx, "local", "abc"); err != nil || len(backups) != 1 {
+		t.Errorf("ListBackups(by id) = %v, %v", backups, err)
+	}
+	if backups, err := db.ListBackups(ctx, "remote", ""); err != nil || len(backups) != 0 {
+		t.Errorf("ListBackups(other host) = %v, %v", backups, err)
+	}
+
+	at := time.Now()
+	if err := db.RecordBackupScheduleRun(ctx, schedule.ID, at, "completed"); err != nil {
+		t.Fatalf("RecordBackupScheduleRun() error = %v", err)
+	}
+	schedule.Cron = "@hourly"
+	schedule.Disabled = true
+	if err := db.UpdateBackupSchedule(ctx, schedule); err != nil {
+		t.Fatalf("UpdateBackupSchedule() error = %v", err)
+	}
+	stored, err := db.GetBackupSchedule(ctx, schedule.ID)
+	if err != nil {
+		t.Fatalf("GetBackupSchedule() error = %v", err)
+	}
+	if stored.Cron != "@hourly" || !stored.Disabled || stored.LastStatus != "completed" || stored.LastRunAt == nil || stored.Mounts[0] != "/data" || stored.Retention.KeepDaily != 7 {
+		t.Errorf("unexpected schedule %+v", stored)
+	}
+
+	// Backups outlive their schedule.
+	if err := db.DeleteBackupSchedule(ctx, schedule.ID); err != nil {
+		t.Fatalf("DeleteBackupSchedule() error = %v", err)
+	}
+	if got, err := db.GetBackup(ctx, backup.ID); err != nil || got.ScheduleID != nil {
+		t.Errorf("GetBackup() after schedule delete = %+v, %v", got, err)
+	}
+
+	if err := db.DeleteBackup(ctx, backup.ID); err != nil {
+		t.Fatalf("DeleteBackup() error = %v", err)
+	}
+	if _, err := db.GetBackup(ctx, backup.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	_ "github.com/joho/godotenv/autoload"
@@ -22,6 +24,16 @@ type Service interface {
 	// Close terminates the database connection.
 	// It returns an error if the connection cannot be closed.
 	Close() error
+
+	HostStore
+	RegistryStore
+	TemplateStore
+	StackStore
+	BackupStore
+	ActionScheduleStore
+	EventStore
+	AuditStore
+	StatsStore
 }
 
 type service struct {
@@ -39,17 +51,55 @@ func New() Service {
 		return dbInstance
 	}
 
-	db, err := sql.Open("sqlite3", dburl)
+	s, err := open(dburl)
 	if err != nil {
-		// This will not be a connection error, but a DSN parse error or
-		// another initialization error.
+		// This will not be a connection error, but a DSN parse error,
+		// a failed migration or another initialization error.
 		log.Fatal(err)
 	}
 
-	dbInstance = &service{
+	dbInstance = s
+	return dbInstance
+}
+
+// Open connects to the SQLite database at dsn and applies the schema.
+// Unlike New, it does not reuse a shared connection.
+func Open(dsn string) (Service, error) {
+	return open(dsn)
+}
+
+func open(dsn string) (*service, error) {
+	if dir := filepath.Dir(dsn); !strings.HasPrefix(dsn, "file:") && dsn != ":memory:" && dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := sql.Open("sqlite3", withDefaultParams(dsn))
+	if err != nil {
+		return nil, err
+	}
+
+	s := &service{
 		db: db,
 	}
-	return dbInstance
+	if err := s.migrate(context.Background()); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to migrate database: %w", err)
+	}
+
+	return s, nil
+}
+
+// withDefaultParams enables foreign keys and waits on locks instead of
+// failing right away when several goroutines write at once.
+func withDefaultParams(dsn string) string {
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+
+	return dsn + sep + "_foreign_keys=on&_busy_timeout=5000"
 }
 
 // Health checks the health of the database connection by pinging the database.
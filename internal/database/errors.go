@@ -0,0 +1,24 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+var (
+	// ErrNotFound is returned when the requested record does not exist.
+	ErrNotFound = errors.New("record not found")
+	// ErrConflict is returned when a record violates a unique constraint.
+	ErrConflict = errors.New("record already exists")
+)
+
+// translateError maps SQLite constraint errors to the package errors.
+func translateError(err error) error {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		return ErrConflict
+	}
+
+	return err
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"mineServers/internal/models"
+)
+
+// EventStore persists the Docker events observed on every host. Events are
+// identified by their host and timestamp in nanoseconds.
+type EventStore interface {
+	// RecordEvent stores an event, ignoring it when it was already stored.
+	RecordEvent(ctx context.Context, event *models.RecordedEvent) error
+	// LastEventID returns the ID of the newest event of the host, 0 when
+	// none was stored.
+	LastEventID(ctx context.Context, host string) (int64, error)
+	// ListContainerEvents returns the events of a container, newest first.
+	ListContainerEvents(ctx context.Context, query *EventQuery) ([]models.RecordedEvent, error)
+	// PruneEvents deletes the events older than before and returns how
+	// many were deleted.
+	PruneEvents(ctx context.Context, before time.Time) (int64, error)
+}
+
+// EventQuery selects the events of the container with the given ID or
+// name. Zero bounds are ignored; From is inclusive, To and Before, an
+// event ID, are exclusive.
+type EventQuery struct {
+	Host        string
+	ContainerID string
+	Name        string
+	From        time.Time
+	To          time.Time
+	Before      int64
+	Limit       int
+}
+
+const eventColumns = `host, time_nano, type, action, actor_id, name, attributes, exit_code, oom_killed`
+
+func (s *service) RecordEvent(ctx context.Context, event *models.RecordedEvent) error {
+	timeNano, err := strconv.ParseInt(event.ID, 10, 64)
+	if err != nil {
+		return err
+	}
+	attributes, err := json.Marshal(event.Attributes)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		`INSERT OR IGNORE INTO events (`+eventColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		event.Host, timeNano, event.Type, event.Action, event.ActorID, event.Name, string(attributes), event.ExitCode,
+		event.OOMKilled,
+	)
+
+	return translateError(err)
+}
+
+func (s *service) LastEventID(ctx context.Context, host string) (int64, error) {
+	var id sql.NullInt64
+	if err := s.db.QueryRowContext(ctx, `SELECT MAX(time_nano) FROM events WHERE host = ?`, host).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id.Int64, nil
+}
+
+func (s *service) ListContainerEvents(ctx context.Context, query *EventQuery) ([]models.RecordedEvent, error) {
+	var from, to int64
+	if !query.From.IsZero() {
+		from = query.From.UnixNano()
+	}
+	if !query.To.IsZero() {
+		to = query.To.UnixNano()
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+eventColumns+` FROM events
+		WHERE host = ? AND type = 'container' AND (actor_id = ? OR name = ?)
+		AND time_nano >= ? AND (? = 0 OR time_nano < ?) AND (? = 0 OR time_nano < ?)
+		ORDER BY time_nano DESC LIMIT ?`,
+		query.Host, query.ContainerID, query.Name, from, to, to, query.Before, query.Before, query.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.RecordedEvent{}
+	for rows.Next() {
+		var (
+			event      models.RecordedEvent
+			timeNano   int64
+			attributes string
+			exitCode   sql.NullInt64
+		)
+		if err := rows.Scan(&event.Host, &timeNano, &event.Type, &event.Action, &event.ActorID, &event.Name, &attributes,
+			&exitCode, &event.OOMKilled); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(attributes), &event.Attributes); err != nil {
+			return nil, err
+		}
+		event.ID = strconv.FormatInt(timeNano, 10)
+		event.Time = time.Unix(0, timeNano).UTC()
+		if exitCode.Valid {
+			code := int(exitCode.Int64)
+			event.ExitCode = &code
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func (s *service) PruneEvents(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM events WHERE time_nano < ?`, before.UnixNano())
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
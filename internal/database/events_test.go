@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"mineServers/internal/models"
+)
+
+func TestEventStore_RecordListAndPrune(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	if id, err := db.LastEventID(ctx, "local"); err != nil || id != 0 {
+		t.Fatalf("LastEventID() on empty store = %d, %v", id, err)
+	}
+
+	start := time.Date(2026, 1, 1, 3, 0, 0, 0, time.UTC)
+	exitCode := 137
+	record := func(offset time.Duration, action, actor, name string, code *int, oom bool) {
+		t.Helper()
+		event := &models.RecordedEvent{
+			Event: models.Event{
+				ID:         strconv.FormatInt(start.Add(offset).UnixNano(), 10),
+				Type:       "container",
+				Action:     action,
+				ActorID:    actor,
+				Name:       name,
+				Attributes: map[string]string{"name": name},
+			},
+			Host:      "local",
+			ExitCode:  code,
+			OOMKilled: oom,
+		}
+		if err := db.RecordEvent(ctx, event); err != nil {
+			t.Fatalf("RecordEvent(%s) error = %v", action, err)
+		}
+	}
+	record(0, "start", "abc", "mc", nil, false)
+	record(time.Minute, "oom", "abc", "mc", nil, true)
+	record(time.Minute+time.Second, "die", "abc", "mc", &exitCode, true)
+	record(time.Minute+time.Second, "die", "abc", "mc", &exitCode, true)
+	record(2*time.Minute, "start", "def", "mc", nil, false)
+	record(3*time.Minute, "start", "xyz", "proxy", nil, false)
+
+	events, err := db.ListContainerEvents(ctx, &EventQuery{Host: "local", ContainerID: "abc", Name: "mc", Limit: 10})
+	if err != nil || len(events) != 4 {
+		t.Fatalf("ListContainerEvents() = %+v, %v", events, err)
+	}
+	if events[0].ActorID != "def" || events[1].Action != "die" || *events[1].ExitCode != 137 || !events[1].OOMKilled || events[1].Attributes["name"] != "mc" {
+		t.Fatalf("events = %+v", events)
+	}
+	if !events[3].Time.Equal(start) || events[3].ExitCode != nil {
+		t.Fatalf("oldest event = %+v", events[3])
+	}
+
+	before, _ := strconv.ParseInt(events[1].ID, 10, 64)
+	page, err := db.ListContainerEvents(ctx, &EventQuery{Host: "local", ContainerID: "abc", Before: before, From: start.Add(time.Second), Limit: 10})
+	if err != nil || len(page) != 1 || page[0].Action != "oom" {
+		t.Fatalf("ListContainerEvents(before, from) = %+v, %v", page, err)
+	}
+	if id, _ := db.LastEventID(ctx, "local"); id != start.Add(3*time.Minute).UnixNano() {
+		t.Fatalf("LastEventID() = %d", id)
+	}
+
+	n, err := db.PruneEvents(ctx, start.Add(90*time.Second))
+	if err != nil || n != 3 {
+		t.Fatalf("PruneEvents() = %d, %v", n, err)
+	}
+}
@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"mineServers/internal/models"
+)
+
+// HostStore persists the Docker endpoints managed by the server.
+type HostStore interface {
+	CreateHost(ctx context.Context, host *models.Host) error
+	GetHost(ctx context.Context, name string) (*models.Host, error)
+	ListHosts(ctx context.Context) ([]models.Host, error)
+	UpdateHost(ctx context.Context, host *models.Host) error
+	DeleteHost(ctx context.Context, name string) error
+}
+
+const hostColumns = `id, name, url, tls_ca_cert, tls_cert, tls_key, created_at, updated_at`
+
+func (s *service) CreateHost(ctx context.Context, host *models.Host) error {
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO hosts (name, url, tls_ca_cert, tls_cert, tls_key, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		host.Name, host.URL, host.TLSCACert, host.TLSCert, host.TLSKey, now, now,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+
+	host.ID, _ = res.LastInsertId()
+	host.CreatedAt = now
+	host.UpdatedAt = now
+
+	return nil
+}
+
+func (s *service) GetHost(ctx context.Context, name string) (*models.Host, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+hostColumns+` FROM hosts WHERE name = ?`, name)
+
+	host, err := scanHost(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+
+	return host, err
+}
+
+func (s *service) ListHosts(ctx context.Context) ([]models.Host, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+hostColumns+` FROM hosts ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hosts := []models.Host{}
+	for rows.Next() {
+		host, err := scanHost(rows)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, *host)
+	}
+
+	return hosts, rows.Err()
+}
+
+// UpdateHost replaces the endpoint configuration of the host with the same name.
+func (s *service) UpdateHost(ctx context.Context, host *models.Host) error {
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE hosts SET url = ?, tls_ca_cert = ?, tls_cert = ?, tls_key = ?, updated_at = ? WHERE name = ?`,
+		host.URL, host.TLSCACert, host.TLSCert, host.TLSKey, now, host.Name,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	host.UpdatedAt = now
+
+	return nil
+}
+
+func (s *service) DeleteHost(ctx context.Context, name string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM hosts WHERE name = ?`, name)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanHost(row scanner) (*models.Host, error) {
+	var host models.Host
+	if err := row.Scan(&host.ID, &host.Name, &host.URL, &host.TLSCACert, &host.TLSCert, &host.TLSKey, &host.CreatedAt, &host.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	return &host, nil
+}
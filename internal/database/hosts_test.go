@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"mineServers/internal/models"
+)
+
+func openTestDB(t *testing.T) Service {
+	t.Helper()
+
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("unable to open database: %s", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func TestHostStore_CRUD(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	host := &models.Host{Name: "build", URL: "tcp://10.0.0.2:2376", TLSCACert: "/certs/ca.pem"}
+	if err := db.CreateHost(ctx, host); err != nil {
+		t.Fatalf("CreateHost() error = %v", err)
+	}
+	if host.ID == 0 || host.CreatedAt.IsZero() {
+		t.Errorf("expected ID and timestamps to be set, got %+v", host)
+	}
+
+	if err := db.CreateHost(ctx, &models.Host{Name: "build", URL: "unix:///var/run/docker.sock"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict for duplicated name, got %v", err)
+	}
+
+	got, err := db.GetHost(ctx, "build")
+	if err != nil {
+		t.Fatalf("GetHost() error = %v", err)
+	}
+	if got.URL != host.URL || got.TLSCACert != host.TLSCACert {
+		t.Errorf("GetHost() = %+v, expected %+v", got, host)
+	}
+
+	got.URL = "tcp://10.0.0.3:2376"
+	if err := db.UpdateHost(ctx, got); err != nil {
+		t.Fatalf("UpdateHost() error = %v", err)
+	}
+
+	hosts, err := db.ListHosts(ctx)
+	if err != nil {
+		t.Fatalf("ListHosts() error = %v", err)
+	}
+	if len(hosts) != 1 || hosts[0].URL != "tcp://10.0.0.3:2376" {
+		t.Errorf("ListHosts() = %+v", hosts)
+	}
+
+	if err := db.DeleteHost(ctx, "build"); err != nil {
+		t.Fatalf("DeleteHost() error = %v", err)
+	}
+	if _, err := db.GetHost(ctx, "build"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+	if err := db.DeleteHost(ctx, "build"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+	}
+}
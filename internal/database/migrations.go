@@ -0,0 +1,172 @@
+package database
+
+import "context"
+
+// schema holds the statements creating every table used by the server.
+// Statements must be idempotent since they run on every start.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS hosts (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		name        TEXT NOT NULL UNIQUE,
+		url         TEXT NOT NULL,
+		tls_ca_cert TEXT NOT NULL DEFAULT '',
+		tls_cert    TEXT NOT NULL DEFAULT '',
+		tls_key     TEXT NOT NULL DEFAULT '',
+		created_at  TIMESTAMP NOT NULL,
+		updated_at  TIMESTAMP NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS registries (
+		id             INTEGER PRIMARY KEY AUTOINCREMENT,
+		server         TEXT NOT NULL UNIQUE,
+		username       TEXT NOT NULL DEFAULT '',
+		password       TEXT NOT NULL DEFAULT '',
+		identity_token TEXT NOT NULL DEFAULT '',
+		created_at     TIMESTAMP NOT NULL,
+		updated_at     TIMESTAMP NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS templates (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		name        TEXT NOT NULL UNIQUE,
+		description TEXT NOT NULL DEFAULT '',
+		parameters  TEXT NOT NULL DEFAULT '[]',
+		spec        TEXT NOT NULL DEFAULT '{}',
+		created_at  TIMESTAMP NOT NULL,
+		updated_at  TIMESTAMP NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS stacks (
+		id         INTEGER PRIMARY KEY AUTOINCREMENT,
+		name       TEXT NOT NULL UNIQUE,
+		host       TEXT NOT NULL,
+		compose    TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS backup_schedules (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		host        TEXT NOT NULL,
+		container   TEXT NOT NULL,
+		cron        TEXT NOT NULL,
+		timezone    TEXT NOT NULL DEFAULT '',
+		mounts      TEXT NOT NULL DEFAULT '[]',
+		mode        TEXT NOT NULL DEFAULT '',
+		keep_last   INTEGER NOT NULL DEFAULT 0,
+		keep_daily  INTEGER NOT NULL DEFAULT 0,
+		disabled    BOOLEAN NOT NULL DEFAULT 0,
+		last_run_at TIMESTAMP,
+		last_status TEXT NOT NULL DEFAULT '',
+		created_at  TIMESTAMP NOT NULL,
+		updated_at  TIMESTAMP NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS backups (
+		id             INTEGER PRIMARY KEY AUTOINCREMENT,
+		host           TEXT NOT NULL,
+		container_id   TEXT NOT NULL,
+		container_name TEXT NOT NULL,
+		schedule_id    INTEGER REFERENCES backup_schedules(id) ON DELETE SET NULL,
+		mount_type     TEXT NOT NULL,
+		source         TEXT NOT NULL,
+		destination    TEXT NOT NULL,
+		mode           TEXT NOT NULL,
+		file           TEXT NOT NULL,
+		size           INTEGER NOT NULL DEFAULT 0,
+		checksum       TEXT NOT NULL DEFAULT '',
+		duration_ms    INTEGER NOT NULL DEFAULT 0,
+		status         TEXT NOT NULL,
+		error          TEXT NOT NULL DEFAULT '',
+		created_at     TIMESTAMP NOT NULL,
+		finished_at    TIMESTAMP
+	)`,
+	`CREATE INDEX IF NOT EXISTS backups_container ON backups (host, container_name, destination)`,
+	`CREATE TABLE IF NOT EXISTS action_schedules (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		host        TEXT NOT NULL,
+		container   TEXT NOT NULL,
+		action      TEXT NOT NULL,
+		cron        TEXT NOT NULL,
+		timezone    TEXT NOT NULL DEFAULT '',
+		exec        TEXT NOT NULL DEFAULT 'null',
+		recreate    TEXT NOT NULL DEFAULT 'null',
+		catch_up    TEXT NOT NULL DEFAULT '',
+		disabled    BOOLEAN NOT NULL DEFAULT 0,
+		last_run_at TIMESTAMP,
+		last_status TEXT NOT NULL DEFAULT '',
+		created_at  TIMESTAMP NOT NULL,
+		updated_at  TIMESTAMP NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS action_schedule_runs (
+		id           INTEGER PRIMARY KEY AUTOINCREMENT,
+		schedule_id  INTEGER NOT NULL REFERENCES action_schedules(id) ON DELETE CASCADE,
+		action       TEXT NOT NULL,
+		triggered_by TEXT NOT NULL,
+		status       TEXT NOT NULL,
+		error        TEXT NOT NULL DEFAULT '',
+		output       TEXT NOT NULL DEFAULT '',
+		started_at   TIMESTAMP NOT NULL,
+		duration_ms  INTEGER NOT NULL DEFAULT 0
+	)`,
+	`CREATE INDEX IF NOT EXISTS action_schedule_runs_schedule ON action_schedule_runs (schedule_id, started_at)`,
+	`CREATE TABLE IF NOT EXISTS events (
+		id         INTEGER PRIMARY KEY AUTOINCREMENT,
+		host       TEXT NOT NULL,
+		time_nano  INTEGER NOT NULL,
+		type       TEXT NOT NULL,
+		action     TEXT NOT NULL,
+		actor_id   TEXT NOT NULL,
+		name       TEXT NOT NULL DEFAULT '',
+		attributes TEXT NOT NULL DEFAULT '{}',
+		exit_code  INTEGER,
+		oom_killed BOOLEAN NOT NULL DEFAULT 0,
+		UNIQUE (host, time_nano, type, action, actor_id)
+	)`,
+	`CREATE INDEX IF NOT EXISTS events_actor ON events (host, actor_id, time_nano)`,
+	`CREATE INDEX IF NOT EXISTS events_name ON events (host, name, time_nano)`,
+	`CREATE INDEX IF NOT EXISTS events_time ON events (time_nano)`,
+	`CREATE TABLE IF NOT EXISTS audit_log (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		time        TIMESTAMP NOT NULL,
+		actor       TEXT NOT NULL,
+		source_ip   TEXT NOT NULL DEFAULT '',
+		method      TEXT NOT NULL,
+		route       TEXT NOT NULL,
+		path        TEXT NOT NULL,
+		target      TEXT NOT NULL DEFAULT '',
+		host        TEXT NOT NULL DEFAULT '',
+		payload     TEXT NOT NULL DEFAULT '',
+		status      INTEGER NOT NULL,
+		result      TEXT NOT NULL,
+		error       TEXT NOT NULL DEFAULT '',
+		duration_ms INTEGER NOT NULL DEFAULT 0
+	)`,
+	`CREATE INDEX IF NOT EXISTS audit_log_time ON audit_log (time)`,
+	`CREATE INDEX IF NOT EXISTS audit_log_target ON audit_log (target)`,
+	`CREATE TABLE IF NOT EXISTS stats_samples (
+		host         TEXT NOT NULL,
+		container_id TEXT NOT NULL,
+		name         TEXT NOT NULL DEFAULT '',
+		resolution   INTEGER NOT NULL,
+		time         INTEGER NOT NULL,
+		count        INTEGER NOT NULL,
+		cpu_avg      REAL NOT NULL DEFAULT 0,
+		cpu_max      REAL NOT NULL DEFAULT 0,
+		mem_avg      REAL NOT NULL DEFAULT 0,
+		mem_max      INTEGER NOT NULL DEFAULT 0,
+		mem_limit    INTEGER NOT NULL DEFAULT 0,
+		net_rx       INTEGER NOT NULL DEFAULT 0,
+		net_tx       INTEGER NOT NULL DEFAULT 0,
+		blk_read     INTEGER NOT NULL DEFAULT 0,
+		blk_write    INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (host, container_id, resolution, time)
+	)`,
+	`CREATE INDEX IF NOT EXISTS stats_samples_name ON stats_samples (host, name, resolution, time)`,
+	`CREATE INDEX IF NOT EXISTS stats_samples_time ON stats_samples (resolution, time)`,
+}
+
+func (s *service) migrate(ctx context.Context) error {
+	for _, stmt := range schema {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
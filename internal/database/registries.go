@@ -0,0 +1,110 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"mineServers/internal/models"
+)
+
+// RegistryStore persists the credentials of private registries, keyed by
+// server. Secrets are stored as given, callers are expected to encrypt them.
+type RegistryStore interface {
+	CreateRegistry(ctx context.Context, registry *models.Registry) error
+	GetRegistry(ctx context.Context, server string) (*models.Registry, error)
+	ListRegistries(ctx context.Context) ([]models.Registry, error)
+	UpdateRegistry(ctx context.Context, registry *models.Registry) error
+	DeleteRegistry(ctx context.Context, server string) error
+}
+
+const registryColumns = `id, server, username, password, identity_token, created_at, updated_at`
+
+func (s *service) CreateRegistry(ctx context.Context, registry *models.Registry) error {
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO registries (server, username, password, identity_token, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		registry.Server, registry.Username, registry.Password, registry.IdentityToken, now, now,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+
+	registry.ID, _ = res.LastInsertId()
+	registry.CreatedAt = now
+	registry.UpdatedAt = now
+
+	return nil
+}
+
+func (s *service) GetRegistry(ctx context.Context, server string) (*models.Registry, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+registryColumns+` FROM registries WHERE server = ?`, server)
+
+	registry, err := scanRegistry(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+
+	return registry, err
+}
+
+func (s *service) ListRegistries(ctx context.Context) ([]models.Registry, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+registryColumns+` FROM registries ORDER BY server`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	registries := []models.Registry{}
+	for rows.Next() {
+		registry, err := scanRegistry(rows)
+		if err != nil {
+			return nil, err
+		}
+		registries = append(registries, *registry)
+	}
+
+	return registries, rows.Err()
+}
+
+// UpdateRegistry replaces the credentials of the registry with the same server.
+func (s *service) UpdateRegistry(ctx context.Context, registry *models.Registry) error {
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE registries SET username = ?, password = ?, identity_token = ?, updated_at = ? WHERE server = ?`,
+		registry.Username, registry.Password, registry.IdentityToken, now, registry.Server,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	registry.UpdatedAt = now
+
+	return nil
+}
+
+func (s *service) DeleteRegistry(ctx context.Context, server string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM registries WHERE server = ?`, server)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func scanRegistry(row scanner) (*models.Registry, error) {
+	var registry models.Registry
+	if err := row.Scan(&registry.ID, &registry.Server, &registry.Username, &registry.Password, &registry.IdentityToken, &registry.CreatedAt, &registry.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	return &registry, nil
+}
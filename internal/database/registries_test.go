@@ -0,0 +1,42 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"mineServers/internal/models"
+)
+
+func TestRegistryStore_CRUD(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	registry := &models.Registry{Server: "ghcr.io", Username: "octocat", Password: "v1:sealed"}
+	if err := db.CreateRegistry(ctx, registry); err != nil {
+		t.Fatalf("CreateRegistry() error = %v", err)
+	}
+	if err := db.CreateRegistry(ctx, &models.Registry{Server: "ghcr.io"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict for duplicated server, got %v", err)
+	}
+
+	registry.Username = "hubot"
+	if err := db.UpdateRegistry(ctx, registry); err != nil {
+		t.Fatalf("UpdateRegistry() error = %v", err)
+	}
+
+	got, err := db.GetRegistry(ctx, "ghcr.io")
+	if err != nil {
+		t.Fatalf("GetRegistry() error = %v", err)
+	}
+	if got.Username != "hubot" || got.Password != "v1:sealed" {
+		t.Errorf("GetRegistry() = %+v", got)
+	}
+
+	if err := db.DeleteRegistry(ctx, "ghcr.io"); err != nil {
+		t.Fatalf("DeleteRegistry() error = %v", err)
+	}
+	if _, err := db.GetRegistry(ctx, "ghcr.io"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"mineServers/internal/models"
+)
+
+// ActionScheduleStore persists the schedules running actions on containers
+// and their run history. Deleting a schedule deletes its history.
+type ActionScheduleStore interface {
+	CreateActionSchedule(ctx context.Context, schedule *models.ActionSchedule) error
+	GetActionSchedule(ctx context.Context, id int64) (*models.ActionSchedule, error)
+	ListActionSchedules(ctx context.Context) ([]models.ActionSchedule, error)
+	UpdateActionSchedule(ctx context.Context, schedule *models.ActionSchedule) error
+	DeleteActionSchedule(ctx context.Context, id int64) error
+	// RecordActionScheduleRun stores a run as the last one of its schedule,
+	// keeping the keep most recent runs of the schedule.
+	RecordActionScheduleRun(ctx context.Context, run *models.ActionScheduleRun, keep int) error
+	// ListActionScheduleRuns returns the runs of a schedule, newest first.
+	ListActionScheduleRuns(ctx context.Context, id int64, limit int) ([]models.ActionScheduleRun, error)
+}
+
+const actionScheduleColumns = `id, host, container, action, cron, timezone, exec, recreate, catch_up, disabled, last_run_at, last_status, created_at, updated_at`
+
+const actionScheduleRunColumns = `id, schedule_id, action, triggered_by, status, error, output, started_at, duration_ms`
+
+func (s *service) CreateActionSchedule(ctx context.Context, schedule *models.ActionSchedule) error {
+	exec, recreate, err := marshalActionOptions(schedule)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO action_schedules (host, container, action, cron, timezone, exec, recreate, catch_up, disabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		schedule.Host, schedule.Container, schedule.Action, schedule.Cron, schedule.Timezone, exec, recreate,
+		schedule.CatchUp, schedule.Disabled, now, now,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+
+	schedule.ID, _ = res.LastInsertId()
+	schedule.CreatedAt = now
+	schedule.UpdatedAt = now
+
+	return nil
+}
+
+func (s *service) GetActionSchedule(ctx context.Context, id int64) (*models.ActionSchedule, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+actionScheduleColumns+` FROM action_schedules WHERE id = ?`, id)
+
+	schedule, err := scanActionSchedule(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+
+	return schedule, err
+}
+
+func (s *service) ListActionSchedules(ctx context.Context) ([]models.ActionSchedule, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+actionScheduleColumns+` FROM action_schedules ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := []models.ActionSchedule{}
+	for rows.Next() {
+		schedule, err := scanActionSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, *schedule)
+	}
+
+	return schedules, rows.Err()
+}
+
+// UpdateActionSchedule replaces the definition of the schedule with the
+// same ID. Its host and run history are kept.
+func (s *service) UpdateActionSchedule(ctx context.Context, schedule *models.ActionSchedule) error {
+	exec, recreate, err := marshalActionOptions(schedule)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE action_schedules SET container = ?, action = ?, cron = ?, timezone = ?, exec = ?, recreate = ?, catch_up = ?, disabled = ?, updated_at = ?
+		WHERE id = ?`,
+		schedule.Container, schedule.Action, schedule.Cron, schedule.Timezone, exec, recreate, schedule.CatchUp,
+		schedule.Disabled, now, schedule.ID,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	schedule.UpdatedAt = now
+
+	return nil
+}
+
+func (s *service) DeleteActionSchedule(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM action_schedules WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *service) RecordActionScheduleRun(ctx context.Context, run *models.ActionScheduleRun, keep int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE action_schedules SET last_run_at = ?, last_status = ? WHERE id = ?`,
+		run.StartedAt.UTC(), run.Status, run.ScheduleID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	res, err = tx.ExecContext(ctx,
+		`INSERT INTO action_schedule_runs (schedule_id, action, triggered_by, status, error, output, started_at, duration_ms)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.ScheduleID, run.Action, run.Trigger, run.Status, run.Error, run.Output, run.StartedAt.UTC(), run.DurationMs,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+	run.ID, _ = res.LastInsertId()
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM action_schedule_runs WHERE schedule_id = ? AND id NOT IN (
+			SELECT id FROM action_schedule_runs WHERE schedule_id = ? ORDER BY started_at DESC, id DESC LIMIT ?
+		)`,
+		run.ScheduleID, run.ScheduleID, keep,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *service) ListActionScheduleRuns(ctx context.Context, id int64, limit int) ([]models.ActionScheduleRun, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+actionScheduleRunColumns+` FROM action_schedule_runs WHERE schedule_id = ? ORDER BY started_at DESC, id DESC LIMIT ?`,
+		id, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []models.ActionScheduleRun{}
+	for rows.Next() {
+		var run models.ActionScheduleRun
+		if err := rows.Scan(&run.ID, &run.ScheduleID, &run.Action, &run.Trigger, &run.Status, &run.Error, &run.Output,
+			&run.StartedAt, &run.DurationMs); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
+
+func marshalActionOptions(schedule *models.ActionSchedule) (string, string, error) {
+	exec, err := json.Marshal(schedule.Exec)
+	if err != nil {
+		return "", "", err
+	}
+	recreate, err := json.Marshal(schedule.Recreate)
+	if err != nil {
+		return "", "", err
+	}
+
+	return string(exec), string(recreate), nil
+}
+
+func scanActionSchedule(row scanner) (*models.ActionSchedule, error) {
+	var (
+		schedule       models.ActionSchedule
+		exec, recreate string
+		lastRunAt      sql.NullTime
+	)
+	if err := row.Scan(&schedule.ID, &schedule.Host, &schedule.Container, &schedule.Action, &schedule.Cron, &schedule.Timezone,
+		&exec, &recreate, &schedule.CatchUp, &schedule.Disabled, &lastRunAt, &schedule.LastStatus, &schedule.CreatedAt,
+		&schedule.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(exec), &schedule.Exec); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(recreate), &schedule.Recreate); err != nil {
+		return nil, err
+	}
+	if lastRunAt.Valid {
+		schedule.LastRunAt = &lastRunAt.Time
+	}
+
+	return &schedule, nil
+}
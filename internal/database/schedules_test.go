@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"mineServers/internal/models"
+)
+
+func TestActionScheduleStore_SchedulesAndRuns(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	schedule := &models.ActionSchedule{Host: "local", ActionScheduleDefinition: models.ActionScheduleDefinition{
+		Container: "mc",
+		Action:    "exec",
+		Cron:      "0 4 * * *",
+		Timezone:  "Europe/Berlin",
+		Exec:      &models.ExecRequest{Cmd: []string{"rcon-cli", "save-all"}, Timeout: "30s"},
+		CatchUp:   "run_once",
+	}}
+	if err := db.CreateActionSchedule(ctx, schedule); err != nil {
+		t.Fatalf("CreateActionSchedule() error = %v", err)
+	}
+
+	start := time.Now().Add(-time.Hour)
+	for i := range 3 {
+		run := &models.ActionScheduleRun{ScheduleID: schedule.ID, Action: "exec", Trigger: "cron", Status: "succeeded",
+			StartedAt: start.Add(time.Duration(i) * time.Minute), DurationMs: int64(i)}
+		if err := db.RecordActionScheduleRun(ctx, run, 2); err != nil {
+			t.Fatalf("RecordActionScheduleRun() error = %v", err)
+		}
+	}
+	runs, err := db.ListActionScheduleRuns(ctx, schedule.ID, 10)
+	if err != nil || len(runs) != 2 || runs[0].DurationMs != 2 || runs[1].DurationMs != 1 {
+		t.Fatalf("ListActionScheduleRuns() = %+v, %v", runs, err)
+	}
+
+	schedule.Action = "restart"
+	schedule.Exec = nil
+	schedule.Disabled = true
+	if err := db.UpdateActionSchedule(ctx, schedule); err != nil {
+		t.Fatalf("UpdateActionSchedule() error = %v", err)
+	}
+	stored, err := db.GetActionSchedule(ctx, schedule.ID)
+	if err != nil {
+		t.Fatalf("GetActionSchedule() error = %v", err)
+	}
+	if stored.Action != "restart" || stored.Exec != nil || !stored.Disabled || stored.CatchUp != "run_once" ||
+		stored.LastStatus != "succeeded" || stored.LastRunAt == nil || !stored.LastRunAt.Equal(start.Add(2*time.Minute).UTC()) {
+		t.Errorf("unexpected schedule %+v", stored)
+	}
+	if schedules, err := db.ListActionSchedules(ctx); err != nil || len(schedules) != 1 {
+		t.Errorf("ListActionSchedules() = %v, %v", schedules, err)
+	}
+
+	// The history goes with its schedule.
+	if err := db.DeleteActionSchedule(ctx, schedule.ID); err != nil {
+		t.Fatalf("DeleteActionSchedule() error = %v", err)
+	}
+	if runs, err := db.ListActionScheduleRuns(ctx, schedule.ID, 10); err != nil || len(runs) != 0 {
+		t.Errorf("ListActionScheduleRuns() after delete = %+v, %v", runs, err)
+	}
+	if _, err := db.GetActionSchedule(ctx, schedule.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+	run := &models.ActionScheduleRun{ScheduleID: schedule.ID, Action: "restart", Trigger: "manual", Status: "succeeded", StartedAt: time.Now()}
+	if err := db.RecordActionScheduleRun(ctx, run, 2); !errors.Is(err, ErrNotFound) {
+		t.Errorf("RecordActionScheduleRun(deleted) error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"mineServers/internal/models"
+)
+
+// StackStore persists the compose files deployed as stacks. Stack names
+// are unique across hosts since they are used as compose project names.
+type StackStore interface {
+	CreateStack(ctx context.Context, stack *models.Stack) error
+	GetStack(ctx context.Context, name string) (*models.Stack, error)
+	ListStacks(ctx context.Context) ([]models.Stack, error)
+	UpdateStack(ctx context.Context, stack *models.Stack) error
+	DeleteStack(ctx context.Context, name string) error
+}
+
+const stackColumns = `id, name, host, compose, created_at, updated_at`
+
+func (s *service) CreateStack(ctx context.Context, stack *models.Stack) error {
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO stacks (name, host, compose, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+		stack.Name, stack.Host, stack.Compose, now, now,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+
+	stack.ID, _ = res.LastInsertId()
+	stack.CreatedAt = now
+	stack.UpdatedAt = now
+
+	return nil
+}
+
+func (s *service) GetStack(ctx context.Context, name string) (*models.Stack, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+stackColumns+` FROM stacks WHERE name = ?`, name)
+
+	stack, err := scanStack(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+
+	return stack, err
+}
+
+func (s *service) ListStacks(ctx context.Context) ([]models.Stack, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+stackColumns+` FROM stacks ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stacks := []models.Stack{}
+	for rows.Next() {
+		stack, err := scanStack(rows)
+		if err != nil {
+			return nil, err
+		}
+		stacks = append(stacks, *stack)
+	}
+
+	return stacks, rows.Err()
+}
+
+// UpdateStack replaces the compose file of the stack with the same name.
+// The host of a stack cannot change.
+func (s *service) UpdateStack(ctx context.Context, stack *models.Stack) error {
+	now := time.Now().UTC()
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE stacks SET compose = ?, updated_at = ? WHERE name = ?`,
+		stack.Compose, now, stack.Name,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	stack.UpdatedAt = now
+
+	return nil
+}
+
+func (s *service) DeleteStack(ctx context.Context, name string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM stacks WHERE name = ?`, name)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func scanStack(row scanner) (*models.Stack, error) {
+	var stack models.Stack
+	if err := row.Scan(&stack.ID, &stack.Name, &stack.Host, &stack.Compose, &stack.CreatedAt, &stack.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	return &stack, nil
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"mineServers/internal/models"
+)
+
+func TestStackStore_CRUD(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	stack := &models.Stack{Name: "survival", Host: "local", Compose: "services: {}\n"}
+	if err := db.CreateStack(ctx, stack); err != nil {
+		t.Fatalf("CreateStack() error = %v", err)
+	}
+	if err := db.CreateStack(ctx, &models.Stack{Name: "survival", Host: "remote"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict for duplicated name, got %v", err)
+	}
+
+	stack.Compose = "services:\n  web:\n    image: nginx\n"
+	stack.Host = "remote"
+	if err := db.UpdateStack(ctx, stack); err != nil {
+		t.Fatalf("UpdateStack() error = %v", err)
+	}
+	got, err := db.GetStack(ctx, "survival")
+	if err != nil {
+		t.Fatalf("GetStack() error = %v", err)
+	}
+	if got.Compose != stack.Compose || got.Host != "local" {
+		t.Errorf("expected the compose file to change but not the host, got %+v", got)
+	}
+
+	if stacks, err := db.ListStacks(ctx); err != nil || len(stacks) != 1 {
+		t.Errorf("ListStacks() = %v, %v", stacks, err)
+	}
+
+	if err := db.DeleteStack(ctx, "survival"); err != nil {
+		t.Fatalf("DeleteStack() error = %v", err)
+	}
+	if err := db.DeleteStack(ctx, "survival"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"mineServers/internal/models"
+)
+
+// StatsStore persists the stats samples of the containers at several
+// resolutions: the raw samples and their rollups, such as one per minute.
+type StatsStore interface {
+	RecordStatsSamples(ctx context.Context, samples []models.StatsSample) error
+	// RollupStats aggregates the rows of the source resolution between
+	// from and to into buckets of the given resolution, replacing the
+	// buckets already rolled up.
+	RollupStats(ctx context.Context, source, resolution time.Duration, from, to time.Time) error
+	// ListStatsPoints aggregates the rows of a container into points of
+	// the query step, oldest first.
+	ListStatsPoints(ctx context.Context, query *StatsQuery) ([]models.StatsPoint, error)
+	// PruneStats deletes the rows of the resolution older than before and
+	// returns how many were deleted.
+	PruneStats(ctx context.Context, resolution time.Duration, before time.Time) (int64, error)
+}
+
+// StatsQuery selects the rows of a resolution of the container with the
+// given ID or name between From, inclusive, and To, exclusive.
+type StatsQuery struct {
+	Host        string
+	ContainerID string
+	Name        string
+	Resolution  time.Duration
+	From        time.Time
+	To          time.Time
+	Step        time.Duration
+}
+
+func (s *service) RecordStatsSamples(ctx context.Context, samples []models.StatsSample) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx,
+		`INSERT OR REPLACE INTO stats_samples (host, container_id, name, resolution, time, count, cpu_avg, cpu_max, mem_avg, mem_max, mem_limit, net_rx, net_tx, blk_read, blk_write)
+		VALUES (?, ?, ?, 0, ?, 1, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, sample := range samples {
+		if _, err := stmt.ExecContext(ctx,
+			sample.Host, sample.ContainerID, sample.Name, sample.Time.Unix(), sample.CPUPercent, sample.CPUPercent,
+			sample.MemUsage, sample.MemUsage, sample.MemLimit, sample.NetRx, sample.NetTx, sample.BlockRead, sample.BlockWrite,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *service) RollupStats(ctx context.Context, source, resolution time.Duration, from, to time.Time) error {
+	step := int64(resolution / time.Second)
+	_, err := s.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO stats_samples (host, container_id, name, resolution, time, count, cpu_avg, cpu_max, mem_avg, mem_max, mem_limit, net_rx, net_tx, blk_read, blk_write)
+		SELECT host, container_id, MAX(name), ?, (time / ?) * ?, SUM(count), SUM(cpu_avg * count) / SUM(count), MAX(cpu_max),
+			SUM(mem_avg * count) / SUM(count), MAX(mem_max), MAX(mem_limit), MAX(net_rx), MAX(net_tx), MAX(blk_read), MAX(blk_write)
+		FROM stats_samples WHERE resolution = ? AND time >= ? AND time < ?
+		GROUP BY host, container_id, time / ?`,
+		step, step, step, int64(source/time.Second), from.Unix()/step*step, to.Unix(), step,
+	)
+
+	return err
+}
+
+func (s *service) ListStatsPoints(ctx context.Context, query *StatsQuery) ([]models.StatsPoint, error) {
+	step := int64(query.Step / time.Second)
+	// Samples are stored by the second, the one of to is only included when
+	// to is past its start.
+	to := query.To.Add(time.Second - 1).Unix()
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT (time / ?) * ?, SUM(cpu_avg * count) / SUM(count), MAX(cpu_max), SUM(mem_avg * count) / SUM(count), MAX(mem_max),
+			MAX(mem_limit), MAX(net_rx), MAX(net_tx), MAX(blk_read), MAX(blk_write)
+		FROM stats_samples
+		WHERE host = ? AND (container_id = ? OR name = ?) AND resolution = ? AND time >= ? AND time < ?
+		GROUP BY time / ? ORDER BY time / ?`,
+		step, step, query.Host, query.ContainerID, query.Name, int64(query.Resolution/time.Second), query.From.Unix(),
+		to, step, step,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := []models.StatsPoint{}
+	for rows.Next() {
+		var (
+			point            models.StatsPoint
+			at               int64
+			memUsage, memMax float64
+		)
+		if err := rows.Scan(&at, &point.CPUPercent, &point.CPUMax, &memUsage, &memMax, &point.MemLimit, &point.NetRx,
+			&point.NetTx, &point.BlockRead, &point.BlockWrite); err != nil {
+			return nil, err
+		}
+		point.Time = time.Unix(at, 0).UTC()
+		point.MemUsage = uint64(memUsage)
+		point.MemMax = uint64(memMax)
+		points = append(points, point)
+	}
+
+	return points, rows.Err()
+}
+
+func (s *service) PruneStats(ctx context.Context, resolution time.Duration, before time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx,
+		`DELETE FROM stats_samples WHERE resolution = ? AND time < ?`,
+		int64(resolution/time.Second), before.Unix(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
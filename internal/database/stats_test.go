@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mineServers/internal/models"
+)
+
+func TestStatsStore_RollupAndList(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	start := time.Date(2026, 1, 1, 3, 0, 0, 0, time.UTC)
+	var samples []models.StatsSample
+	for i := range 12 {
+		samples = append(samples, models.StatsSample{
+			Host: "local", ContainerID: "abc", Name: "mc", Time: start.Add(time.Duration(i) * 10 * time.Second),
+			CPUPercent: float64(i), MemUsage: uint64(100 + i), MemLimit: 1000, NetRx: uint64(i * 10),
+		})
+	}
+	if err := db.RecordStatsSamples(ctx, samples); err != nil {
+		t.Fatalf("RecordStatsSamples() error = %v", err)
+	}
+
+	raw, err := db.ListStatsPoints(ctx, &StatsQuery{Host: "local", ContainerID: "abc", From: start, To: start.Add(time.Hour), Step: 30 * time.Second})
+	if err != nil || len(raw) != 4 || raw[0].CPUPercent != 1 || raw[0].CPUMax != 2 || raw[3].NetRx != 110 {
+		t.Fatalf("ListStatsPoints(raw) = %+v, %v", raw, err)
+	}
+
+	if err := db.RollupStats(ctx, 0, time.Minute, start, start.Add(time.Hour)); err != nil {
+		t.Fatalf("RollupStats(1m) error = %v", err)
+	}
+	if err := db.RollupStats(ctx, time.Minute, time.Hour, start, start.Add(time.Hour)); err != nil {
+		t.Fatalf("RollupStats(1h) error = %v", err)
+	}
+	minutes, err := db.ListStatsPoints(ctx, &StatsQuery{Host: "local", Name: "mc", Resolution: time.Minute, From: start, To: start.Add(time.Hour), Step: time.Minute})
+	if err != nil || len(minutes) != 2 || minutes[0].CPUPercent != 2.5 || minutes[1].CPUMax != 11 || minutes[1].MemMax != 111 {
+		t.Fatalf("ListStatsPoints(1m) = %+v, %v", minutes, err)
+	}
+	hours, err := db.ListStatsPoints(ctx, &StatsQuery{Host: "local", ContainerID: "abc", Resolution: time.Hour, From: start, To: start.Add(time.Hour), Step: time.Hour})
+	if err != nil || len(hours) != 1 || hours[0].CPUPercent != 5.5 || hours[0].MemLimit != 1000 || !hours[0].Time.Equal(start) {
+		t.Fatalf("ListStatsPoints(1h) = %+v, %v", hours, err)
+	}
+
+	n, err := db.PruneStats(ctx, 0, start.Add(time.Minute))
+	if err != nil || n != 6 {
+		t.Fatalf("PruneStats() = %d, %v", n, err)
+	}
+}
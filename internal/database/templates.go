@@ -0,0 +1,193 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"mineServers/internal/models"
+)
+
+// TemplateStore persists container templates. Parameters and spec are
+// stored as JSON documents.
+type TemplateStore interface {
+	CreateTemplate(ctx context.Context, template *models.Template) error
+	GetTemplate(ctx context.Context, id int64) (*models.Template, error)
+	GetTemplateByName(ctx context.Context, name string) (*models.Template, error)
+	ListTemplates(ctx context.Context) ([]models.Template, error)
+	UpdateTemplate(ctx context.Context, template *models.Template) error
+	// SaveTemplates creates the templates without an ID and updates the
+	// others in one transaction, storing all of them or none.
+	SaveTemplates(ctx context.Context, templates []*models.Template) error
+	DeleteTemplate(ctx context.Context, id int64) error
+}
+
+// execer runs statements on the database or within a transaction.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+const templateColumns = `id, name, description, parameters, spec, created_at, updated_at`
+
+func (s *service) CreateTemplate(ctx context.Context, template *models.Template) error {
+	return createTemplate(ctx, s.db, template)
+}
+
+func createTemplate(ctx context.Context, db execer, template *models.Template) error {
+	parameters, spec, err := marshalTemplate(template)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	res, err := db.ExecContext(ctx,
+		`INSERT INTO templates (name, description, parameters, spec, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		template.Name, template.Description, parameters, spec, now, now,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+
+	template.ID, _ = res.LastInsertId()
+	template.CreatedAt = now
+	template.UpdatedAt = now
+
+	return nil
+}
+
+func (s *service) GetTemplate(ctx context.Context, id int64) (*models.Template, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+templateColumns+` FROM templates WHERE id = ?`, id)
+
+	template, err := scanTemplate(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+
+	return template, err
+}
+
+func (s *service) GetTemplateByName(ctx context.Context, name string) (*models.Template, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+templateColumns+` FROM templates WHERE name = ?`, name)
+
+	template, err := scanTemplate(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+
+	return template, err
+}
+
+func (s *service) ListTemplates(ctx context.Context) ([]models.Template, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+templateColumns+` FROM templates ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []models.Template{}
+	for rows.Next() {
+		template, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *template)
+	}
+
+	return templates, rows.Err()
+}
+
+// UpdateTemplate replaces the template with the same ID.
+func (s *service) UpdateTemplate(ctx context.Context, template *models.Template) error {
+	return updateTemplate(ctx, s.db, template)
+}
+
+func updateTemplate(ctx context.Context, db execer, template *models.Template) error {
+	parameters, spec, err := marshalTemplate(template)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	res, err := db.ExecContext(ctx,
+		`UPDATE templates SET name = ?, description = ?, parameters = ?, spec = ?, updated_at = ? WHERE id = ?`,
+		template.Name, template.Description, parameters, spec, now, template.ID,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	template.UpdatedAt = now
+
+	return nil
+}
+
+func (s *service) SaveTemplates(ctx context.Context, templates []*models.Template) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, template := range templates {
+		if template.ID != 0 {
+			err = updateTemplate(ctx, tx, template)
+		} else {
+			err = createTemplate(ctx, tx, template)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *service) DeleteTemplate(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM templates WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func marshalTemplate(template *models.Template) (string, string, error) {
+	parameters, err := json.Marshal(template.Parameters)
+	if err != nil {
+		return "", "", err
+	}
+	spec, err := json.Marshal(template.Spec)
+	if err != nil {
+		return "", "", err
+	}
+
+	return string(parameters), string(spec), nil
+}
+
+func scanTemplate(row scanner) (*models.Template, error) {
+	var (
+		template         models.Template
+		parameters, spec string
+	)
+	if err := row.Scan(&template.ID, &template.Name, &template.Description, &parameters, &spec, &template.CreatedAt, &template.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(parameters), &template.Parameters); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(spec), &template.Spec); err != nil {
+		return nil, err
+	}
+
+	return &template, nil
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"mineServers/internal/models"
+)
+
+func TestTemplateStore_CRUD(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	template := &models.Template{TemplateDefinition: models.TemplateDefinition{
+		Name:       "paper",
+		Parameters: []models.TemplateParameter{{Name: "MEMORY", Default: "2G"}},
+		Spec:       map[string]any{"image": "itzg/minecraft-server", "env": map[string]any{"MEMORY": "${MEMORY}"}},
+	}}
+	if err := db.CreateTemplate(ctx, template); err != nil {
+		t.Fatalf("CreateTemplate() error = %v", err)
+	}
+	if err := db.CreateTemplate(ctx, &models.Template{TemplateDefinition: models.TemplateDefinition{Name: "paper"}}); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict for duplicated name, got %v", err)
+	}
+
+	got, err := db.GetTemplate(ctx, template.ID)
+	if err != nil {
+		t.Fatalf("GetTemplate() error = %v", err)
+	}
+	env, _ := got.Spec["env"].(map[string]any)
+	if len(got.Parameters) != 1 || env["MEMORY"] != "${MEMORY}" {
+		t.Errorf("GetTemplate() = %+v", got)
+	}
+
+	got.Description = "Paper server"
+	if err := db.UpdateTemplate(ctx, got); err != nil {
+		t.Fatalf("UpdateTemplate() error = %v", err)
+	}
+	if byName, err := db.GetTemplateByName(ctx, "paper"); err != nil || byName.Description != "Paper server" {
+		t.Errorf("GetTemplateByName() = %+v, %v", byName, err)
+	}
+
+	if err := db.DeleteTemplate(ctx, template.ID); err != nil {
+		t.Fatalf("DeleteTemplate() error = %v", err)
+	}
+	if _, err := db.GetTemplate(ctx, template.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestTemplateStore_SaveTemplatesIsAtomic(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	paper := &models.Template{TemplateDefinition: models.TemplateDefinition{Name: "paper"}}
+	if err := db.CreateTemplate(ctx, paper); err != nil {
+		t.Fatalf("CreateTemplate() error = %v", err)
+	}
+
+	err := db.SaveTemplates(ctx, []*models.Template{
+		{TemplateDefinition: models.TemplateDefinition{Name: "vanilla"}},
+		{TemplateDefinition: models.TemplateDefinition{Name: "paper"}},
+	})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict for duplicated name, got %v", err)
+	}
+	if _, err := db.GetTemplateByName(ctx, "vanilla"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the failed save to store nothing, got %v", err)
+	}
+
+	vanilla := &models.Template{TemplateDefinition: models.TemplateDefinition{Name: "vanilla"}}
+	paper.Description = "Paper server"
+	if err := db.SaveTemplates(ctx, []*models.Template{vanilla, paper}); err != nil {
+		t.Fatalf("SaveTemplates() error = %v", err)
+	}
+	if vanilla.ID == 0 {
+		t.Errorf("expected the created template to get an ID")
+	}
+	if got, err := db.GetTemplateByName(ctx, "paper"); err != nil || got.Description != "Paper server" {
+		t.Errorf("GetTemplateByName() = %+v, %v", got, err)
+	}
+}
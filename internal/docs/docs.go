@@ -15,29 +15,197 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/containers": {
+        "/audit": {
             "get": {
-                "description": "Get a list of all Docker containers",
-                "consumes": [
+                "description": "List the mutating API calls, such as creations, deletions, state changes, exec commands and file uploads, newest first.\nEntries record the actor, taken from the X-Forwarded-User or X-Remote-User headers set by an authenticating proxy listed\nin TRUSTED_PROXIES or from basic auth, the source IP, the payload with its secrets redacted, the target, the result and the error.\nPass the next cursor as before to get the following page.",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "List the audit log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Actor",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "POST",
+                            "PUT",
+                            "PATCH",
+                            "DELETE",
+                            "GET"
+                        ],
+                        "type": "string",
+                        "description": "HTTP method",
+                        "name": "method",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Target, such as a container name",
+                        "name": "target",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name",
+                        "name": "host",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "success",
+                            "failure"
+                        ],
+                        "type": "string",
+                        "description": "Result",
+                        "name": "result",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only calls at or after this RFC 3339 time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only calls before this RFC 3339 time",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Next cursor of the previous page",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of entries, defaults to 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AuditPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/audit/export": {
+            "get": {
+                "description": "Download every entry of the audit log matching the filters, newest first, as CSV or JSON",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "tags": [
-                    "containers"
+                    "audit"
+                ],
+                "summary": "Export the audit log",
+                "parameters": [
+                    {
+                        "enum": [
+                            "json",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "description": "Export format, defaults to json",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Actor",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "POST",
+                            "PUT",
+                            "PATCH",
+                            "DELETE",
+                            "GET"
+                        ],
+                        "type": "string",
+                        "description": "HTTP method",
+                        "name": "method",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Target, such as a container name",
+                        "name": "target",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name",
+                        "name": "host",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "success",
+                            "failure"
+                        ],
+                        "type": "string",
+                        "description": "Result",
+                        "name": "result",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only calls at or after this RFC 3339 time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only calls before this RFC 3339 time",
+                        "name": "to",
+                        "in": "query"
+                    }
                 ],
-                "summary": "List all containers",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Container"
+                                "$ref": "#/definitions/models.AuditEntry"
                             }
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -45,42 +213,70 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Create a new Docker container with specified configuration",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/backups": {
+            "get": {
+                "description": "List backups, newest first, optionally of a host and container",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "containers"
+                    "backups"
                 ],
-                "summary": "Create a new container",
+                "summary": "List backups",
                 "parameters": [
                     {
-                        "description": "Container Configuration",
+                        "type": "string",
+                        "description": "Docker host name",
+                        "name": "host",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Container name or ID",
                         "name": "container",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.CreateOptions"
-                        }
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Container"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Backup"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    }
+                }
+            }
+        },
+        "/backups/schedules": {
+            "get": {
+                "description": "List the backup schedules with their last and next runs",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "backups"
+                ],
+                "summary": "List backup schedules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.BackupSchedule"
+                            }
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
@@ -89,11 +285,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/containers/{id}": {
-            "delete": {
-                "description": "Delete a Docker container by ID",
+            },
+            "post": {
+                "description": "Back up a container periodically. After every run the retention policy prunes the older backups of each mount.",
                 "consumes": [
                     "application/json"
                 ],
@@ -101,23 +295,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "containers"
+                    "backups"
                 ],
-                "summary": "Delete a container",
+                "summary": "Create a backup schedule",
                 "parameters": [
+                    {
+                        "description": "Schedule",
+                        "name": "schedule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BackupScheduleDefinition"
+                        }
+                    },
                     {
                         "type": "string",
-                        "description": "Container ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.BackupSchedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "404": {
@@ -135,23 +343,20 @@ const docTemplate = `{
                 }
             }
         },
-        "/containers/{id}/logs": {
+        "/backups/schedules/{id}": {
             "get": {
-                "description": "Stream logs from a Docker container",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get a backup schedule by ID",
                 "produces": [
-                    "text/event-stream"
+                    "application/json"
                 ],
                 "tags": [
-                    "containers"
+                    "backups"
                 ],
-                "summary": "Get container logs",
+                "summary": "Get a backup schedule",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Container ID",
+                        "type": "integer",
+                        "description": "Schedule ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -159,9 +364,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Server-Sent Events",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.BackupSchedule"
                         }
                     },
                     "404": {
@@ -177,11 +382,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/containers/{id}/start": {
-            "post": {
-                "description": "Start a Docker container by ID",
+            },
+            "put": {
+                "description": "Replace the definition of a backup schedule. Its host and run history are kept.",
                 "consumes": [
                     "application/json"
                 ],
@@ -189,23 +392,38 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "containers"
+                    "backups"
                 ],
-                "summary": "Start a container",
+                "summary": "Update a backup schedule",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Container ID",
+                        "type": "integer",
+                        "description": "Schedule ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Schedule",
+                        "name": "schedule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BackupScheduleDefinition"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "$ref": "#/definitions/models.BackupSchedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "404": {
@@ -221,36 +439,25 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/containers/{id}/stats": {
-            "get": {
-                "description": "Stream stats from a Docker container",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "text/event-stream"
-                ],
+            },
+            "delete": {
+                "description": "Delete a backup schedule. The backups it made are kept.",
                 "tags": [
-                    "containers"
+                    "backups"
                 ],
-                "summary": "Get container stats",
+                "summary": "Delete a backup schedule",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Container ID",
+                        "type": "integer",
+                        "description": "Schedule ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Server-Sent Events",
-                        "schema": {
-                            "type": "string"
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
                     "404": {
                         "description": "Not Found",
@@ -267,33 +474,33 @@ const docTemplate = `{
                 }
             }
         },
-        "/containers/{id}/stop": {
+        "/backups/schedules/{id}/run": {
             "post": {
-                "description": "Stop a Docker container by ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Run a backup schedule right away, then apply its retention policy",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "containers"
+                    "backups"
                 ],
-                "summary": "Stop a container",
+                "summary": "Run a backup schedule",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Container ID",
+                        "type": "integer",
+                        "description": "Schedule ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Backup"
+                            }
                         }
                     },
                     "404": {
@@ -302,6 +509,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -310,113 +523,6330 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handlers.CreateOptions": {
-            "type": "object",
-            "properties": {
-                "commands": {
+        },
+        "/backups/{id}": {
+            "get": {
+                "description": "Get a backup by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "backups"
+                ],
+                "summary": "Get a backup",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Backup ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Backup"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a backup and its archive",
+                "tags": [
+                    "backups"
+                ],
+                "summary": "Delete a backup",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Backup ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/backups/{id}/download": {
+            "get": {
+                "description": "Download the gzip compressed tar archive of a completed backup",
+                "produces": [
+                    "application/gzip"
+                ],
+                "tags": [
+                    "backups"
+                ],
+                "summary": "Download a backup",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Backup ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/backups/{id}/restore": {
+            "post": {
+                "description": "Extract a backup into the mount it was taken from, found through the container ID or name, or into a named volume,\ncreated when missing. Files missing from the backup are kept.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "backups"
+                ],
+                "summary": "Restore a backup",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Backup ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Target volume and mode",
+                        "name": "restore",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.RestoreRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RestoreResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/containers": {
+            "get": {
+                "description": "Get a list of all Docker containers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "List all containers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Container"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new Docker container with specified configuration.\nThe image is given either as a full reference or through registry, image and version (a tag or a digest).\nIt is pulled as a background job following pull_policy (if-not-present by default): the request waits for it,\nunless async=true returns the job right away and the container is created once the pull completes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "Create a new container",
+                "parameters": [
+                    {
+                        "description": "Container Configuration",
+                        "name": "container",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateOptions"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return the pull job instead of waiting for the creation",
+                        "name": "async",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Container"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.PullJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/{id}": {
+            "delete": {
+                "description": "Delete a Docker container by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "Delete a container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Change the memory, swap, CPU and pids limits and the restart policy of a container without recreating it.\nOnly the fields that are set are changed. The values are checked against the capacity of the host and the\neffective limits are returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "Update container limits",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Limits to change",
+                        "name": "update",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ResourceUpdate"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ContainerResources"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/{id}/attach": {
+            "get": {
+                "description": "Upgrade to a WebSocket attached to the main process of a running container, such as the console of a\ngame server. Its stdout and stderr are sent as binary frames. Clients send lines to its stdin as binary\nframes or as {\"type\":\"input\",\"data\":\"say hello\\n\"}, which requires a container created with stdin open.\nObservers attach with readonly=true. {\"type\":\"detach\"} or closing the socket detaches and leaves the\nprocess running, when it exits the server sends {\"type\":\"exit\",\"exit_code\":0} and closes the socket.",
+                "tags": [
+                    "containers"
+                ],
+                "summary": "Attach to a container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only receive the output",
+                        "name": "readonly",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/{id}/backups": {
+            "post": {
+                "description": "Archive volumes and bind mounts of a container into compressed tar files, one backup per mount.\nThe container can be paused or stopped meanwhile for consistency; it is brought back afterwards.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "backups"
+                ],
+                "summary": "Back up a container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID or name",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Mounts and mode",
+                        "name": "backup",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.BackupRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Backup"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/{id}/clone": {
+            "post": {
+                "description": "Create a copy of a container with the same image, configuration, host configuration and networks.\nEnv and labels are merged into the ones of the source and ports replace its bindings. Host ports already\nin use are remapped to the next free port, or rejected with port_conflict=reject. Compose labels, static IPs, network aliases\nand the MAC address are not copied. A copy that fails to start is removed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "Clone a container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Overrides",
+                        "name": "clone",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.CloneRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.CloneResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/{id}/commit": {
+            "post": {
+                "description": "Snapshot the current state of a container into a new image, optionally tagged, with a message and\nDockerfile changes (CMD, ENTRYPOINT, ENV, EXPOSE, LABEL, ONBUILD, STOPSIGNAL, USER, VOLUME, WORKDIR)\napplied to its config. The container is paused while committed unless pause is false.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "Commit a container to an image",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Image to create",
+                        "name": "commit",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CommitRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImageSnapshot"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/{id}/exec": {
+            "post": {
+                "description": "Run a command inside a running container and return its exit code with its stdout and stderr. The\ncommand stops being waited for after the timeout (30s by default, at most 10m) and each output is\ncapped by max_output (1m by default, at most 16m).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "Run a command in a container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Command",
+                        "name": "exec",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ExecRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ExecResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/{id}/export": {
+            "get": {
+                "description": "Stream the filesystem of a container as a tar archive. Volumes are not included.",
+                "produces": [
+                    "application/x-tar"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "Export a container filesystem",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/{id}/files": {
+            "get": {
+                "description": "List the entries of a directory inside a container, directories first. Works on stopped containers.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "List a directory of a container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Absolute path of the directory, defaults to /",
+                        "name": "path",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DirectoryListing"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/{id}/files/content": {
+            "get": {
+                "description": "Read a text file of up to 1MB inside a container. Works on stopped containers.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "Read a text file of a container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Absolute path of the file",
+                        "name": "path",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.FileContent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Create or overwrite a text file of up to 1MB inside a container, keeping the mode and owner of an\nexisting file. The parent directory must exist.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "Write a text file of a container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Path and content of the file",
+                        "name": "file",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.FileContent"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.FileContent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/{id}/files/download": {
+            "get": {
+                "description": "Download a file or a directory of a container as a tar (default) or zip archive. Works on stopped\ncontainers.",
+                "produces": [
+                    "application/x-tar",
+                    "application/zip"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "Download files from a container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Absolute path of the file or directory",
+                        "name": "path",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "tar",
+                            "zip"
+                        ],
+                        "type": "string",
+                        "description": "Archive format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/{id}/files/upload": {
+            "post": {
+                "description": "Upload files into an existing directory of a container, overwriting files with the same name. With\nextract set, .tar, .tar.gz, .tgz and .zip archives are extracted into the directory instead.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "Upload files into a container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Absolute path of the destination directory",
+                        "name": "path",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Extract archives into the directory",
+                        "name": "extract",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Files to upload",
+                        "name": "files",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UploadResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/{id}/kill": {
+            "post": {
+                "description": "Send a signal to the main process of a container, SIGKILL by default. Signals such as SIGHUP leave it\nrunning, for instance to reload its configuration.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "Kill a container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Signal",
+                        "name": "options",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.StateChange"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StateTransition"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/{id}/logs": {
+            "get": {
+                "description": "Stream logs from a Docker container",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "Get container logs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Server-Sent Events",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/{id}/pause": {
+            "post": {
+                "description": "Suspend every process of a running container.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "Pause a container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StateTransition"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/{id}/recreate": {
+            "post": {
+                "description": "Pull a new image for a container, a full reference or another tag of its image, or by default its current tag again,\nand replace the container with one created from the same configuration, mounts, networks and name.\nThe new container is started and, when it has a healthcheck, must become healthy within the timeout; otherwise the old\ncontainer is restored. The response reports every step.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "Recreate a container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Image to recreate the container with",
+                        "name": "recreate",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.RecreateRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RecreateResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Details hold the models.RecreateResult",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/{id}/rename": {
+            "post": {
+                "description": "Give a container a new name.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "Rename a container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New name",
+                        "name": "rename",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RenameRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StateTransition"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/{id}/restart": {
+            "post": {
+                "description": "Restart a Docker container by ID. The timeout is the number of seconds to wait before killing it, defaults\nto 10 and -1 waits forever. The signal replaces the stop signal of the container.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "Restart a container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Timeout and signal",
+                        "name": "options",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.StateChange"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StateTransition"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/{id}/start": {
+            "post": {
+                "description": "Start a Docker container by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "Start a container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/{id}/stats": {
+            "get": {
+                "description": "Stream stats from a Docker container",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "Get container stats",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Server-Sent Events",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/{id}/stats/history": {
+            "get": {
+                "description": "Get the CPU, memory, network and block I/O stats of a container as a time series for charts. Stats of the running containers\nare sampled every STATS_INTERVAL (10s by default) and rolled up per minute and per hour; points are computed from the finest\nrollup still kept for the range. Without a step, one is chosen to return at most 300 points.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "Get the stats history of a container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID or name",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the range as an RFC 3339 time, defaults to an hour ago",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the range as an RFC 3339 time, defaults to now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Duration between points, such as 30s, 5m or 1h",
+                        "name": "step",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StatsHistory"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/{id}/stop": {
+            "post": {
+                "description": "Stop a Docker container by ID. The timeout is the number of seconds to wait before killing it, defaults\nto 10 and -1 waits forever. The signal replaces the stop signal of the container.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "Stop a container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Timeout and signal",
+                        "name": "options",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.StateChange"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StateTransition"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/{id}/terminal": {
+            "get": {
+                "description": "Upgrade to a WebSocket running an interactive shell with a TTY inside a running container. The output\nis sent as binary frames. Clients send input as binary frames or as {\"type\":\"input\",\"data\":\"...\"} and\nresize the TTY with {\"type\":\"resize\",\"rows\":24,\"cols\":80}. When the shell exits the server sends\n{\"type\":\"exit\",\"exit_code\":0} and closes the socket, closing the socket ends the shell.",
+                "tags": [
+                    "containers"
+                ],
+                "summary": "Open a web terminal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Command, /bin/sh by default",
+                        "name": "cmd",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "User running the command",
+                        "name": "user",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Working directory",
+                        "name": "workdir",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Initial rows of the TTY",
+                        "name": "rows",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Initial columns of the TTY",
+                        "name": "cols",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/{id}/timeline": {
+            "get": {
+                "description": "List the recorded Docker events of a container, newest first, with the exit code of die events and whether it was OOM killed.\nEvents are kept even once the container is removed, for as long as EVENT_RETENTION (30 days by default).\nPass the next cursor as before to get the following page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "Get the timeline of a container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID or name",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events at or after this RFC 3339 time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events before this RFC 3339 time",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Next cursor of the previous page",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of events, defaults to 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Timeline"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/containers/{id}/unpause": {
+            "post": {
+                "description": "Resume the processes of a paused container.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "containers"
+                ],
+                "summary": "Unpause a container",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Container ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StateTransition"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events": {
+            "get": {
+                "description": "Stream the events of the Docker daemon, such as container create, start, die, oom and health_status, image pull and delete\nor volume and network events. Every event is sent with its ID; reconnecting with it in the Last-Event-ID header replays the\nevents missed in between. A heartbeat comment is sent every 15 seconds. Values of a filter are alternatives, filters must all match.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Stream Docker events",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Event types, such as container, image, volume or network",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Event actions, such as start, die or health_status",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Container names or IDs",
+                        "name": "container",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Labels as key or key=value",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the last event received, to resume after it",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Server-Sent Events",
+                        "schema": {
+                            "$ref": "#/definitions/models.Event"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/hosts": {
+            "get": {
+                "description": "List the registered Docker hosts, including the local one, with their connectivity status",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "hosts"
+                ],
+                "summary": "List Docker hosts",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.HostStatus"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Register a Docker endpoint reachable through unix://, tcp:// (optionally with TLS certificates) or ssh://",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "hosts"
+                ],
+                "summary": "Register a Docker host",
+                "parameters": [
+                    {
+                        "description": "Host configuration",
+                        "name": "host",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Host"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Host"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/hosts/{name}": {
+            "get": {
+                "description": "Get a Docker host by name with its connectivity status",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "hosts"
+                ],
+                "summary": "Get a Docker host",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Host name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HostStatus"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the endpoint configuration of a registered Docker host",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "hosts"
+                ],
+                "summary": "Update a Docker host",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Host name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Host configuration",
+                        "name": "host",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Host"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Host"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Unregister a Docker host. Its containers are left untouched.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "hosts"
+                ],
+                "summary": "Delete a Docker host",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Host name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/images/import": {
+            "post": {
+                "description": "Create an image from an uploaded tarball of a filesystem, such as the export of a container, optionally\ntagged and with Dockerfile changes applied to its config.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Import an image",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Tarball of the filesystem, optionally gzip compressed",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Reference of the image",
+                        "name": "reference",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Commit message of the image",
+                        "name": "message",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Dockerfile instructions such as ENV or CMD",
+                        "name": "changes",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Platform of the image, as os/arch[/variant]",
+                        "name": "platform",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImageSnapshot"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/images/pulls": {
+            "post": {
+                "description": "Start pulling an image in the background, given as a full reference or through its parts, optionally for another platform.\nFollow the returned job through its events endpoint.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Pull an image",
+                "parameters": [
+                    {
+                        "description": "Image to pull",
+                        "name": "pull",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PullRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.PullJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/images/pulls/{id}": {
+            "get": {
+                "description": "Get the current progress of an image pull job",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Get a pull job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pull job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PullJob"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/images/pulls/{id}/events": {
+            "get": {
+                "description": "Server-Sent Events with the state of the pull job on every progress update. The stream ends with the final state of the job.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Stream pull job progress",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pull job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PullJob"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects": {
+            "get": {
+                "description": "Group the containers labelled by docker compose, stacks included, by project with their aggregate state: running, partial or stopped",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "List compose projects",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Project"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{name}": {
+            "get": {
+                "description": "Get the containers of a compose project in dependency order",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "Get a compose project",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Project"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Stop and remove the containers of a project in reverse dependency order, then its networks, like docker compose down.\nStacks deployed from this API are still stored and come back on their next up.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "Remove a compose project",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also remove the volumes of the project",
+                        "name": "volumes",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{name}/restart": {
+            "post": {
+                "description": "Restart the containers of a project in dependency order",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "Restart a compose project",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Project"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{name}/start": {
+            "post": {
+                "description": "Start the stopped containers of a project, the services they depend on first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "Start a compose project",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Project"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/projects/{name}/stop": {
+            "post": {
+                "description": "Stop the running containers of a project in reverse dependency order",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "Stop a compose project",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Project"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/registries": {
+            "get": {
+                "description": "List the private registries with stored credentials. Secrets are never returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registries"
+                ],
+                "summary": "List registries",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Registry"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Store the credentials of a private registry. They are used by every pull of an image hosted on it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registries"
+                ],
+                "summary": "Add registry credentials",
+                "parameters": [
+                    {
+                        "description": "Registry credentials",
+                        "name": "registry",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Registry"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Registry"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/registries/{server}": {
+            "get": {
+                "description": "Get the stored credentials of a registry, without its secrets",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registries"
+                ],
+                "summary": "Get a registry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Registry hostname, e.g. ghcr.io or localhost:5000",
+                        "name": "server",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Registry"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the credentials of a registry. Omitting both password and identity_token keeps the stored secret.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registries"
+                ],
+                "summary": "Update registry credentials",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Registry hostname",
+                        "name": "server",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Registry credentials",
+                        "name": "registry",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Registry"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Registry"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete the stored credentials of a registry. Its images are pulled anonymously afterwards.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registries"
+                ],
+                "summary": "Delete registry credentials",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Registry hostname",
+                        "name": "server",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/registries/{server}/login": {
+            "post": {
+                "description": "Log into the registry with the stored credentials through the daemon of the selected host.\nPlain HTTP registries such as a local registry:2 must be listed in the daemon insecure-registries.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registries"
+                ],
+                "summary": "Test registry login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Registry hostname",
+                        "name": "server",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RegistryLoginResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules": {
+            "get": {
+                "description": "List the container action schedules with their last and next runs",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "List schedules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ActionSchedule"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Start, stop, restart, recreate or run a command in a container periodically, for example \"0 4 * * *\" to restart nightly at 04:00\nor \"0 20 * * 1-5\" to stop at 20:00 on weekdays. Runs missed while the server was down are skipped or, with the run_once catch-up policy,\nrun once at startup.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "Create a schedule",
+                "parameters": [
+                    {
+                        "description": "Schedule",
+                        "name": "schedule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ActionScheduleDefinition"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ActionSchedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules/{id}": {
+            "get": {
+                "description": "Get a container action schedule by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "Get a schedule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ActionSchedule"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the definition of a schedule. Its host and run history are kept.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "Update a schedule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Schedule",
+                        "name": "schedule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ActionScheduleDefinition"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ActionSchedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a schedule and its run history",
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "Delete a schedule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules/{id}/run": {
+            "post": {
+                "description": "Run the action of a schedule right away, even when it is disabled. The run is recorded in its history.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "Run a schedule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ActionScheduleRun"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules/{id}/runs": {
+            "get": {
+                "description": "List the most recent runs of a schedule, newest first. The last 100 runs are kept.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedules"
+                ],
+                "summary": "List the runs of a schedule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of runs, defaults to 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ActionScheduleRun"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stacks": {
+            "get": {
+                "description": "List the stored compose stacks",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stacks"
+                ],
+                "summary": "List stacks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Stack"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Store a docker-compose file and bring it up: networks, volumes and containers are created in dependency order and labelled with the stack name.\nSupported keys are services (image, container_name, command, entrypoint, environment, labels, ports, volumes, depends_on, networks, restart, working_dir, user, hostname, pull_policy, platform, healthcheck), networks and volumes.\nThe file is sent either as JSON or as a raw YAML body (application/yaml) with the name in the query.",
+                "consumes": [
+                    "application/json",
+                    "application/yaml"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stacks"
+                ],
+                "summary": "Deploy a stack",
+                "parameters": [
+                    {
+                        "description": "Stack",
+                        "name": "stack",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.StackRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Stack name, for YAML bodies",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.StackStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stacks/{name}": {
+            "get": {
+                "description": "Get a stack with its compose file",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stacks"
+                ],
+                "summary": "Get a stack",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stack name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Stack"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the compose file of a stack and bring it up again. Unchanged services keep their containers,\nchanged ones are recreated and removed ones are deleted.",
+                "consumes": [
+                    "application/json",
+                    "application/yaml"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stacks"
+                ],
+                "summary": "Update a stack",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stack name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Stack, the name is ignored",
+                        "name": "stack",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.StackRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StackStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Take a stack down and forget it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stacks"
+                ],
+                "summary": "Delete a stack",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stack name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also remove the volumes of the stack",
+                        "name": "volumes",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stacks/{name}/down": {
+            "post": {
+                "description": "Stop and remove the containers of a stack in reverse dependency order, then its networks",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stacks"
+                ],
+                "summary": "Take a stack down",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stack name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also remove the volumes of the stack",
+                        "name": "volumes",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StackStatus"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stacks/{name}/logs": {
+            "get": {
+                "description": "Server-Sent Events with the logs of every container of the stack, one \"log\" event per line tagged with its service",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "stacks"
+                ],
+                "summary": "Stream stack logs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stack name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StackLogLine"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stacks/{name}/restart": {
+            "post": {
+                "description": "Restart the containers of a stack in dependency order",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stacks"
+                ],
+                "summary": "Restart a stack",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stack name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StackStatus"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stacks/{name}/status": {
+            "get": {
+                "description": "Get the container of every service of a stack and the aggregate state: running, partial, stopped or down",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stacks"
+                ],
+                "summary": "Get the status of a stack",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stack name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StackStatus"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stacks/{name}/up": {
+            "post": {
+                "description": "Create or start what is missing so every service of the stack runs its current configuration",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stacks"
+                ],
+                "summary": "Bring a stack up",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stack name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StackStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates": {
+            "get": {
+                "description": "List the stored container templates",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "List templates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Template"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Store a creation spec whose strings may reference the declared parameters as ${NAME}",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Create a template",
+                "parameters": [
+                    {
+                        "description": "Template",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TemplateDefinition"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Template"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/export": {
+            "get": {
+                "description": "Download the definitions of every template as a JSON or YAML list",
+                "produces": [
+                    "application/json",
+                    "application/yaml"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Export every template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "json (default) or yaml",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TemplateDefinition"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/import": {
+            "post": {
+                "description": "Import a JSON or YAML document holding a template definition or a list of them.\nNothing is stored if one is invalid. Templates with an existing name are rejected unless overwrite=true.",
+                "consumes": [
+                    "application/json",
+                    "application/yaml"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Import templates",
+                "parameters": [
+                    {
+                        "description": "Template definition or list of them",
+                        "name": "templates",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TemplateDefinition"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Replace the templates with the same name",
+                        "name": "overwrite",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Template"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{id}": {
+            "get": {
+                "description": "Get a container template by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Get a template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Template"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the definition of a template",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Update a template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Template",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TemplateDefinition"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Template"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a template. Containers created from it are left untouched.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Delete a template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{id}/export": {
+            "get": {
+                "description": "Download the definition of a template as JSON or YAML",
+                "produces": [
+                    "application/json",
+                    "application/yaml"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Export a template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (default) or yaml",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TemplateDefinition"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{id}/instantiate": {
+            "post": {
+                "description": "Substitute the parameters into the template, validate the resulting options and create the container like POST /containers does",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Create a container from a template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Parameter values",
+                        "name": "parameters",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.InstantiateRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Docker host name, defaults to local",
+                        "name": "host",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return the pull job instead of waiting for the creation",
+                        "name": "async",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.PullJob"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.ActionSchedule": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "start",
+                        "stop",
+                        "restart",
+                        "exec",
+                        "recreate"
+                    ],
+                    "example": "restart"
+                },
+                "catch_up": {
+                    "type": "string",
+                    "enum": [
+                        "skip",
+                        "run_once"
+                    ],
+                    "example": "skip"
+                },
+                "container": {
+                    "type": "string",
+                    "example": "mc"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "cron": {
+                    "type": "string",
+                    "example": "0 4 * * *"
+                },
+                "disabled": {
+                    "type": "boolean"
+                },
+                "exec": {
+                    "$ref": "#/definitions/models.ExecRequest"
+                },
+                "host": {
+                    "type": "string",
+                    "example": "local"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_run_at": {
+                    "type": "string"
+                },
+                "last_status": {
+                    "type": "string",
+                    "enum": [
+                        "succeeded",
+                        "failed",
+                        "skipped"
+                    ],
+                    "example": "succeeded"
+                },
+                "next_run_at": {
+                    "type": "string"
+                },
+                "recreate": {
+                    "$ref": "#/definitions/models.RecreateRequest"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Europe/Berlin"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ActionScheduleDefinition": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "start",
+                        "stop",
+                        "restart",
+                        "exec",
+                        "recreate"
+                    ],
+                    "example": "restart"
+                },
+                "catch_up": {
+                    "type": "string",
+                    "enum": [
+                        "skip",
+                        "run_once"
+                    ],
+                    "example": "skip"
+                },
+                "container": {
+                    "type": "string",
+                    "example": "mc"
+                },
+                "cron": {
+                    "type": "string",
+                    "example": "0 4 * * *"
+                },
+                "disabled": {
+                    "type": "boolean"
+                },
+                "exec": {
+                    "$ref": "#/definitions/models.ExecRequest"
+                },
+                "recreate": {
+                    "$ref": "#/definitions/models.RecreateRequest"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Europe/Berlin"
+                }
+            }
+        },
+        "models.ActionScheduleRun": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "restart"
+                },
+                "duration_ms": {
+                    "type": "integer",
+                    "example": 1520
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "output": {
+                    "type": "string"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "succeeded",
+                        "failed",
+                        "skipped"
+                    ],
+                    "example": "succeeded"
+                },
+                "trigger": {
+                    "type": "string",
+                    "enum": [
+                        "cron",
+                        "catch_up",
+                        "manual"
+                    ],
+                    "example": "cron"
+                }
+            }
+        },
+        "models.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string",
+                    "example": "alice"
+                },
+                "duration_ms": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "error": {
+                    "type": "string",
+                    "example": "No such container: mc"
+                },
+                "host": {
+                    "type": "string",
+                    "example": "nas"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string",
+                    "example": "POST"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/api/containers/mc/stop?host=nas"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "result": {
+                    "type": "string",
+                    "enum": [
+                        "success",
+                        "failure"
+                    ],
+                    "example": "success"
+                },
+                "route": {
+                    "type": "string",
+                    "example": "/api/containers/:id/stop"
+                },
+                "source_ip": {
+                    "type": "string",
+                    "example": "192.168.1.20"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 204
+                },
+                "target": {
+                    "type": "string",
+                    "example": "mc"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AuditPage": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuditEntry"
+                    }
+                },
+                "next": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Backup": {
+            "type": "object",
+            "properties": {
+                "checksum": {
+                    "type": "string",
+                    "example": "sha256:9f86d081884c7d65..."
+                },
+                "container_id": {
+                    "type": "string"
+                },
+                "container_name": {
+                    "type": "string",
+                    "example": "mc"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "destination": {
+                    "type": "string",
+                    "example": "/data"
+                },
+                "duration_ms": {
+                    "type": "integer",
+                    "example": 1520
+                },
+                "error": {
+                    "type": "string"
+                },
+                "file": {
+                    "type": "string",
+                    "example": "local/mc/20260101T040000Z-data.tar.gz"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "host": {
+                    "type": "string",
+                    "example": "local"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "pause",
+                        "stop"
+                    ],
+                    "example": "pause"
+                },
+                "mount_type": {
+                    "type": "string",
+                    "enum": [
+                        "volume",
+                        "bind"
+                    ],
+                    "example": "volume"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 1048576
+                },
+                "source": {
+                    "type": "string",
+                    "example": "mc-data"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "running",
+                        "completed",
+                        "failed"
+                    ],
+                    "example": "completed"
+                }
+            }
+        },
+        "models.BackupRequest": {
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "pause",
+                        "stop"
+                    ],
+                    "example": "pause"
+                },
+                "mounts": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "/data"
+                    ]
+                }
+            }
+        },
+        "models.BackupSchedule": {
+            "type": "object",
+            "properties": {
+                "container": {
+                    "type": "string",
+                    "example": "mc"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "cron": {
+                    "type": "string",
+                    "example": "0 4 * * *"
+                },
+                "disabled": {
+                    "type": "boolean"
+                },
+                "host": {
+                    "type": "string",
+                    "example": "local"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_run_at": {
+                    "type": "string"
+                },
+                "last_status": {
+                    "type": "string",
+                    "enum": [
+                        "completed",
+                        "failed"
+                    ],
+                    "example": "completed"
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "pause",
+                        "stop"
+                    ],
+                    "example": "pause"
+                },
+                "mounts": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "/data"
+                    ]
+                },
+                "next_run_at": {
+                    "type": "string"
+                },
+                "retention": {
+                    "$ref": "#/definitions/models.RetentionPolicy"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Europe/Berlin"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BackupScheduleDefinition": {
+            "type": "object",
+            "properties": {
+                "container": {
+                    "type": "string",
+                    "example": "mc"
+                },
+                "cron": {
+                    "type": "string",
+                    "example": "0 4 * * *"
+                },
+                "disabled": {
+                    "type": "boolean"
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "pause",
+                        "stop"
+                    ],
+                    "example": "pause"
+                },
+                "mounts": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "/data"
+                    ]
+                },
+                "retention": {
+                    "$ref": "#/definitions/models.RetentionPolicy"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Europe/Berlin"
+                }
+            }
+        },
+        "models.CloneRequest": {
+            "type": "object",
+            "properties": {
+                "env": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "survival-copy"
+                },
+                "port_conflict": {
+                    "type": "string",
+                    "enum": [
+                        "remap",
+                        "reject"
+                    ],
+                    "example": "remap"
+                },
+                "ports": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PortBinding"
+                    }
+                },
+                "start": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.CloneResult": {
+            "type": "object",
+            "properties": {
+                "container_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "survival-copy"
+                },
+                "ports": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PortBinding"
+                    }
+                },
+                "remapped": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PortRemap"
+                    }
+                },
+                "source_id": {
+                    "type": "string"
+                },
+                "started": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.CommitRequest": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "ops"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ENV MEMORY=4G"
+                    ]
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Tuned JVM flags"
+                },
+                "pause": {
+                    "description": "Pause pauses the container while it is committed, true by default.",
+                    "type": "boolean"
+                },
+                "reference": {
+                    "type": "string",
+                    "example": "mc-snapshots:before-1.21"
+                }
+            }
+        },
+        "models.Container": {
+            "type": "object",
+            "properties": {
+                "command": {
+                    "type": "string"
+                },
+                "created": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "image": {
+                    "type": "string"
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "names": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "ports": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "state": {
+                    "type": "string"
+                },
+                "stats": {
+                    "$ref": "#/definitions/models.ContainerStats"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ContainerResources": {
+            "type": "object",
+            "properties": {
+                "cpu_period": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "cpu_quota": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "cpu_shares": {
+                    "type": "integer",
+                    "example": 1024
+                },
+                "cpus": {
+                    "type": "number",
+                    "example": 2
+                },
+                "cpuset_cpus": {
+                    "type": "string",
+                    "example": "0-1"
+                },
+                "memory": {
+                    "type": "integer",
+                    "example": 4294967296
+                },
+                "memory_swap": {
+                    "type": "integer",
+                    "example": 6442450944
+                },
+                "pids_limit": {
+                    "type": "integer",
+                    "example": 512
+                },
+                "restart_policy": {
+                    "$ref": "#/definitions/models.RestartPolicy"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.ContainerStats": {
+            "type": "object",
+            "properties": {
+                "cpu_total": {
+                    "type": "integer"
+                },
+                "cpu_usage": {
+                    "type": "integer"
+                },
+                "mem_total": {
+                    "type": "integer"
+                },
+                "mem_usage": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CreateOptions": {
+            "type": "object",
+            "properties": {
+                "commands": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "entrypoint": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "env": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "healthcheck": {
+                    "$ref": "#/definitions/models.Healthcheck"
+                },
+                "hostname": {
+                    "type": "string"
+                },
+                "image": {
+                    "type": "string"
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "mounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Mount"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "network": {
+                    "type": "string",
+                    "example": "bridge"
+                },
+                "network_aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "platform": {
+                    "type": "string",
+                    "example": "linux/arm64"
+                },
+                "ports": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PortBinding"
+                    }
+                },
+                "pull_policy": {
+                    "type": "string",
+                    "enum": [
+                        "always",
+                        "if-not-present",
+                        "never"
+                    ],
+                    "example": "if-not-present"
+                },
+                "reference": {
+                    "type": "string",
+                    "example": "ghcr.io/itzg/minecraft-server@sha256:4f8a..."
+                },
+                "registry": {
+                    "type": "string"
+                },
+                "resources": {
+                    "$ref": "#/definitions/models.Resources"
+                },
+                "restart_policy": {
+                    "$ref": "#/definitions/models.RestartPolicy"
+                },
+                "user": {
+                    "type": "string",
+                    "example": "1000:1000"
+                },
+                "version": {
+                    "type": "string"
+                },
+                "working_dir": {
+                    "type": "string",
+                    "example": "/data"
+                }
+            }
+        },
+        "models.DirectoryListing": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FileEntry"
+                    }
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/data"
+                }
+            }
+        },
+        "models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "Message": {
+                    "type": "string",
+                    "example": "Container with ID aal12345 not found."
+                },
+                "code": {
+                    "type": "string",
+                    "example": "CONTAINER_NOT_FOUND"
+                },
+                "details": {}
+            }
+        },
+        "models.Event": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "die"
+                },
+                "actor_id": {
+                    "type": "string"
+                },
+                "attributes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string",
+                    "example": "1767240000123456789"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "mc"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "container",
+                        "image",
+                        "volume",
+                        "network",
+                        "daemon",
+                        "plugin",
+                        "service",
+                        "node",
+                        "secret",
+                        "config"
+                    ],
+                    "example": "container"
+                }
+            }
+        },
+        "models.ExecRequest": {
+            "type": "object",
+            "properties": {
+                "cmd": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "rcon-cli",
+                        "list"
+                    ]
+                },
+                "env": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "max_output": {
+                    "type": "string",
+                    "example": "1m"
+                },
+                "timeout": {
+                    "type": "string",
+                    "example": "30s"
+                },
+                "user": {
+                    "type": "string",
+                    "example": "1000:1000"
+                },
+                "working_dir": {
+                    "type": "string",
+                    "example": "/data"
+                }
+            }
+        },
+        "models.ExecResult": {
+            "type": "object",
+            "properties": {
+                "duration": {
+                    "type": "string",
+                    "example": "152ms"
+                },
+                "exit_code": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "stderr": {
+                    "type": "string"
+                },
+                "stdout": {
+                    "type": "string",
+                    "example": "There are 0 of a max of 20 players online"
+                },
+                "timed_out": {
+                    "type": "boolean"
+                },
+                "truncated": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.FileContent": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "example": "motd=A Minecraft Server"
+                },
+                "mode": {
+                    "type": "string",
+                    "example": "-rw-r--r--"
+                },
+                "mtime": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/data/server.properties"
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 1432
+                }
+            }
+        },
+        "models.FileEntry": {
+            "type": "object",
+            "properties": {
+                "link_target": {
+                    "type": "string"
+                },
+                "mode": {
+                    "type": "string",
+                    "example": "-rw-r--r--"
+                },
+                "mtime": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "server.properties"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/data/server.properties"
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 1432
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "file",
+                        "dir",
+                        "symlink",
+                        "other"
+                    ],
+                    "example": "file"
+                }
+            }
+        },
+        "models.Healthcheck": {
+            "type": "object",
+            "properties": {
+                "interval": {
+                    "type": "string",
+                    "example": "30s"
+                },
+                "retries": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "start_period": {
+                    "type": "string",
+                    "example": "1m"
+                },
+                "test": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "CMD-SHELL",
+                        "curl -f http://localhost/ || exit 1"
+                    ]
+                },
+                "timeout": {
+                    "type": "string",
+                    "example": "5s"
+                }
+            }
+        },
+        "models.Host": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "build-box"
+                },
+                "tls_ca_cert": {
+                    "type": "string",
+                    "example": "/etc/docker-manager/certs/ca.pem"
+                },
+                "tls_cert": {
+                    "type": "string",
+                    "example": "/etc/docker-manager/certs/cert.pem"
+                },
+                "tls_key": {
+                    "type": "string",
+                    "example": "/etc/docker-manager/certs/key.pem"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "tcp://10.0.0.12:2376"
+                }
+            }
+        },
+        "models.HostStatus": {
+            "type": "object",
+            "properties": {
+                "api_version": {
+                    "type": "string",
+                    "example": "1.48"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "build-box"
+                },
+                "os_type": {
+                    "type": "string",
+                    "example": "linux"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "up"
+                },
+                "tls_ca_cert": {
+                    "type": "string",
+                    "example": "/etc/docker-manager/certs/ca.pem"
+                },
+                "tls_cert": {
+                    "type": "string",
+                    "example": "/etc/docker-manager/certs/cert.pem"
+                },
+                "tls_key": {
+                    "type": "string",
+                    "example": "/etc/docker-manager/certs/key.pem"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "tcp://10.0.0.12:2376"
+                }
+            }
+        },
+        "models.ImageSnapshot": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "container": {
+                    "type": "string",
+                    "example": "mc"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "sha256:4b1c..."
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Tuned JVM flags"
+                },
+                "reference": {
+                    "type": "string",
+                    "example": "docker.io/library/mc-snapshots:before-1.21"
+                }
+            }
+        },
+        "models.InstantiateRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "survival-1"
+                },
+                "parameters": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "MEMORY": "4G",
+                        "PORT": "25566"
+                    }
+                }
+            }
+        },
+        "models.LayerProgress": {
+            "type": "object",
+            "properties": {
+                "downloaded": {
+                    "type": "integer"
+                },
+                "extracted": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "a2abf6c4d29d"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "Extracting"
+                }
+            }
+        },
+        "models.Mount": {
+            "type": "object",
+            "properties": {
+                "read_only": {
+                    "type": "boolean"
+                },
+                "source": {
+                    "type": "string",
+                    "example": "minecraft-data"
+                },
+                "target": {
+                    "type": "string",
+                    "example": "/data"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "bind",
+                        "volume"
+                    ],
+                    "example": "volume"
+                }
+            }
+        },
+        "models.PortBinding": {
+            "type": "object",
+            "properties": {
+                "container_port": {
+                    "type": "integer",
+                    "example": 25565
+                },
+                "host_ip": {
+                    "type": "string",
+                    "example": "0.0.0.0"
+                },
+                "host_port": {
+                    "type": "integer",
+                    "example": 25565
+                },
+                "protocol": {
+                    "type": "string",
+                    "enum": [
+                        "tcp",
+                        "udp",
+                        "sctp"
+                    ],
+                    "example": "tcp"
+                }
+            }
+        },
+        "models.PortRemap": {
+            "type": "object",
+            "properties": {
+                "container_port": {
+                    "type": "integer",
+                    "example": 25565
+                },
+                "host_port": {
+                    "type": "integer",
+                    "example": 25565
+                },
+                "new_host_port": {
+                    "type": "integer",
+                    "example": 25566
+                },
+                "protocol": {
+                    "type": "string",
+                    "example": "tcp"
+                }
+            }
+        },
+        "models.Project": {
+            "type": "object",
+            "properties": {
+                "config_files": {
+                    "type": "string",
+                    "example": "/srv/survival/docker-compose.yml"
+                },
+                "containers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProjectContainer"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "survival"
+                },
+                "services": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "db",
+                        "web"
+                    ]
+                },
+                "state": {
+                    "type": "string",
+                    "enum": [
+                        "running",
+                        "partial",
+                        "stopped"
+                    ],
+                    "example": "running"
+                },
+                "working_dir": {
+                    "type": "string",
+                    "example": "/srv/survival"
+                }
+            }
+        },
+        "models.ProjectContainer": {
+            "type": "object",
+            "properties": {
+                "depends_on": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "db"
+                    ]
+                },
+                "id": {
+                    "type": "string"
+                },
+                "image": {
+                    "type": "string",
+                    "example": "nginx:latest"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "survival-web-1"
+                },
+                "number": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "service": {
+                    "type": "string",
+                    "example": "web"
+                },
+                "state": {
+                    "type": "string",
+                    "example": "running"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "Up 2 minutes"
+                }
+            }
+        },
+        "models.PullJob": {
+            "type": "object",
+            "properties": {
+                "container_id": {
+                    "type": "string"
+                },
+                "container_name": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "downloaded": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "extracted": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "host": {
+                    "type": "string",
+                    "example": "local"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "image": {
+                    "type": "string",
+                    "example": "docker.io/itzg/minecraft-server:latest"
+                },
+                "layers": {
                     "type": "array",
                     "items": {
+                        "$ref": "#/definitions/models.LayerProgress"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Downloading"
+                },
+                "percent": {
+                    "type": "number",
+                    "example": 42.5
+                },
+                "platform": {
+                    "type": "string",
+                    "example": "linux/arm64"
+                },
+                "pull_policy": {
+                    "type": "string",
+                    "example": "if-not-present"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "pulling",
+                        "creating",
+                        "completed",
+                        "failed"
+                    ],
+                    "example": "pulling"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PullRequest": {
+            "type": "object",
+            "properties": {
+                "image": {
+                    "type": "string",
+                    "example": "itzg/minecraft-server"
+                },
+                "platform": {
+                    "type": "string",
+                    "example": "linux/arm64"
+                },
+                "reference": {
+                    "type": "string",
+                    "example": "ghcr.io/itzg/minecraft-server:java21"
+                },
+                "registry": {
+                    "type": "string",
+                    "example": "docker.io"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "latest"
+                }
+            }
+        },
+        "models.RecordedEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "die"
+                },
+                "actor_id": {
+                    "type": "string"
+                },
+                "attributes": {
+                    "type": "object",
+                    "additionalProperties": {
                         "type": "string"
                     }
                 },
-                "image": {
-                    "type": "string"
+                "exit_code": {
+                    "type": "integer",
+                    "example": 137
+                },
+                "host": {
+                    "type": "string",
+                    "example": "local"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "1767240000123456789"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "mc"
+                },
+                "oom_killed": {
+                    "type": "boolean"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "container",
+                        "image",
+                        "volume",
+                        "network",
+                        "daemon",
+                        "plugin",
+                        "service",
+                        "node",
+                        "secret",
+                        "config"
+                    ],
+                    "example": "container"
+                }
+            }
+        },
+        "models.RecreateRequest": {
+            "type": "object",
+            "properties": {
+                "platform": {
+                    "type": "string",
+                    "example": "linux/arm64"
+                },
+                "pull_policy": {
+                    "type": "string",
+                    "enum": [
+                        "always",
+                        "if-not-present",
+                        "never"
+                    ],
+                    "example": "always"
+                },
+                "reference": {
+                    "type": "string",
+                    "example": "docker.io/itzg/minecraft-server:java21"
+                },
+                "timeout": {
+                    "description": "Timeout bounds the wait for the new container to become healthy.",
+                    "type": "string",
+                    "example": "1m"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "java21"
+                }
+            }
+        },
+        "models.RecreateResult": {
+            "type": "object",
+            "properties": {
+                "container_id": {
+                    "type": "string"
+                },
+                "image": {
+                    "type": "string",
+                    "example": "docker.io/itzg/minecraft-server:java21"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "survival"
+                },
+                "old_id": {
+                    "type": "string"
+                },
+                "previous_image": {
+                    "type": "string",
+                    "example": "docker.io/itzg/minecraft-server:latest"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "recreated",
+                        "rolled_back",
+                        "failed"
+                    ],
+                    "example": "recreated"
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RecreateStep"
+                    }
+                }
+            }
+        },
+        "models.RecreateStep": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "done",
+                        "failed"
+                    ],
+                    "example": "done"
+                },
+                "step": {
+                    "type": "string",
+                    "example": "pull"
+                }
+            }
+        },
+        "models.Registry": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "identity_token": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "ghp_xxxxxxxxxxxx"
+                },
+                "server": {
+                    "type": "string",
+                    "example": "ghcr.io"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "octocat"
+                }
+            }
+        },
+        "models.RegistryLoginResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "host": {
+                    "type": "string",
+                    "example": "local"
+                },
+                "server": {
+                    "type": "string",
+                    "example": "ghcr.io"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "Login Succeeded"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.RenameRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "survival"
+                }
+            }
+        },
+        "models.ResourceUpdate": {
+            "type": "object",
+            "properties": {
+                "cpu_period": {
+                    "type": "integer",
+                    "example": 100000
+                },
+                "cpu_quota": {
+                    "type": "integer",
+                    "example": 150000
+                },
+                "cpu_shares": {
+                    "type": "integer",
+                    "example": 1024
+                },
+                "cpus": {
+                    "type": "number",
+                    "example": 2
+                },
+                "cpuset_cpus": {
+                    "type": "string",
+                    "example": "0-1"
+                },
+                "memory": {
+                    "type": "string",
+                    "example": "4g"
+                },
+                "memory_swap": {
+                    "type": "string",
+                    "example": "6g"
+                },
+                "pids_limit": {
+                    "type": "integer",
+                    "example": 512
+                },
+                "restart_policy": {
+                    "$ref": "#/definitions/models.RestartPolicy"
+                }
+            }
+        },
+        "models.Resources": {
+            "type": "object",
+            "properties": {
+                "cpu_shares": {
+                    "type": "integer",
+                    "example": 1024
+                },
+                "cpus": {
+                    "type": "number",
+                    "example": 1.5
+                },
+                "memory": {
+                    "type": "string",
+                    "example": "2g"
+                },
+                "memory_swap": {
+                    "type": "string",
+                    "example": "4g"
+                },
+                "pids_limit": {
+                    "type": "integer",
+                    "example": 512
+                }
+            }
+        },
+        "models.RestartPolicy": {
+            "type": "object",
+            "properties": {
+                "maximum_retry_count": {
+                    "type": "integer"
                 },
                 "name": {
+                    "type": "string",
+                    "enum": [
+                        "no",
+                        "always",
+                        "unless-stopped",
+                        "on-failure"
+                    ],
+                    "example": "unless-stopped"
+                }
+            }
+        },
+        "models.RestoreRequest": {
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "pause",
+                        "stop"
+                    ],
+                    "example": "stop"
+                },
+                "volume": {
+                    "type": "string",
+                    "example": "mc-data-restored"
+                }
+            }
+        },
+        "models.RestoreResult": {
+            "type": "object",
+            "properties": {
+                "backup_id": {
+                    "type": "integer"
+                },
+                "container": {
+                    "type": "string",
+                    "example": "mc"
+                },
+                "destination": {
+                    "type": "string",
+                    "example": "/data"
+                },
+                "duration_ms": {
+                    "type": "integer",
+                    "example": 830
+                },
+                "volume": {
+                    "type": "string",
+                    "example": "mc-data"
+                }
+            }
+        },
+        "models.RetentionPolicy": {
+            "type": "object",
+            "properties": {
+                "keep_daily": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "keep_last": {
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
+        "models.Stack": {
+            "type": "object",
+            "properties": {
+                "compose": {
                     "type": "string"
                 },
-                "registry": {
+                "created_at": {
                     "type": "string"
                 },
-                "version": {
+                "host": {
+                    "type": "string",
+                    "example": "local"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "survival"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "models.Container": {
+        "models.StackLogLine": {
             "type": "object",
             "properties": {
-                "command": {
+                "line": {
                     "type": "string"
                 },
-                "created": {
-                    "type": "integer"
+                "service": {
+                    "type": "string",
+                    "example": "web"
+                }
+            }
+        },
+        "models.StackRequest": {
+            "type": "object",
+            "properties": {
+                "compose": {
+                    "type": "string",
+                    "example": "services:\n  web:\n    image: nginx\n"
                 },
-                "id": {
+                "name": {
+                    "type": "string",
+                    "example": "survival"
+                }
+            }
+        },
+        "models.StackService": {
+            "type": "object",
+            "properties": {
+                "container_id": {
                     "type": "string"
                 },
+                "container_name": {
+                    "type": "string",
+                    "example": "survival-web-1"
+                },
                 "image": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "docker.io/library/nginx:latest"
                 },
-                "labels": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    }
+                "service": {
+                    "type": "string",
+                    "example": "web"
                 },
-                "names": {
+                "state": {
+                    "type": "string",
+                    "example": "running"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "Up 2 minutes"
+                }
+            }
+        },
+        "models.StackStatus": {
+            "type": "object",
+            "properties": {
+                "host": {
+                    "type": "string",
+                    "example": "local"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "survival"
+                },
+                "services": {
                     "type": "array",
                     "items": {
-                        "type": "string"
-                    }
-                },
-                "ports": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
+                        "$ref": "#/definitions/models.StackService"
                     }
                 },
                 "state": {
+                    "type": "string",
+                    "enum": [
+                        "running",
+                        "partial",
+                        "stopped",
+                        "down"
+                    ],
+                    "example": "running"
+                }
+            }
+        },
+        "models.StateChange": {
+            "type": "object",
+            "properties": {
+                "signal": {
+                    "type": "string",
+                    "example": "SIGHUP"
+                },
+                "timeout": {
+                    "type": "integer",
+                    "example": 30
+                }
+            }
+        },
+        "models.StateTransition": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "stop",
+                        "restart",
+                        "kill",
+                        "pause",
+                        "unpause",
+                        "rename"
+                    ],
+                    "example": "kill"
+                },
+                "from": {
+                    "type": "string",
+                    "example": "running"
+                },
+                "id": {
                     "type": "string"
                 },
-                "stats": {
-                    "$ref": "#/definitions/models.ContainerStats"
+                "name": {
+                    "type": "string",
+                    "example": "survival"
                 },
-                "status": {
+                "previous_name": {
+                    "type": "string",
+                    "example": "survival-old"
+                },
+                "signal": {
+                    "type": "string",
+                    "example": "SIGHUP"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "running"
+                }
+            }
+        },
+        "models.StatsHistory": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StatsPoint"
+                    }
+                },
+                "resolution": {
+                    "type": "string",
+                    "enum": [
+                        "raw",
+                        "1m",
+                        "1h"
+                    ],
+                    "example": "1m"
+                },
+                "step": {
+                    "type": "string",
+                    "example": "5m0s"
+                },
+                "to": {
                     "type": "string"
                 }
             }
         },
-        "models.ContainerStats": {
+        "models.StatsPoint": {
             "type": "object",
             "properties": {
-                "cpu_total": {
-                    "type": "integer"
+                "block_read": {
+                    "type": "integer",
+                    "example": 8388608
                 },
-                "cpu_usage": {
-                    "type": "integer"
+                "block_write": {
+                    "type": "integer",
+                    "example": 4194304
                 },
-                "mem_total": {
-                    "type": "integer"
+                "cpu_max": {
+                    "type": "number",
+                    "example": 48.2
+                },
+                "cpu_percent": {
+                    "type": "number",
+                    "example": 12.5
+                },
+                "mem_limit": {
+                    "type": "integer",
+                    "example": 2147483648
+                },
+                "mem_max": {
+                    "type": "integer",
+                    "example": 301989888
                 },
                 "mem_usage": {
+                    "type": "integer",
+                    "example": 268435456
+                },
+                "net_rx": {
+                    "type": "integer",
+                    "example": 1048576
+                },
+                "net_tx": {
+                    "type": "integer",
+                    "example": 524288
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SuccessResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Operation completed successfully"
+                }
+            }
+        },
+        "models.Template": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Paper server with a persistent world"
+                },
+                "id": {
                     "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "paper-survival"
+                },
+                "parameters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TemplateParameter"
+                    }
+                },
+                "spec": {
+                    "description": "Spec is a CreateOptions document where any string may reference a\nparameter as ${NAME}. A string made only of a placeholder takes the\ntype of its field, so numeric fields such as host_port can use one.",
+                    "type": "object"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "models.ErrorResponse": {
+        "models.TemplateDefinition": {
             "type": "object",
             "properties": {
-                "Message": {
+                "description": {
                     "type": "string",
-                    "example": "Container with ID aal12345 not found."
+                    "example": "Paper server with a persistent world"
                 },
-                "code": {
+                "name": {
                     "type": "string",
-                    "example": "CONTAINER_NOT_FOUND"
+                    "example": "paper-survival"
                 },
-                "details": {}
+                "parameters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TemplateParameter"
+                    }
+                },
+                "spec": {
+                    "description": "Spec is a CreateOptions document where any string may reference a\nparameter as ${NAME}. A string made only of a placeholder takes the\ntype of its field, so numeric fields such as host_port can use one.",
+                    "type": "object"
+                }
             }
         },
-        "models.SuccessResponse": {
+        "models.TemplateParameter": {
             "type": "object",
             "properties": {
-                "message": {
+                "default": {
                     "type": "string",
-                    "example": "Operation completed successfully"
+                    "example": "2G"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Heap size of the server"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "MEMORY"
+                },
+                "required": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.Timeline": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RecordedEvent"
+                    }
+                },
+                "next": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UploadResult": {
+            "type": "object",
+            "properties": {
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "/data/world.zip"
+                    ]
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/data"
                 }
             }
         }
@@ -0,0 +1,99 @@
+package fakedocker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/errdefs"
+)
+
+// Stdin returns everything written to the standard input of the container
+// through attach.
+func (e *Engine) Stdin(ref string) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		return ""
+	}
+	return c.stdin.String()
+}
+
+// ContainerAttach streams the log lines appended from now on until the
+// container stops, on one end of an in-memory connection. The input is
+// recorded when stdin is attached, see Stdin.
+func (e *Engine) ContainerAttach(ctx context.Context, ref string, options container.AttachOptions) (types.HijackedResponse, error) {
+	e.mu.Lock()
+	if err := e.failure("ContainerAttach"); err != nil {
+		e.mu.Unlock()
+		return types.HijackedResponse{}, err
+	}
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		e.mu.Unlock()
+		return types.HijackedResponse{}, err
+	}
+	if c.State != "running" && c.State != "paused" {
+		e.mu.Unlock()
+		return types.HijackedResponse{}, errdefs.Conflict(fmt.Errorf("You cannot attach to a stopped container, start it first"))
+	}
+	id, tty, from := c.ID, c.Config.Tty, len(c.logs)
+	e.mu.Unlock()
+
+	logs := container.LogsOptions{ShowStdout: options.Stdout, ShowStderr: options.Stderr}
+	output := e.follow(context.Background(), id, true, emitLogs(logs, tty), from)
+
+	client, server := net.Pipe()
+	go func() {
+		io.Copy(server, output)
+		server.Close()
+	}()
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, err := server.Read(buf)
+			if n > 0 && options.Stdin {
+				e.mu.Lock()
+				c.stdin.Write(buf[:n])
+				e.notify()
+				e.mu.Unlock()
+			}
+			if err != nil {
+				output.Close()
+				return
+			}
+		}
+	}()
+
+	mediaType := types.MediaTypeMultiplexedStream
+	if tty {
+		mediaType = types.MediaTypeRawStream
+	}
+	return types.NewHijackedResponse(client, mediaType), nil
+}
+
+func (e *Engine) ContainerResize(ctx context.Context, ref string, options container.ResizeOptions) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("ContainerResize"); err != nil {
+		return err
+	}
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		return err
+	}
+	if c.State != "running" {
+		return errdefs.Conflict(fmt.Errorf("Container %s is not running", c.ID))
+	}
+
+	c.TTYSize = [2]uint{options.Height, options.Width}
+	return nil
+}
@@ -0,0 +1,868 @@
+// Package fakedocker provides an in-memory Docker engine implementing
+// service.DockerAPI so handlers and services can be tested without a daemon.
+package fakedocker
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/api/types/system"
+	"github.com/docker/docker/errdefs"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// APIVersion is the Engine API version reported by the fake daemon.
+const APIVersion = "1.48"
+
+// Capacity of the host reported by Info.
+const (
+	HostCPUs   = 4
+	HostMemory = 8 << 30
+)
+
+// Container is the state the fake engine keeps for every created container.
+type Container struct {
+	ID         string
+	Name       string
+	Image      string
+	ImageID    string
+	Config     *container.Config
+	HostConfig *container.HostConfig
+	Networking *network.NetworkingConfig
+	State      string
+	ExitCode   int
+	Created    time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// Health is the healthcheck status, empty when the container has none.
+	Health string
+	// Signals lists the signals sent by kill and by stop or restart with a
+	// signal, in order.
+	Signals []string
+	// TTYSize is the last size, [height, width], set by ContainerResize.
+	TTYSize [2]uint
+
+	logs  []logEntry
+	stats []container.StatsResponse
+	stdin bytes.Buffer
+	files map[string]*file
+}
+
+// Engine is an in-memory stand-in for the Docker daemon.
+// The zero value is not usable, use New instead.
+type Engine struct {
+	mu         sync.Mutex
+	seq        int
+	pulls      int
+	containers map[string]*Container
+	images     map[string]*Image
+	networks   map[string]*Network
+	volumes    map[string]*Volume
+	registries map[string]registry.AuthConfig
+	failures   map[string]error
+	// crashes holds the exit code of the images whose containers exit
+	// right after starting.
+	crashes map[string]int
+	execs   map[string]*execSession
+	// execFunc runs the commands of exec sessions, see OnExec.
+	execFunc ExecFunc
+	// events holds every event emitted, oldest first.
+	events []events.Message
+	// changed is closed and replaced on every state mutation so streams
+	// following a container can wake up.
+	changed chan struct{}
+	closed  bool
+}
+
+func New() *Engine {
+	return &Engine{
+		containers: make(map[string]*Container),
+		images:     make(map[string]*Image),
+		networks:   make(map[string]*Network),
+		volumes:    make(map[string]*Volume),
+		registries: make(map[string]registry.AuthConfig),
+		failures:   make(map[string]error),
+		crashes:    make(map[string]int),
+		execs:      make(map[string]*execSession),
+		changed:    make(chan struct{}),
+	}
+}
+
+// FailOn makes every following call to the named method (e.g. "ContainerStart")
+// return err. Passing a nil error clears the failure.
+func (e *Engine) FailOn(method string, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err == nil {
+		delete(e.failures, method)
+		return
+	}
+	e.failures[method] = err
+}
+
+// CrashOnStart makes the containers of the image exit with code right
+// after they start, like a broken release would.
+func (e *Engine) CrashOnStart(ref string, code int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.crashes[ref] = code
+}
+
+// Closed reports whether Close was called on the engine.
+func (e *Engine) Closed() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.closed
+}
+
+// Container returns a copy of the container state, looked up by ID, ID prefix or name.
+func (e *Engine) Container(ref string) (Container, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		return Container{}, false
+	}
+
+	return *c, true
+}
+
+// ContainerCount returns the number of containers known to the engine.
+func (e *Engine) ContainerCount() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return len(e.containers)
+}
+
+// SetHealth overrides the healthcheck status of the container, e.g.
+// container.Unhealthy. Containers with a healthcheck become healthy on start.
+func (e *Engine) SetHealth(ref, status string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		return err
+	}
+
+	c.Health = status
+	e.emitContainer(c, events.Action(string(events.ActionHealthStatus)+": "+status), nil)
+
+	return nil
+}
+
+func (e *Engine) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.closed = true
+	return nil
+}
+
+// Ping fails when a failure was registered for "Ping", which lets tests
+// simulate an unreachable daemon.
+func (e *Engine) Ping(ctx context.Context) (types.Ping, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("Ping"); err != nil {
+		return types.Ping{}, err
+	}
+
+	return types.Ping{APIVersion: APIVersion, OSType: "linux"}, nil
+}
+
+// Info reports a host with HostCPUs CPUs and HostMemory bytes of memory.
+func (e *Engine) Info(ctx context.Context) (system.Info, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("Info"); err != nil {
+		return system.Info{}, err
+	}
+
+	return system.Info{
+		ID:              "fake",
+		Name:            "fakedocker",
+		OSType:          "linux",
+		NCPU:            HostCPUs,
+		MemTotal:        HostMemory,
+		ServerVersion:   "28.0.1",
+		Containers:      len(e.containers),
+		Images:          len(e.images),
+		OperatingSystem: "fakedocker",
+	}, nil
+}
+
+func (e *Engine) DaemonHost() string {
+	return "unix:///var/run/fake-docker.sock"
+}
+
+func (e *Engine) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *ocispec.Platform, containerName string) (container.CreateResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("ContainerCreate"); err != nil {
+		return container.CreateResponse{}, err
+	}
+
+	if config == nil || config.Image == "" {
+		return container.CreateResponse{}, errdefs.InvalidParameter(fmt.Errorf("config.Image is required"))
+	}
+	img, ok := e.lookupImage(config.Image)
+	if !ok {
+		return container.CreateResponse{}, errdefs.NotFound(fmt.Errorf("No such image: %s", config.Image))
+	}
+	if platform != nil && !img.matches(platform) {
+		return container.CreateResponse{}, errdefs.NotFound(fmt.Errorf("image with reference %s was found but does not match the specified platform: wanted %s/%s, actual: %s/%s",
+			config.Image, platform.OS, platform.Architecture, img.OS, img.Architecture))
+	}
+
+	e.seq++
+	id := newID(fmt.Sprintf("container-%d", e.seq))
+	name := strings.TrimPrefix(containerName, "/")
+	if name == "" {
+		name = fmt.Sprintf("fake_container_%d", e.seq)
+	}
+	for _, c := range e.containers {
+		if c.Name == name {
+			return container.CreateResponse{}, errdefs.Conflict(fmt.Errorf("Conflict. The container name \"/%s\" is already in use by container \"%s\"", name, c.ID))
+		}
+	}
+
+	if networkingConfig != nil {
+		for name := range networkingConfig.EndpointsConfig {
+			if _, err := e.lookupNetwork(name); err != nil && !builtinNetworks[name] {
+				return container.CreateResponse{}, err
+			}
+		}
+	}
+
+	if hostConfig == nil {
+		hostConfig = &container.HostConfig{}
+	}
+	// Like the daemon, named volumes are created on first use.
+	for _, m := range hostConfig.Mounts {
+		if m.Type == mount.TypeVolume && m.Source != "" {
+			e.createVolume(m.Source, "", nil)
+		}
+	}
+
+	c := &Container{
+		ID:         id,
+		Name:       name,
+		Image:      config.Image,
+		ImageID:    img.ID,
+		Config:     config,
+		HostConfig: hostConfig,
+		Networking: networkingConfig,
+		State:      "created",
+		Created:    time.Now().UTC(),
+		files:      maps.Clone(img.files),
+	}
+	e.containers[id] = c
+	e.emitContainer(c, events.ActionCreate, nil)
+
+	return container.CreateResponse{ID: id}, nil
+}
+
+func (e *Engine) ContainerInspect(ctx context.Context, ref string) (container.InspectResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("ContainerInspect"); err != nil {
+		return container.InspectResponse{}, err
+	}
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		return container.InspectResponse{}, err
+	}
+
+	return c.inspect(), nil
+}
+
+func (e *Engine) ContainerList(ctx context.Context, options container.ListOptions) ([]container.Summary, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("ContainerList"); err != nil {
+		return nil, err
+	}
+
+	out := make([]container.Summary, 0, len(e.containers))
+	for _, c := range e.sorted() {
+		if !options.All && c.State != "running" {
+			continue
+		}
+		if !matchesFilters(c, options) {
+			continue
+		}
+		out = append(out, c.summary())
+	}
+
+	return out, nil
+}
+
+func (e *Engine) ContainerStart(ctx context.Context, ref string, options container.StartOptions) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("ContainerStart"); err != nil {
+		return err
+	}
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		return err
+	}
+
+	if c.State != "running" {
+		c.State = "running"
+		c.ExitCode = 0
+		c.StartedAt = time.Now().UTC()
+		if hc := c.Config.Healthcheck; hc != nil && len(hc.Test) > 0 && hc.Test[0] != "NONE" {
+			c.Health = container.Healthy
+		}
+		e.emitContainer(c, events.ActionStart, nil)
+		if code, ok := e.crashes[c.Image]; ok {
+			c.State = "exited"
+			c.ExitCode = code
+			c.FinishedAt = time.Now().UTC()
+			e.emitContainer(c, events.ActionDie, map[string]string{"exitCode": strconv.Itoa(code)})
+		}
+	}
+
+	return nil
+}
+
+func (e *Engine) ContainerStop(ctx context.Context, ref string, options container.StopOptions) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("ContainerStop"); err != nil {
+		return err
+	}
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		return err
+	}
+
+	if options.Signal != "" {
+		c.Signals = append(c.Signals, options.Signal)
+	}
+	if e.stop(c, 0) {
+		e.emitContainer(c, events.ActionStop, nil)
+	}
+	return nil
+}
+
+func (e *Engine) ContainerRestart(ctx context.Context, ref string, options container.StopOptions) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("ContainerRestart"); err != nil {
+		return err
+	}
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		return err
+	}
+
+	if options.Signal != "" {
+		c.Signals = append(c.Signals, options.Signal)
+	}
+	e.stop(c, 0)
+	c.State = "running"
+	c.StartedAt = time.Now().UTC()
+	e.emitContainer(c, events.ActionStart, nil)
+	e.emitContainer(c, events.ActionRestart, nil)
+
+	return nil
+}
+
+// ContainerKill records the signal, the container exits on SIGKILL, SIGTERM
+// and SIGINT and keeps running on any other signal.
+func (e *Engine) ContainerKill(ctx context.Context, ref, signal string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("ContainerKill"); err != nil {
+		return err
+	}
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		return err
+	}
+	if c.State != "running" && c.State != "paused" {
+		return errdefs.Conflict(fmt.Errorf("Cannot kill container: %s: container %s is not running", ref, c.ID))
+	}
+
+	if signal == "" {
+		signal = "SIGKILL"
+	}
+	c.Signals = append(c.Signals, signal)
+	e.emitContainer(c, events.ActionKill, map[string]string{"signal": signal})
+	switch strings.TrimPrefix(strings.ToUpper(signal), "SIG") {
+	case "KILL", "9":
+		e.stop(c, 137)
+	case "TERM", "15":
+		e.stop(c, 143)
+	case "INT", "2":
+		e.stop(c, 130)
+	}
+
+	return nil
+}
+
+func (e *Engine) ContainerPause(ctx context.Context, ref string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("ContainerPause"); err != nil {
+		return err
+	}
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		return err
+	}
+	switch c.State {
+	case "running":
+	case "paused":
+		return errdefs.Conflict(fmt.Errorf("cannot pause container %s: container is already paused", c.ID))
+	default:
+		return errdefs.Conflict(fmt.Errorf("cannot pause container %s: container is not running", c.ID))
+	}
+
+	c.State = "paused"
+	e.emitContainer(c, events.ActionPause, nil)
+	return nil
+}
+
+func (e *Engine) ContainerUnpause(ctx context.Context, ref string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("ContainerUnpause"); err != nil {
+		return err
+	}
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		return err
+	}
+	if c.State != "paused" {
+		return errdefs.Conflict(fmt.Errorf("cannot unpause container %s: container is not paused", c.ID))
+	}
+
+	c.State = "running"
+	e.emitContainer(c, events.ActionUnPause, nil)
+	return nil
+}
+
+func (e *Engine) ContainerRename(ctx context.Context, ref, newName string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("ContainerRename"); err != nil {
+		return err
+	}
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		return err
+	}
+
+	newName = strings.TrimPrefix(newName, "/")
+	for _, other := range e.containers {
+		if other.Name == newName && other.ID != c.ID {
+			return errdefs.Conflict(fmt.Errorf("Conflict. The container name \"/%s\" is already in use by container \"%s\"", newName, other.ID))
+		}
+	}
+	oldName := c.Name
+	c.Name = newName
+	e.emitContainer(c, events.ActionRename, map[string]string{"oldName": "/" + oldName})
+
+	return nil
+}
+
+// ContainerUpdate applies the limits that are set, like the daemon it
+// refuses a memory limit above the memory and swap limit.
+func (e *Engine) ContainerUpdate(ctx context.Context, ref string, updateConfig container.UpdateConfig) (container.UpdateResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("ContainerUpdate"); err != nil {
+		return container.UpdateResponse{}, err
+	}
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		return container.UpdateResponse{}, err
+	}
+
+	res := updateConfig.Resources
+	hc := c.HostConfig
+	memory, swap := hc.Memory, hc.MemorySwap
+	if res.Memory != 0 {
+		memory = res.Memory
+	}
+	if res.MemorySwap != 0 {
+		swap = res.MemorySwap
+	}
+	if swap > 0 && memory > swap {
+		return container.UpdateResponse{}, errdefs.InvalidParameter(fmt.Errorf("Memory limit should be smaller than already set memoryswap limit, update the memoryswap at the same time"))
+	}
+
+	hc.Memory, hc.MemorySwap = memory, swap
+	if res.NanoCPUs != 0 {
+		hc.NanoCPUs = res.NanoCPUs
+	}
+	if res.CPUShares != 0 {
+		hc.CPUShares = res.CPUShares
+	}
+	if res.CPUQuota != 0 {
+		hc.CPUQuota = res.CPUQuota
+	}
+	if res.CPUPeriod != 0 {
+		hc.CPUPeriod = res.CPUPeriod
+	}
+	if res.CpusetCpus != "" {
+		hc.CpusetCpus = res.CpusetCpus
+	}
+	if res.PidsLimit != nil {
+		limit := *res.PidsLimit
+		hc.PidsLimit = &limit
+	}
+	if updateConfig.RestartPolicy.Name != "" {
+		hc.RestartPolicy = updateConfig.RestartPolicy
+	}
+	e.emitContainer(c, events.ActionUpdate, nil)
+
+	return container.UpdateResponse{}, nil
+}
+
+func (e *Engine) ContainerRemove(ctx context.Context, ref string, options container.RemoveOptions) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("ContainerRemove"); err != nil {
+		return err
+	}
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		return err
+	}
+
+	if c.State == "running" && !options.Force {
+		return errdefs.Conflict(fmt.Errorf("You cannot remove a running container %s. Stop the container before attempting removal or force remove", c.ID))
+	}
+
+	e.stop(c, 137)
+	delete(e.containers, c.ID)
+	e.emitContainer(c, events.ActionDestroy, nil)
+
+	return nil
+}
+
+// stop moves a running container into the exited state with the exit code
+// and reports whether it was running. Callers must hold e.mu.
+func (e *Engine) stop(c *Container, code int) bool {
+	if c.State != "running" && c.State != "paused" {
+		return false
+	}
+
+	c.State = "exited"
+	c.ExitCode = code
+	c.FinishedAt = time.Now().UTC()
+	e.emitContainer(c, events.ActionDie, map[string]string{"exitCode": strconv.Itoa(code)})
+	return true
+}
+
+// lookup resolves a container by full ID, unique ID prefix or name.
+// Callers must hold e.mu.
+func (e *Engine) lookup(ref string) (*Container, error) {
+	if c, ok := e.containers[ref]; ok {
+		return c, nil
+	}
+
+	name := strings.TrimPrefix(ref, "/")
+	var match *Container
+	for _, c := range e.containers {
+		if c.Name == name {
+			return c, nil
+		}
+		if ref != "" && strings.HasPrefix(c.ID, ref) {
+			if match != nil {
+				return nil, errdefs.InvalidParameter(fmt.Errorf("multiple IDs found with provided prefix: %s", ref))
+			}
+			match = c
+		}
+	}
+
+	if match == nil {
+		return nil, errdefs.NotFound(fmt.Errorf("No such container: %s", ref))
+	}
+
+	return match, nil
+}
+
+// sorted returns the containers ordered from newest to oldest, like the daemon.
+// Callers must hold e.mu.
+func (e *Engine) sorted() []*Container {
+	out := make([]*Container, 0, len(e.containers))
+	for _, c := range e.containers {
+		out = append(out, c)
+	}
+
+	for i := 1; i < len(out); i++ {
+		for j := i; j > 0 && out[j].newerThan(out[j-1]); j-- {
+			out[j], out[j-1] = out[j-1], out[j]
+		}
+	}
+
+	return out
+}
+
+func (e *Engine) failure(method string) error {
+	return e.failures[method]
+}
+
+// notify wakes up every stream waiting for a change. Callers must hold e.mu.
+func (e *Engine) notify() {
+	close(e.changed)
+	e.changed = make(chan struct{})
+}
+
+func (c *Container) newerThan(o *Container) bool {
+	if c.Created.Equal(o.Created) {
+		return c.ID > o.ID
+	}
+
+	return c.Created.After(o.Created)
+}
+
+func (c *Container) status() string {
+	switch c.State {
+	case "running":
+		return "Up " + time.Since(c.StartedAt).Round(time.Second).String()
+	case "paused":
+		return "Up " + time.Since(c.StartedAt).Round(time.Second).String() + " (Paused)"
+	case "exited":
+		return fmt.Sprintf("Exited (%d) %s ago", c.ExitCode, time.Since(c.FinishedAt).Round(time.Second))
+	default:
+		return "Created"
+	}
+}
+
+func (c *Container) summary() container.Summary {
+	var ports []container.Port
+	for port, bindings := range c.HostConfig.PortBindings {
+		for _, b := range bindings {
+			var public int
+			fmt.Sscanf(b.HostPort, "%d", &public)
+			ports = append(ports, container.Port{
+				IP:          b.HostIP,
+				PrivatePort: uint16(port.Int()),
+				PublicPort:  uint16(public),
+				Type:        port.Proto(),
+			})
+		}
+	}
+
+	return container.Summary{
+		ID:      c.ID,
+		Names:   []string{"/" + c.Name},
+		Image:   c.Image,
+		ImageID: c.ImageID,
+		Command: strings.Join(append(append([]string{}, c.Config.Entrypoint...), c.Config.Cmd...), " "),
+		Created: c.Created.Unix(),
+		Ports:   ports,
+		Labels:  c.Config.Labels,
+		State:   c.State,
+		Status:  c.status(),
+	}
+}
+
+func (c *Container) inspect() container.InspectResponse {
+	state := &container.State{
+		Status:   c.State,
+		Running:  c.State == "running" || c.State == "paused",
+		Paused:   c.State == "paused",
+		ExitCode: c.ExitCode,
+	}
+	if !c.StartedAt.IsZero() {
+		state.StartedAt = c.StartedAt.Format(time.RFC3339Nano)
+	}
+	if !c.FinishedAt.IsZero() {
+		state.FinishedAt = c.FinishedAt.Format(time.RFC3339Nano)
+	}
+	if c.Health != "" {
+		state.Health = &container.Health{Status: c.Health}
+	}
+
+	networks := make(map[string]*network.EndpointSettings)
+	if c.Networking != nil {
+		for name, ep := range c.Networking.EndpointsConfig {
+			networks[name] = ep
+		}
+	}
+	if len(networks) == 0 {
+		networks["bridge"] = &network.EndpointSettings{}
+	}
+
+	var mounts []container.MountPoint
+	for _, m := range c.HostConfig.Mounts {
+		point := container.MountPoint{Type: m.Type, Source: m.Source, Destination: m.Target, RW: !m.ReadOnly}
+		if m.Type == mount.TypeVolume {
+			point.Name = m.Source
+		}
+		mounts = append(mounts, point)
+	}
+
+	return container.InspectResponse{
+		Mounts: mounts,
+		ContainerJSONBase: &container.ContainerJSONBase{
+			ID:         c.ID,
+			Created:    c.Created.Format(time.RFC3339Nano),
+			Path:       firstOf(c.Config.Entrypoint, c.Config.Cmd),
+			Args:       c.Config.Cmd,
+			State:      state,
+			Image:      c.ImageID,
+			Name:       "/" + c.Name,
+			Driver:     "overlay2",
+			Platform:   "linux",
+			HostConfig: c.HostConfig,
+		},
+		Config: c.Config,
+		NetworkSettings: &container.NetworkSettings{
+			Networks: networks,
+		},
+	}
+}
+
+func firstOf(lists ...[]string) string {
+	for _, l := range lists {
+		if len(l) > 0 {
+			return l[0]
+		}
+	}
+
+	return ""
+}
+
+func matchesFilters(c *Container, options container.ListOptions) bool {
+	if options.Filters.Len() == 0 {
+		return true
+	}
+
+	if !matchesLabels(c.Config.Labels, options.Filters.Get("label")) {
+		return false
+	}
+
+	if names := options.Filters.Get("name"); len(names) > 0 {
+		found := false
+		for _, name := range names {
+			if strings.Contains(c.Name, strings.TrimPrefix(name, "/")) {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if ids := options.Filters.Get("id"); len(ids) > 0 {
+		found := false
+		for _, id := range ids {
+			if strings.HasPrefix(c.ID, id) {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if states := options.Filters.Get("status"); len(states) > 0 {
+		found := false
+		for _, state := range states {
+			if c.State == state {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchesLabels reports whether labels satisfy every "key" or "key=value" filter.
+func matchesLabels(labels map[string]string, filters []string) bool {
+	for _, label := range filters {
+		key, value, hasValue := strings.Cut(label, "=")
+		got, ok := labels[key]
+		if !ok || (hasValue && got != value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sortedByName returns the values of m ordered by the given name.
+func sortedByName[T any](m map[string]*T, name func(*T) string) []*T {
+	out := make([]*T, 0, len(m))
+	for _, v := range m {
+		out = append(out, v)
+	}
+	sort.Slice(out, func(i, j int) bool { return name(out[i]) < name(out[j]) })
+
+	return out
+}
+
+func newID(seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+	return hex.EncodeToString(sum[:])
+}
+
+func imageID(ref string) string {
+	return "sha256:" + newID("image-"+ref)
+}
@@ -0,0 +1,181 @@
+package fakedocker
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	timetypes "github.com/docker/docker/api/types/time"
+	"github.com/docker/docker/errdefs"
+)
+
+// Emit records an event the fake does not produce by itself, such as an
+// oom, as if the daemon had. Time is set when zero.
+func (e *Engine) Emit(msg events.Message) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.record(msg)
+}
+
+// Events streams the events matching the filters of options. Like the
+// daemon, past events are only replayed from options.Since and the stream
+// ends with io.EOF once options.Until has passed.
+func (e *Engine) Events(ctx context.Context, options events.ListOptions) (<-chan events.Message, <-chan error) {
+	messages := make(chan events.Message)
+	errs := make(chan error, 1)
+
+	e.mu.Lock()
+	if err := e.failure("Events"); err != nil {
+		e.mu.Unlock()
+		errs <- err
+		return messages, errs
+	}
+	since, until, err := eventRange(options)
+	if err != nil {
+		e.mu.Unlock()
+		errs <- errdefs.InvalidParameter(err)
+		return messages, errs
+	}
+	next := len(e.events)
+	if options.Since != "" {
+		next = 0
+	}
+	e.mu.Unlock()
+
+	go func() {
+		for {
+			e.mu.Lock()
+			pending := e.events[next:]
+			next = len(e.events)
+			changed := e.changed
+			e.mu.Unlock()
+
+			for _, msg := range pending {
+				if !until.IsZero() && time.Unix(0, msg.TimeNano).After(until) {
+					errs <- io.EOF
+					return
+				}
+				if time.Unix(0, msg.TimeNano).Before(since) || !matchesEvent(msg, options.Filters) {
+					continue
+				}
+				select {
+				case messages <- msg:
+				case <-ctx.Done():
+					errs <- ctx.Err()
+					return
+				}
+			}
+
+			var deadline <-chan time.Time
+			if !until.IsZero() {
+				deadline = time.After(time.Until(until))
+			}
+			select {
+			case <-changed:
+			case <-deadline:
+				errs <- io.EOF
+				return
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return messages, errs
+}
+
+// emitContainer records an event of the container carrying its name, image
+// and labels along with the extra attributes. Callers must hold e.mu.
+func (e *Engine) emitContainer(c *Container, action events.Action, extra map[string]string) {
+	attrs := map[string]string{"name": c.Name, "image": c.Image}
+	for k, v := range c.Config.Labels {
+		attrs[k] = v
+	}
+	for k, v := range extra {
+		attrs[k] = v
+	}
+	e.emit(events.ContainerEventType, action, c.ID, attrs)
+}
+
+// emit records an event. Callers must hold e.mu.
+func (e *Engine) emit(typ events.Type, action events.Action, id string, attrs map[string]string) {
+	e.record(events.Message{Type: typ, Action: action, Actor: events.Actor{ID: id, Attributes: attrs}})
+}
+
+// record appends the event with a timestamp after the previous one, so
+// timestamps identify events, and wakes up the streams. Callers must hold
+// e.mu.
+func (e *Engine) record(msg events.Message) {
+	if msg.TimeNano == 0 {
+		msg.TimeNano = time.Now().UnixNano()
+	}
+	if n := len(e.events); n > 0 && msg.TimeNano <= e.events[n-1].TimeNano {
+		msg.TimeNano = e.events[n-1].TimeNano + 1
+	}
+	msg.Time = msg.TimeNano / int64(time.Second)
+	if msg.Scope == "" {
+		msg.Scope = "local"
+	}
+
+	e.events = append(e.events, msg)
+	e.notify()
+}
+
+func eventRange(options events.ListOptions) (since, until time.Time, err error) {
+	now := time.Now()
+	if options.Since != "" {
+		if since, err = eventTime(options.Since, now); err != nil {
+			return
+		}
+	}
+	if options.Until != "" {
+		until, err = eventTime(options.Until, now)
+	}
+	return
+}
+
+func eventTime(value string, now time.Time) (time.Time, error) {
+	ts, err := timetypes.GetTimestamp(value, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	sec, nsec, err := timetypes.ParseTimestamps(ts, 0)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(sec, nsec), nil
+}
+
+// matchesEvent applies the type, event, container, image, volume, network
+// and label filters the way the daemon does.
+func matchesEvent(msg events.Message, args filters.Args) bool {
+	action := string(msg.Action)
+	if prefix, _, ok := strings.Cut(action, ":"); ok && !args.ExactMatch("event", action) {
+		action = prefix
+	}
+	if !args.ExactMatch("type", string(msg.Type)) || !args.ExactMatch("event", action) || !args.MatchKVList("label", msg.Actor.Attributes) {
+		return false
+	}
+
+	for key, typ := range map[string]events.Type{
+		"container": events.ContainerEventType,
+		"image":     events.ImageEventType,
+		"volume":    events.VolumeEventType,
+		"network":   events.NetworkEventType,
+	} {
+		if !args.Contains(key) {
+			continue
+		}
+		if msg.Type != typ || !(args.ExactMatch(key, msg.Actor.ID) || args.ExactMatch(key, msg.Actor.Attributes["name"])) {
+			return false
+		}
+	}
+
+	return true
+}
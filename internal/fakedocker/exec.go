@@ -0,0 +1,222 @@
+package fakedocker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// ExecFunc runs the command of an exec session: it reads the input from
+// stdin, writes to stdout and stderr and returns the exit code. ctx is
+// canceled once the client closes the attached connection.
+type ExecFunc func(ctx context.Context, options container.ExecOptions, stdin io.Reader, stdout, stderr io.Writer) int
+
+type execSession struct {
+	id        string
+	seq       int
+	container string
+	options   container.ExecOptions
+	started   bool
+	running   bool
+	exitCode  int
+	size      [2]uint
+}
+
+// OnExec sets the function running the commands of exec sessions. Without
+// one every command exits with 0 and no output.
+func (e *Engine) OnExec(fn ExecFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.execFunc = fn
+}
+
+// Execs returns the IDs of the exec sessions created in the container, in
+// creation order.
+func (e *Engine) Execs(ref string) []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		return nil
+	}
+
+	var sessions []*execSession
+	for _, s := range e.execs {
+		if s.container == c.ID {
+			sessions = append(sessions, s)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].seq < sessions[j].seq })
+
+	ids := make([]string, 0, len(sessions))
+	for _, s := range sessions {
+		ids = append(ids, s.id)
+	}
+	return ids
+}
+
+// ExecSize returns the terminal size, [height, width], of the exec session.
+func (e *Engine) ExecSize(execID string) [2]uint {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if s, ok := e.execs[execID]; ok {
+		return s.size
+	}
+	return [2]uint{}
+}
+
+func (e *Engine) ContainerExecCreate(ctx context.Context, ref string, options container.ExecOptions) (container.ExecCreateResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("ContainerExecCreate"); err != nil {
+		return container.ExecCreateResponse{}, err
+	}
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		return container.ExecCreateResponse{}, err
+	}
+	switch c.State {
+	case "running":
+	case "paused":
+		return container.ExecCreateResponse{}, errdefs.Conflict(fmt.Errorf("container %s is paused, unpause the container before exec", c.ID))
+	default:
+		return container.ExecCreateResponse{}, errdefs.Conflict(fmt.Errorf("container %s is not running", c.ID))
+	}
+	if len(options.Cmd) == 0 {
+		return container.ExecCreateResponse{}, errdefs.InvalidParameter(fmt.Errorf("no exec command specified"))
+	}
+
+	e.seq++
+	s := &execSession{id: newID(fmt.Sprintf("exec-%d", e.seq)), seq: e.seq, container: c.ID, options: options}
+	if options.ConsoleSize != nil {
+		s.size = *options.ConsoleSize
+	}
+	e.execs[s.id] = s
+
+	return container.ExecCreateResponse{ID: s.id}, nil
+}
+
+// ContainerExecAttach starts the session and runs it on one end of an
+// in-memory connection, multiplexing the output unless it has a TTY.
+func (e *Engine) ContainerExecAttach(ctx context.Context, execID string, config container.ExecAttachOptions) (types.HijackedResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("ContainerExecAttach"); err != nil {
+		return types.HijackedResponse{}, err
+	}
+
+	s, ok := e.execs[execID]
+	if !ok {
+		return types.HijackedResponse{}, errdefs.NotFound(fmt.Errorf("No such exec instance: %s", execID))
+	}
+	if s.started {
+		return types.HijackedResponse{}, errdefs.Conflict(fmt.Errorf("Error: Exec command %s is already running", execID))
+	}
+	s.started, s.running = true, true
+
+	fn := e.execFunc
+	if fn == nil {
+		fn = func(context.Context, container.ExecOptions, io.Reader, io.Writer, io.Writer) int { return 0 }
+	}
+
+	client, server := net.Pipe()
+	go e.runExec(s, fn, server)
+
+	mediaType := types.MediaTypeMultiplexedStream
+	if s.options.Tty {
+		mediaType = types.MediaTypeRawStream
+	}
+	return types.NewHijackedResponse(client, mediaType), nil
+}
+
+func (e *Engine) runExec(s *execSession, fn ExecFunc, conn net.Conn) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The connection is read until the client closes it, forwarding the
+	// input when stdin is attached.
+	stdin, input := io.Pipe()
+	go func() {
+		defer cancel()
+		var err error
+		if s.options.AttachStdin {
+			_, err = io.Copy(input, conn)
+		} else {
+			_, err = io.Copy(io.Discard, conn)
+		}
+		input.CloseWithError(err)
+	}()
+
+	var stdout, stderr io.Writer = conn, conn
+	if !s.options.Tty {
+		stdout = stdcopy.NewStdWriter(conn, stdcopy.Stdout)
+		stderr = stdcopy.NewStdWriter(conn, stdcopy.Stderr)
+	}
+	if !s.options.AttachStdout {
+		stdout = io.Discard
+	}
+	if !s.options.AttachStderr {
+		stderr = io.Discard
+	}
+
+	code := fn(ctx, s.options, stdin, stdout, stderr)
+
+	e.mu.Lock()
+	s.running, s.exitCode = false, code
+	e.mu.Unlock()
+	conn.Close()
+}
+
+func (e *Engine) ContainerExecResize(ctx context.Context, execID string, options container.ResizeOptions) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("ContainerExecResize"); err != nil {
+		return err
+	}
+
+	s, ok := e.execs[execID]
+	if !ok {
+		return errdefs.NotFound(fmt.Errorf("No such exec instance: %s", execID))
+	}
+	if !s.running {
+		return errdefs.Conflict(fmt.Errorf("Exec %s is not running", execID))
+	}
+
+	s.size = [2]uint{options.Height, options.Width}
+	return nil
+}
+
+func (e *Engine) ContainerExecInspect(ctx context.Context, execID string) (container.ExecInspect, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("ContainerExecInspect"); err != nil {
+		return container.ExecInspect{}, err
+	}
+
+	s, ok := e.execs[execID]
+	if !ok {
+		return container.ExecInspect{}, errdefs.NotFound(fmt.Errorf("No such exec instance: %s", execID))
+	}
+
+	return container.ExecInspect{
+		ExecID:      s.id,
+		ContainerID: s.container,
+		Running:     s.running,
+		ExitCode:    s.exitCode,
+	}, nil
+}
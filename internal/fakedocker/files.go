@@ -0,0 +1,340 @@
+package fakedocker
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/errdefs"
+)
+
+// file is an entry of the filesystem of a fake container.
+type file struct {
+	mode  os.FileMode
+	data  []byte
+	link  string
+	mtime time.Time
+	uid   int
+	gid   int
+}
+
+// WriteFile creates or replaces a file in the container, creating its
+// parent directories.
+func (e *Engine) WriteFile(ref, name string, data []byte, mode os.FileMode) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		return err
+	}
+
+	files, rel := e.tree(c, path.Clean(name))
+	mkdirAll(files, path.Dir(rel))
+	files[rel] = &file{mode: mode.Perm(), data: bytes.Clone(data), mtime: time.Now().UTC()}
+	return nil
+}
+
+// ReadFile returns the content of a file of the container.
+func (e *Engine) ReadFile(ref, name string) ([]byte, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		return nil, false
+	}
+
+	f, ok := e.view(c)[path.Clean(name)]
+	if !ok || !f.mode.IsRegular() {
+		return nil, false
+	}
+	return bytes.Clone(f.data), true
+}
+
+// ReadVolumeFile returns the content of a file of the named volume, name
+// being relative to its root.
+func (e *Engine) ReadVolumeFile(volume, name string) ([]byte, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	v, ok := e.volumes[volume]
+	if !ok {
+		return nil, false
+	}
+	f, ok := v.fs()[path.Clean("/"+name)]
+	if !ok || !f.mode.IsRegular() {
+		return nil, false
+	}
+	return bytes.Clone(f.data), true
+}
+
+func (e *Engine) ContainerStatPath(ctx context.Context, ref, name string) (container.PathStat, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("ContainerStatPath"); err != nil {
+		return container.PathStat{}, err
+	}
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		return container.PathStat{}, err
+	}
+
+	return stat(e.view(c), c.Name, name)
+}
+
+// CopyFromContainer returns a tar archive of the path, whose entries are
+// named after its base name like the daemon does.
+func (e *Engine) CopyFromContainer(ctx context.Context, ref, name string) (io.ReadCloser, container.PathStat, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("CopyFromContainer"); err != nil {
+		return nil, container.PathStat{}, err
+	}
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		return nil, container.PathStat{}, err
+	}
+	files := e.view(c)
+	stat, err := stat(files, c.Name, name)
+	if err != nil {
+		return nil, container.PathStat{}, err
+	}
+
+	root := path.Clean(name)
+	base := path.Base(root)
+	if root == "/" {
+		base = "."
+	}
+
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+	for _, p := range paths(files) {
+		if p != root && !strings.HasPrefix(p, strings.TrimSuffix(root, "/")+"/") {
+			continue
+		}
+		entry := path.Join(base, strings.TrimPrefix(p, root))
+		if base == "." && p != root {
+			entry = "./" + entry
+		}
+		if err := files[p].writeTar(tw, entry); err != nil {
+			return nil, container.PathStat{}, err
+		}
+	}
+	tw.Close()
+
+	return io.NopCloser(buf), stat, nil
+}
+
+// CopyToContainer extracts a tar archive, optionally gzip compressed, into
+// an existing directory of the container.
+func (e *Engine) CopyToContainer(ctx context.Context, ref, dstPath string, content io.Reader, options container.CopyToContainerOptions) error {
+	// The archive is read before locking, it may be produced concurrently.
+	entries, err := readTar(content)
+	if err != nil {
+		return errdefs.InvalidParameter(err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("CopyToContainer"); err != nil {
+		return err
+	}
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		return err
+	}
+	dir := path.Clean(dstPath)
+	view := e.view(c)
+	if f, ok := view[dir]; !ok {
+		return errdefs.NotFound(fmt.Errorf("Could not find the file %s in container %s", dstPath, ref))
+	} else if !f.mode.IsDir() {
+		return errdefs.InvalidParameter(fmt.Errorf("extraction point is not a directory"))
+	}
+
+	for _, entry := range entries {
+		name := path.Join(dir, entry.name)
+		if existing, ok := view[name]; ok && existing.mode.IsDir() && !entry.file.mode.IsDir() && !options.AllowOverwriteDirWithFile {
+			return errdefs.InvalidParameter(fmt.Errorf("cannot overwrite directory %q with non-directory", name))
+		}
+		files, rel := e.tree(c, name)
+		mkdirAll(files, path.Dir(rel))
+		files[rel] = entry.file
+	}
+	e.notify()
+
+	return nil
+}
+
+type tarEntry struct {
+	name string
+	file *file
+}
+
+func readTar(r io.Reader) ([]tarEntry, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		r = gz
+	} else {
+		r = br
+	}
+
+	var entries []tarEntry
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, tarEntry{
+			name: path.Clean("/" + hdr.Name),
+			file: &file{mode: hdr.FileInfo().Mode(), data: data, link: hdr.Linkname, mtime: hdr.ModTime, uid: hdr.Uid, gid: hdr.Gid},
+		})
+	}
+}
+
+func (f *file) writeTar(tw *tar.Writer, name string) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    int64(f.mode.Perm()),
+		ModTime: f.mtime,
+		Uid:     f.uid,
+		Gid:     f.gid,
+	}
+	switch {
+	case f.mode.IsDir():
+		hdr.Typeflag, hdr.Name = tar.TypeDir, name+"/"
+	case f.mode&os.ModeSymlink != 0:
+		hdr.Typeflag, hdr.Linkname = tar.TypeSymlink, f.link
+	default:
+		hdr.Typeflag, hdr.Size = tar.TypeReg, int64(len(f.data))
+	}
+
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := tw.Write(f.data)
+	return err
+}
+
+// fs returns the filesystem of the container, which starts with an empty
+// root directory. Volumes are not part of it. Callers must hold e.mu.
+func (c *Container) fs() map[string]*file {
+	if c.files == nil {
+		c.files = map[string]*file{"/": {mode: os.ModeDir | 0o755, mtime: c.Created}}
+	}
+	return c.files
+}
+
+// fs returns the files of the volume, rooted at "/".
+func (v *Volume) fs() map[string]*file {
+	if v.files == nil {
+		v.files = map[string]*file{"/": {mode: os.ModeDir | 0o755, mtime: v.Created}}
+	}
+	return v.files
+}
+
+// tree returns the filesystem holding the path of the container, the one of
+// the volume mounted on it if any, and the path inside it. Callers must
+// hold e.mu.
+func (e *Engine) tree(c *Container, p string) (map[string]*file, string) {
+	target := ""
+	var files map[string]*file
+	for _, m := range c.HostConfig.Mounts {
+		v, ok := e.volumes[m.Source]
+		if m.Type != mount.TypeVolume || !ok || len(m.Target) <= len(target) {
+			continue
+		}
+		if p == m.Target || strings.HasPrefix(p, m.Target+"/") {
+			target, files = m.Target, v.fs()
+		}
+	}
+	if files == nil {
+		return c.fs(), p
+	}
+
+	return files, path.Join("/", strings.TrimPrefix(p, target))
+}
+
+// view returns the filesystem of the container as its processes see it,
+// with its volumes mounted. Callers must hold e.mu.
+func (e *Engine) view(c *Container) map[string]*file {
+	files := maps.Clone(c.fs())
+	for _, m := range c.HostConfig.Mounts {
+		v, ok := e.volumes[m.Source]
+		if m.Type != mount.TypeVolume || !ok {
+			continue
+		}
+		mkdirAll(files, m.Target)
+		for rel, f := range v.fs() {
+			files[path.Join(m.Target, rel)] = f
+		}
+	}
+
+	return files
+}
+
+func mkdirAll(files map[string]*file, dir string) {
+	for p := dir; ; p = path.Dir(p) {
+		if _, ok := files[p]; !ok {
+			files[p] = &file{mode: os.ModeDir | 0o755, mtime: time.Now().UTC()}
+		}
+		if p == "/" {
+			return
+		}
+	}
+}
+
+func paths(files map[string]*file) []string {
+	out := make([]string, 0, len(files))
+	for p := range files {
+		out = append(out, p)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func stat(files map[string]*file, owner, name string) (container.PathStat, error) {
+	f, ok := files[path.Clean(name)]
+	if !ok {
+		return container.PathStat{}, errdefs.NotFound(fmt.Errorf("Could not find the file %s in container %s", name, owner))
+	}
+
+	return container.PathStat{
+		Name:       path.Base(path.Clean(name)),
+		Size:       int64(len(f.data)),
+		Mode:       f.mode,
+		Mtime:      f.mtime,
+		LinkTarget: f.link,
+	}, nil
+}
@@ -0,0 +1,209 @@
+package fakedocker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/jsonmessage"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Image is an image known to the fake engine.
+type Image struct {
+	Ref          string
+	ID           string
+	OS           string
+	Architecture string
+	Variant      string
+	Layers       []int64
+	// Comment, Author and Changes are set on committed and imported images.
+	Comment string
+	Author  string
+	Changes []string
+	// Paused reports whether the container was paused while committed.
+	Paused bool
+
+	// files is the filesystem new containers of the image start with.
+	files map[string]*file
+}
+
+// defaultLayers are the layer sizes reported while pulling an image.
+var defaultLayers = []int64{2048, 4096}
+
+// Pulls returns how many times ImagePull was called, so tests can tell
+// whether a pull policy skipped it.
+func (e *Engine) Pulls() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.pulls
+}
+
+// AddImage registers ref as already present on the engine for linux/amd64.
+func (e *Engine) AddImage(ref string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.images[ref] = newImage(ref, "")
+}
+
+func (e *Engine) ImageInspect(ctx context.Context, imageID string, inspectOpts ...client.ImageInspectOption) (image.InspectResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("ImageInspect"); err != nil {
+		return image.InspectResponse{}, err
+	}
+
+	img, ok := e.lookupImage(imageID)
+	if !ok {
+		return image.InspectResponse{}, errdefs.NotFound(fmt.Errorf("No such image: %s", imageID))
+	}
+
+	var tags []string
+	if img.Ref != "" {
+		tags = []string{img.Ref}
+	}
+	return image.InspectResponse{
+		ID:           img.ID,
+		RepoTags:     tags,
+		Os:           img.OS,
+		Architecture: img.Architecture,
+		Variant:      img.Variant,
+		Comment:      img.Comment,
+		Author:       img.Author,
+	}, nil
+}
+
+// lookupImage resolves an image by reference or ID. Callers must hold e.mu.
+func (e *Engine) lookupImage(ref string) (*Image, bool) {
+	if img, ok := e.images[ref]; ok {
+		return img, true
+	}
+	for _, candidate := range e.images {
+		if candidate.ID == ref {
+			return candidate, true
+		}
+	}
+
+	return nil, false
+}
+
+// Image returns a copy of the image known by reference or ID.
+func (e *Engine) Image(ref string) (Image, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	img, ok := e.lookupImage(ref)
+	if !ok {
+		return Image{}, false
+	}
+	return *img, true
+}
+
+// HasImage reports whether ref has been pulled or added to the engine.
+func (e *Engine) HasImage(ref string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	_, ok := e.images[ref]
+	return ok
+}
+
+// ImagePull stores the image and returns the progress messages the daemon
+// would emit while downloading and extracting its layers.
+func (e *Engine) ImagePull(ctx context.Context, ref string, options image.PullOptions) (io.ReadCloser, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("ImagePull"); err != nil {
+		return nil, err
+	}
+	if err := e.authorizePull(ref, options.RegistryAuth); err != nil {
+		return nil, err
+	}
+
+	e.pulls++
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+
+	if img, ok := e.images[ref]; ok && (options.Platform == "" || img.platform() == options.Platform) {
+		enc.Encode(jsonmessage.JSONMessage{Status: "Status: Image is up to date for " + ref})
+		return io.NopCloser(buf), nil
+	}
+
+	img := newImage(ref, options.Platform)
+	enc.Encode(jsonmessage.JSONMessage{Status: "Pulling from " + ref, ID: "latest"})
+	for i := range img.Layers {
+		enc.Encode(jsonmessage.JSONMessage{Status: "Pulling fs layer", ID: layerID(ref, i)})
+	}
+	for i, size := range img.Layers {
+		id := layerID(ref, i)
+		for _, current := range []int64{size / 2, size} {
+			enc.Encode(jsonmessage.JSONMessage{
+				Status:   "Downloading",
+				ID:       id,
+				Progress: &jsonmessage.JSONProgress{Current: current, Total: size},
+			})
+		}
+		enc.Encode(jsonmessage.JSONMessage{Status: "Download complete", ID: id})
+	}
+	for i, size := range img.Layers {
+		id := layerID(ref, i)
+		for _, current := range []int64{size / 2, size} {
+			enc.Encode(jsonmessage.JSONMessage{
+				Status:   "Extracting",
+				ID:       id,
+				Progress: &jsonmessage.JSONProgress{Current: current, Total: size},
+			})
+		}
+		enc.Encode(jsonmessage.JSONMessage{Status: "Pull complete", ID: id})
+	}
+	enc.Encode(jsonmessage.JSONMessage{Status: "Digest: " + img.ID})
+	enc.Encode(jsonmessage.JSONMessage{Status: "Status: Downloaded newer image for " + ref})
+
+	e.images[ref] = img
+	e.emit(events.ImageEventType, events.ActionPull, ref, map[string]string{"name": ref})
+	return io.NopCloser(buf), nil
+}
+
+// newImage creates an image for platform, formatted as os/arch[/variant],
+// defaulting to linux/amd64.
+func newImage(ref, platform string) *Image {
+	if platform == "" {
+		platform = "linux/amd64"
+	}
+	parts := strings.SplitN(platform, "/", 3)
+	img := &Image{Ref: ref, ID: imageID(ref + "@" + platform), OS: parts[0], Layers: defaultLayers}
+	if len(parts) > 1 {
+		img.Architecture = parts[1]
+	}
+	if len(parts) > 2 {
+		img.Variant = parts[2]
+	}
+
+	return img
+}
+
+func (i *Image) platform() string {
+	if i.Variant != "" {
+		return i.OS + "/" + i.Architecture + "/" + i.Variant
+	}
+	return i.OS + "/" + i.Architecture
+}
+
+func (i *Image) matches(p *ocispec.Platform) bool {
+	return i.OS == p.OS && i.Architecture == p.Architecture && (p.Variant == "" || i.Variant == p.Variant)
+}
+
+func layerID(ref string, i int) string {
+	return newID(fmt.Sprintf("%s-layer-%d", ref, i))[:12]
+}
@@ -0,0 +1,162 @@
+package fakedocker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/errdefs"
+)
+
+// builtinNetworks exist on every daemon and cannot be created or removed.
+var builtinNetworks = map[string]bool{"bridge": true, "host": true, "none": true}
+
+// Network is a user-defined network known to the fake engine.
+type Network struct {
+	ID      string
+	Name    string
+	Driver  string
+	Labels  map[string]string
+	Created time.Time
+}
+
+// Networks returns the user-defined networks of the engine sorted by name.
+func (e *Engine) Networks() []Network {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	out := make([]Network, 0, len(e.networks))
+	for _, n := range sortedByName(e.networks, func(n *Network) string { return n.Name }) {
+		out = append(out, *n)
+	}
+
+	return out
+}
+
+func (e *Engine) NetworkCreate(ctx context.Context, name string, options network.CreateOptions) (network.CreateResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("NetworkCreate"); err != nil {
+		return network.CreateResponse{}, err
+	}
+
+	if builtinNetworks[name] {
+		return network.CreateResponse{}, errdefs.Forbidden(fmt.Errorf("%s is a pre-defined network and cannot be created", name))
+	}
+	if _, err := e.lookupNetwork(name); err == nil {
+		return network.CreateResponse{}, errdefs.Conflict(fmt.Errorf("network with name %s already exists", name))
+	}
+
+	driver := options.Driver
+	if driver == "" {
+		driver = "bridge"
+	}
+
+	e.seq++
+	n := &Network{
+		ID:      newID(fmt.Sprintf("network-%d", e.seq)),
+		Name:    name,
+		Driver:  driver,
+		Labels:  options.Labels,
+		Created: time.Now().UTC(),
+	}
+	e.networks[n.ID] = n
+	e.emit(events.NetworkEventType, events.ActionCreate, n.ID, map[string]string{"name": n.Name, "type": n.Driver})
+
+	return network.CreateResponse{ID: n.ID}, nil
+}
+
+func (e *Engine) NetworkInspect(ctx context.Context, ref string, options network.InspectOptions) (network.Inspect, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("NetworkInspect"); err != nil {
+		return network.Inspect{}, err
+	}
+
+	n, err := e.lookupNetwork(ref)
+	if err != nil {
+		return network.Inspect{}, err
+	}
+
+	return n.inspect(), nil
+}
+
+func (e *Engine) NetworkList(ctx context.Context, options network.ListOptions) ([]network.Summary, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("NetworkList"); err != nil {
+		return nil, err
+	}
+
+	out := []network.Summary{}
+	for _, n := range sortedByName(e.networks, func(n *Network) string { return n.Name }) {
+		if !matchesLabels(n.Labels, options.Filters.Get("label")) {
+			continue
+		}
+		if names := options.Filters.Get("name"); len(names) > 0 && !contains(names, n.Name) {
+			continue
+		}
+		out = append(out, n.inspect())
+	}
+
+	return out, nil
+}
+
+func (e *Engine) NetworkRemove(ctx context.Context, ref string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("NetworkRemove"); err != nil {
+		return err
+	}
+
+	n, err := e.lookupNetwork(ref)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range e.containers {
+		if c.Networking == nil {
+			continue
+		}
+		if _, ok := c.Networking.EndpointsConfig[n.Name]; ok {
+			return errdefs.Forbidden(fmt.Errorf("error while removing network: network %s id %s has active endpoints", n.Name, n.ID))
+		}
+	}
+
+	delete(e.networks, n.ID)
+	e.emit(events.NetworkEventType, events.ActionDestroy, n.ID, map[string]string{"name": n.Name, "type": n.Driver})
+
+	return nil
+}
+
+// lookupNetwork finds a network by ID, ID prefix or name. Callers must hold e.mu.
+func (e *Engine) lookupNetwork(ref string) (*Network, error) {
+	if n, ok := e.networks[ref]; ok {
+		return n, nil
+	}
+
+	for _, n := range e.networks {
+		if n.Name == ref || (len(ref) >= 12 && len(n.ID) >= len(ref) && n.ID[:len(ref)] == ref) {
+			return n, nil
+		}
+	}
+
+	return nil, errdefs.NotFound(fmt.Errorf("network %s not found", ref))
+}
+
+func (n *Network) inspect() network.Inspect {
+	return network.Inspect{
+		ID:      n.ID,
+		Name:    n.Name,
+		Driver:  n.Driver,
+		Scope:   "local",
+		Created: n.Created,
+		Labels:  n.Labels,
+	}
+}
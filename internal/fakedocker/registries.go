@@ -0,0 +1,73 @@
+package fakedocker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/errdefs"
+)
+
+// AddRegistry protects server (e.g. "localhost:5000") with the given
+// credentials, like a registry:2 container configured with htpasswd. Pulls
+// of its images and logins then require them.
+func (e *Engine) AddRegistry(server, username, password string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.registries[server] = registry.AuthConfig{Username: username, Password: password}
+}
+
+func (e *Engine) RegistryLogin(ctx context.Context, auth registry.AuthConfig) (registry.AuthenticateOKBody, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("RegistryLogin"); err != nil {
+		return registry.AuthenticateOKBody{}, err
+	}
+
+	server := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(auth.ServerAddress, "https://"), "http://"), "/")
+	if err := e.authorize(server, auth); err != nil {
+		return registry.AuthenticateOKBody{}, err
+	}
+
+	return registry.AuthenticateOKBody{Status: "Login Succeeded"}, nil
+}
+
+// authorizePull checks the encoded credentials sent along a pull of ref.
+// Callers must hold e.mu.
+func (e *Engine) authorizePull(ref, encodedAuth string) error {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return errdefs.InvalidParameter(err)
+	}
+
+	var auth registry.AuthConfig
+	if encodedAuth != "" {
+		decoded, err := registry.DecodeAuthConfig(encodedAuth)
+		if err != nil {
+			return errdefs.InvalidParameter(err)
+		}
+		auth = *decoded
+	}
+
+	return e.authorize(reference.Domain(named), auth)
+}
+
+// authorize compares auth with the credentials of server, if protected.
+// Callers must hold e.mu.
+func (e *Engine) authorize(server string, auth registry.AuthConfig) error {
+	want, ok := e.registries[server]
+	if !ok {
+		return nil
+	}
+
+	if auth.Username != want.Username || auth.Password != want.Password {
+		return errdefs.Unauthorized(fmt.Errorf("Get \"https://%s/v2/\": %w", server, errors.New("unauthorized: authentication required")))
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package fakedocker
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// ContainerCommit creates an image holding a copy of the filesystem of the
+// container. Untagged images are only reachable through their ID.
+func (e *Engine) ContainerCommit(ctx context.Context, ref string, options container.CommitOptions) (container.CommitResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("ContainerCommit"); err != nil {
+		return container.CommitResponse{}, err
+	}
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		return container.CommitResponse{}, err
+	}
+
+	e.seq++
+	img := newImage(options.Reference, "")
+	img.ID = imageID(fmt.Sprintf("commit-%d", e.seq))
+	img.Comment = options.Comment
+	img.Author = options.Author
+	img.Changes = options.Changes
+	img.Paused = options.Pause
+	img.files = maps.Clone(c.fs())
+	e.addImage(img)
+	e.emitContainer(c, events.ActionCommit, nil)
+
+	return container.CommitResponse{ID: img.ID}, nil
+}
+
+// ContainerExport returns a tar archive of the whole filesystem of the
+// container, with entries relative to its root.
+func (e *Engine) ContainerExport(ctx context.Context, ref string) (io.ReadCloser, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("ContainerExport"); err != nil {
+		return nil, err
+	}
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+	for _, p := range paths(c.fs()) {
+		if p == "/" {
+			continue
+		}
+		if err := c.fs()[p].writeTar(tw, strings.TrimPrefix(p, "/")); err != nil {
+			return nil, err
+		}
+	}
+	tw.Close()
+
+	return io.NopCloser(buf), nil
+}
+
+// ImageImport creates an image from a tar archive read from source and
+// reports its ID like the daemon does.
+func (e *Engine) ImageImport(ctx context.Context, source image.ImportSource, ref string, options image.ImportOptions) (io.ReadCloser, error) {
+	if source.SourceName != "-" {
+		return nil, errdefs.InvalidParameter(fmt.Errorf("only imports from the request body are supported"))
+	}
+	entries, err := readTar(source.Source)
+	if err != nil {
+		return nil, errdefs.InvalidParameter(err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("ImageImport"); err != nil {
+		return nil, err
+	}
+
+	e.seq++
+	img := newImage(ref, options.Platform)
+	img.ID = imageID(fmt.Sprintf("import-%d", e.seq))
+	img.Comment = options.Message
+	img.Changes = options.Changes
+	img.files = map[string]*file{}
+	for _, entry := range entries {
+		img.files[entry.name] = entry.file
+	}
+	e.addImage(img)
+	e.emit(events.ImageEventType, events.ActionImport, img.ID, map[string]string{"name": ref})
+
+	buf := new(bytes.Buffer)
+	json.NewEncoder(buf).Encode(jsonmessage.JSONMessage{Status: img.ID})
+	return io.NopCloser(buf), nil
+}
+
+// addImage stores the image under its reference, or its ID when it has
+// none. Callers must hold e.mu.
+func (e *Engine) addImage(img *Image) {
+	if img.Ref == "" {
+		e.images[img.ID] = img
+		return
+	}
+	e.images[img.Ref] = img
+}
@@ -0,0 +1,259 @@
+package fakedocker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+type logEntry struct {
+	stream stdcopy.StdType
+	line   string
+	at     time.Time
+}
+
+// AppendLogs adds lines to the output of the container on the given stream
+// (stdcopy.Stdout or stdcopy.Stderr), waking up any following log reader.
+func (e *Engine) AppendLogs(ref string, stream stdcopy.StdType, lines ...string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	for _, line := range lines {
+		c.logs = append(c.logs, logEntry{stream: stream, line: line, at: now})
+	}
+	e.notify()
+
+	return nil
+}
+
+// AppendStats queues stats samples for the container. Streaming readers emit
+// every queued sample in order while one-shot reads return the latest one.
+func (e *Engine) AppendStats(ref string, samples ...container.StatsResponse) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		return err
+	}
+
+	c.stats = append(c.stats, samples...)
+	e.notify()
+
+	return nil
+}
+
+func (e *Engine) ContainerLogs(ctx context.Context, ref string, options container.LogsOptions) (io.ReadCloser, error) {
+	e.mu.Lock()
+	if err := e.failure("ContainerLogs"); err != nil {
+		e.mu.Unlock()
+		return nil, err
+	}
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		e.mu.Unlock()
+		return nil, err
+	}
+	id, tty := c.ID, c.Config.Tty
+
+	start := 0
+	if options.Tail != "" && options.Tail != "all" {
+		n, err := strconv.Atoi(options.Tail)
+		if err == nil && n < len(c.logs) {
+			start = len(c.logs) - n
+		}
+	}
+	e.mu.Unlock()
+
+	return e.follow(ctx, id, options.Follow, emitLogs(options, tty), start), nil
+}
+
+// emitLogs writes the log lines selected by options, multiplexed unless the
+// container has a TTY.
+func emitLogs(options container.LogsOptions, tty bool) emitFunc {
+	return func(w io.Writer, c *Container, from int) (int, error) {
+		for _, entry := range c.logs[from:] {
+			if (entry.stream == stdcopy.Stdout && !options.ShowStdout) || (entry.stream == stdcopy.Stderr && !options.ShowStderr) {
+				continue
+			}
+
+			line := entry.line + "\n"
+			if options.Timestamps {
+				line = entry.at.Format(time.RFC3339Nano) + " " + line
+			}
+
+			out := w
+			if !tty {
+				out = stdcopy.NewStdWriter(w, entry.stream)
+			}
+			if _, err := io.WriteString(out, line); err != nil {
+				return from, err
+			}
+		}
+
+		return len(c.logs), nil
+	}
+}
+
+func (e *Engine) ContainerStats(ctx context.Context, ref string, stream bool) (container.StatsResponseReader, error) {
+	e.mu.Lock()
+	if err := e.failure("ContainerStats"); err != nil {
+		e.mu.Unlock()
+		return container.StatsResponseReader{}, err
+	}
+
+	c, err := e.lookup(ref)
+	if err != nil {
+		e.mu.Unlock()
+		return container.StatsResponseReader{}, err
+	}
+	id := c.ID
+
+	if !stream {
+		sample := c.latestStats()
+		e.mu.Unlock()
+
+		body, _ := json.Marshal(sample)
+		return container.StatsResponseReader{Body: io.NopCloser(bytes.NewReader(body)), OSType: "linux"}, nil
+	}
+	e.mu.Unlock()
+
+	body := e.follow(ctx, id, true, func(w io.Writer, c *Container, from int) (int, error) {
+		samples := c.stats[from:]
+		if from == 0 && len(c.stats) == 0 {
+			samples = []container.StatsResponse{c.latestStats()}
+		}
+
+		enc := json.NewEncoder(w)
+		for _, sample := range samples {
+			if err := enc.Encode(sample); err != nil {
+				return from, err
+			}
+		}
+
+		return len(c.stats), nil
+	}, 0)
+
+	return container.StatsResponseReader{Body: body, OSType: "linux"}, nil
+}
+
+// latestStats returns the last queued sample or a fixed default one.
+// Callers must hold e.mu.
+func (c *Container) latestStats() container.StatsResponse {
+	if len(c.stats) > 0 {
+		return c.stats[len(c.stats)-1]
+	}
+
+	if c.State != "running" {
+		return container.StatsResponse{Name: "/" + c.Name, ID: c.ID}
+	}
+
+	return container.StatsResponse{
+		Name: "/" + c.Name,
+		ID:   c.ID,
+		Read: time.Now().UTC(),
+		CPUStats: container.CPUStats{
+			CPUUsage: container.CPUUsage{
+				TotalUsage:  200_000_000,
+				PercpuUsage: []uint64{100_000_000, 100_000_000},
+			},
+			SystemUsage: 2_000_000_000,
+			OnlineCPUs:  2,
+		},
+		PreCPUStats: container.CPUStats{
+			CPUUsage: container.CPUUsage{
+				TotalUsage:  100_000_000,
+				PercpuUsage: []uint64{50_000_000, 50_000_000},
+			},
+			SystemUsage: 1_000_000_000,
+			OnlineCPUs:  2,
+		},
+		MemoryStats: container.MemoryStats{
+			Usage: 64 << 20,
+			Limit: 1 << 30,
+		},
+	}
+}
+
+// emitFunc writes everything available from index from onwards and returns
+// the index to resume from on the next change.
+type emitFunc func(w io.Writer, c *Container, from int) (int, error)
+
+// follow streams the output of emit for the container. When follow is set the
+// stream stays open while the container runs, until ctx is done or the
+// reader is closed.
+func (e *Engine) follow(ctx context.Context, id string, follow bool, emit emitFunc, from int) io.ReadCloser {
+	pr, pw := io.Pipe()
+	r := &streamReader{PipeReader: pr, done: make(chan struct{})}
+
+	go func() {
+		next := from
+		for {
+			e.mu.Lock()
+			c, ok := e.containers[id]
+			if !ok {
+				e.mu.Unlock()
+				pw.Close()
+				return
+			}
+
+			// Emit into a buffer so the pipe is never written with the lock held.
+			buf := new(bytes.Buffer)
+			n, err := emit(buf, c, next)
+			running := c.State == "running" || c.State == "paused"
+			changed := e.changed
+			e.mu.Unlock()
+
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			next = n
+
+			if _, err := io.Copy(pw, buf); err != nil {
+				return
+			}
+
+			if !follow || !running {
+				pw.Close()
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				pw.CloseWithError(ctx.Err())
+				return
+			case <-r.done:
+				return
+			case <-changed:
+			}
+		}
+	}()
+
+	return r
+}
+
+type streamReader struct {
+	*io.PipeReader
+	once sync.Once
+	done chan struct{}
+}
+
+func (r *streamReader) Close() error {
+	r.once.Do(func() { close(r.done) })
+	return r.PipeReader.Close()
+}
@@ -0,0 +1,156 @@
+package fakedocker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/errdefs"
+)
+
+// Volume is a named volume known to the fake engine.
+type Volume struct {
+	Name    string
+	Driver  string
+	Labels  map[string]string
+	Created time.Time
+
+	files map[string]*file
+}
+
+// Volumes returns the volumes of the engine sorted by name.
+func (e *Engine) Volumes() []Volume {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	out := make([]Volume, 0, len(e.volumes))
+	for _, v := range sortedByName(e.volumes, func(v *Volume) string { return v.Name }) {
+		out = append(out, *v)
+	}
+
+	return out
+}
+
+// VolumeCreate returns the existing volume when one with the same name
+// exists, like the daemon does.
+func (e *Engine) VolumeCreate(ctx context.Context, options volume.CreateOptions) (volume.Volume, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("VolumeCreate"); err != nil {
+		return volume.Volume{}, err
+	}
+
+	name := options.Name
+	if name == "" {
+		e.seq++
+		name = newID(fmt.Sprintf("volume-%d", e.seq))
+	}
+
+	return e.createVolume(name, options.Driver, options.Labels).summary(), nil
+}
+
+func (e *Engine) VolumeInspect(ctx context.Context, name string) (volume.Volume, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("VolumeInspect"); err != nil {
+		return volume.Volume{}, err
+	}
+
+	v, ok := e.volumes[name]
+	if !ok {
+		return volume.Volume{}, errdefs.NotFound(fmt.Errorf("get %s: no such volume", name))
+	}
+
+	return v.summary(), nil
+}
+
+func (e *Engine) VolumeList(ctx context.Context, options volume.ListOptions) (volume.ListResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("VolumeList"); err != nil {
+		return volume.ListResponse{}, err
+	}
+
+	out := volume.ListResponse{Volumes: []*volume.Volume{}}
+	for _, v := range sortedByName(e.volumes, func(v *Volume) string { return v.Name }) {
+		if !matchesLabels(v.Labels, options.Filters.Get("label")) {
+			continue
+		}
+		if names := options.Filters.Get("name"); len(names) > 0 && !contains(names, v.Name) {
+			continue
+		}
+		summary := v.summary()
+		out.Volumes = append(out.Volumes, &summary)
+	}
+
+	return out, nil
+}
+
+func (e *Engine) VolumeRemove(ctx context.Context, name string, force bool) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.failure("VolumeRemove"); err != nil {
+		return err
+	}
+
+	if _, ok := e.volumes[name]; !ok {
+		if force {
+			return nil
+		}
+		return errdefs.NotFound(fmt.Errorf("get %s: no such volume", name))
+	}
+
+	for _, c := range e.containers {
+		for _, m := range c.HostConfig.Mounts {
+			if m.Type == mount.TypeVolume && m.Source == name {
+				return errdefs.Conflict(fmt.Errorf("remove %s: volume is in use - [%s]", name, c.ID))
+			}
+		}
+	}
+
+	driver := e.volumes[name].Driver
+	delete(e.volumes, name)
+	e.emit(events.VolumeEventType, events.ActionDestroy, name, map[string]string{"driver": driver})
+
+	return nil
+}
+
+// createVolume returns the named volume, creating it when missing.
+// Callers must hold e.mu.
+func (e *Engine) createVolume(name, driver string, labels map[string]string) *Volume {
+	if v, ok := e.volumes[name]; ok {
+		return v
+	}
+
+	if driver == "" {
+		driver = "local"
+	}
+	v := &Volume{
+		Name:    name,
+		Driver:  driver,
+		Labels:  labels,
+		Created: time.Now().UTC(),
+	}
+	e.volumes[name] = v
+	e.emit(events.VolumeEventType, events.ActionCreate, name, map[string]string{"driver": driver})
+
+	return v
+}
+
+func (v *Volume) summary() volume.Volume {
+	return volume.Volume{
+		Name:       v.Name,
+		Driver:     v.Driver,
+		Labels:     v.Labels,
+		Mountpoint: "/var/lib/docker/volumes/" + v.Name + "/_data",
+		Scope:      "local",
+		CreatedAt:  v.Created.Format(time.RFC3339),
+	}
+}
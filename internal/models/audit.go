@@ -0,0 +1,47 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditEntry records a mutating API call. Payload is the JSON body of the
+// request with its secrets redacted, or a summary of other bodies.
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	Time       time.Time       `json:"time"`
+	Actor      string          `json:"actor" example:"alice"`
+	SourceIP   string          `json:"source_ip" example:"192.168.1.20"`
+	Method     string          `json:"method" example:"POST"`
+	Route      string          `json:"route" example:"/api/containers/:id/stop"`
+	Path       string          `json:"path" example:"/api/containers/mc/stop?host=nas"`
+	Target     string          `json:"target,omitempty" example:"mc"`
+	Host       string          `json:"host,omitempty" example:"nas"`
+	Payload    json.RawMessage `json:"payload,omitempty" swaggertype:"object"`
+	Status     int             `json:"status" example:"204"`
+	Result     string          `json:"result" example:"success" enums:"success,failure"`
+	Error      string          `json:"error,omitempty" example:"No such container: mc"`
+	DurationMs int64           `json:"duration_ms" example:"120"`
+}
+
+// AuditQuery selects a page of the audit log. Empty fields match every
+// entry; From and To are RFC 3339 times and Before is the Next cursor of
+// the previous page.
+type AuditQuery struct {
+	Actor  string `query:"actor" example:"alice"`
+	Method string `query:"method" example:"DELETE"`
+	Target string `query:"target" example:"mc"`
+	Host   string `query:"host" example:"nas"`
+	Result string `query:"result" example:"failure"`
+	From   string `query:"from" example:"2026-01-01T00:00:00Z"`
+	To     string `query:"to" example:"2026-01-02T00:00:00Z"`
+	Before string `query:"before"`
+	Limit  int    `query:"limit" example:"100"`
+}
+
+// AuditPage is a page of the audit log, newest first. Next is the cursor of
+// the following page and is empty on the last page.
+type AuditPage struct {
+	Entries []AuditEntry `json:"entries"`
+	Next    string       `json:"next,omitempty"`
+}
@@ -0,0 +1,82 @@
+package models
+
+import "time"
+
+// BackupRequest selects the mounts of a container to back up, by
+// destination or volume name, all of its volumes and bind mounts by
+// default. Mode pauses or stops the container meanwhile for consistency.
+type BackupRequest struct {
+	Mounts []string `json:"mounts,omitempty" example:"/data"`
+	Mode   string   `json:"mode,omitempty" example:"pause" enums:"none,pause,stop"`
+}
+
+// Backup is the compressed tar archive of a mount of a container.
+type Backup struct {
+	ID            int64      `json:"id"`
+	Host          string     `json:"host" example:"local"`
+	ContainerID   string     `json:"container_id"`
+	ContainerName string     `json:"container_name" example:"mc"`
+	ScheduleID    *int64     `json:"schedule_id,omitempty"`
+	MountType     string     `json:"mount_type" example:"volume" enums:"volume,bind"`
+	Source        string     `json:"source" example:"mc-data"`
+	Destination   string     `json:"destination" example:"/data"`
+	Mode          string     `json:"mode" example:"pause" enums:"none,pause,stop"`
+	File          string     `json:"file" example:"local/mc/20260101T040000Z-data.tar.gz"`
+	Size          int64      `json:"size" example:"1048576"`
+	Checksum      string     `json:"checksum,omitempty" example:"sha256:9f86d081884c7d65..."`
+	DurationMs    int64      `json:"duration_ms" example:"1520"`
+	Status        string     `json:"status" example:"completed" enums:"running,completed,failed"`
+	Error         string     `json:"error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+}
+
+// RestoreRequest restores a backup into the mount it was taken from or,
+// when Volume is set, into that named volume, created if missing. Mode
+// pauses or stops the container of the mount meanwhile.
+type RestoreRequest struct {
+	Volume string `json:"volume,omitempty" example:"mc-data-restored"`
+	Mode   string `json:"mode,omitempty" example:"stop" enums:"none,pause,stop"`
+}
+
+// RestoreResult describes where a backup was restored.
+type RestoreResult struct {
+	BackupID    int64  `json:"backup_id"`
+	Container   string `json:"container,omitempty" example:"mc"`
+	Volume      string `json:"volume,omitempty" example:"mc-data"`
+	Destination string `json:"destination,omitempty" example:"/data"`
+	DurationMs  int64  `json:"duration_ms" example:"830"`
+}
+
+// RetentionPolicy prunes the completed backups of a mount after every
+// scheduled run. A backup is kept when it is one of the KeepLast newest or
+// the newest of one of the last KeepDaily days. Zero values keep everything.
+type RetentionPolicy struct {
+	KeepLast  int `json:"keep_last,omitempty" example:"5"`
+	KeepDaily int `json:"keep_daily,omitempty" example:"7"`
+}
+
+// BackupScheduleDefinition is the part of a backup schedule set by users.
+// Cron is a five field expression or a descriptor such as "@daily",
+// evaluated in Timezone, UTC by default.
+type BackupScheduleDefinition struct {
+	Container string          `json:"container" example:"mc"`
+	Cron      string          `json:"cron" example:"0 4 * * *"`
+	Timezone  string          `json:"timezone,omitempty" example:"Europe/Berlin"`
+	Mounts    []string        `json:"mounts,omitempty" example:"/data"`
+	Mode      string          `json:"mode,omitempty" example:"pause" enums:"none,pause,stop"`
+	Retention RetentionPolicy `json:"retention"`
+	Disabled  bool            `json:"disabled"`
+}
+
+// BackupSchedule backs up a container of a host periodically.
+type BackupSchedule struct {
+	ID   int64  `json:"id"`
+	Host string `json:"host" example:"local"`
+	BackupScheduleDefinition
+	LastRunAt  *time.Time `json:"last_run_at,omitempty"`
+	LastStatus string     `json:"last_status,omitempty" example:"completed" enums:"completed,failed"`
+	NextRunAt  *time.Time `json:"next_run_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
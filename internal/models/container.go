@@ -19,3 +19,213 @@ type ContainerStats struct {
 	CpuTotal uint64 `json:"cpu_total"`
 	MemTotal uint64 `json:"mem_total"`
 }
+
+type CreateOptions struct {
+	Name           string            `json:"name"`
+	Reference      string            `json:"reference,omitempty" example:"ghcr.io/itzg/minecraft-server@sha256:4f8a..."`
+	Registry       string            `json:"registry"`
+	Image          string            `json:"image"`
+	Version        string            `json:"version"`
+	PullPolicy     string            `json:"pull_policy,omitempty" example:"if-not-present" enums:"always,if-not-present,never"`
+	Platform       string            `json:"platform,omitempty" example:"linux/arm64"`
+	Commands       []string          `json:"commands"`
+	Entrypoint     []string          `json:"entrypoint,omitempty"`
+	Env            map[string]string `json:"env,omitempty"`
+	Ports          []PortBinding     `json:"ports,omitempty"`
+	Mounts         []Mount           `json:"mounts,omitempty"`
+	Resources      *Resources        `json:"resources,omitempty"`
+	RestartPolicy  *RestartPolicy    `json:"restart_policy,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	WorkingDir     string            `json:"working_dir,omitempty" example:"/data"`
+	User           string            `json:"user,omitempty" example:"1000:1000"`
+	Hostname       string            `json:"hostname,omitempty"`
+	Network        string            `json:"network,omitempty" example:"bridge"`
+	NetworkAliases []string          `json:"network_aliases,omitempty"`
+	Healthcheck    *Healthcheck      `json:"healthcheck,omitempty"`
+}
+
+// PortBinding publishes a container port on the host. A zero HostPort lets
+// Docker pick a free port.
+type PortBinding struct {
+	ContainerPort uint16 `json:"container_port" example:"25565"`
+	HostIP        string `json:"host_ip,omitempty" example:"0.0.0.0"`
+	HostPort      uint16 `json:"host_port,omitempty" example:"25565"`
+	Protocol      string `json:"protocol,omitempty" example:"tcp" enums:"tcp,udp,sctp"`
+}
+
+// Mount attaches a host path (bind) or a named volume to the container.
+// An empty Source on a volume mount creates an anonymous volume.
+type Mount struct {
+	Type     string `json:"type" example:"volume" enums:"bind,volume"`
+	Source   string `json:"source" example:"minecraft-data"`
+	Target   string `json:"target" example:"/data"`
+	ReadOnly bool   `json:"read_only,omitempty"`
+}
+
+// Resources limits what the container can use. Memory values accept units
+// such as "512m" or "2g".
+type Resources struct {
+	CPUs       float64 `json:"cpus,omitempty" example:"1.5"`
+	CPUShares  int64   `json:"cpu_shares,omitempty" example:"1024"`
+	Memory     string  `json:"memory,omitempty" example:"2g"`
+	MemorySwap string  `json:"memory_swap,omitempty" example:"4g"`
+	PidsLimit  int64   `json:"pids_limit,omitempty" example:"512"`
+}
+
+type RestartPolicy struct {
+	Name              string `json:"name" example:"unless-stopped" enums:"no,always,unless-stopped,on-failure"`
+	MaximumRetryCount int    `json:"maximum_retry_count,omitempty"`
+}
+
+// Healthcheck follows the Docker format: Test starts with NONE, CMD or
+// CMD-SHELL and durations are strings like "30s".
+type Healthcheck struct {
+	Test        []string `json:"test" example:"CMD-SHELL,curl -f http://localhost/ || exit 1"`
+	Interval    string   `json:"interval,omitempty" example:"30s"`
+	Timeout     string   `json:"timeout,omitempty" example:"5s"`
+	StartPeriod string   `json:"start_period,omitempty" example:"1m"`
+	Retries     int      `json:"retries,omitempty" example:"3"`
+}
+
+// RecreateRequest selects the image a container is recreated with: a full
+// reference, or a new tag or digest of its current image. Without either the
+// current tag is pulled again to pick up its newest image.
+type RecreateRequest struct {
+	Reference  string `json:"reference,omitempty" example:"docker.io/itzg/minecraft-server:java21"`
+	Version    string `json:"version,omitempty" example:"java21"`
+	PullPolicy string `json:"pull_policy,omitempty" example:"always" enums:"always,if-not-present,never"`
+	Platform   string `json:"platform,omitempty" example:"linux/arm64"`
+	// Timeout bounds the wait for the new container to become healthy.
+	Timeout string `json:"timeout,omitempty" example:"1m"`
+}
+
+// RecreateResult reports every step of a recreation. Status is recreated
+// when the new container replaced the old one, rolled_back when the old one
+// was restored and failed when even that was not possible.
+type RecreateResult struct {
+	Name          string         `json:"name" example:"survival"`
+	Status        string         `json:"status" example:"recreated" enums:"recreated,rolled_back,failed"`
+	ContainerID   string         `json:"container_id"`
+	OldID         string         `json:"old_id"`
+	Image         string         `json:"image" example:"docker.io/itzg/minecraft-server:java21"`
+	PreviousImage string         `json:"previous_image" example:"docker.io/itzg/minecraft-server:latest"`
+	Steps         []RecreateStep `json:"steps"`
+}
+
+// RecreateStep is one step of a recreation and how it went.
+type RecreateStep struct {
+	Step    string `json:"step" example:"pull"`
+	Status  string `json:"status" example:"done" enums:"done,failed"`
+	Message string `json:"message,omitempty"`
+}
+
+// CloneRequest overrides parts of the configuration of a cloned container.
+// Env and labels are merged into the ones of the source, ports replace its
+// port bindings. Host ports already in use are remapped to the next free
+// port unless PortConflict is "reject".
+type CloneRequest struct {
+	Name         string            `json:"name,omitempty" example:"survival-copy"`
+	Env          map[string]string `json:"env,omitempty"`
+	Ports        []PortBinding     `json:"ports,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	PortConflict string            `json:"port_conflict,omitempty" example:"remap" enums:"remap,reject"`
+	Start        bool              `json:"start,omitempty"`
+}
+
+// CloneResult is the container created by a clone with its effective port
+// bindings.
+type CloneResult struct {
+	ContainerID string        `json:"container_id"`
+	Name        string        `json:"name" example:"survival-copy"`
+	SourceID    string        `json:"source_id"`
+	Started     bool          `json:"started"`
+	Ports       []PortBinding `json:"ports"`
+	Remapped    []PortRemap   `json:"remapped,omitempty"`
+}
+
+// PortRemap is a host port of the source that was in use and the one the
+// clone got instead.
+type PortRemap struct {
+	ContainerPort uint16 `json:"container_port" example:"25565"`
+	Protocol      string `json:"protocol" example:"tcp"`
+	HostPort      uint16 `json:"host_port" example:"25565"`
+	NewHostPort   uint16 `json:"new_host_port" example:"25566"`
+}
+
+// ResourceUpdate changes the limits of a running container. Only the fields
+// that are set are changed, memory values accept units such as "512m".
+type ResourceUpdate struct {
+	Memory        *string        `json:"memory,omitempty" example:"4g"`
+	MemorySwap    *string        `json:"memory_swap,omitempty" example:"6g"`
+	CPUs          *float64       `json:"cpus,omitempty" example:"2"`
+	CPUShares     *int64         `json:"cpu_shares,omitempty" example:"1024"`
+	CPUQuota      *int64         `json:"cpu_quota,omitempty" example:"150000"`
+	CPUPeriod     *int64         `json:"cpu_period,omitempty" example:"100000"`
+	CpusetCpus    *string        `json:"cpuset_cpus,omitempty" example:"0-1"`
+	PidsLimit     *int64         `json:"pids_limit,omitempty" example:"512"`
+	RestartPolicy *RestartPolicy `json:"restart_policy,omitempty"`
+}
+
+// ContainerResources are the effective limits of a container. Zero means
+// unlimited, except for a MemorySwap of -1 which is unlimited swap.
+type ContainerResources struct {
+	Memory        int64         `json:"memory" example:"4294967296"`
+	MemorySwap    int64         `json:"memory_swap" example:"6442450944"`
+	CPUs          float64       `json:"cpus" example:"2"`
+	CPUShares     int64         `json:"cpu_shares" example:"1024"`
+	CPUQuota      int64         `json:"cpu_quota" example:"0"`
+	CPUPeriod     int64         `json:"cpu_period" example:"0"`
+	CpusetCpus    string        `json:"cpuset_cpus" example:"0-1"`
+	PidsLimit     int64         `json:"pids_limit" example:"512"`
+	RestartPolicy RestartPolicy `json:"restart_policy"`
+	Warnings      []string      `json:"warnings,omitempty"`
+}
+
+// StateChange tunes stop, restart and kill. Timeout is the number of seconds
+// stop and restart wait before killing the container, -1 waits forever.
+// Signal replaces the stop signal of the container, or SIGKILL for kill, and
+// accepts names such as "SIGHUP" or "HUP" and numbers.
+type StateChange struct {
+	Timeout *int   `json:"timeout,omitempty" example:"30"`
+	Signal  string `json:"signal,omitempty" example:"SIGHUP"`
+}
+
+// RenameRequest is the new name of a container.
+type RenameRequest struct {
+	Name string `json:"name" example:"survival"`
+}
+
+// StateTransition reports the state of a container before and after an
+// operation. PreviousName is only set by a rename.
+type StateTransition struct {
+	ID           string `json:"id"`
+	Name         string `json:"name" example:"survival"`
+	PreviousName string `json:"previous_name,omitempty" example:"survival-old"`
+	Action       string `json:"action" example:"kill" enums:"stop,restart,kill,pause,unpause,rename"`
+	Signal       string `json:"signal,omitempty" example:"SIGHUP"`
+	From         string `json:"from" example:"running"`
+	To           string `json:"to" example:"running"`
+}
+
+// ExecRequest is a command run inside a running container. Timeout is a
+// duration such as "30s" and MaxOutput a size such as "1m" capping each of
+// stdout and stderr.
+type ExecRequest struct {
+	Cmd        []string          `json:"cmd" example:"rcon-cli,list"`
+	Env        map[string]string `json:"env,omitempty"`
+	User       string            `json:"user,omitempty" example:"1000:1000"`
+	WorkingDir string            `json:"working_dir,omitempty" example:"/data"`
+	Timeout    string            `json:"timeout,omitempty" example:"30s"`
+	MaxOutput  string            `json:"max_output,omitempty" example:"1m"`
+}
+
+// ExecResult is the outcome of a command. ExitCode is -1 when the command
+// did not finish before the timeout. Truncated reports output over the cap.
+type ExecResult struct {
+	ExitCode  int    `json:"exit_code" example:"0"`
+	Stdout    string `json:"stdout" example:"There are 0 of a max of 20 players online"`
+	Stderr    string `json:"stderr"`
+	Truncated bool   `json:"truncated"`
+	TimedOut  bool   `json:"timed_out"`
+	Duration  string `json:"duration" example:"152ms"`
+}
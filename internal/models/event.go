@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Event is an event of the Docker daemon. ID is the timestamp of the event
+// in nanoseconds; streams send it so clients can resume after it.
+type Event struct {
+	ID         string            `json:"id" example:"1767240000123456789"`
+	Type       string            `json:"type" example:"container" enums:"container,image,volume,network,daemon,plugin,service,node,secret,config"`
+	Action     string            `json:"action" example:"die"`
+	ActorID    string            `json:"actor_id"`
+	Name       string            `json:"name,omitempty" example:"mc"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Time       time.Time         `json:"time"`
+}
+
+// EventFilter selects events. An event must match every field that is set
+// and one of its values. Containers match by name or ID and labels as key
+// or key=value.
+type EventFilter struct {
+	Types      []string `json:"types,omitempty" example:"container"`
+	Actions    []string `json:"actions,omitempty" example:"die"`
+	Containers []string `json:"containers,omitempty" example:"mc"`
+	Labels     []string `json:"labels,omitempty" example:"com.docker.compose.project=shop"`
+}
+
+// RecordedEvent is an event kept in the event history of a host. ExitCode
+// is set on die events; OOMKilled on oom events and on the die event
+// following one.
+type RecordedEvent struct {
+	Event
+	Host      string `json:"host" example:"local"`
+	ExitCode  *int   `json:"exit_code,omitempty" example:"137"`
+	OOMKilled bool   `json:"oom_killed,omitempty"`
+}
+
+// TimelineQuery selects a page of the events of a container. From and To
+// are RFC 3339 times bounding the events, Before is the Next cursor of the
+// previous page.
+type TimelineQuery struct {
+	From   string `query:"from" example:"2026-01-01T00:00:00Z"`
+	To     string `query:"to" example:"2026-01-02T00:00:00Z"`
+	Before string `query:"before"`
+	Limit  int    `query:"limit" example:"100"`
+}
+
+// Timeline is a page of the recorded events of a container, newest first.
+// Next is the cursor of the following page and is empty on the last page.
+type Timeline struct {
+	Events []RecordedEvent `json:"events"`
+	Next   string          `json:"next,omitempty"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// FileEntry is a file, directory or link inside a container.
+type FileEntry struct {
+	Name       string    `json:"name" example:"server.properties"`
+	Path       string    `json:"path" example:"/data/server.properties"`
+	Type       string    `json:"type" example:"file" enums:"file,dir,symlink,other"`
+	Size       int64     `json:"size" example:"1432"`
+	Mode       string    `json:"mode" example:"-rw-r--r--"`
+	ModTime    time.Time `json:"mtime"`
+	LinkTarget string    `json:"link_target,omitempty"`
+}
+
+// DirectoryListing is the content of a directory inside a container.
+type DirectoryListing struct {
+	Path    string      `json:"path" example:"/data"`
+	Entries []FileEntry `json:"entries"`
+}
+
+// FileContent is a text file inside a container. Only Path and Content are
+// read when writing a file.
+type FileContent struct {
+	Path    string    `json:"path" example:"/data/server.properties"`
+	Content string    `json:"content" example:"motd=A Minecraft Server"`
+	Size    int64     `json:"size" example:"1432"`
+	Mode    string    `json:"mode" example:"-rw-r--r--"`
+	ModTime time.Time `json:"mtime"`
+}
+
+// UploadResult lists the entries written into a container by an upload.
+type UploadResult struct {
+	Path  string   `json:"path" example:"/data"`
+	Files []string `json:"files" example:"/data/world.zip"`
+}
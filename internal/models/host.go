@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Host is a Docker endpoint the server can manage containers on.
+type Host struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name" example:"build-box"`
+	URL       string    `json:"url" example:"tcp://10.0.0.12:2376"`
+	TLSCACert string    `json:"tls_ca_cert,omitempty" example:"/etc/docker-manager/certs/ca.pem"`
+	TLSCert   string    `json:"tls_cert,omitempty" example:"/etc/docker-manager/certs/cert.pem"`
+	TLSKey    string    `json:"tls_key,omitempty" example:"/etc/docker-manager/certs/key.pem"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// HostStatus is a Host along with the result of pinging its daemon.
+type HostStatus struct {
+	Host
+	Status     string `json:"status" example:"up"`
+	APIVersion string `json:"api_version,omitempty" example:"1.48"`
+	OSType     string `json:"os_type,omitempty" example:"linux"`
+	Error      string `json:"error,omitempty"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// PullRequest describes the image to pull, either as a full reference or
+// through the same parts as CreateOptions.
+type PullRequest struct {
+	Reference string `json:"reference,omitempty" example:"ghcr.io/itzg/minecraft-server:java21"`
+	Registry  string `json:"registry" example:"docker.io"`
+	Image     string `json:"image" example:"itzg/minecraft-server"`
+	Version   string `json:"version" example:"latest"`
+	Platform  string `json:"platform,omitempty" example:"linux/arm64"`
+}
+
+// PullJob is the state of an image pull running in the background and,
+// when it was started by a container creation, of the chained creation.
+type PullJob struct {
+	ID            string          `json:"id"`
+	Host          string          `json:"host" example:"local"`
+	Image         string          `json:"image" example:"docker.io/itzg/minecraft-server:latest"`
+	Platform      string          `json:"platform,omitempty" example:"linux/arm64"`
+	PullPolicy    string          `json:"pull_policy,omitempty" example:"if-not-present"`
+	Status        string          `json:"status" example:"pulling" enums:"pending,pulling,creating,completed,failed"`
+	Message       string          `json:"message,omitempty" example:"Downloading"`
+	Layers        []LayerProgress `json:"layers"`
+	Downloaded    int64           `json:"downloaded"`
+	Extracted     int64           `json:"extracted"`
+	Total         int64           `json:"total"`
+	Percent       float64         `json:"percent" example:"42.5"`
+	ContainerID   string          `json:"container_id,omitempty"`
+	ContainerName string          `json:"container_name,omitempty"`
+	Error         string          `json:"error,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	FinishedAt    *time.Time      `json:"finished_at,omitempty"`
+}
+
+// LayerProgress tracks the download and extraction of a single image layer.
+type LayerProgress struct {
+	ID         string `json:"id" example:"a2abf6c4d29d"`
+	Status     string `json:"status" example:"Extracting"`
+	Size       int64  `json:"size"`
+	Downloaded int64  `json:"downloaded"`
+	Extracted  int64  `json:"extracted"`
+}
+
+// CommitRequest snapshots a container into an image. Changes are Dockerfile
+// instructions applied to the image config, such as ENV or CMD.
+type CommitRequest struct {
+	Reference string   `json:"reference" example:"mc-snapshots:before-1.21"`
+	Message   string   `json:"message" example:"Tuned JVM flags"`
+	Author    string   `json:"author,omitempty" example:"ops"`
+	Changes   []string `json:"changes,omitempty" example:"ENV MEMORY=4G"`
+	// Pause pauses the container while it is committed, true by default.
+	Pause *bool `json:"pause,omitempty"`
+}
+
+// ImportRequest turns an uploaded tarball of a filesystem into an image.
+type ImportRequest struct {
+	Reference string   `form:"reference" example:"mc-snapshots:imported"`
+	Message   string   `form:"message" example:"Restored from export"`
+	Changes   []string `form:"changes" example:"ENV MODE=restored"`
+	Platform  string   `form:"platform" example:"linux/amd64"`
+}
+
+// ImageSnapshot is an image created by committing a container or importing
+// a tarball.
+type ImageSnapshot struct {
+	ID        string   `json:"id" example:"sha256:4b1c..."`
+	Reference string   `json:"reference,omitempty" example:"docker.io/library/mc-snapshots:before-1.21"`
+	Container string   `json:"container,omitempty" example:"mc"`
+	Message   string   `json:"message,omitempty" example:"Tuned JVM flags"`
+	Changes   []string `json:"changes,omitempty"`
+}
@@ -0,0 +1,27 @@
+package models
+
+// Project is a compose project found from the labels of its containers,
+// whether it was started by docker compose or as a stack. State is running
+// when every container runs, partial when only some do and stopped when
+// none does. Services are listed in dependency order.
+type Project struct {
+	Name        string             `json:"name" example:"survival"`
+	State       string             `json:"state" example:"running" enums:"running,partial,stopped"`
+	WorkingDir  string             `json:"working_dir,omitempty" example:"/srv/survival"`
+	ConfigFiles string             `json:"config_files,omitempty" example:"/srv/survival/docker-compose.yml"`
+	Services    []string           `json:"services" example:"db,web"`
+	Containers  []ProjectContainer `json:"containers"`
+}
+
+// ProjectContainer is a container of a compose project with the services
+// it depends on.
+type ProjectContainer struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name" example:"survival-web-1"`
+	Service   string   `json:"service" example:"web"`
+	Number    int      `json:"number" example:"1"`
+	Image     string   `json:"image" example:"nginx:latest"`
+	State     string   `json:"state" example:"running"`
+	Status    string   `json:"status,omitempty" example:"Up 2 minutes"`
+	DependsOn []string `json:"depends_on,omitempty" example:"db"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Registry holds the credentials used to pull from a private registry.
+// Secrets are write-only: they are never included in responses.
+type Registry struct {
+	ID            int64     `json:"id"`
+	Server        string    `json:"server" example:"ghcr.io"`
+	Username      string    `json:"username" example:"octocat"`
+	Password      string    `json:"password,omitempty" example:"ghp_xxxxxxxxxxxx"`
+	IdentityToken string    `json:"identity_token,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// RegistryLoginResult is the outcome of testing the credentials of a registry.
+type RegistryLoginResult struct {
+	Server  string `json:"server" example:"ghcr.io"`
+	Host    string `json:"host" example:"local"`
+	Success bool   `json:"success"`
+	Status  string `json:"status,omitempty" example:"Login Succeeded"`
+	Error   string `json:"error,omitempty"`
+}
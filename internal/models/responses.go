@@ -9,3 +9,9 @@ type ErrorResponse struct {
 type SuccessResponse struct {
 	Message string `json:"message" example:"Operation completed successfully"`
 }
+
+// FieldError describes why a single field of a request is invalid.
+type FieldError struct {
+	Field   string `json:"field" example:"ports[0].host_port"`
+	Message string `json:"message" example:"port 8080/tcp is already bound"`
+}
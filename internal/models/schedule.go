@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// ActionScheduleDefinition is the part of an action schedule set by users.
+// Cron is a five field expression or a descriptor such as "@daily",
+// evaluated in Timezone, UTC by default. Exec is required by the exec action
+// and Recreate optionally tunes the recreate one. CatchUp decides what
+// happens to the runs missed while the server was down: skip records them as
+// skipped, run_once runs the action once at startup.
+type ActionScheduleDefinition struct {
+	Container string           `json:"container" example:"mc"`
+	Action    string           `json:"action" example:"restart" enums:"start,stop,restart,exec,recreate"`
+	Cron      string           `json:"cron" example:"0 4 * * *"`
+	Timezone  string           `json:"timezone,omitempty" example:"Europe/Berlin"`
+	Exec      *ExecRequest     `json:"exec,omitempty"`
+	Recreate  *RecreateRequest `json:"recreate,omitempty"`
+	CatchUp   string           `json:"catch_up,omitempty" example:"skip" enums:"skip,run_once"`
+	Disabled  bool             `json:"disabled"`
+}
+
+// ActionSchedule runs an action on a container of a host periodically.
+type ActionSchedule struct {
+	ID   int64  `json:"id"`
+	Host string `json:"host" example:"local"`
+	ActionScheduleDefinition
+	LastRunAt  *time.Time `json:"last_run_at,omitempty"`
+	LastStatus string     `json:"last_status,omitempty" example:"succeeded" enums:"succeeded,failed,skipped"`
+	NextRunAt  *time.Time `json:"next_run_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// ActionScheduleRun is the outcome of a run of an action schedule. Trigger
+// tells whether it was due, caught up at startup or requested through the
+// API. Output holds the beginning of the output of exec actions.
+type ActionScheduleRun struct {
+	ID         int64     `json:"id"`
+	ScheduleID int64     `json:"schedule_id"`
+	Action     string    `json:"action" example:"restart"`
+	Trigger    string    `json:"trigger" example:"cron" enums:"cron,catch_up,manual"`
+	Status     string    `json:"status" example:"succeeded" enums:"succeeded,failed,skipped"`
+	Error      string    `json:"error,omitempty"`
+	Output     string    `json:"output,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs int64     `json:"duration_ms" example:"1520"`
+}
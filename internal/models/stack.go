@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// StackRequest deploys a compose file under a project name.
+type StackRequest struct {
+	Name    string `json:"name" example:"survival"`
+	Compose string `json:"compose" example:"services:\n  web:\n    image: nginx\n"`
+}
+
+// Stack is a stored compose file deployed on a Docker host.
+type Stack struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name" example:"survival"`
+	Host      string    `json:"host" example:"local"`
+	Compose   string    `json:"compose"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// StackStatus is the state of the containers of a stack. State is running
+// when every service runs, partial when only some do, stopped when none
+// does and down when no container exists.
+type StackStatus struct {
+	Name     string         `json:"name" example:"survival"`
+	Host     string         `json:"host" example:"local"`
+	State    string         `json:"state" example:"running" enums:"running,partial,stopped,down"`
+	Services []StackService `json:"services"`
+}
+
+// StackService is the container of a compose service. State is "missing"
+// when the service has no container.
+type StackService struct {
+	Service       string `json:"service" example:"web"`
+	ContainerID   string `json:"container_id,omitempty"`
+	ContainerName string `json:"container_name,omitempty" example:"survival-web-1"`
+	Image         string `json:"image" example:"docker.io/library/nginx:latest"`
+	State         string `json:"state" example:"running"`
+	Status        string `json:"status,omitempty" example:"Up 2 minutes"`
+}
+
+// StackLogLine is a line of output of a stack service.
+type StackLogLine struct {
+	Service string `json:"service" example:"web"`
+	Line    string `json:"line"`
+}
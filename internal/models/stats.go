@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// StatsSample is a stats sample of a running container. Network and block
+// I/O are the counters since the container started.
+type StatsSample struct {
+	Host        string
+	ContainerID string
+	Name        string
+	Time        time.Time
+	CPUPercent  float64
+	MemUsage    uint64
+	MemLimit    uint64
+	NetRx       uint64
+	NetTx       uint64
+	BlockRead   uint64
+	BlockWrite  uint64
+}
+
+// StatsPoint summarizes the samples of a container over a step of a stats
+// history. I/O counters are the last ones of the step.
+type StatsPoint struct {
+	Time       time.Time `json:"time"`
+	CPUPercent float64   `json:"cpu_percent" example:"12.5"`
+	CPUMax     float64   `json:"cpu_max" example:"48.2"`
+	MemUsage   uint64    `json:"mem_usage" example:"268435456"`
+	MemMax     uint64    `json:"mem_max" example:"301989888"`
+	MemLimit   uint64    `json:"mem_limit" example:"2147483648"`
+	NetRx      uint64    `json:"net_rx" example:"1048576"`
+	NetTx      uint64    `json:"net_tx" example:"524288"`
+	BlockRead  uint64    `json:"block_read" example:"8388608"`
+	BlockWrite uint64    `json:"block_write" example:"4194304"`
+}
+
+// StatsHistoryQuery selects a stats history. From and To are RFC 3339
+// times, the last hour by default, and Step a duration such as 5m, chosen
+// to return at most 300 points by default.
+type StatsHistoryQuery struct {
+	From string `query:"from" example:"2026-01-01T00:00:00Z"`
+	To   string `query:"to" example:"2026-01-02T00:00:00Z"`
+	Step string `query:"step" example:"5m"`
+}
+
+// StatsHistory is the time series of the stats of a container. Resolution
+// is the rollup the points are computed from: raw, 1m or 1h.
+type StatsHistory struct {
+	From       time.Time    `json:"from"`
+	To         time.Time    `json:"to"`
+	Step       string       `json:"step" example:"5m0s"`
+	Resolution string       `json:"resolution" example:"1m" enums:"raw,1m,1h"`
+	Points     []StatsPoint `json:"points"`
+}
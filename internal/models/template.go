@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// TemplateDefinition is the portable part of a template, as exported and imported.
+type TemplateDefinition struct {
+	Name        string              `json:"name" example:"paper-survival"`
+	Description string              `json:"description,omitempty" example:"Paper server with a persistent world"`
+	Parameters  []TemplateParameter `json:"parameters"`
+	// Spec is a CreateOptions document where any string may reference a
+	// parameter as ${NAME}. A string made only of a placeholder takes the
+	// type of its field, so numeric fields such as host_port can use one.
+	Spec map[string]any `json:"spec" swaggertype:"object"`
+}
+
+// Template is a stored creation spec with parameter placeholders.
+type Template struct {
+	ID int64 `json:"id"`
+	TemplateDefinition
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TemplateParameter declares a placeholder usable in the spec of a template.
+type TemplateParameter struct {
+	Name        string `json:"name" example:"MEMORY"`
+	Description string `json:"description,omitempty" example:"Heap size of the server"`
+	Default     string `json:"default,omitempty" example:"2G"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// InstantiateRequest holds the parameter values used to create a container
+// from a template. Name overrides the container name of the spec.
+type InstantiateRequest struct {
+	Name       string            `json:"name,omitempty" example:"survival-1"`
+	Parameters map[string]string `json:"parameters" example:"MEMORY:4G,PORT:25566"`
+}
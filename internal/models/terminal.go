@@ -0,0 +1,23 @@
+package models
+
+// TerminalOptions configure the shell of a web terminal. Rows and Cols set
+// the initial size of the TTY.
+type TerminalOptions struct {
+	Cmd        []string
+	User       string
+	WorkingDir string
+	Rows       uint
+	Cols       uint
+}
+
+// TerminalMessage is a control message of a terminal or attach WebSocket,
+// sent as a text frame. The output of the process and raw input travel as
+// binary frames. Clients send input, resize and detach messages, the server
+// reports the exit code of the process or an error.
+type TerminalMessage struct {
+	Type     string `json:"type" example:"resize" enums:"input,resize,detach,exit,error"`
+	Data     string `json:"data,omitempty"`
+	Rows     uint   `json:"rows,omitempty" example:"24"`
+	Cols     uint   `json:"cols,omitempty" example:"80"`
+	ExitCode *int   `json:"exit_code,omitempty"`
+}
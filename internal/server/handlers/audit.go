@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mineServers/internal/models"
+	"mineServers/internal/service"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// maxAuditPayload bounds the request bodies recorded in the audit log,
+	// larger ones are summarized.
+	maxAuditPayload = 64 << 10
+	// maxAuditError bounds the error responses read for the audit log.
+	maxAuditError = 4 << 10
+)
+
+// auditUserHeaders carry the user authenticated by a reverse proxy in
+// front of the server, the actor of the audited calls. They are only read
+// on calls coming from a trusted proxy.
+var auditUserHeaders = []string{"X-Forwarded-User", "X-Remote-User", "Remote-User"}
+
+var auditCSVHeader = []string{"id", "time", "actor", "source_ip", "method", "route", "path", "target", "host", "status", "result", "error", "duration_ms", "payload"}
+
+type AuditHandler struct {
+	audit *service.AuditLog
+}
+
+func NewAuditHandler(audit *service.AuditLog) *AuditHandler {
+	return &AuditHandler{
+		audit: audit,
+	}
+}
+
+// AuditMiddleware records the mutating calls, every call but GET, HEAD and
+// OPTIONS ones and the WebSocket sessions, in the audit log once they are
+// handled. The actor is taken from the user headers only on calls whose
+// peer is in trustedProxies.
+func AuditMiddleware(audit *service.AuditLog, trustedProxies []*net.IPNet) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(e echo.Context) error {
+			req := e.Request()
+			websocket := strings.EqualFold(req.Header.Get(echo.HeaderUpgrade), "websocket")
+			switch req.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				if !websocket {
+					return next(e)
+				}
+			}
+
+			start := time.Now()
+			payload := auditPayload(req)
+			writer := &auditWriter{ResponseWriter: e.Response().Writer}
+			e.Response().Writer = writer
+
+			err := next(e)
+			if err != nil {
+				e.Error(err)
+			}
+
+			entry := &models.AuditEntry{
+				Time:       start,
+				Actor:      auditActor(req, trustedProxies),
+				SourceIP:   e.RealIP(),
+				Method:     req.Method,
+				Route:      e.Path(),
+				Path:       req.URL.RequestURI(),
+				Target:     auditTarget(e, payload),
+				Host:       e.QueryParam("host"),
+				Payload:    payload,
+				Status:     e.Response().Status,
+				Result:     service.AuditSuccess,
+				DurationMs: time.Since(start).Milliseconds(),
+			}
+			if websocket && !e.Response().Committed {
+				// The connection was hijacked by the WebSocket session.
+				entry.Status = http.StatusSwitchingProtocols
+			}
+			if entry.Status >= http.StatusBadRequest || err != nil {
+				entry.Result = service.AuditFailure
+				entry.Error = writer.errorMessage(entry.Status, err)
+			}
+			audit.Record(context.WithoutCancel(req.Context()), entry)
+
+			return nil
+		}
+	}
+}
+
+// @Summary List the audit log
+// @Description List the mutating API calls, such as creations, deletions, state changes, exec commands and file uploads, newest first.
+// @Description Entries record the actor, taken from the X-Forwarded-User or X-Remote-User headers set by an authenticating proxy listed
+// @Description in TRUSTED_PROXIES or from basic auth, the source IP, the payload with its secrets redacted, the target, the result and the error.
+// @Description Pass the next cursor as before to get the following page.
+// @Tags audit
+// @Produce json
+// @Param actor query string false "Actor"
+// @Param method query string false "HTTP method" Enums(POST, PUT, PATCH, DELETE, GET)
+// @Param target query string false "Target, such as a container name"
+// @Param host query string false "Docker host name"
+// @Param result query string false "Result" Enums(success, failure)
+// @Param from query string false "Only calls at or after this RFC 3339 time"
+// @Param to query string false "Only calls before this RFC 3339 time"
+// @Param before query string false "Next cursor of the previous page"
+// @Param limit query int false "Maximum number of entries, defaults to 100"
+// @Success 200 {object} models.AuditPage
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /audit [get]
+func (s *AuditHandler) ListAuditHandler(e echo.Context) error {
+	query := new(models.AuditQuery)
+	if err := e.Bind(query); err != nil {
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{Code: "INVALID_QUERY", Message: "Unable to parse the audit query"})
+	}
+
+	page, err := s.audit.List(e.Request().Context(), query)
+	if err != nil {
+		return containerErrorResponse(e, err, nil)
+	}
+
+	return e.JSON(http.StatusOK, page)
+}
+
+// @Summary Export the audit log
+// @Description Download every entry of the audit log matching the filters, newest first, as CSV or JSON
+// @Tags audit
+// @Produce json
+// @Produce text/csv
+// @Param format query string false "Export format, defaults to json" Enums(json, csv)
+// @Param actor query string false "Actor"
+// @Param method query string false "HTTP method" Enums(POST, PUT, PATCH, DELETE, GET)
+// @Param target query string false "Target, such as a container name"
+// @Param host query string false "Docker host name"
+// @Param result query string false "Result" Enums(success, failure)
+// @Param from query string false "Only calls at or after this RFC 3339 time"
+// @Param to query string false "Only calls before this RFC 3339 time"
+// @Success 200 {array} models.AuditEntry
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /audit/export [get]
+func (s *AuditHandler) ExportAuditHandler(e echo.Context) error {
+	format := e.QueryParam("format")
+	if format == "" {
+		format = service.FormatJSON
+	}
+	if format != service.FormatJSON && format != service.FormatCSV {
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{Code: "INVALID_FORMAT", Message: "format must be json or csv"})
+	}
+
+	query := new(models.AuditQuery)
+	if err := e.Bind(query); err != nil {
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{Code: "INVALID_QUERY", Message: "Unable to parse the audit query"})
+	}
+	query.Before, query.Limit = "", 0
+	if err := service.ValidateAuditQuery(query); err != nil {
+		return containerErrorResponse(e, err, nil)
+	}
+
+	disableWriteTimeout(e)
+	contentType := echo.MIMEApplicationJSON
+	if format == service.FormatCSV {
+		contentType = "text/csv"
+	}
+	e.Response().Header().Set(echo.HeaderContentType, contentType)
+	e.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", "audit."+format))
+	e.Response().WriteHeader(http.StatusOK)
+
+	if format == service.FormatCSV {
+		w := csv.NewWriter(e.Response())
+		w.Write(auditCSVHeader)
+		err := s.audit.Export(e.Request().Context(), query, func(entry models.AuditEntry) error {
+			return w.Write([]string{
+				strconv.FormatInt(entry.ID, 10), entry.Time.UTC().Format(time.RFC3339Nano), entry.Actor, entry.SourceIP,
+				entry.Method, entry.Route, entry.Path, entry.Target, entry.Host, strconv.Itoa(entry.Status), entry.Result,
+				entry.Error, strconv.FormatInt(entry.DurationMs, 10), string(entry.Payload),
+			})
+		})
+		w.Flush()
+		return err
+	}
+
+	io.WriteString(e.Response(), "[")
+	first := true
+	err := s.audit.Export(e.Request().Context(), query, func(entry models.AuditEntry) error {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		if !first {
+			io.WriteString(e.Response(), ",")
+		}
+		first = false
+		_, err = e.Response().Write(data)
+		return err
+	})
+	io.WriteString(e.Response(), "]")
+
+	return err
+}
+
+// auditPayload reads the JSON body of the request, with its secrets
+// redacted, and puts it back for the handler. Other bodies are summarized
+// by their content type and size.
+func auditPayload(req *http.Request) json.RawMessage {
+	if req.Body == nil || req.Body == http.NoBody || req.ContentLength == 0 {
+		return nil
+	}
+
+	contentType := req.Header.Get(echo.HeaderContentType)
+	if strings.HasPrefix(contentType, echo.MIMEApplicationJSON) && req.ContentLength <= maxAuditPayload {
+		body, err := io.ReadAll(io.LimitReader(req.Body, maxAuditPayload+1))
+		req.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+		if err == nil && len(body) <= maxAuditPayload {
+			if payload := service.RedactPayload(body); payload != nil {
+				return payload
+			}
+		}
+	}
+
+	summary := map[string]any{"content_type": contentType}
+	if req.ContentLength > 0 {
+		summary["bytes"] = req.ContentLength
+	}
+	payload, _ := json.Marshal(summary)
+
+	return payload
+}
+
+func auditActor(req *http.Request, trustedProxies []*net.IPNet) string {
+	if fromTrustedProxy(req, trustedProxies) {
+		for _, header := range auditUserHeaders {
+			if user := req.Header.Get(header); user != "" {
+				return user
+			}
+		}
+	}
+	if user, _, ok := req.BasicAuth(); ok && user != "" {
+		return user
+	}
+
+	return service.AuditAnonymous
+}
+
+// fromTrustedProxy reports whether the peer of the request, not the
+// addresses it forwards, is in one of the trusted proxy ranges.
+func fromTrustedProxy(req *http.Request, trustedProxies []*net.IPNet) bool {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// auditTarget returns the path parameters of the call, such as the
+// container ID, or the name given in the payload of creations.
+func auditTarget(e echo.Context, payload json.RawMessage) string {
+	var params []string
+	for _, value := range e.ParamValues() {
+		if value != "" {
+			params = append(params, value)
+		}
+	}
+	if len(params) > 0 {
+		return strings.Join(params, "/")
+	}
+
+	var body struct {
+		Name string `json:"name"`
+	}
+	json.Unmarshal(payload, &body)
+
+	return body.Name
+}
+
+// auditWriter keeps the beginning of error responses to record their
+// message in the audit log.
+type auditWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *auditWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *auditWriter) Write(b []byte) (int, error) {
+	if w.status >= http.StatusBadRequest && w.body.Len() < maxAuditError {
+		w.body.Write(b[:min(len(b), maxAuditError-w.body.Len())])
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *auditWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *auditWriter) errorMessage(status int, err error) string {
+	var resp models.ErrorResponse
+	if json.Unmarshal(w.body.Bytes(), &resp) == nil && resp.Message != "" {
+		return resp.Message
+	}
+	if err != nil {
+		return err.Error()
+	}
+
+	return http.StatusText(status)
+}
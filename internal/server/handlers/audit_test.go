@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"mineServers/internal/models"
+	"mineServers/internal/service"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestAuditMiddleware_RecordsMutations(t *testing.T) {
+	db := openTestDB(t)
+
+	hosts, engine := newTestHostManager(t)
+	containers := NewContainerHandler(hosts, service.NewPullManager(context.Background(), nil))
+	audit := service.NewAuditLog(db)
+	handler := NewAuditHandler(audit)
+	createTestContainer(t, engine, "mc", true)
+
+	// httptest requests come from 192.0.2.1.
+	_, proxy, _ := net.ParseCIDR("192.0.2.0/24")
+	e := echo.New()
+	api := e.Group("/api")
+	api.Use(AuditMiddleware(audit, []*net.IPNet{proxy}))
+	api.GET("/containers/", containers.ListContainersHandler)
+	api.POST("/containers/:id/stop", containers.StopContainer)
+	api.POST("/containers/:id/exec", containers.ExecContainerHandler)
+	api.DELETE("/containers/:id", containers.DeleteContainerHandler)
+
+	serve := func(method, target, body string, headers ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	serve(http.MethodGet, "/api/containers/", "")
+	serve(http.MethodPost, "/api/containers/mc/exec", `{"cmd":["mysql","--password=x"],"env":{"MYSQL_PWD":"hunter2"}}`)
+	if rec := serve(http.MethodPost, "/api/containers/mc/stop", "", "X-Forwarded-User", "alice"); rec.Code >= 400 {
+		t.Fatalf("stop status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	serve(http.MethodDelete, "/api/containers/missing", "")
+
+	page, err := audit.List(context.Background(), &models.AuditQuery{})
+	if err != nil || len(page.Entries) != 3 {
+		t.Fatalf("List() = %+v, %v", page, err)
+	}
+	deleted, stop, exec := page.Entries[0], page.Entries[1], page.Entries[2]
+	if stop.Actor != "alice" || stop.Route != "/api/containers/:id/stop" || stop.Target != "mc" || stop.Result != service.AuditSuccess || stop.SourceIP == "" {
+		t.Fatalf("stop entry = %+v", stop)
+	}
+	if exec.Actor != service.AuditAnonymous || exec.Result != service.AuditSuccess || !strings.Contains(string(exec.Payload), `"MYSQL_PWD":"[REDACTED]"`) || strings.Contains(string(exec.Payload), "hunter2") {
+		t.Fatalf("exec entry = %+v, payload = %s", exec, exec.Payload)
+	}
+	if deleted.Result != service.AuditFailure || deleted.Status < http.StatusBadRequest || deleted.Error == "" || deleted.Target != "missing" {
+		t.Fatalf("delete entry = %+v", deleted)
+	}
+
+	ctx, rec := newTestContext(http.MethodGet, "/audit/export?format=csv&result=failure", "")
+	if err := handler.ExportAuditHandler(ctx); err != nil {
+		t.Fatalf("ExportAuditHandler() error = %v", err)
+	}
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil || len(records) != 2 || records[0][0] != "id" || records[1][4] != http.MethodDelete {
+		t.Fatalf("csv = %v, %v", records, err)
+	}
+
+	ctx, rec = newTestContext(http.MethodGet, "/audit/export", "")
+	if err := handler.ExportAuditHandler(ctx); err != nil {
+		t.Fatalf("ExportAuditHandler() error = %v", err)
+	}
+	var exported []models.AuditEntry
+	if err := json.Unmarshal(rec.Body.Bytes(), &exported); err != nil || len(exported) != 3 || rec.Header().Get(echo.HeaderContentDisposition) == "" {
+		t.Fatalf("json export = %s, %v", rec.Body.String(), err)
+	}
+
+	ctx, rec = newTestContext(http.MethodGet, "/audit?result=maybe", "")
+	if err := handler.ListAuditHandler(ctx); err != nil {
+		t.Fatalf("ListAuditHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestAuditActor_TrustsOnlyProxyHeaders(t *testing.T) {
+	_, proxy, _ := net.ParseCIDR("10.0.0.0/8")
+	trusted := []*net.IPNet{proxy}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/containers/mc/stop", nil)
+	req.Header.Set("X-Forwarded-User", "alice")
+	req.RemoteAddr = "10.1.2.3:41000"
+	if actor := auditActor(req, trusted); actor != "alice" {
+		t.Fatalf("actor from the proxy = %q, want alice", actor)
+	}
+
+	req.RemoteAddr = "203.0.113.7:41000"
+	if actor := auditActor(req, trusted); actor != service.AuditAnonymous {
+		t.Fatalf("actor from another peer = %q, want %q", actor, service.AuditAnonymous)
+	}
+	req.SetBasicAuth("bob", "secret")
+	if actor := auditActor(req, nil); actor != "bob" {
+		t.Fatalf("actor without proxies = %q, want bob", actor)
+	}
+}
@@ -0,0 +1,350 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"mineServers/internal/models"
+	"mineServers/internal/service"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	backupNotFoundResponse = models.ErrorResponse{
+		Code:    "BACKUP_NOT_FOUND",
+		Message: "No backup with this ID",
+	}
+	backupScheduleNotFoundResponse = models.ErrorResponse{
+		Code:    "BACKUP_SCHEDULE_NOT_FOUND",
+		Message: "No backup schedule with this ID",
+	}
+)
+
+type BackupHandler struct {
+	backups *service.BackupManager
+}
+
+func NewBackupHandler(backups *service.BackupManager) *BackupHandler {
+	return &BackupHandler{
+		backups: backups,
+	}
+}
+
+// @Summary Back up a container
+// @Description Archive volumes and bind mounts of a container into compressed tar files, one backup per mount.
+// @Description The container can be paused or stopped meanwhile for consistency; it is brought back afterwards.
+// @Tags backups
+// @Accept json
+// @Produce json
+// @Param id path string true "Container ID or name"
+// @Param backup body models.BackupRequest false "Mounts and mode"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 201 {array} models.Backup
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /containers/{id}/backups [post]
+func (s *BackupHandler) BackupContainerHandler(e echo.Context) error {
+	req := new(models.BackupRequest)
+	if err := e.Bind(req); err != nil {
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{Code: "INVALID_PAYLOAD", Message: "Unable to parse the backup payload"})
+	}
+
+	disableWriteTimeout(e)
+	backups, err := s.backups.BackupContainer(e.Request().Context(), e.QueryParam("host"), e.Param("id"), req)
+	if err != nil {
+		return backupErrorResponse(e, err, backups)
+	}
+
+	return e.JSON(http.StatusCreated, backups)
+}
+
+// @Summary List backups
+// @Description List backups, newest first, optionally of a host and container
+// @Tags backups
+// @Produce json
+// @Param host query string false "Docker host name"
+// @Param container query string false "Container name or ID"
+// @Success 200 {array} models.Backup
+// @Failure 500 {object} models.ErrorResponse
+// @Router /backups [get]
+func (s *BackupHandler) ListBackupsHandler(e echo.Context) error {
+	backups, err := s.backups.ListBackups(e.Request().Context(), e.QueryParam("host"), e.QueryParam("container"))
+	if err != nil {
+		return backupErrorResponse(e, err, nil)
+	}
+
+	return e.JSON(http.StatusOK, backups)
+}
+
+// @Summary Get a backup
+// @Description Get a backup by ID
+// @Tags backups
+// @Produce json
+// @Param id path int true "Backup ID"
+// @Success 200 {object} models.Backup
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /backups/{id} [get]
+func (s *BackupHandler) GetBackupHandler(e echo.Context) error {
+	id, err := strconv.ParseInt(e.Param("id"), 10, 64)
+	if err != nil {
+		return e.JSON(http.StatusNotFound, backupNotFoundResponse)
+	}
+
+	backup, err := s.backups.GetBackup(e.Request().Context(), id)
+	if err != nil {
+		return backupErrorResponse(e, err, nil)
+	}
+
+	return e.JSON(http.StatusOK, backup)
+}
+
+// @Summary Download a backup
+// @Description Download the gzip compressed tar archive of a completed backup
+// @Tags backups
+// @Produce application/gzip
+// @Param id path int true "Backup ID"
+// @Success 200 {file} binary
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /backups/{id}/download [get]
+func (s *BackupHandler) DownloadBackupHandler(e echo.Context) error {
+	id, err := strconv.ParseInt(e.Param("id"), 10, 64)
+	if err != nil {
+		return e.JSON(http.StatusNotFound, backupNotFoundResponse)
+	}
+
+	f, backup, err := s.backups.OpenBackup(e.Request().Context(), id)
+	if err != nil {
+		return backupErrorResponse(e, err, nil)
+	}
+	defer f.Close()
+
+	disableWriteTimeout(e)
+	e.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", backup.ContainerName+"-"+filepath.Base(backup.File)))
+	return e.Stream(http.StatusOK, "application/gzip", f)
+}
+
+// @Summary Restore a backup
+// @Description Extract a backup into the mount it was taken from, found through the container ID or name, or into a named volume,
+// @Description created when missing. Files missing from the backup are kept.
+// @Tags backups
+// @Accept json
+// @Produce json
+// @Param id path int true "Backup ID"
+// @Param restore body models.RestoreRequest false "Target volume and mode"
+// @Success 200 {object} models.RestoreResult
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /backups/{id}/restore [post]
+func (s *BackupHandler) RestoreBackupHandler(e echo.Context) error {
+	id, err := strconv.ParseInt(e.Param("id"), 10, 64)
+	if err != nil {
+		return e.JSON(http.StatusNotFound, backupNotFoundResponse)
+	}
+
+	req := new(models.RestoreRequest)
+	if err := e.Bind(req); err != nil {
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{Code: "INVALID_PAYLOAD", Message: "Unable to parse the restore payload"})
+	}
+
+	disableWriteTimeout(e)
+	result, err := s.backups.RestoreBackup(e.Request().Context(), id, req)
+	if err != nil {
+		return backupErrorResponse(e, err, nil)
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+// @Summary Delete a backup
+// @Description Delete a backup and its archive
+// @Tags backups
+// @Param id path int true "Backup ID"
+// @Success 204
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /backups/{id} [delete]
+func (s *BackupHandler) DeleteBackupHandler(e echo.Context) error {
+	id, err := strconv.ParseInt(e.Param("id"), 10, 64)
+	if err != nil {
+		return e.JSON(http.StatusNotFound, backupNotFoundResponse)
+	}
+
+	if err := s.backups.DeleteBackup(e.Request().Context(), id); err != nil {
+		return backupErrorResponse(e, err, nil)
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// @Summary List backup schedules
+// @Description List the backup schedules with their last and next runs
+// @Tags backups
+// @Produce json
+// @Success 200 {array} models.BackupSchedule
+// @Failure 500 {object} models.ErrorResponse
+// @Router /backups/schedules [get]
+func (s *BackupHandler) ListSchedulesHandler(e echo.Context) error {
+	schedules, err := s.backups.ListSchedules(e.Request().Context())
+	if err != nil {
+		return backupErrorResponse(e, err, nil)
+	}
+
+	return e.JSON(http.StatusOK, schedules)
+}
+
+// @Summary Create a backup schedule
+// @Description Back up a container periodically. After every run the retention policy prunes the older backups of each mount.
+// @Tags backups
+// @Accept json
+// @Produce json
+// @Param schedule body models.BackupScheduleDefinition true "Schedule"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 201 {object} models.BackupSchedule
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /backups/schedules [post]
+func (s *BackupHandler) CreateScheduleHandler(e echo.Context) error {
+	def := new(models.BackupScheduleDefinition)
+	if err := e.Bind(def); err != nil {
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{Code: "INVALID_PAYLOAD", Message: "Unable to parse the schedule payload"})
+	}
+
+	schedule, err := s.backups.CreateSchedule(e.Request().Context(), e.QueryParam("host"), def)
+	if err != nil {
+		return backupErrorResponse(e, err, nil)
+	}
+
+	return e.JSON(http.StatusCreated, schedule)
+}
+
+// @Summary Get a backup schedule
+// @Description Get a backup schedule by ID
+// @Tags backups
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Success 200 {object} models.BackupSchedule
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /backups/schedules/{id} [get]
+func (s *BackupHandler) GetScheduleHandler(e echo.Context) error {
+	id, err := strconv.ParseInt(e.Param("id"), 10, 64)
+	if err != nil {
+		return e.JSON(http.StatusNotFound, backupScheduleNotFoundResponse)
+	}
+
+	schedule, err := s.backups.GetSchedule(e.Request().Context(), id)
+	if err != nil {
+		return backupErrorResponse(e, err, nil)
+	}
+
+	return e.JSON(http.StatusOK, schedule)
+}
+
+// @Summary Update a backup schedule
+// @Description Replace the definition of a backup schedule. Its host and run history are kept.
+// @Tags backups
+// @Accept json
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Param schedule body models.BackupScheduleDefinition true "Schedule"
+// @Success 200 {object} models.BackupSchedule
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /backups/schedules/{id} [put]
+func (s *BackupHandler) UpdateScheduleHandler(e echo.Context) error {
+	id, err := strconv.ParseInt(e.Param("id"), 10, 64)
+	if err != nil {
+		return e.JSON(http.StatusNotFound, backupScheduleNotFoundResponse)
+	}
+
+	def := new(models.BackupScheduleDefinition)
+	if err := e.Bind(def); err != nil {
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{Code: "INVALID_PAYLOAD", Message: "Unable to parse the schedule payload"})
+	}
+
+	schedule, err := s.backups.UpdateSchedule(e.Request().Context(), id, def)
+	if err != nil {
+		return backupErrorResponse(e, err, nil)
+	}
+
+	return e.JSON(http.StatusOK, schedule)
+}
+
+// @Summary Delete a backup schedule
+// @Description Delete a backup schedule. The backups it made are kept.
+// @Tags backups
+// @Param id path int true "Schedule ID"
+// @Success 204
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /backups/schedules/{id} [delete]
+func (s *BackupHandler) DeleteScheduleHandler(e echo.Context) error {
+	id, err := strconv.ParseInt(e.Param("id"), 10, 64)
+	if err != nil {
+		return e.JSON(http.StatusNotFound, backupScheduleNotFoundResponse)
+	}
+
+	if err := s.backups.DeleteSchedule(e.Request().Context(), id); err != nil {
+		return backupErrorResponse(e, err, nil)
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// @Summary Run a backup schedule
+// @Description Run a backup schedule right away, then apply its retention policy
+// @Tags backups
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Success 201 {array} models.Backup
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /backups/schedules/{id}/run [post]
+func (s *BackupHandler) RunScheduleHandler(e echo.Context) error {
+	id, err := strconv.ParseInt(e.Param("id"), 10, 64)
+	if err != nil {
+		return e.JSON(http.StatusNotFound, backupScheduleNotFoundResponse)
+	}
+
+	disableWriteTimeout(e)
+	backups, err := s.backups.RunSchedule(e.Request().Context(), id)
+	if err != nil {
+		return backupErrorResponse(e, err, backups)
+	}
+
+	return e.JSON(http.StatusCreated, backups)
+}
+
+// backupErrorResponse handles the backup errors, falling back to the
+// container ones. details are the records of a failed backup.
+func backupErrorResponse(e echo.Context, err error, details any) error {
+	switch {
+	case errors.Is(err, service.ErrBackupNotFound):
+		return e.JSON(http.StatusNotFound, backupNotFoundResponse)
+	case errors.Is(err, service.ErrBackupScheduleNotFound):
+		return e.JSON(http.StatusNotFound, backupScheduleNotFoundResponse)
+	case errors.Is(err, service.ErrHostNotFound):
+		return e.JSON(http.StatusNotFound, hostNotFoundResponse)
+	case errors.Is(err, service.ErrBackupInProgress):
+		return e.JSON(http.StatusConflict, models.ErrorResponse{Code: "BACKUP_IN_PROGRESS", Message: err.Error()})
+	case errors.Is(err, service.ErrBackupUnavailable):
+		return e.JSON(http.StatusConflict, models.ErrorResponse{Code: "BACKUP_UNAVAILABLE", Message: err.Error()})
+	case errors.Is(err, service.ErrBackupFailed):
+		return e.JSON(http.StatusInternalServerError, models.ErrorResponse{Code: "BACKUP_FAILED", Message: err.Error(), Details: details})
+	default:
+		return containerErrorResponse(e, err, nil)
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"mineServers/internal/fakedocker"
+	"mineServers/internal/models"
+	"mineServers/internal/service"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+)
+
+func newTestBackupHandler(t *testing.T) (*BackupHandler, *fakedocker.Engine) {
+	t.Helper()
+
+	db := openTestDB(t)
+
+	hosts, engine := newTestHostManager(t)
+
+	return NewBackupHandler(service.NewBackupManager(context.Background(), db, hosts, t.TempDir())), engine
+}
+
+func TestBackupHandlers_BackupDownloadAndRestore(t *testing.T) {
+	handler, engine := newTestBackupHandler(t)
+	engine.AddImage("docker.io/library/alpine:latest")
+	resp, err := engine.ContainerCreate(context.Background(), &container.Config{Image: "docker.io/library/alpine:latest"},
+		&container.HostConfig{Mounts: []mount.Mount{{Type: mount.TypeVolume, Source: "mc-data", Target: "/data"}}}, nil, nil, "mc")
+	if err != nil {
+		t.Fatalf("unable to create container: %s", err)
+	}
+	engine.WriteFile(resp.ID, "/data/world/level.dat", []byte("level"), 0o644)
+
+	ctx, rec := newTestContext(http.MethodPost, "/containers/mc/backups", `{"mounts":["/data"],"mode":"stop"}`, "id", "mc")
+	if err := handler.BackupContainerHandler(ctx); err != nil {
+		t.Fatalf("BackupContainerHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var backups []models.Backup
+	if err := json.Unmarshal(rec.Body.Bytes(), &backups); err != nil || len(backups) != 1 || backups[0].Status != service.BackupCompleted {
+		t.Fatalf("backups = %+v, %v", backups, err)
+	}
+	id := strconv.FormatInt(backups[0].ID, 10)
+
+	ctx, rec = newTestContext(http.MethodGet, "/backups/"+id+"/download", "", "id", id)
+	if err := handler.DownloadBackupHandler(ctx); err != nil {
+		t.Fatalf("DownloadBackupHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/gzip" {
+		t.Fatalf("status = %d, headers = %v", rec.Code, rec.Header())
+	}
+	gz, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	var names []string
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("tar.Next() error = %v", err)
+		}
+		names = append(names, hdr.Name)
+	}
+	if len(names) != 2 || names[0] != "world/" || names[1] != "world/level.dat" {
+		t.Fatalf("archive entries = %v", names)
+	}
+
+	engine.WriteFile(resp.ID, "/data/world/level.dat", []byte("griefed"), 0o644)
+	ctx, rec = newTestContext(http.MethodPost, "/backups/"+id+"/restore", `{}`, "id", id)
+	if err := handler.RestoreBackupHandler(ctx); err != nil {
+		t.Fatalf("RestoreBackupHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if data, _ := engine.ReadFile(resp.ID, "/data/world/level.dat"); string(data) != "level" {
+		t.Fatalf("level.dat = %q, want level", data)
+	}
+
+	ctx, rec = newTestContext(http.MethodGet, "/backups/42", "", "id", "42")
+	if err := handler.GetBackupHandler(ctx); err != nil {
+		t.Fatalf("GetBackupHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", rec.Code)
+	}
+
+	ctx, rec = newTestContext(http.MethodPost, "/backups/schedules", `{"container":"mc","cron":"every day"}`)
+	if err := handler.CreateScheduleHandler(ctx); err != nil {
+		t.Fatalf("CreateScheduleHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400, body = %s", rec.Code, rec.Body.String())
+	}
+}
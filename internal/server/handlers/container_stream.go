@@ -1,17 +1,16 @@
 package handlers
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"mineServers/internal/models"
 	"net/http"
-	"strings"
 
 	"github.com/charmbracelet/log"
 	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/client"
 	"github.com/labstack/echo/v4"
 )
 
@@ -24,6 +23,10 @@ var (
 		Code:    "DOCKER_READER_ERROR",
 		Message: "Failed to create Docker Reader",
 	}
+	hostNotFoundResponse = models.ErrorResponse{
+		Code:    "HOST_NOT_FOUND",
+		Message: "The selected Docker host is not registered",
+	}
 )
 
 // @Summary Get container stats
@@ -32,6 +35,7 @@ var (
 // @Accept json
 // @Produce text/event-stream
 // @Param id path string true "Container ID"
+// @Param host query string false "Docker host name, defaults to local"
 // @Success 200 {string} string "Server-Sent Events"
 // @Failure 404 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
@@ -39,23 +43,19 @@ var (
 func (s *ContainerHandler) StreamStatContainers(e echo.Context) error {
 	containerId := e.Param("id")
 
-	cli, err := newDockerClient(client.FromEnv)
+	svc, err := s.containerService(e)
 	if err != nil {
-		log.Warnf("CONTAINER-CLIENT: Unable to create docker client due: %s", err)
-		e.JSON(http.StatusInternalServerError, dockerClientErrResponse)
 		return err
 	}
-	defer cli.Close()
 
-	stats, err := cli.ContainerStats(context.Background(), containerId, true)
+	stats, err := svc.StreamContainerStats(e.Request().Context(), containerId)
 	if err != nil {
-		log.Warnf("CONTAINER-CLIENT: Unable to create docker reader due: %s", err)
 		e.JSON(http.StatusInternalServerError, dockerReaderErrResponse)
 
 		return err
 	}
 
-	defer stats.Body.Close()
+	defer stats.Close()
 
 	res := e.Response()
 	res.Header().Set(echo.HeaderContentType, "text/event-stream")
@@ -68,18 +68,18 @@ func (s *ContainerHandler) StreamStatContainers(e echo.Context) error {
 		return e.NoContent(http.StatusInternalServerError)
 	}
 
-	decoder := json.NewDecoder(stats.Body)
+	decoder := json.NewDecoder(stats)
 
 	var prevStats *container.StatsResponse
 
 	for {
 		var v *container.StatsResponse
 		if err := decoder.Decode(&v); err != nil {
-			if err == io.EOF {
-				break
+			if err != io.EOF {
+				log.Warnf("CONTAINER-CLIENT: Error decoding stats: %v", err)
 			}
 
-			log.Warnf("CONTAINER-CLIENT: Error decoding stats: %v", err)
+			break
 		}
 		var cpuPercent float64
 		if prevStats != nil {
@@ -128,6 +128,7 @@ func calculateCpuPercentUnix(v *container.StatsResponse) float64 {
 // @Accept json
 // @Produce text/event-stream
 // @Param id path string true "Container ID"
+// @Param host query string false "Docker host name, defaults to local"
 // @Success 200 {string} string "Server-Sent Events"
 // @Failure 404 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
@@ -137,24 +138,13 @@ func (s *ContainerHandler) StreamLogContainers(e echo.Context) error {
 	ctx, cancel := context.WithCancel(e.Request().Context())
 	defer cancel()
 
-	cli, err := newDockerClient(client.FromEnv)
+	svc, err := s.containerService(e)
 	if err != nil {
-		log.Warnf("CONTAINER-CLIENT: Unable to create docker client due: %s", err)
-		e.JSON(http.StatusInternalServerError, dockerClientErrResponse)
 		return err
 	}
-	defer cli.Close()
 
-	options := container.LogsOptions{
-		ShowStdout: true,
-		ShowStderr: true,
-		Follow:     true,
-		Tail:       "20",
-	}
-
-	reader, err := cli.ContainerLogs(context.Background(), containerId, options)
+	reader, err := svc.StreamContainerLogs(ctx, containerId)
 	if err != nil {
-		log.Warnf("CONTAINER-CLIENT: Unable to create docker reader due: %s", err)
 		e.JSON(http.StatusInternalServerError, dockerReaderErrResponse)
 
 		return err
@@ -177,19 +167,12 @@ func (s *ContainerHandler) StreamLogContainers(e echo.Context) error {
 		return e.NoContent(http.StatusInternalServerError)
 	}
 
-	buf := make([]byte, 4096)
-	for {
-		n, err := reader.Read(buf)
-		if n > 0 {
-			logLine := string(buf[:n])
-
-			fmt.Fprintf(res, `%s\n\n`, strings.TrimPrefix(".", logLine))
-			flusher.Flush()
-		}
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		logLine, _ := json.Marshal(scanner.Text())
 
-		if err != nil {
-			break
-		}
+		fmt.Fprintf(res, "data: %s\n\n", logLine)
+		flusher.Flush()
 	}
 
 	return nil
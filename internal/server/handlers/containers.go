@@ -1,45 +1,46 @@
 package handlers
 
 import (
-	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"mineServers/internal/models"
 	"mineServers/internal/service"
 	"net/http"
 
 	"github.com/charmbracelet/log"
-	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/api/types/image"
-	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
 	"github.com/labstack/echo/v4"
 )
 
-type ContainerHandler struct {
-	svc *service.ContainerService
+var containerNotFoundResponse = models.ErrorResponse{
+	Code:    "CONTAINER_NOT_FOUND",
+	Message: "No such container on the selected host",
 }
 
-type CreateOptions struct {
-	Name     string   `json:"name"`
-	Registry string   `json:"registry"`
-	Image    string   `json:"image"`
-	Version  string   `json:"version"`
-	Commands []string `json:"commands"`
+type ContainerHandler struct {
+	hosts *service.HostManager
+	pulls *service.PullManager
 }
 
 // @Summary Create a new container
-// @Description Create a new Docker container with specified configuration
+// @Description Create a new Docker container with specified configuration.
+// @Description The image is given either as a full reference or through registry, image and version (a tag or a digest).
+// @Description It is pulled as a background job following pull_policy (if-not-present by default): the request waits for it,
+// @Description unless async=true returns the job right away and the container is created once the pull completes.
 // @Tags containers
 // @Accept json
 // @Produce json
-// @Param container body CreateOptions true "Container Configuration"
+// @Param container body models.CreateOptions true "Container Configuration"
+// @Param host query string false "Docker host name, defaults to local"
+// @Param async query bool false "Return the pull job instead of waiting for the creation"
 // @Success 201 {object} models.Container
+// @Success 202 {object} models.PullJob
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /containers [post]
 func (s *ContainerHandler) CreateContainerHandler(e echo.Context) error {
 	// Parse opts
-	opts := new(CreateOptions)
+	opts := new(models.CreateOptions)
 	if err := e.Bind(opts); err != nil {
 		log.Warnf("ECHO: unable to bind payload due: %s", err)
 		e.JSON(http.StatusInternalServerError, map[string]string{
@@ -49,59 +50,17 @@ func (s *ContainerHandler) CreateContainerHandler(e echo.Context) error {
 	}
 
 	if err := parseCreateOpts(opts); err != nil {
-		e.JSON(http.StatusBadRequest, map[string]string{
-			"error": "image name is required",
-		})
-
-		return err
-	}
+		e.JSON(http.StatusBadRequest, validationErrorResponse(err))
 
-	// This here allows me to interact with the docker hub without needing to do http requests each time
-	cli, err := newDockerClient(client.FromEnv)
-	if err != nil {
-		e.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "internal server error.",
-		})
 		return err
 	}
-	defer cli.Close()
 
-	// Parse image name - service
-	imageName := fmt.Sprintf("%s/%s:%s", opts.Registry, opts.Image, opts.Version)
-
-	reader, err := s.svc.PullContainerImage(cli, context.Background(), imageName, image.PullOptions{})
+	svc, err := s.containerService(e)
 	if err != nil {
-		e.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "internal server error.",
-		})
 		return err
 	}
-	defer reader.Close()
-
-	resp, err := createDockerContainer(context.Background(), cli, reader, opts, imageName)
-	if err != nil {
-		e.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "internal server error.",
-		})
-
-		return err
-	}
-
-	log.Infof("CONTAINER: Container ID: %s", resp.ID)
-	if err := cli.ContainerStart(context.Background(), resp.ID, container.StartOptions{}); err != nil {
-		log.Warnf("CONTAINER: Unable to start container due: %s", err)
-		e.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "internal server error.",
-		})
-		return err
-	}
-
-	log.Info("CONTAINER: Container created sucessfully!")
-	e.JSON(http.StatusCreated, map[string]string{
-		"success": fmt.Sprintf("created container with ID: %s successfully!", resp.ID),
-	})
 
-	return nil
+	return createContainer(e, svc, s.pulls, opts)
 }
 
 // @Summary Delete a container
@@ -110,29 +69,20 @@ func (s *ContainerHandler) CreateContainerHandler(e echo.Context) error {
 // @Accept json
 // @Produce json
 // @Param id path string true "Container ID"
+// @Param host query string false "Docker host name, defaults to local"
 // @Success 200 {object} models.SuccessResponse
 // @Failure 404 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /containers/{id} [delete]
 func (s *ContainerHandler) DeleteContainerHandler(e echo.Context) error {
 	id := e.Param("id")
-	cli, err := client.NewClientWithOpts(client.FromEnv)
+
+	svc, err := s.containerService(e)
 	if err != nil {
-		log.Warnf("CONTAINER-CLIENT: Unable to create docker client due: %s", err)
-		e.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "internal server error.",
-		})
 		return err
 	}
-	defer cli.Close()
-
-	removeOptions := container.RemoveOptions{
-		Force:         true,
-		RemoveVolumes: false,
-	}
 
-	if err := cli.ContainerRemove(context.Background(), id, removeOptions); err != nil {
-		log.Warnf("CONTAINER-DELETE: Unable to delete container due: %s", err)
+	if err := svc.RemoveContainer(e.Request().Context(), id); err != nil {
 		e.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "internal server error.",
 		})
@@ -160,69 +110,24 @@ func (s *ContainerHandler) DeleteAllContainersHandler(e echo.Context) error {
 // @Tags containers
 // @Accept json
 // @Produce json
+// @Param host query string false "Docker host name, defaults to local"
 // @Success 200 {array} models.Container
 // @Failure 500 {object} models.ErrorResponse
 // @Router /containers [get]
 func (s *ContainerHandler) ListContainersHandler(e echo.Context) error {
-	cli, err := newDockerClient(client.FromEnv)
+	svc, err := s.containerService(e)
 	if err != nil {
-		e.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "internal server error.",
-		})
 		return err
 	}
-	defer cli.Close()
 
-	containers, err := cli.ContainerList(context.Background(), container.ListOptions{All: true})
+	out, err := svc.ListContainers(e.Request().Context())
 	if err != nil {
-		log.Warnf("CONTAINER-CLIENT: Unable to get containers due: %s", err)
 		e.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "internal server error.",
 		})
 		return err
 	}
 
-	var out []models.Container
-	for _, box := range containers {
-		statsReader, err := cli.ContainerStats(context.Background(), box.ID, false)
-		if err != nil {
-			log.Warnf("CONTAINER-CLIENT: Unable to get container stats: %s", err)
-			return e.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "unable to get container stats",
-			})
-		}
-
-		var containerStats container.StatsResponse
-		if err := json.NewDecoder(statsReader.Body).Decode(&containerStats); err != nil {
-			log.Warnf("CONTAINER-CLIENT: Unable to decode stats: %s", err)
-			return e.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "unable to get container stats",
-			})
-		}
-
-		statsResponse := models.ContainerStats{
-			CpuUsage: containerStats.CPUStats.CPUUsage.TotalUsage,
-			MemUsage: containerStats.MemoryStats.Usage,
-			CpuTotal: containerStats.CPUStats.SystemUsage,
-			MemTotal: containerStats.MemoryStats.Limit,
-		}
-		parsedPorts := s.svc.ParsePorts(box.Ports)
-
-		curr := models.Container{
-			ID:      box.ID,
-			Names:   box.Names,
-			Image:   box.Image,
-			Command: box.Command,
-			Created: box.Created,
-			Labels:  box.Labels,
-			State:   box.State,
-			Status:  box.Status,
-			Ports:   parsedPorts,
-			Stats:   statsResponse,
-		}
-		out = append(out, curr)
-	}
-
 	e.JSON(http.StatusOK, out)
 	return nil
 }
@@ -233,30 +138,26 @@ func (s *ContainerHandler) ListContainersHandler(e echo.Context) error {
 // @Accept json
 // @Produce json
 // @Param id path string true "Container ID"
+// @Param host query string false "Docker host name, defaults to local"
 // @Success 200 {object} models.SuccessResponse
 // @Failure 404 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /containers/{id}/start [post]
 func (s *ContainerHandler) StartContainer(e echo.Context) error {
 	id := e.Param("id")
-	cli, err := newDockerClient(client.FromEnv)
+
+	svc, err := s.containerService(e)
 	if err != nil {
-		log.Warnf("CONTAINER-CLIENT: Unable to create docker client due: %s", err)
-		return e.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "internal server error",
-		})
+		return err
 	}
 
-	defer cli.Close()
-
-	if err := cli.ContainerStart(context.Background(), id, container.StartOptions{}); err != nil {
-		log.Warnf("CONTAINER-CLIENT: Unable to start docker container due: %s", err)
+	if err := svc.StartContainer(e.Request().Context(), id); err != nil {
 		return e.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "failed to start container",
 		})
 	}
 
-	log.Info("CONTAINER-START: Container '%s' started successfully!", id)
+	log.Infof("CONTAINER-START: Container '%s' started successfully!", id)
 
 	return e.JSON(http.StatusOK, map[string]string{
 		"success": fmt.Sprintf("started containerd with ID: %s", id),
@@ -264,124 +165,433 @@ func (s *ContainerHandler) StartContainer(e echo.Context) error {
 }
 
 // @Summary Stop a container
-// @Description Stop a Docker container by ID
+// @Description Stop a Docker container by ID. The timeout is the number of seconds to wait before killing it, defaults
+// @Description to 10 and -1 waits forever. The signal replaces the stop signal of the container.
 // @Tags containers
 // @Accept json
 // @Produce json
 // @Param id path string true "Container ID"
-// @Success 200 {object} models.SuccessResponse
+// @Param options body models.StateChange false "Timeout and signal"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 200 {object} models.StateTransition
+// @Failure 400 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Router /containers/{id}/stop [post]
 func (s *ContainerHandler) StopContainer(e echo.Context) error {
-	id := e.Param("id")
-	cli, err := newDockerClient(client.FromEnv)
-	if err != nil {
-		log.Warnf("CONTAINER-CLIENT: Unable to create docker client due: %s", err)
-		return e.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "internal server error",
+	return s.changeContainerState(e, service.ActionStop)
+}
+
+// @Summary Restart a container
+// @Description Restart a Docker container by ID. The timeout is the number of seconds to wait before killing it, defaults
+// @Description to 10 and -1 waits forever. The signal replaces the stop signal of the container.
+// @Tags containers
+// @Accept json
+// @Produce json
+// @Param id path string true "Container ID"
+// @Param options body models.StateChange false "Timeout and signal"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 200 {object} models.StateTransition
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /containers/{id}/restart [post]
+func (s *ContainerHandler) RestartContainer(e echo.Context) error {
+	return s.changeContainerState(e, service.ActionRestart)
+}
+
+// @Summary Kill a container
+// @Description Send a signal to the main process of a container, SIGKILL by default. Signals such as SIGHUP leave it
+// @Description running, for instance to reload its configuration.
+// @Tags containers
+// @Accept json
+// @Produce json
+// @Param id path string true "Container ID"
+// @Param options body models.StateChange false "Signal"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 200 {object} models.StateTransition
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /containers/{id}/kill [post]
+func (s *ContainerHandler) KillContainer(e echo.Context) error {
+	return s.changeContainerState(e, service.ActionKill)
+}
+
+// @Summary Pause a container
+// @Description Suspend every process of a running container.
+// @Tags containers
+// @Produce json
+// @Param id path string true "Container ID"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 200 {object} models.StateTransition
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /containers/{id}/pause [post]
+func (s *ContainerHandler) PauseContainer(e echo.Context) error {
+	return s.changeContainerState(e, service.ActionPause)
+}
+
+// @Summary Unpause a container
+// @Description Resume the processes of a paused container.
+// @Tags containers
+// @Produce json
+// @Param id path string true "Container ID"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 200 {object} models.StateTransition
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /containers/{id}/unpause [post]
+func (s *ContainerHandler) UnpauseContainer(e echo.Context) error {
+	return s.changeContainerState(e, service.ActionUnpause)
+}
+
+// @Summary Rename a container
+// @Description Give a container a new name.
+// @Tags containers
+// @Accept json
+// @Produce json
+// @Param id path string true "Container ID"
+// @Param rename body models.RenameRequest true "New name"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 200 {object} models.StateTransition
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /containers/{id}/rename [post]
+func (s *ContainerHandler) RenameContainer(e echo.Context) error {
+	req := new(models.RenameRequest)
+	if err := e.Bind(req); err != nil {
+		log.Warnf("ECHO: unable to bind payload due: %s", err)
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_PAYLOAD",
+			Message: "Unable to parse the rename payload",
 		})
 	}
 
-	defer cli.Close()
+	svc, err := s.containerService(e)
+	if err != nil {
+		return err
+	}
+
+	transition, err := svc.RenameContainer(e.Request().Context(), e.Param("id"), req.Name)
+	if err != nil {
+		return containerErrorResponse(e, err, nil)
+	}
 
-	timeout := 10
-	if err := cli.ContainerStop(context.Background(), id, container.StopOptions{Timeout: &timeout}); err != nil {
-		log.Warnf("CONTAINER-CLIENT: Unable to stop docker container due: %s", err)
-		return e.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "failed to stop container",
+	return e.JSON(http.StatusOK, transition)
+}
+
+// @Summary Run a command in a container
+// @Description Run a command inside a running container and return its exit code with its stdout and stderr. The
+// @Description command stops being waited for after the timeout (30s by default, at most 10m) and each output is
+// @Description capped by max_output (1m by default, at most 16m).
+// @Tags containers
+// @Accept json
+// @Produce json
+// @Param id path string true "Container ID"
+// @Param exec body models.ExecRequest true "Command"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 200 {object} models.ExecResult
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /containers/{id}/exec [post]
+func (s *ContainerHandler) ExecContainerHandler(e echo.Context) error {
+	req := new(models.ExecRequest)
+	if err := e.Bind(req); err != nil {
+		log.Warnf("ECHO: unable to bind payload due: %s", err)
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_PAYLOAD",
+			Message: "Unable to parse the exec payload",
 		})
 	}
 
-	log.Info("CONTAINER-STOP: Container '%s' stopped successfully!", id)
+	svc, err := s.containerService(e)
+	if err != nil {
+		return err
+	}
 
-	return e.JSON(http.StatusOK, map[string]string{
-		"success": fmt.Sprintf("stopped container with ID: %s", id),
-	})
+	disableWriteTimeout(e)
+	result, err := svc.ExecCommand(e.Request().Context(), e.Param("id"), req)
+	if err != nil {
+		return containerErrorResponse(e, err, nil)
+	}
+
+	return e.JSON(http.StatusOK, result)
 }
 
-func (s *ContainerHandler) RestartContainer(e echo.Context) error {
-	id := e.Param("id")
-	cli, err := newDockerClient(client.FromEnv)
-	if err != nil {
-		log.Warnf("CONTAINER-CLIENT: Unable to create docker client due: %s", err)
-		return e.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "internal server error",
+// @Summary Commit a container to an image
+// @Description Snapshot the current state of a container into a new image, optionally tagged, with a message and
+// @Description Dockerfile changes (CMD, ENTRYPOINT, ENV, EXPOSE, LABEL, ONBUILD, STOPSIGNAL, USER, VOLUME, WORKDIR)
+// @Description applied to its config. The container is paused while committed unless pause is false.
+// @Tags containers
+// @Accept json
+// @Produce json
+// @Param id path string true "Container ID"
+// @Param commit body models.CommitRequest true "Image to create"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 201 {object} models.ImageSnapshot
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /containers/{id}/commit [post]
+func (s *ContainerHandler) CommitContainerHandler(e echo.Context) error {
+	req := new(models.CommitRequest)
+	if err := e.Bind(req); err != nil {
+		log.Warnf("ECHO: unable to bind payload due: %s", err)
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_PAYLOAD",
+			Message: "Unable to parse the commit payload",
 		})
 	}
 
-	defer cli.Close()
+	svc, err := s.containerService(e)
+	if err != nil {
+		return err
+	}
 
-	timeout := 10
-	if err := cli.ContainerRestart(context.Background(), id, container.StopOptions{Timeout: &timeout}); err != nil {
-		log.Warnf("CONTAINER-RESTART: Unable to restart docker container due: %s", err)
-		return e.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "failed to stop container",
-		})
+	disableWriteTimeout(e)
+	snapshot, err := svc.CommitContainer(e.Request().Context(), e.Param("id"), req)
+	if err != nil {
+		return containerErrorResponse(e, err, nil)
 	}
-	log.Info("CONTAINER-RESTART: Container '%s' restarted successfully!", id)
-	return e.JSON(http.StatusOK, map[string]string{
-		"success": fmt.Sprintf("restarted container with ID: %s", id),
-	})
+
+	return e.JSON(http.StatusCreated, snapshot)
 }
 
-func (s *ContainerHandler) GetContainerStats(e echo.Context) error {
-	id := e.Param(":id")
-	cli, err := newDockerClient(client.FromEnv)
+// @Summary Export a container filesystem
+// @Description Stream the filesystem of a container as a tar archive. Volumes are not included.
+// @Tags containers
+// @Produce application/x-tar
+// @Param id path string true "Container ID"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 200 {file} binary
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /containers/{id}/export [get]
+func (s *ContainerHandler) ExportContainerHandler(e echo.Context) error {
+	svc, err := s.containerService(e)
 	if err != nil {
-		log.Warnf("CONTAINER-CLIENT: Unable to create docker client due: %s", err)
-		return e.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "internal server error",
+		return err
+	}
+
+	rc, name, err := svc.ExportContainer(e.Request().Context(), e.Param("id"))
+	if err != nil {
+		return containerErrorResponse(e, err, nil)
+	}
+	defer rc.Close()
+
+	disableWriteTimeout(e)
+	e.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", name+".tar"))
+	return e.Stream(http.StatusOK, "application/x-tar", rc)
+}
+
+// changeContainerState runs a state change with the optional options of the
+// payload and responds with the transition.
+func (s *ContainerHandler) changeContainerState(e echo.Context, action string) error {
+	opts := new(models.StateChange)
+	if err := e.Bind(opts); err != nil {
+		log.Warnf("ECHO: unable to bind payload due: %s", err)
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_PAYLOAD",
+			Message: "Unable to parse the " + action + " payload",
 		})
 	}
 
-	defer cli.Close()
+	svc, err := s.containerService(e)
+	if err != nil {
+		return err
+	}
 
-	statsReader, err := cli.ContainerStats(context.Background(), id, false)
+	if action == service.ActionStop || action == service.ActionRestart {
+		disableWriteTimeout(e)
+	}
+	transition, err := svc.ChangeContainerState(e.Request().Context(), e.Param("id"), action, opts)
 	if err != nil {
-		log.Warnf("CONTAINER-CLIENT: Unable to get container stats: %s", err)
-		return e.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "unable to get container stats",
-		})
+		return containerErrorResponse(e, err, nil)
 	}
 
-	var containerStats container.StatsResponse
-	if err := json.NewDecoder(statsReader.Body).Decode(&containerStats); err != nil {
-		log.Warnf("CONTAINER-CLIENT: Unable to decode stats: %s", err)
+	return e.JSON(http.StatusOK, transition)
+}
+
+func (s *ContainerHandler) GetContainerStats(e echo.Context) error {
+	id := e.Param("id")
+
+	svc, err := s.containerService(e)
+	if err != nil {
+		return err
+	}
+
+	statsResponse, err := svc.ContainerStats(e.Request().Context(), id)
+	if err != nil {
 		return e.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "unable to get container stats",
 		})
 	}
 
-	statsResponse := models.ContainerStats{
-		CpuUsage: containerStats.CPUStats.CPUUsage.TotalUsage,
-		MemUsage: containerStats.MemoryStats.Usage,
-		CpuTotal: containerStats.CPUStats.SystemUsage,
-		MemTotal: containerStats.MemoryStats.Limit,
-	}
-
 	return e.JSON(http.StatusOK, statsResponse)
 }
 
 func (s *ContainerHandler) GetContainerCredentails(e echo.Context) error {
 	id := e.Param("id")
-	cli, err := newDockerClient(client.FromEnv)
+
+	svc, err := s.containerService(e)
+	if err != nil {
+		return err
+	}
+
+	containerJSON, err := svc.InspectContainer(e.Request().Context(), id)
 	if err != nil {
-		log.Warnf("CONTAINER-CLIENT: Unable to create docker client due: %s", err)
 		return e.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "internal server error",
 		})
 	}
 
-	defer cli.Close()
+	return e.JSON(http.StatusOK, containerJSON)
+}
+
+// @Summary Recreate a container
+// @Description Pull a new image for a container, a full reference or another tag of its image, or by default its current tag again,
+// @Description and replace the container with one created from the same configuration, mounts, networks and name.
+// @Description The new container is started and, when it has a healthcheck, must become healthy within the timeout; otherwise the old
+// @Description container is restored. The response reports every step.
+// @Tags containers
+// @Accept json
+// @Produce json
+// @Param id path string true "Container ID"
+// @Param recreate body models.RecreateRequest false "Image to recreate the container with"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 200 {object} models.RecreateResult
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse "Details hold the models.RecreateResult"
+// @Router /containers/{id}/recreate [post]
+func (s *ContainerHandler) RecreateContainerHandler(e echo.Context) error {
+	req := new(models.RecreateRequest)
+	if err := e.Bind(req); err != nil {
+		log.Warnf("ECHO: unable to bind payload due: %s", err)
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_PAYLOAD",
+			Message: "Unable to parse the recreate payload",
+		})
+	}
 
-	containerJSON, err := cli.ContainerInspect(context.Background(), id)
+	svc, err := s.containerService(e)
 	if err != nil {
-		log.Warnf("CONTAINER-CLIENT: Unable to inspect container data due: %s", err)
-		return e.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "internal server error",
+		return err
+	}
+
+	// Pulling the new image can take longer than the server WriteTimeout.
+	disableWriteTimeout(e)
+	result, err := svc.RecreateContainer(e.Request().Context(), s.pulls, hostName(e), e.Param("id"), req)
+	if err != nil {
+		return containerErrorResponse(e, err, result)
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+// @Summary Clone a container
+// @Description Create a copy of a container with the same image, configuration, host configuration and networks.
+// @Description Env and labels are merged into the ones of the source and ports replace its bindings. Host ports already
+// @Description in use are remapped to the next free port, or rejected with port_conflict=reject. Compose labels, static IPs, network aliases
+// @Description and the MAC address are not copied. A copy that fails to start is removed.
+// @Tags containers
+// @Accept json
+// @Produce json
+// @Param id path string true "Container ID"
+// @Param clone body models.CloneRequest false "Overrides"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 201 {object} models.CloneResult
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /containers/{id}/clone [post]
+func (s *ContainerHandler) CloneContainerHandler(e echo.Context) error {
+	req := new(models.CloneRequest)
+	if err := e.Bind(req); err != nil {
+		log.Warnf("ECHO: unable to bind payload due: %s", err)
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_PAYLOAD",
+			Message: "Unable to parse the clone payload",
 		})
 	}
 
-	return e.JSON(http.StatusOK, containerJSON)
+	svc, err := s.containerService(e)
+	if err != nil {
+		return err
+	}
+
+	result, err := svc.CloneContainer(e.Request().Context(), e.Param("id"), req)
+	if err != nil {
+		return containerErrorResponse(e, err, nil)
+	}
+
+	return e.JSON(http.StatusCreated, result)
+}
+
+// @Summary Update container limits
+// @Description Change the memory, swap, CPU and pids limits and the restart policy of a container without recreating it.
+// @Description Only the fields that are set are changed. The values are checked against the capacity of the host and the
+// @Description effective limits are returned.
+// @Tags containers
+// @Accept json
+// @Produce json
+// @Param id path string true "Container ID"
+// @Param update body models.ResourceUpdate true "Limits to change"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 200 {object} models.ContainerResources
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /containers/{id} [patch]
+func (s *ContainerHandler) UpdateContainerHandler(e echo.Context) error {
+	req := new(models.ResourceUpdate)
+	if err := e.Bind(req); err != nil {
+		log.Warnf("ECHO: unable to bind payload due: %s", err)
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_PAYLOAD",
+			Message: "Unable to parse the update payload",
+		})
+	}
+
+	svc, err := s.containerService(e)
+	if err != nil {
+		return err
+	}
+
+	resources, err := svc.UpdateContainerResources(e.Request().Context(), e.Param("id"), req)
+	if err != nil {
+		return containerErrorResponse(e, err, nil)
+	}
+
+	return e.JSON(http.StatusOK, resources)
+}
+
+// containerErrorResponse maps the errors of container operations, with
+// details such as the steps of a failed recreation.
+func containerErrorResponse(e echo.Context, err error, details any) error {
+	var verr *service.ValidationError
+	switch {
+	case errors.As(err, &verr), errdefs.IsInvalidParameter(err):
+		return e.JSON(http.StatusBadRequest, validationErrorResponse(err))
+	case errdefs.IsNotFound(err):
+		return e.JSON(http.StatusNotFound, containerNotFoundResponse)
+	case errors.Is(err, service.ErrPortConflict):
+		return e.JSON(http.StatusConflict, models.ErrorResponse{Code: "PORT_CONFLICT", Message: err.Error()})
+	case errdefs.IsConflict(err):
+		return e.JSON(http.StatusConflict, models.ErrorResponse{Code: "CONTAINER_CONFLICT", Message: err.Error()})
+	case errors.Is(err, service.ErrRecreateFailed):
+		return e.JSON(http.StatusInternalServerError, models.ErrorResponse{Code: "RECREATE_FAILED", Message: err.Error(), Details: details})
+	default:
+		log.Warnf("CONTAINER: Unable to handle container request due: %s", err)
+		return e.JSON(http.StatusInternalServerError, models.ErrorResponse{Code: "INTERNAL_ERROR", Message: "internal server error"})
+	}
 }
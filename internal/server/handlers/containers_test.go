@@ -1,24 +1,83 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"mineServers/internal/fakedocker"
+	"mineServers/internal/models"
 	"mineServers/internal/service"
 
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/labstack/echo/v4"
 )
 
+var _ service.DockerAPI = (*fakedocker.Engine)(nil)
+
+func newTestHandler(t *testing.T) (*ContainerHandler, *fakedocker.Engine) {
+	t.Helper()
+
+	hosts, engine := newTestHostManager(t)
+	return NewContainerHandler(hosts, service.NewPullManager(context.Background(), nil)), engine
+}
+
+func newTestContext(method, target, body string, params ...string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	var names, values []string
+	for i := 0; i+1 < len(params); i += 2 {
+		names = append(names, params[i])
+		values = append(values, params[i+1])
+	}
+	ctx.SetParamNames(names...)
+	ctx.SetParamValues(values...)
+
+	return ctx, rec
+}
+
+// createTestContainer creates a container directly on the engine, bypassing the handlers.
+func createTestContainer(t *testing.T, engine *fakedocker.Engine, name string, running bool) string {
+	t.Helper()
+
+	engine.AddImage("docker.io/library/alpine:latest")
+	resp, err := engine.ContainerCreate(context.Background(), &container.Config{Image: "docker.io/library/alpine:latest"}, nil, nil, nil, name)
+	if err != nil {
+		t.Fatalf("unable to create container: %s", err)
+	}
+
+	if running {
+		if err := engine.ContainerStart(context.Background(), resp.ID, container.StartOptions{}); err != nil {
+			t.Fatalf("unable to start container: %s", err)
+		}
+	}
+
+	return resp.ID
+}
+
 func TestListContainersHandler_ReturnsErrorWithoutDocker(t *testing.T) {
 	e := echo.New()
 	req := httptest.NewRequest(http.MethodGet, "/containers", nil)
 	rec := httptest.NewRecorder()
 	ctx := e.NewContext(req, rec)
 
-	handler := &ContainerHandler{svc: service.NewContainerService(nil)}
+	cli, err := service.NewDockerClient(client.WithHost("unix:///nonexistent/docker.sock"))
+	if err != nil {
+		t.Fatalf("unable to create docker client: %s", err)
+	}
+	handler := NewContainerHandler(service.NewHostManager(nil, service.NewContainerService(nil, cli), nil, nil), service.NewPullManager(context.Background(), nil))
 
-	err := handler.ListContainersHandler(ctx)
+	err = handler.ListContainersHandler(ctx)
 	if err == nil {
 		t.Errorf("expected error when Docker is not available, got nil")
 	}
@@ -26,3 +85,391 @@ func TestListContainersHandler_ReturnsErrorWithoutDocker(t *testing.T) {
 		t.Errorf("expected status 500, got %d", rec.Code)
 	}
 }
+
+func TestCreateContainerHandler_PullsCreatesAndStarts(t *testing.T) {
+	handler, engine := newTestHandler(t)
+	ctx, rec := newTestContext(http.MethodPost, "/containers", `{"name":"web","image":"nginx","version":"1.27"}`)
+
+	if err := handler.CreateContainerHandler(ctx); err != nil {
+		t.Fatalf("CreateContainerHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if !engine.HasImage("docker.io/library/nginx:1.27") {
+		t.Errorf("expected image docker.io/library/nginx:1.27 to be pulled")
+	}
+
+	c, ok := engine.Container("web")
+	if !ok {
+		t.Fatalf("expected container 'web' to exist")
+	}
+	if c.State != "running" {
+		t.Errorf("expected container to be running, got %s", c.State)
+	}
+}
+
+func TestCreateContainerHandler_RequiresImage(t *testing.T) {
+	handler, engine := newTestHandler(t)
+	ctx, rec := newTestContext(http.MethodPost, "/containers", `{"name":"web"}`)
+
+	if err := handler.CreateContainerHandler(ctx); err == nil {
+		t.Errorf("expected an error for a missing image")
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rec.Code)
+	}
+	if engine.ContainerCount() != 0 {
+		t.Errorf("expected no container to be created")
+	}
+}
+
+func TestCreateContainerHandler_AppliesHostConfig(t *testing.T) {
+	handler, engine := newTestHandler(t)
+	body := `{
+		"name": "web",
+		"image": "nginx",
+		"env": {"NGINX_PORT": "80"},
+		"ports": [{"container_port": 80, "host_port": 8080}],
+		"mounts": [{"type": "volume", "source": "web-data", "target": "/usr/share/nginx/html"}],
+		"resources": {"memory": "256m"},
+		"restart_policy": {"name": "unless-stopped"}
+	}`
+	ctx, rec := newTestContext(http.MethodPost, "/containers", body)
+
+	if err := handler.CreateContainerHandler(ctx); err != nil {
+		t.Fatalf("CreateContainerHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	c, _ := engine.Container("web")
+	if c.HostConfig.PortBindings["80/tcp"][0].HostPort != "8080" {
+		t.Errorf("expected port 80 to be published on 8080, got %v", c.HostConfig.PortBindings)
+	}
+	if len(c.HostConfig.Mounts) != 1 || c.HostConfig.Memory != 256<<20 || c.HostConfig.RestartPolicy.Name != "unless-stopped" {
+		t.Errorf("unexpected host config: %+v", c.HostConfig)
+	}
+	if len(c.Config.Env) != 1 || c.Config.Env[0] != "NGINX_PORT=80" {
+		t.Errorf("unexpected env: %v", c.Config.Env)
+	}
+}
+
+func TestCreateContainerHandler_ReturnsFieldErrors(t *testing.T) {
+	handler, _ := newTestHandler(t)
+	ctx, rec := newTestContext(http.MethodPost, "/containers", `{"image":"nginx","ports":[{"container_port":0}]}`)
+
+	handler.CreateContainerHandler(ctx)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+
+	var resp struct {
+		Code    string              `json:"code"`
+		Details []models.FieldError `json:"details"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("unable to decode response: %s", err)
+	}
+	if resp.Code != "INVALID_OPTIONS" || len(resp.Details) != 1 || resp.Details[0].Field != "ports[0].container_port" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestListContainersHandler_ReturnsContainersWithStats(t *testing.T) {
+	handler, engine := newTestHandler(t)
+	createTestContainer(t, engine, "running", true)
+	createTestContainer(t, engine, "stopped", false)
+
+	ctx, rec := newTestContext(http.MethodGet, "/containers", "")
+	if err := handler.ListContainersHandler(ctx); err != nil {
+		t.Fatalf("ListContainersHandler() error = %v", err)
+	}
+
+	var out []models.Container
+	if err := json.NewDecoder(rec.Body).Decode(&out); err != nil {
+		t.Fatalf("unable to decode response: %s", err)
+	}
+	if len(out) != 2 {
+		t.Fatalf("expected 2 containers, got %d", len(out))
+	}
+
+	states := map[string]models.Container{}
+	for _, c := range out {
+		states[c.Names[0]] = c
+	}
+	if states["/running"].State != "running" || states["/running"].Stats.MemUsage == 0 {
+		t.Errorf("expected running container with stats, got %+v", states["/running"])
+	}
+	if states["/stopped"].State != "created" {
+		t.Errorf("expected stopped container to be in created state, got %s", states["/stopped"].State)
+	}
+}
+
+func TestContainerLifecycleHandlers(t *testing.T) {
+	handler, engine := newTestHandler(t)
+	id := createTestContainer(t, engine, "lifecycle", false)
+
+	steps := []struct {
+		name    string
+		handler echo.HandlerFunc
+		state   string
+	}{
+		{"start", handler.StartContainer, "running"},
+		{"stop", handler.StopContainer, "exited"},
+		{"restart", handler.RestartContainer, "running"},
+	}
+
+	for _, step := range steps {
+		ctx, rec := newTestContext(http.MethodPost, "/containers/"+id+"/"+step.name, "", "id", id)
+		if err := step.handler(ctx); err != nil {
+			t.Fatalf("%s: unexpected error = %v", step.name, err)
+		}
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d", step.name, rec.Code)
+		}
+
+		c, _ := engine.Container(id)
+		if c.State != step.state {
+			t.Errorf("%s: expected state %s, got %s", step.name, step.state, c.State)
+		}
+	}
+
+	ctx, _ := newTestContext(http.MethodDelete, "/containers/"+id, "", "id", id)
+	if err := handler.DeleteContainerHandler(ctx); err != nil {
+		t.Fatalf("delete: unexpected error = %v", err)
+	}
+	if _, ok := engine.Container(id); ok {
+		t.Errorf("expected container to be removed")
+	}
+}
+
+func TestContainerStateHandlers_ReportTransitions(t *testing.T) {
+	handler, engine := newTestHandler(t)
+	id := createTestContainer(t, engine, "proxy", true)
+
+	ctx, rec := newTestContext(http.MethodPost, "/containers/proxy/kill", `{"signal":"SIGHUP"}`, "id", "proxy")
+	if err := handler.KillContainer(ctx); err != nil {
+		t.Fatalf("KillContainer() error = %v", err)
+	}
+	var transition models.StateTransition
+	json.Unmarshal(rec.Body.Bytes(), &transition)
+	if rec.Code != http.StatusOK || transition.Signal != "SIGHUP" || transition.From != "running" || transition.To != "running" {
+		t.Fatalf("expected the container to keep running, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	ctx, rec = newTestContext(http.MethodPost, "/containers/proxy/stop", `{"timeout":1}`, "id", "proxy")
+	handler.StopContainer(ctx)
+	json.Unmarshal(rec.Body.Bytes(), &transition)
+	if rec.Code != http.StatusOK || transition.To != "exited" {
+		t.Fatalf("expected the container to stop, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	ctx, rec = newTestContext(http.MethodPost, "/containers/proxy/pause", "", "id", "proxy")
+	handler.PauseContainer(ctx)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected status 409 pausing a stopped container, got %d", rec.Code)
+	}
+
+	ctx, rec = newTestContext(http.MethodPost, "/containers/proxy/rename", `{"name":"proxy-old"}`, "id", id)
+	handler.RenameContainer(ctx)
+	json.Unmarshal(rec.Body.Bytes(), &transition)
+	if rec.Code != http.StatusOK || transition.Name != "proxy-old" || transition.PreviousName != "proxy" {
+		t.Errorf("expected the container to be renamed, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestExecContainerHandler_ReturnsOutput(t *testing.T) {
+	handler, engine := newTestHandler(t)
+	createTestContainer(t, engine, "db", true)
+	engine.OnExec(func(ctx context.Context, opts container.ExecOptions, stdin io.Reader, stdout, stderr io.Writer) int {
+		io.WriteString(stdout, strings.Join(opts.Cmd, " "))
+		io.WriteString(stderr, "warning")
+		return 3
+	})
+
+	ctx, rec := newTestContext(http.MethodPost, "/containers/db/exec", `{"cmd":["migrate","up"]}`, "id", "db")
+	if err := handler.ExecContainerHandler(ctx); err != nil {
+		t.Fatalf("ExecContainerHandler() error = %v", err)
+	}
+	var result models.ExecResult
+	json.Unmarshal(rec.Body.Bytes(), &result)
+	if rec.Code != http.StatusOK || result.ExitCode != 3 || result.Stdout != "migrate up" || result.Stderr != "warning" {
+		t.Fatalf("unexpected response %d: %s", rec.Code, rec.Body.String())
+	}
+
+	ctx, rec = newTestContext(http.MethodPost, "/containers/db/exec", `{}`, "id", "db")
+	handler.ExecContainerHandler(ctx)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 without a command, got %d", rec.Code)
+	}
+}
+
+func TestStartContainer_UnknownContainer(t *testing.T) {
+	handler, _ := newTestHandler(t)
+	ctx, rec := newTestContext(http.MethodPost, "/containers/missing/start", "", "id", "missing")
+
+	handler.StartContainer(ctx)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", rec.Code)
+	}
+}
+
+func TestRecreateContainerHandler_ReportsStepsAndRollback(t *testing.T) {
+	handler, engine := newTestHandler(t)
+	id := createTestContainer(t, engine, "web", true)
+
+	ctx, rec := newTestContext(http.MethodPost, "/containers/web/recreate", `{"version":"3.20","timeout":"10ms"}`, "id", "web")
+	if err := handler.RecreateContainerHandler(ctx); err != nil {
+		t.Fatalf("RecreateContainerHandler() error = %v", err)
+	}
+	var result models.RecreateResult
+	json.Unmarshal(rec.Body.Bytes(), &result)
+	if rec.Code != http.StatusOK || result.Status != service.RecreateDone || result.OldID != id {
+		t.Fatalf("expected the container to be recreated, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if c, _ := engine.Container("web"); c.Image != "docker.io/library/alpine:3.20" {
+		t.Errorf("expected the container to run alpine:3.20, got %s", c.Image)
+	}
+
+	engine.CrashOnStart("docker.io/library/alpine:edge", 1)
+	ctx, rec = newTestContext(http.MethodPost, "/containers/web/recreate", `{"version":"edge"}`, "id", "web")
+	handler.RecreateContainerHandler(ctx)
+	if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), `"status":"rolled_back"`) {
+		t.Errorf("expected status 500 with the rolled back steps, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	ctx, rec = newTestContext(http.MethodPost, "/containers/nope/recreate", "", "id", "nope")
+	handler.RecreateContainerHandler(ctx)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown container, got %d", rec.Code)
+	}
+}
+
+func TestCloneContainerHandler_CopiesOrRejectsPorts(t *testing.T) {
+	handler, engine := newTestHandler(t)
+	ctx, rec := newTestContext(http.MethodPost, "/containers", `{"name":"web","image":"nginx","ports":[{"container_port":80,"host_port":8080}]}`)
+	handler.CreateContainerHandler(ctx)
+
+	ctx, rec = newTestContext(http.MethodPost, "/containers/web/clone", `{"name":"web-staging","env":{"MODE":"staging"}}`, "id", "web")
+	if err := handler.CloneContainerHandler(ctx); err != nil {
+		t.Fatalf("CloneContainerHandler() error = %v", err)
+	}
+	var result models.CloneResult
+	json.Unmarshal(rec.Body.Bytes(), &result)
+	if rec.Code != http.StatusCreated || len(result.Remapped) != 1 || result.Ports[0].HostPort != 8081 {
+		t.Fatalf("expected a clone remapped to 8081, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if _, ok := engine.Container("web-staging"); !ok {
+		t.Errorf("expected the clone to exist")
+	}
+
+	ctx, rec = newTestContext(http.MethodPost, "/containers/web/clone", `{"port_conflict":"reject"}`, "id", "web")
+	handler.CloneContainerHandler(ctx)
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "8080/tcp is used by web") {
+		t.Errorf("expected status 409 naming the used port, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestUpdateContainerHandler_ChangesLimits(t *testing.T) {
+	handler, engine := newTestHandler(t)
+	createTestContainer(t, engine, "web", true)
+
+	ctx, rec := newTestContext(http.MethodPatch, "/containers/web", `{"memory":"1g","cpus":1.5,"restart_policy":{"name":"unless-stopped"}}`, "id", "web")
+	if err := handler.UpdateContainerHandler(ctx); err != nil {
+		t.Fatalf("UpdateContainerHandler() error = %v", err)
+	}
+	var resources models.ContainerResources
+	json.Unmarshal(rec.Body.Bytes(), &resources)
+	if rec.Code != http.StatusOK || resources.Memory != 1<<30 || resources.CPUs != 1.5 || resources.RestartPolicy.Name != "unless-stopped" {
+		t.Fatalf("expected the effective limits, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	ctx, rec = newTestContext(http.MethodPatch, "/containers/web", `{"cpus":16}`, "id", "web")
+	handler.UpdateContainerHandler(ctx)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "4 CPUs of the host") {
+		t.Errorf("expected status 400 over the host capacity, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestStreamLogContainers_StreamsOutput(t *testing.T) {
+	handler, engine := newTestHandler(t)
+	id := createTestContainer(t, engine, "logs", false)
+	if err := engine.AppendLogs(id, stdcopy.Stdout, "server started", "listening on :25565"); err != nil {
+		t.Fatalf("unable to append logs: %s", err)
+	}
+
+	ctx, rec := newTestContext(http.MethodGet, "/containers/"+id+"/logs", "", "id", id)
+	if err := handler.StreamLogContainers(ctx); err != nil {
+		t.Fatalf("StreamLogContainers() error = %v", err)
+	}
+
+	if got := rec.Header().Get(echo.HeaderContentType); got != "text/event-stream" {
+		t.Errorf("expected event stream content type, got %q", got)
+	}
+	for _, line := range []string{"server started", "listening on :25565"} {
+		if !strings.Contains(rec.Body.String(), line) {
+			t.Errorf("expected logs to contain %q, got %q", line, rec.Body.String())
+		}
+	}
+}
+
+func TestStreamStatContainers_EmitsSamples(t *testing.T) {
+	handler, engine := newTestHandler(t)
+	id := createTestContainer(t, engine, "stats", false)
+
+	sample := container.StatsResponse{}
+	sample.MemoryStats.Usage = 256
+	sample.MemoryStats.Limit = 1024
+	if err := engine.AppendStats(id, sample, sample); err != nil {
+		t.Fatalf("unable to append stats: %s", err)
+	}
+
+	ctx, rec := newTestContext(http.MethodGet, "/containers/"+id+"/stats", "", "id", id)
+	if err := handler.StreamStatContainers(ctx); err != nil {
+		t.Fatalf("StreamStatContainers() error = %v", err)
+	}
+
+	events := strings.Count(rec.Body.String(), "data: ")
+	if events != 2 {
+		t.Fatalf("expected 2 events, got %d: %s", events, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), `"mem_percent":25`) {
+		t.Errorf("expected mem_percent of 25, got %s", rec.Body.String())
+	}
+}
+
+func TestCommitAndExportContainerHandlers(t *testing.T) {
+	h, engine := newTestHandler(t)
+	id := createTestContainer(t, engine, "mc", true)
+	engine.WriteFile(id, "/data/ops.json", []byte("[]"), 0o644)
+
+	ctx, rec := newTestContext(http.MethodPost, "/containers/mc/commit", `{"reference":"mc:snapshot","message":"before upgrade","changes":["ENV MEMORY=4G"]}`, "id", "mc")
+	if err := h.CommitContainerHandler(ctx); err != nil || rec.Code != http.StatusCreated {
+		t.Fatalf("CommitContainerHandler() = %d %s, %v", rec.Code, rec.Body, err)
+	}
+	var snapshot models.ImageSnapshot
+	json.Unmarshal(rec.Body.Bytes(), &snapshot)
+	if snapshot.Reference != "docker.io/library/mc:snapshot" || !engine.HasImage(snapshot.Reference) {
+		t.Fatalf("snapshot = %+v", snapshot)
+	}
+
+	ctx, rec = newTestContext(http.MethodPost, "/containers/mc/commit", `{"changes":["RUN echo"]}`, "id", "mc")
+	h.CommitContainerHandler(ctx)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("CommitContainerHandler(invalid) = %d %s", rec.Code, rec.Body)
+	}
+
+	ctx, rec = newTestContext(http.MethodGet, "/containers/mc/export", "", "id", "mc")
+	if err := h.ExportContainerHandler(ctx); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("ExportContainerHandler() = %d %s, %v", rec.Code, rec.Body, err)
+	}
+	if got := rec.Header().Get(echo.HeaderContentDisposition); got != `attachment; filename="mc.tar"` {
+		t.Fatalf("Content-Disposition = %s", got)
+	}
+	if !strings.Contains(rec.Body.String(), "data/ops.json") {
+		t.Fatalf("export does not contain data/ops.json")
+	}
+}
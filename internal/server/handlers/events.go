@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"mineServers/internal/models"
+	"mineServers/internal/service"
+
+	"github.com/charmbracelet/log"
+	"github.com/labstack/echo/v4"
+)
+
+// eventHeartbeat is how often a comment is sent on idle event streams so
+// proxies keep them open and clients notice dropped connections.
+var eventHeartbeat = 15 * time.Second
+
+type EventHandler struct {
+	hosts   *service.HostManager
+	history *service.EventRecorder
+}
+
+func NewEventHandler(hosts *service.HostManager, history *service.EventRecorder) *EventHandler {
+	return &EventHandler{
+		hosts:   hosts,
+		history: history,
+	}
+}
+
+// @Summary Stream Docker events
+// @Description Stream the events of the Docker daemon, such as container create, start, die, oom and health_status, image pull and delete
+// @Description or volume and network events. Every event is sent with its ID; reconnecting with it in the Last-Event-ID header replays the
+// @Description events missed in between. A heartbeat comment is sent every 15 seconds. Values of a filter are alternatives, filters must all match.
+// @Tags events
+// @Produce text/event-stream
+// @Param type query []string false "Event types, such as container, image, volume or network" collectionFormat(multi)
+// @Param action query []string false "Event actions, such as start, die or health_status" collectionFormat(multi)
+// @Param container query []string false "Container names or IDs" collectionFormat(multi)
+// @Param label query []string false "Labels as key or key=value" collectionFormat(multi)
+// @Param host query string false "Docker host name, defaults to local"
+// @Param Last-Event-ID header string false "ID of the last event received, to resume after it"
+// @Success 200 {object} models.Event "Server-Sent Events"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /events [get]
+func (s *EventHandler) StreamEventsHandler(e echo.Context) error {
+	var after int64
+	if lastID := e.Request().Header.Get("Last-Event-ID"); lastID != "" {
+		id, err := strconv.ParseInt(lastID, 10, 64)
+		if err != nil || id < 0 {
+			return e.JSON(http.StatusBadRequest, models.ErrorResponse{Code: "INVALID_LAST_EVENT_ID", Message: "Last-Event-ID is not the ID of an event"})
+		}
+		after = id
+	}
+
+	svc, err := resolveService(e, s.hosts)
+	if err != nil {
+		return err
+	}
+
+	filter := &models.EventFilter{
+		Types:      queryList(e, "type"),
+		Actions:    queryList(e, "action"),
+		Containers: queryList(e, "container"),
+		Labels:     queryList(e, "label"),
+	}
+	ctx := e.Request().Context()
+	events, errs, err := svc.StreamEvents(ctx, filter, after)
+	if err != nil {
+		return containerErrorResponse(e, err, nil)
+	}
+
+	disableWriteTimeout(e)
+	e.Response().Header().Set(echo.HeaderContentType, "text/event-stream")
+	e.Response().Header().Set("Cache-Control", "no-cache")
+	e.Response().Header().Set("Connection", "keep-alive")
+	e.Response().WriteHeader(http.StatusOK)
+	e.Response().Flush()
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := io.WriteString(e.Response(), ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+			e.Response().Flush()
+		case event, ok := <-events:
+			if !ok {
+				err := <-errs
+				if err == nil || errors.Is(err, io.EOF) || ctx.Err() != nil {
+					return nil
+				}
+				log.Warnf("EVENTS: Unable to follow the Docker events due: %s", err)
+				data, _ := json.Marshal(models.ErrorResponse{Code: "DOCKER_EVENTS_ERROR", Message: err.Error()})
+				fmt.Fprintf(e.Response(), "event: error\ndata: %s\n\n", data)
+				e.Response().Flush()
+				return nil
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(e.Response(), "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+				return nil
+			}
+			e.Response().Flush()
+		}
+	}
+}
+
+// @Summary Get the timeline of a container
+// @Description List the recorded Docker events of a container, newest first, with the exit code of die events and whether it was OOM killed.
+// @Description Events are kept even once the container is removed, for as long as EVENT_RETENTION (30 days by default).
+// @Description Pass the next cursor as before to get the following page.
+// @Tags containers
+// @Produce json
+// @Param id path string true "Container ID or name"
+// @Param from query string false "Only events at or after this RFC 3339 time"
+// @Param to query string false "Only events before this RFC 3339 time"
+// @Param before query string false "Next cursor of the previous page"
+// @Param limit query int false "Maximum number of events, defaults to 100"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 200 {object} models.Timeline
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /containers/{id}/timeline [get]
+func (s *EventHandler) ContainerTimelineHandler(e echo.Context) error {
+	query := new(models.TimelineQuery)
+	if err := e.Bind(query); err != nil {
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{Code: "INVALID_QUERY", Message: "Unable to parse the timeline query"})
+	}
+
+	timeline, err := s.history.Timeline(e.Request().Context(), e.QueryParam("host"), e.Param("id"), query)
+	if err != nil {
+		if errors.Is(err, service.ErrHostNotFound) {
+			return e.JSON(http.StatusNotFound, hostNotFoundResponse)
+		}
+		return containerErrorResponse(e, err, nil)
+	}
+
+	return e.JSON(http.StatusOK, timeline)
+}
+
+// queryList returns the values of a repeatable query parameter, also
+// splitting comma separated values.
+func queryList(e echo.Context, name string) []string {
+	var values []string
+	for _, param := range e.QueryParams()[name] {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+
+	return values
+}
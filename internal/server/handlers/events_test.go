@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"mineServers/internal/models"
+	"mineServers/internal/service"
+)
+
+func TestStreamEventsHandler(t *testing.T) {
+	hosts, engine := newTestHostManager(t)
+	handler := NewEventHandler(hosts, nil)
+	createTestContainer(t, engine, "db", true)
+	createTestContainer(t, engine, "cache", false)
+
+	heartbeat := eventHeartbeat
+	eventHeartbeat = 20 * time.Millisecond
+	t.Cleanup(func() { eventHeartbeat = heartbeat })
+
+	ctx, rec := newTestContext(http.MethodGet, "/events?container=db&type=container", "")
+	reqCtx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	ctx.SetRequest(ctx.Request().WithContext(reqCtx))
+	ctx.Request().Header.Set("Last-Event-ID", "1")
+	if err := handler.StreamEventsHandler(ctx); err != nil {
+		t.Fatalf("StreamEventsHandler() error = %v", err)
+	}
+
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("status = %d, headers = %v", rec.Code, rec.Header())
+	}
+	if !strings.Contains(body, "event: container\n") || !strings.Contains(body, `"action":"start"`) || !strings.Contains(body, "id: ") {
+		t.Fatalf("body = %s", body)
+	}
+	if strings.Contains(body, `"name":"cache"`) {
+		t.Fatalf("body contains events of other containers: %s", body)
+	}
+	if !strings.Contains(body, ": heartbeat\n\n") {
+		t.Fatalf("body has no heartbeat: %s", body)
+	}
+
+	ctx, rec = newTestContext(http.MethodGet, "/events", "")
+	ctx.Request().Header.Set("Last-Event-ID", "yesterday")
+	if err := handler.StreamEventsHandler(ctx); err != nil {
+		t.Fatalf("StreamEventsHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+
+	ctx, rec = newTestContext(http.MethodGet, "/events?type=vm", "")
+	if err := handler.StreamEventsHandler(ctx); err != nil {
+		t.Fatalf("StreamEventsHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400, body = %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestContainerTimelineHandler(t *testing.T) {
+	db := openTestDB(t)
+
+	hosts, engine := newTestHostManager(t)
+	handler := NewEventHandler(hosts, service.NewEventRecorder(db, hosts, 0))
+	id := createTestContainer(t, engine, "db", false)
+
+	at := time.Date(2026, 1, 1, 3, 0, 0, 0, time.UTC)
+	for i, action := range []string{"create", "start", "die"} {
+		event := models.Event{ID: strconv.FormatInt(at.Add(time.Duration(i)*time.Minute).UnixNano(), 10), Type: "container", Action: action, ActorID: id, Name: "db"}
+		if err := db.RecordEvent(context.Background(), &models.RecordedEvent{Event: event, Host: service.LocalHost}); err != nil {
+			t.Fatalf("RecordEvent() error = %v", err)
+		}
+	}
+
+	ctx, rec := newTestContext(http.MethodGet, "/containers/db/timeline?limit=2&from=2026-01-01T00:00:00Z", "", "id", "db")
+	if err := handler.ContainerTimelineHandler(ctx); err != nil {
+		t.Fatalf("ContainerTimelineHandler() error = %v", err)
+	}
+	var timeline models.Timeline
+	if err := json.Unmarshal(rec.Body.Bytes(), &timeline); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if len(timeline.Events) != 2 || timeline.Events[0].Action != "die" || timeline.Next == "" {
+		t.Fatalf("timeline = %+v", timeline)
+	}
+
+	ctx, rec = newTestContext(http.MethodGet, "/containers/db/timeline?before="+timeline.Next, "", "id", "db")
+	if err := handler.ContainerTimelineHandler(ctx); err != nil {
+		t.Fatalf("ContainerTimelineHandler() error = %v", err)
+	}
+	timeline = models.Timeline{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &timeline); err != nil || len(timeline.Events) != 1 || timeline.Events[0].Action != "create" || timeline.Next != "" {
+		t.Fatalf("second page = %+v, %v", timeline, err)
+	}
+
+	ctx, rec = newTestContext(http.MethodGet, "/containers/db/timeline?from=yesterday", "", "id", "db")
+	if err := handler.ContainerTimelineHandler(ctx); err != nil {
+		t.Fatalf("ContainerTimelineHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400, body = %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+
+	"mineServers/internal/models"
+	"mineServers/internal/service"
+
+	"github.com/charmbracelet/log"
+	"github.com/labstack/echo/v4"
+)
+
+// @Summary List a directory of a container
+// @Description List the entries of a directory inside a container, directories first. Works on stopped containers.
+// @Tags containers
+// @Produce json
+// @Param id path string true "Container ID"
+// @Param path query string false "Absolute path of the directory, defaults to /"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 200 {object} models.DirectoryListing
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /containers/{id}/files [get]
+func (s *ContainerHandler) ListFilesHandler(e echo.Context) error {
+	svc, err := s.containerService(e)
+	if err != nil {
+		return err
+	}
+
+	listing, err := svc.ListFiles(e.Request().Context(), e.Param("id"), e.QueryParam("path"))
+	if err != nil {
+		return filesErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, listing)
+}
+
+// @Summary Download files from a container
+// @Description Download a file or a directory of a container as a tar (default) or zip archive. Works on stopped
+// @Description containers.
+// @Tags containers
+// @Produce application/x-tar
+// @Produce application/zip
+// @Param id path string true "Container ID"
+// @Param path query string true "Absolute path of the file or directory"
+// @Param format query string false "Archive format" Enums(tar, zip)
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 200 {file} binary
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /containers/{id}/files/download [get]
+func (s *ContainerHandler) DownloadFilesHandler(e echo.Context) error {
+	format := e.QueryParam("format")
+	if format == "" {
+		format = service.ArchiveTar
+	}
+	if format != service.ArchiveTar && format != service.ArchiveZip {
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_FORMAT",
+			Message: "format must be tar or zip",
+		})
+	}
+
+	svc, err := s.containerService(e)
+	if err != nil {
+		return err
+	}
+
+	rc, stat, err := svc.DownloadFiles(e.Request().Context(), e.Param("id"), e.QueryParam("path"))
+	if err != nil {
+		return filesErrorResponse(e, err)
+	}
+	defer rc.Close()
+
+	name := path.Base(stat.Name)
+	if name == "/" || name == "." {
+		name = "root"
+	}
+	disableWriteTimeout(e)
+	e.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", name+"."+format))
+
+	if format == service.ArchiveZip {
+		e.Response().Header().Set(echo.HeaderContentType, "application/zip")
+		e.Response().WriteHeader(http.StatusOK)
+		if err := service.TarToZip(e.Response(), rc); err != nil {
+			log.Warnf("CONTAINER-FILES: Unable to write zip archive of '%s' due: %s", stat.Name, err)
+		}
+		return nil
+	}
+
+	return e.Stream(http.StatusOK, "application/x-tar", rc)
+}
+
+// @Summary Upload files into a container
+// @Description Upload files into an existing directory of a container, overwriting files with the same name. With
+// @Description extract set, .tar, .tar.gz, .tgz and .zip archives are extracted into the directory instead.
+// @Tags containers
+// @Accept mpfd
+// @Produce json
+// @Param id path string true "Container ID"
+// @Param path query string true "Absolute path of the destination directory"
+// @Param extract query bool false "Extract archives into the directory"
+// @Param files formData file true "Files to upload"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 200 {object} models.UploadResult
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /containers/{id}/files/upload [post]
+func (s *ContainerHandler) UploadFilesHandler(e echo.Context) error {
+	disableReadTimeout(e)
+	form, err := e.MultipartForm()
+	if err != nil {
+		log.Warnf("ECHO: unable to parse multipart form due: %s", err)
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_PAYLOAD",
+			Message: "Unable to parse the upload payload",
+		})
+	}
+	extract, _ := strconv.ParseBool(e.QueryParam("extract"))
+
+	var files []service.UploadFile
+	for _, fh := range form.File["files"] {
+		files = append(files, service.UploadFile{Name: fh.Filename, Size: fh.Size, Open: fh.Open})
+	}
+
+	svc, err := s.containerService(e)
+	if err != nil {
+		return err
+	}
+
+	disableWriteTimeout(e)
+	result, err := svc.UploadFiles(e.Request().Context(), e.Param("id"), e.QueryParam("path"), files, extract)
+	if err != nil {
+		return filesErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+// @Summary Read a text file of a container
+// @Description Read a text file of up to 1MB inside a container. Works on stopped containers.
+// @Tags containers
+// @Produce json
+// @Param id path string true "Container ID"
+// @Param path query string true "Absolute path of the file"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 200 {object} models.FileContent
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /containers/{id}/files/content [get]
+func (s *ContainerHandler) ReadFileHandler(e echo.Context) error {
+	svc, err := s.containerService(e)
+	if err != nil {
+		return err
+	}
+
+	content, err := svc.ReadTextFile(e.Request().Context(), e.Param("id"), e.QueryParam("path"))
+	if err != nil {
+		return filesErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, content)
+}
+
+// @Summary Write a text file of a container
+// @Description Create or overwrite a text file of up to 1MB inside a container, keeping the mode and owner of an
+// @Description existing file. The parent directory must exist.
+// @Tags containers
+// @Accept json
+// @Produce json
+// @Param id path string true "Container ID"
+// @Param file body models.FileContent true "Path and content of the file"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 200 {object} models.FileContent
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /containers/{id}/files/content [put]
+func (s *ContainerHandler) WriteFileHandler(e echo.Context) error {
+	req := new(models.FileContent)
+	if err := e.Bind(req); err != nil {
+		log.Warnf("ECHO: unable to bind payload due: %s", err)
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_PAYLOAD",
+			Message: "Unable to parse the file payload",
+		})
+	}
+
+	svc, err := s.containerService(e)
+	if err != nil {
+		return err
+	}
+
+	content, err := svc.WriteTextFile(e.Request().Context(), e.Param("id"), req.Path, req.Content)
+	if err != nil {
+		return filesErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, content)
+}
+
+func filesErrorResponse(e echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrPathNotFound):
+		return e.JSON(http.StatusNotFound, models.ErrorResponse{Code: "PATH_NOT_FOUND", Message: err.Error()})
+	case errors.Is(err, service.ErrNotDirectory):
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{Code: "NOT_A_DIRECTORY", Message: err.Error()})
+	case errors.Is(err, service.ErrNotTextFile):
+		return e.JSON(http.StatusUnsupportedMediaType, models.ErrorResponse{Code: "NOT_TEXT_FILE", Message: err.Error()})
+	case errors.Is(err, service.ErrFileTooLarge):
+		return e.JSON(http.StatusRequestEntityTooLarge, models.ErrorResponse{Code: "FILE_TOO_LARGE", Message: err.Error()})
+	default:
+		return containerErrorResponse(e, err, nil)
+	}
+}
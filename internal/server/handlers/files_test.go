@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mineServers/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestFileHandlers_BrowseEditAndUpload(t *testing.T) {
+	h, engine := newTestHandler(t)
+	id := createTestContainer(t, engine, "mc", false)
+	engine.WriteFile(id, "/data/server.properties", []byte("motd=hello"), 0o644)
+
+	ctx, rec := newTestContext(http.MethodGet, "/containers/mc/files?path=/data", "", "id", "mc")
+	if err := h.ListFilesHandler(ctx); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("ListFilesHandler() = %d %s, %v", rec.Code, rec.Body, err)
+	}
+	var listing models.DirectoryListing
+	json.Unmarshal(rec.Body.Bytes(), &listing)
+	if len(listing.Entries) != 1 || listing.Entries[0].Name != "server.properties" {
+		t.Fatalf("listing = %+v", listing)
+	}
+
+	ctx, rec = newTestContext(http.MethodPut, "/containers/mc/files/content", `{"path":"/data/server.properties","content":"motd=bye"}`, "id", "mc")
+	if err := h.WriteFileHandler(ctx); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("WriteFileHandler() = %d %s, %v", rec.Code, rec.Body, err)
+	}
+	ctx, rec = newTestContext(http.MethodGet, "/containers/mc/files/content?path=/data/server.properties", "", "id", "mc")
+	if err := h.ReadFileHandler(ctx); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("ReadFileHandler() = %d %s, %v", rec.Code, rec.Body, err)
+	}
+	var content models.FileContent
+	json.Unmarshal(rec.Body.Bytes(), &content)
+	if content.Content != "motd=bye" {
+		t.Fatalf("content = %+v", content)
+	}
+
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	w, _ := mw.CreateFormFile("files", "ops.json")
+	w.Write([]byte("[]"))
+	mw.Close()
+	req := httptest.NewRequest(http.MethodPost, "/containers/mc/files/upload?path=/data", body)
+	req.Header.Set(echo.HeaderContentType, mw.FormDataContentType())
+	rec = httptest.NewRecorder()
+	ctx = echo.New().NewContext(req, rec)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("mc")
+	if err := h.UploadFilesHandler(ctx); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("UploadFilesHandler() = %d %s, %v", rec.Code, rec.Body, err)
+	}
+	if data, ok := engine.ReadFile(id, "/data/ops.json"); !ok || string(data) != "[]" {
+		t.Fatalf("ops.json = %q, %v", data, ok)
+	}
+
+	ctx, rec = newTestContext(http.MethodGet, "/containers/mc/files/download?path=/data&format=zip", "", "id", "mc")
+	if err := h.DownloadFilesHandler(ctx); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("DownloadFilesHandler() = %d %s, %v", rec.Code, rec.Body, err)
+	}
+	if got := rec.Header().Get(echo.HeaderContentDisposition); got != `attachment; filename="data.zip"` {
+		t.Fatalf("Content-Disposition = %s", got)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil || len(zr.File) != 3 {
+		t.Fatalf("zip = %v, %v", zr, err)
+	}
+
+	ctx, rec = newTestContext(http.MethodGet, "/containers/mc/files?path=/etc", "", "id", "mc")
+	h.ListFilesHandler(ctx)
+	if rec.Code != http.StatusNotFound || !bytes.Contains(rec.Body.Bytes(), []byte("PATH_NOT_FOUND")) {
+		t.Fatalf("ListFilesHandler(missing) = %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"mineServers/internal/database"
+	"mineServers/internal/models"
+	"mineServers/internal/service"
+	"net/http"
+	"sync"
+
+	"github.com/charmbracelet/log"
+	"github.com/labstack/echo/v4"
+)
+
+type HostHandler struct {
+	hosts *service.HostManager
+}
+
+func NewHostHandler(hosts *service.HostManager) *HostHandler {
+	return &HostHandler{
+		hosts: hosts,
+	}
+}
+
+// @Summary List Docker hosts
+// @Description List the registered Docker hosts, including the local one, with their connectivity status
+// @Tags hosts
+// @Produce json
+// @Success 200 {array} models.HostStatus
+// @Failure 500 {object} models.ErrorResponse
+// @Router /hosts [get]
+func (s *HostHandler) ListHostsHandler(e echo.Context) error {
+	ctx := e.Request().Context()
+	hosts, err := s.hosts.ListHosts(ctx)
+	if err != nil {
+		return hostErrorResponse(e, err)
+	}
+
+	// Ping every host concurrently so one unreachable host doesn't delay the others.
+	out := make([]models.HostStatus, len(hosts))
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out[i] = s.hosts.Status(ctx, host)
+		}()
+	}
+	wg.Wait()
+
+	return e.JSON(http.StatusOK, out)
+}
+
+// @Summary Get a Docker host
+// @Description Get a Docker host by name with its connectivity status
+// @Tags hosts
+// @Produce json
+// @Param name path string true "Host name"
+// @Success 200 {object} models.HostStatus
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /hosts/{name} [get]
+func (s *HostHandler) GetHostHandler(e echo.Context) error {
+	host, err := s.hosts.GetHost(e.Request().Context(), e.Param("name"))
+	if err != nil {
+		return hostErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, s.hosts.Status(e.Request().Context(), *host))
+}
+
+// @Summary Register a Docker host
+// @Description Register a Docker endpoint reachable through unix://, tcp:// (optionally with TLS certificates) or ssh://
+// @Tags hosts
+// @Accept json
+// @Produce json
+// @Param host body models.Host true "Host configuration"
+// @Success 201 {object} models.Host
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /hosts [post]
+func (s *HostHandler) CreateHostHandler(e echo.Context) error {
+	host := new(models.Host)
+	if err := e.Bind(host); err != nil {
+		log.Warnf("ECHO: unable to bind payload due: %s", err)
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_PAYLOAD",
+			Message: "Unable to parse the host payload",
+		})
+	}
+
+	if err := s.hosts.CreateHost(e.Request().Context(), host); err != nil {
+		return hostErrorResponse(e, err)
+	}
+
+	log.Infof("HOSTS: Host '%s' registered at %s", host.Name, host.URL)
+	return e.JSON(http.StatusCreated, host)
+}
+
+// @Summary Update a Docker host
+// @Description Replace the endpoint configuration of a registered Docker host
+// @Tags hosts
+// @Accept json
+// @Produce json
+// @Param name path string true "Host name"
+// @Param host body models.Host true "Host configuration"
+// @Success 200 {object} models.Host
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /hosts/{name} [put]
+func (s *HostHandler) UpdateHostHandler(e echo.Context) error {
+	host := new(models.Host)
+	if err := e.Bind(host); err != nil {
+		log.Warnf("ECHO: unable to bind payload due: %s", err)
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_PAYLOAD",
+			Message: "Unable to parse the host payload",
+		})
+	}
+	host.Name = e.Param("name")
+
+	if err := s.hosts.UpdateHost(e.Request().Context(), host); err != nil {
+		return hostErrorResponse(e, err)
+	}
+
+	log.Infof("HOSTS: Host '%s' updated to %s", host.Name, host.URL)
+	return e.JSON(http.StatusOK, host)
+}
+
+// @Summary Delete a Docker host
+// @Description Unregister a Docker host. Its containers are left untouched.
+// @Tags hosts
+// @Produce json
+// @Param name path string true "Host name"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /hosts/{name} [delete]
+func (s *HostHandler) DeleteHostHandler(e echo.Context) error {
+	name := e.Param("name")
+	if err := s.hosts.DeleteHost(e.Request().Context(), name); err != nil {
+		return hostErrorResponse(e, err)
+	}
+
+	log.Infof("HOSTS: Host '%s' deleted", name)
+	return e.JSON(http.StatusOK, models.SuccessResponse{
+		Message: fmt.Sprintf("deleted host %s", name),
+	})
+}
+
+func hostErrorResponse(e echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidHost):
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{Code: "INVALID_HOST", Message: err.Error()})
+	case errors.Is(err, service.ErrHostNotFound):
+		return e.JSON(http.StatusNotFound, hostNotFoundResponse)
+	case errors.Is(err, service.ErrHostReadOnly):
+		return e.JSON(http.StatusForbidden, models.ErrorResponse{Code: "HOST_READ_ONLY", Message: err.Error()})
+	case errors.Is(err, database.ErrConflict):
+		return e.JSON(http.StatusConflict, models.ErrorResponse{Code: "HOST_ALREADY_EXISTS", Message: "A host with this name already exists"})
+	default:
+		log.Warnf("HOSTS: Unable to handle host request due: %s", err)
+		return e.JSON(http.StatusInternalServerError, models.ErrorResponse{Code: "INTERNAL_ERROR", Message: "internal server error"})
+	}
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"mineServers/internal/fakedocker"
+	"mineServers/internal/models"
+	"mineServers/internal/service"
+)
+
+// newTestHosts returns a HostManager backed by a temporary database where
+// every registered host is served by its own fake engine.
+func newTestHosts(t *testing.T) (*service.HostManager, *fakedocker.Engine, map[string]*fakedocker.Engine) {
+	t.Helper()
+
+	db := openTestDB(t)
+
+	local := fakedocker.New()
+	remotes := map[string]*fakedocker.Engine{}
+	dial := func(host models.Host) (service.DockerAPI, error) {
+		engine, ok := remotes[host.Name]
+		if !ok {
+			engine = fakedocker.New()
+			remotes[host.Name] = engine
+		}
+		return engine, nil
+	}
+
+	svc := service.NewContainerService(context.Background(), local)
+	return service.NewHostManager(context.Background(), svc, db, dial), local, remotes
+}
+
+func TestHostHandlers_CRUD(t *testing.T) {
+	hosts, _, _ := newTestHosts(t)
+	handler := NewHostHandler(hosts)
+
+	ctx, rec := newTestContext(http.MethodPost, "/hosts", `{"name":"build","url":"tcp://10.0.0.2:2376"}`)
+	if err := handler.CreateHostHandler(ctx); err != nil {
+		t.Fatalf("CreateHostHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	ctx, rec = newTestContext(http.MethodPost, "/hosts", `{"name":"build","url":"tcp://10.0.0.2:2376"}`)
+	handler.CreateHostHandler(ctx)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected status 409 for a duplicated host, got %d", rec.Code)
+	}
+
+	ctx, rec = newTestContext(http.MethodPut, "/hosts/build", `{"url":"ssh://deploy@build.lan"}`, "name", "build")
+	handler.UpdateHostHandler(ctx)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	ctx, rec = newTestContext(http.MethodGet, "/hosts", "")
+	handler.ListHostsHandler(ctx)
+
+	var statuses []models.HostStatus
+	if err := json.NewDecoder(rec.Body).Decode(&statuses); err != nil {
+		t.Fatalf("unable to decode response: %s", err)
+	}
+	if len(statuses) != 2 || statuses[0].Name != service.LocalHost || statuses[1].URL != "ssh://deploy@build.lan" {
+		t.Fatalf("unexpected hosts: %+v", statuses)
+	}
+	for _, status := range statuses {
+		if status.Status != "up" {
+			t.Errorf("expected host %s to be up, got %+v", status.Name, status)
+		}
+	}
+
+	ctx, rec = newTestContext(http.MethodDelete, "/hosts/local", "", "name", "local")
+	handler.DeleteHostHandler(ctx)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected status 403 deleting the local host, got %d", rec.Code)
+	}
+
+	ctx, rec = newTestContext(http.MethodDelete, "/hosts/build", "", "name", "build")
+	handler.DeleteHostHandler(ctx)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+}
+
+func TestHostHandlers_RejectsInvalidEndpoints(t *testing.T) {
+	hosts, _, _ := newTestHosts(t)
+	handler := NewHostHandler(hosts)
+
+	payloads := []string{
+		`{"name":"","url":"tcp://10.0.0.2:2376"}`,
+		`{"name":"local","url":"tcp://10.0.0.2:2376"}`,
+		`{"name":"web","url":"http://10.0.0.2:2376"}`,
+		`{"name":"web","url":"ssh://build.lan","tls_ca_cert":"/certs/ca.pem"}`,
+		`{"name":"web","url":"tcp://10.0.0.2:2376","tls_cert":"/certs/cert.pem"}`,
+	}
+	for _, payload := range payloads {
+		ctx, rec := newTestContext(http.MethodPost, "/hosts", payload)
+		handler.CreateHostHandler(ctx)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", payload, rec.Code)
+		}
+	}
+}
+
+func TestHostHandlers_ReportsUnreachableHost(t *testing.T) {
+	hosts, _, remotes := newTestHosts(t)
+	handler := NewHostHandler(hosts)
+
+	if err := hosts.CreateHost(context.Background(), &models.Host{Name: "offline", URL: "tcp://10.0.0.9:2376"}); err != nil {
+		t.Fatalf("unable to create host: %s", err)
+	}
+	if _, err := hosts.Resolve(context.Background(), "offline"); err != nil {
+		t.Fatalf("unable to resolve host: %s", err)
+	}
+	remotes["offline"].FailOn("Ping", errors.New("connection refused"))
+
+	ctx, rec := newTestContext(http.MethodGet, "/hosts/offline", "", "name", "offline")
+	handler.GetHostHandler(ctx)
+
+	var status models.HostStatus
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+		t.Fatalf("unable to decode response: %s", err)
+	}
+	if status.Status != "down" || status.Error != "connection refused" {
+		t.Errorf("expected host to be down, got %+v", status)
+	}
+}
+
+func TestContainerHandlers_SelectHost(t *testing.T) {
+	hosts, local, remotes := newTestHosts(t)
+	handler := NewContainerHandler(hosts, service.NewPullManager(context.Background(), nil))
+
+	if err := hosts.CreateHost(context.Background(), &models.Host{Name: "remote", URL: "tcp://10.0.0.2:2376"}); err != nil {
+		t.Fatalf("unable to create host: %s", err)
+	}
+
+	ctx, rec := newTestContext(http.MethodPost, "/containers?host=remote", `{"name":"web","image":"nginx"}`)
+	if err := handler.CreateContainerHandler(ctx); err != nil {
+		t.Fatalf("CreateContainerHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", rec.Code)
+	}
+
+	if _, ok := remotes["remote"].Container("web"); !ok {
+		t.Errorf("expected container to be created on the remote host")
+	}
+	if local.ContainerCount() != 0 {
+		t.Errorf("expected no container on the local host")
+	}
+
+	ctx, rec = newTestContext(http.MethodGet, "/containers?host=unknown", "")
+	handler.ListContainersHandler(ctx)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown host, got %d", rec.Code)
+	}
+}
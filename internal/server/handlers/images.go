@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mineServers/internal/models"
+	"mineServers/internal/service"
+	"net/http"
+
+	"github.com/charmbracelet/log"
+	"github.com/docker/docker/api/types/image"
+	"github.com/labstack/echo/v4"
+)
+
+var pullJobNotFoundResponse = models.ErrorResponse{
+	Code:    "PULL_JOB_NOT_FOUND",
+	Message: "No pull job with this ID",
+}
+
+type ImageHandler struct {
+	hosts *service.HostManager
+	pulls *service.PullManager
+}
+
+func NewImageHandler(hosts *service.HostManager, pulls *service.PullManager) *ImageHandler {
+	return &ImageHandler{
+		hosts: hosts,
+		pulls: pulls,
+	}
+}
+
+// @Summary Pull an image
+// @Description Start pulling an image in the background, given as a full reference or through its parts, optionally for another platform.
+// @Description Follow the returned job through its events endpoint.
+// @Tags images
+// @Accept json
+// @Produce json
+// @Param pull body models.PullRequest true "Image to pull"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 202 {object} models.PullJob
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /images/pulls [post]
+func (s *ImageHandler) PullImageHandler(e echo.Context) error {
+	req := new(models.PullRequest)
+	if err := e.Bind(req); err != nil {
+		log.Warnf("ECHO: unable to bind payload due: %s", err)
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_PAYLOAD",
+			Message: "Unable to parse the pull payload",
+		})
+	}
+
+	imageName, err := service.ImageReference(req.Reference, req.Registry, req.Image, req.Version)
+	if err != nil {
+		field := "image"
+		if req.Reference != "" {
+			field = "reference"
+		}
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_OPTIONS",
+			Message: "Invalid pull options",
+			Details: []models.FieldError{{Field: field, Message: err.Error()}},
+		})
+	}
+	if _, err := service.ParsePlatform(req.Platform); err != nil {
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_OPTIONS",
+			Message: "Invalid pull options",
+			Details: []models.FieldError{{Field: "platform", Message: err.Error()}},
+		})
+	}
+
+	svc, err := resolveService(e, s.hosts)
+	if err != nil {
+		return err
+	}
+
+	job := s.pulls.Start(svc, hostName(e), imageName, service.PullAlways, image.PullOptions{Platform: req.Platform}, nil)
+
+	return e.JSON(http.StatusAccepted, job)
+}
+
+// @Summary Import an image
+// @Description Create an image from an uploaded tarball of a filesystem, such as the export of a container, optionally
+// @Description tagged and with Dockerfile changes applied to its config.
+// @Tags images
+// @Accept mpfd
+// @Produce json
+// @Param file formData file true "Tarball of the filesystem, optionally gzip compressed"
+// @Param reference formData string false "Reference of the image"
+// @Param message formData string false "Commit message of the image"
+// @Param changes formData []string false "Dockerfile instructions such as ENV or CMD" collectionFormat(multi)
+// @Param platform formData string false "Platform of the image, as os/arch[/variant]"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 201 {object} models.ImageSnapshot
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /images/import [post]
+func (s *ImageHandler) ImportImageHandler(e echo.Context) error {
+	disableReadTimeout(e)
+	req := new(models.ImportRequest)
+	if err := e.Bind(req); err != nil {
+		log.Warnf("ECHO: unable to bind payload due: %s", err)
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_PAYLOAD",
+			Message: "Unable to parse the import payload",
+		})
+	}
+	fh, err := e.FormFile("file")
+	if err != nil {
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_PAYLOAD",
+			Message: "A tarball is required in the file field",
+		})
+	}
+
+	svc, err := resolveService(e, s.hosts)
+	if err != nil {
+		return err
+	}
+
+	src, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	disableWriteTimeout(e)
+	snapshot, err := svc.ImportImage(e.Request().Context(), src, req)
+	if err != nil {
+		return containerErrorResponse(e, err, nil)
+	}
+
+	return e.JSON(http.StatusCreated, snapshot)
+}
+
+// @Summary Get a pull job
+// @Description Get the current progress of an image pull job
+// @Tags images
+// @Produce json
+// @Param id path string true "Pull job ID"
+// @Success 200 {object} models.PullJob
+// @Failure 404 {object} models.ErrorResponse
+// @Router /images/pulls/{id} [get]
+func (s *ImageHandler) GetPullJobHandler(e echo.Context) error {
+	job, err := s.pulls.Get(e.Param("id"))
+	if err != nil {
+		return e.JSON(http.StatusNotFound, pullJobNotFoundResponse)
+	}
+
+	return e.JSON(http.StatusOK, job)
+}
+
+// @Summary Stream pull job progress
+// @Description Server-Sent Events with the state of the pull job on every progress update. The stream ends with the final state of the job.
+// @Tags images
+// @Produce text/event-stream
+// @Param id path string true "Pull job ID"
+// @Success 200 {object} models.PullJob
+// @Failure 404 {object} models.ErrorResponse
+// @Router /images/pulls/{id}/events [get]
+func (s *ImageHandler) StreamPullJobHandler(e echo.Context) error {
+	id := e.Param("id")
+	updates, unsubscribe, err := s.pulls.Subscribe(id)
+	if err != nil {
+		if errors.Is(err, service.ErrPullJobNotFound) {
+			return e.JSON(http.StatusNotFound, pullJobNotFoundResponse)
+		}
+		return err
+	}
+	defer unsubscribe()
+
+	disableWriteTimeout(e)
+	e.Response().Header().Set(echo.HeaderContentType, "text/event-stream")
+	e.Response().Header().Set("Cache-Control", "no-cache")
+	e.Response().Header().Set("Connection", "keep-alive")
+	e.Response().WriteHeader(http.StatusOK)
+
+	// Send the current state first so late subscribers don't wait for the next update.
+	job, err := s.pulls.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := writePullEvent(e, "progress", job); err != nil {
+		return nil
+	}
+
+	ctx := e.Request().Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case job, ok := <-updates:
+			if !ok {
+				final, err := s.pulls.Get(id)
+				if err != nil {
+					return nil
+				}
+				writePullEvent(e, final.Status, final)
+				return nil
+			}
+			if err := writePullEvent(e, "progress", job); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+func writePullEvent(e echo.Context, event string, job models.PullJob) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(e.Response(), "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	e.Response().Flush()
+
+	return nil
+}
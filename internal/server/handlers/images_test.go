@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mineServers/internal/fakedocker"
+	"mineServers/internal/models"
+	"mineServers/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+func newTestImageHandler(t *testing.T) (*ImageHandler, *service.PullManager, *fakedocker.Engine) {
+	t.Helper()
+
+	hosts, engine := newTestHostManager(t)
+	pulls := service.NewPullManager(context.Background(), nil)
+	return NewImageHandler(hosts, pulls), pulls, engine
+}
+
+func waitForJob(t *testing.T, pulls *service.PullManager, id string) models.PullJob {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	job, err := pulls.Wait(ctx, id)
+	if err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	return job
+}
+
+func TestPullImageHandler_TracksProgress(t *testing.T) {
+	handler, pulls, engine := newTestImageHandler(t)
+	ctx, rec := newTestContext(http.MethodPost, "/images/pulls", `{"image":"redis","version":"7"}`)
+
+	if err := handler.PullImageHandler(ctx); err != nil {
+		t.Fatalf("PullImageHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var job models.PullJob
+	if err := json.Unmarshal(rec.Body.Bytes(), &job); err != nil {
+		t.Fatalf("unable to decode job: %s", err)
+	}
+	if job.ID == "" || job.Image != "docker.io/library/redis:7" || job.Host != service.LocalHost {
+		t.Fatalf("unexpected job %+v", job)
+	}
+
+	job = waitForJob(t, pulls, job.ID)
+	if job.Status != service.PullCompleted || job.Percent != 100 {
+		t.Fatalf("expected completed job, got %+v", job)
+	}
+	if len(job.Layers) != 2 || job.Total != 2048+4096 {
+		t.Errorf("expected 2 layers totalling 6144 bytes, got %d layers of %d bytes", len(job.Layers), job.Total)
+	}
+	if !engine.HasImage("docker.io/library/redis:7") {
+		t.Errorf("expected image docker.io/library/redis:7 to be pulled")
+	}
+}
+
+func TestPullImageHandler_RequiresImage(t *testing.T) {
+	handler, _, _ := newTestImageHandler(t)
+	ctx, rec := newTestContext(http.MethodPost, "/images/pulls", `{"version":"7"}`)
+
+	if err := handler.PullImageHandler(ctx); err != nil {
+		t.Fatalf("PullImageHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestStreamPullJobHandler_EndsWithFinalState(t *testing.T) {
+	handler, pulls, _ := newTestImageHandler(t)
+	ctx, rec := newTestContext(http.MethodPost, "/images/pulls", `{"image":"redis"}`)
+	if err := handler.PullImageHandler(ctx); err != nil {
+		t.Fatalf("PullImageHandler() error = %v", err)
+	}
+	var job models.PullJob
+	json.Unmarshal(rec.Body.Bytes(), &job)
+
+	ctx, rec = newTestContext(http.MethodGet, "/images/pulls/"+job.ID+"/events", "", "id", job.ID)
+	if err := handler.StreamPullJobHandler(ctx); err != nil {
+		t.Fatalf("StreamPullJobHandler() error = %v", err)
+	}
+
+	waitForJob(t, pulls, job.ID)
+	body := rec.Body.String()
+	if !strings.Contains(body, "event: completed\n") {
+		t.Errorf("expected the stream to end with a completed event, got %q", body)
+	}
+}
+
+func TestGetPullJobHandler_UnknownJob(t *testing.T) {
+	handler, _, _ := newTestImageHandler(t)
+	ctx, rec := newTestContext(http.MethodGet, "/images/pulls/nope", "", "id", "nope")
+
+	if err := handler.GetPullJobHandler(ctx); err != nil {
+		t.Fatalf("GetPullJobHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestCreateContainerHandler_AsyncChainsOnPull(t *testing.T) {
+	handler, engine := newTestHandler(t)
+	ctx, rec := newTestContext(http.MethodPost, "/containers?async=true", `{"name":"cache","image":"redis"}`)
+
+	if err := handler.CreateContainerHandler(ctx); err != nil {
+		t.Fatalf("CreateContainerHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var job models.PullJob
+	if err := json.Unmarshal(rec.Body.Bytes(), &job); err != nil {
+		t.Fatalf("unable to decode job: %s", err)
+	}
+
+	job = waitForJob(t, handler.pulls, job.ID)
+	if job.Status != service.PullCompleted || job.ContainerID == "" || job.ContainerName != "cache" {
+		t.Fatalf("expected completed job with container 'cache', got %+v", job)
+	}
+	if c, ok := engine.Container("cache"); !ok || c.ID != job.ContainerID {
+		t.Errorf("expected container 'cache' with ID %s", job.ContainerID)
+	}
+}
+
+func TestCreateContainerHandler_PullPolicies(t *testing.T) {
+	handler, engine := newTestHandler(t)
+	engine.AddImage("docker.io/library/redis:7")
+
+	create := func(name, body string) int {
+		ctx, rec := newTestContext(http.MethodPost, "/containers", body)
+		handler.CreateContainerHandler(ctx)
+		return rec.Code
+	}
+
+	if code := create("present", `{"name":"present","image":"redis:7"}`); code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", code)
+	}
+	if engine.Pulls() != 0 {
+		t.Errorf("expected if-not-present to skip the pull of a present image, got %d pulls", engine.Pulls())
+	}
+
+	if code := create("always", `{"name":"always","image":"redis","version":"7","pull_policy":"always"}`); code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", code)
+	}
+	if engine.Pulls() != 1 {
+		t.Errorf("expected always to pull, got %d pulls", engine.Pulls())
+	}
+
+	if code := create("never", `{"name":"never","image":"redis:8","pull_policy":"never"}`); code != http.StatusInternalServerError {
+		t.Errorf("expected never to fail on a missing image, got %d", code)
+	}
+	if _, ok := engine.Container("never"); ok || engine.Pulls() != 1 {
+		t.Errorf("expected never to neither pull nor create")
+	}
+
+	if code := create("invalid", `{"name":"invalid","image":"redis","pull_policy":"sometimes"}`); code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an unknown policy, got %d", code)
+	}
+}
+
+func TestCreateContainerHandler_SelectsPlatform(t *testing.T) {
+	handler, engine := newTestHandler(t)
+	engine.AddImage("docker.io/library/redis:7")
+
+	ctx, rec := newTestContext(http.MethodPost, "/containers", `{"name":"arm","reference":"redis:7","platform":"linux/arm64"}`)
+	if err := handler.CreateContainerHandler(ctx); err != nil {
+		t.Fatalf("CreateContainerHandler() error = %v: %s", err, rec.Body.String())
+	}
+
+	// The amd64 image present on the host doesn't satisfy the platform.
+	if engine.Pulls() != 1 {
+		t.Errorf("expected the arm64 variant to be pulled, got %d pulls", engine.Pulls())
+	}
+	if _, ok := engine.Container("arm"); !ok {
+		t.Errorf("expected container 'arm' to exist")
+	}
+}
+
+func TestImportImageHandler_CreatesImageFromTarball(t *testing.T) {
+	handler, _, engine := newTestImageHandler(t)
+	id := createTestContainer(t, engine, "mc", false)
+	engine.WriteFile(id, "/data/ops.json", []byte("[]"), 0o644)
+	rc, err := engine.ContainerExport(context.Background(), id)
+	if err != nil {
+		t.Fatalf("ContainerExport() error = %v", err)
+	}
+	archive, _ := io.ReadAll(rc)
+
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	mw.WriteField("reference", "mc:imported")
+	mw.WriteField("changes", "ENV MODE=restored")
+	mw.WriteField("changes", "WORKDIR /data")
+	w, _ := mw.CreateFormFile("file", "mc.tar")
+	w.Write(archive)
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/images/import", body)
+	req.Header.Set(echo.HeaderContentType, mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	if err := handler.ImportImageHandler(echo.New().NewContext(req, rec)); err != nil || rec.Code != http.StatusCreated {
+		t.Fatalf("ImportImageHandler() = %d %s, %v", rec.Code, rec.Body, err)
+	}
+
+	var snapshot models.ImageSnapshot
+	json.Unmarshal(rec.Body.Bytes(), &snapshot)
+	img, ok := engine.Image("docker.io/library/mc:imported")
+	if !ok || img.ID != snapshot.ID || len(img.Changes) != 2 {
+		t.Fatalf("image = %+v, %v, snapshot = %+v", img, ok, snapshot)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/images/import", strings.NewReader(""))
+	req.Header.Set(echo.HeaderContentType, mw.FormDataContentType())
+	rec = httptest.NewRecorder()
+	handler.ImportImageHandler(echo.New().NewContext(req, rec))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("ImportImageHandler(no file) = %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"errors"
+	"mineServers/internal/models"
+	"mineServers/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/charmbracelet/log"
+	"github.com/labstack/echo/v4"
+)
+
+var projectNotFoundResponse = models.ErrorResponse{
+	Code:    "PROJECT_NOT_FOUND",
+	Message: "No container belongs to this compose project",
+}
+
+type ProjectHandler struct {
+	hosts *service.HostManager
+}
+
+func NewProjectHandler(hosts *service.HostManager) *ProjectHandler {
+	return &ProjectHandler{
+		hosts: hosts,
+	}
+}
+
+// @Summary List compose projects
+// @Description Group the containers labelled by docker compose, stacks included, by project with their aggregate state: running, partial or stopped
+// @Tags projects
+// @Produce json
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 200 {array} models.Project
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /projects [get]
+func (s *ProjectHandler) ListProjectsHandler(e echo.Context) error {
+	svc, err := resolveService(e, s.hosts)
+	if err != nil {
+		return err
+	}
+
+	projects, err := svc.ListProjects(e.Request().Context())
+	if err != nil {
+		return projectErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, projects)
+}
+
+// @Summary Get a compose project
+// @Description Get the containers of a compose project in dependency order
+// @Tags projects
+// @Produce json
+// @Param name path string true "Project name"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 200 {object} models.Project
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /projects/{name} [get]
+func (s *ProjectHandler) GetProjectHandler(e echo.Context) error {
+	svc, err := resolveService(e, s.hosts)
+	if err != nil {
+		return err
+	}
+
+	project, err := svc.GetProject(e.Request().Context(), e.Param("name"))
+	if err != nil {
+		return projectErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, project)
+}
+
+// @Summary Start a compose project
+// @Description Start the stopped containers of a project, the services they depend on first
+// @Tags projects
+// @Produce json
+// @Param name path string true "Project name"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 200 {object} models.Project
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /projects/{name}/start [post]
+func (s *ProjectHandler) StartProjectHandler(e echo.Context) error {
+	svc, err := resolveService(e, s.hosts)
+	if err != nil {
+		return err
+	}
+
+	project, err := svc.StartProject(e.Request().Context(), e.Param("name"))
+	if err != nil {
+		return projectErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, project)
+}
+
+// @Summary Stop a compose project
+// @Description Stop the running containers of a project in reverse dependency order
+// @Tags projects
+// @Produce json
+// @Param name path string true "Project name"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 200 {object} models.Project
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /projects/{name}/stop [post]
+func (s *ProjectHandler) StopProjectHandler(e echo.Context) error {
+	svc, err := resolveService(e, s.hosts)
+	if err != nil {
+		return err
+	}
+
+	project, err := svc.StopProject(e.Request().Context(), e.Param("name"))
+	if err != nil {
+		return projectErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, project)
+}
+
+// @Summary Restart a compose project
+// @Description Restart the containers of a project in dependency order
+// @Tags projects
+// @Produce json
+// @Param name path string true "Project name"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 200 {object} models.Project
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /projects/{name}/restart [post]
+func (s *ProjectHandler) RestartProjectHandler(e echo.Context) error {
+	svc, err := resolveService(e, s.hosts)
+	if err != nil {
+		return err
+	}
+
+	project, err := svc.RestartProject(e.Request().Context(), e.Param("name"))
+	if err != nil {
+		return projectErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, project)
+}
+
+// @Summary Remove a compose project
+// @Description Stop and remove the containers of a project in reverse dependency order, then its networks, like docker compose down.
+// @Description Stacks deployed from this API are still stored and come back on their next up.
+// @Tags projects
+// @Produce json
+// @Param name path string true "Project name"
+// @Param volumes query bool false "Also remove the volumes of the project"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 204
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /projects/{name} [delete]
+func (s *ProjectHandler) RemoveProjectHandler(e echo.Context) error {
+	svc, err := resolveService(e, s.hosts)
+	if err != nil {
+		return err
+	}
+
+	volumes, _ := strconv.ParseBool(e.QueryParam("volumes"))
+	if err := svc.RemoveProject(e.Request().Context(), e.Param("name"), volumes); err != nil {
+		return projectErrorResponse(e, err)
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+func projectErrorResponse(e echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrProjectNotFound):
+		return e.JSON(http.StatusNotFound, projectNotFoundResponse)
+	default:
+		// Project errors name the failing service, which the user needs.
+		log.Warnf("PROJECTS: Unable to handle project request due: %s", err)
+		return e.JSON(http.StatusInternalServerError, models.ErrorResponse{Code: "PROJECT_OPERATION_FAILED", Message: err.Error()})
+	}
+}
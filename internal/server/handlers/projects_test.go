@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"mineServers/internal/fakedocker"
+	"mineServers/internal/models"
+	"mineServers/internal/service"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+func newTestProjectHandler(t *testing.T) (*ProjectHandler, *fakedocker.Engine) {
+	t.Helper()
+
+	hosts, engine := newTestHostManager(t)
+	return NewProjectHandler(hosts), engine
+}
+
+func TestProjectHandlers_Lifecycle(t *testing.T) {
+	handler, engine := newTestProjectHandler(t)
+	engine.AddImage("nginx")
+	for _, name := range []string{"web", "db"} {
+		labels := map[string]string{
+			service.LabelProject: "blog", service.LabelService: name, service.LabelContainerNumber: "1",
+		}
+		if name == "web" {
+			labels[service.LabelDependsOn] = "db:service_started:false"
+		}
+		engine.ContainerCreate(context.Background(), &container.Config{Image: "nginx", Labels: labels}, &container.HostConfig{}, nil, nil, "blog-"+name+"-1")
+	}
+
+	ctx, rec := newTestContext(http.MethodPost, "/projects/blog/start", "", "name", "blog")
+	if err := handler.StartProjectHandler(ctx); err != nil {
+		t.Fatalf("StartProjectHandler() error = %v", err)
+	}
+	var project models.Project
+	json.Unmarshal(rec.Body.Bytes(), &project)
+	if rec.Code != http.StatusOK || project.State != service.ProjectRunning || project.Containers[0].Name != "blog-db-1" {
+		t.Fatalf("expected the project to run db first, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	engine.ContainerStop(context.Background(), "blog-web-1", container.StopOptions{})
+	ctx, rec = newTestContext(http.MethodGet, "/projects", "")
+	if err := handler.ListProjectsHandler(ctx); err != nil {
+		t.Fatalf("ListProjectsHandler() error = %v", err)
+	}
+	var projects []models.Project
+	json.Unmarshal(rec.Body.Bytes(), &projects)
+	if len(projects) != 1 || projects[0].State != service.ProjectPartial {
+		t.Errorf("expected a partial project, got %s", rec.Body.String())
+	}
+
+	ctx, rec = newTestContext(http.MethodDelete, "/projects/blog", "", "name", "blog")
+	if err := handler.RemoveProjectHandler(ctx); err != nil {
+		t.Fatalf("RemoveProjectHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusNoContent || engine.ContainerCount() != 0 {
+		t.Errorf("expected the project to be removed, got %d", rec.Code)
+	}
+
+	ctx, rec = newTestContext(http.MethodGet, "/projects/blog", "", "name", "blog")
+	handler.GetProjectHandler(ctx)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown project, got %d", rec.Code)
+	}
+}
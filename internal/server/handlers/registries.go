@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"mineServers/internal/database"
+	"mineServers/internal/models"
+	"mineServers/internal/service"
+	"net/http"
+
+	"github.com/charmbracelet/log"
+	"github.com/labstack/echo/v4"
+)
+
+type RegistryHandler struct {
+	hosts      *service.HostManager
+	registries *service.RegistryManager
+}
+
+func NewRegistryHandler(hosts *service.HostManager, registries *service.RegistryManager) *RegistryHandler {
+	return &RegistryHandler{
+		hosts:      hosts,
+		registries: registries,
+	}
+}
+
+// @Summary List registries
+// @Description List the private registries with stored credentials. Secrets are never returned.
+// @Tags registries
+// @Produce json
+// @Success 200 {array} models.Registry
+// @Failure 500 {object} models.ErrorResponse
+// @Router /registries [get]
+func (s *RegistryHandler) ListRegistriesHandler(e echo.Context) error {
+	registries, err := s.registries.ListRegistries(e.Request().Context())
+	if err != nil {
+		return registryErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, registries)
+}
+
+// @Summary Get a registry
+// @Description Get the stored credentials of a registry, without its secrets
+// @Tags registries
+// @Produce json
+// @Param server path string true "Registry hostname, e.g. ghcr.io or localhost:5000"
+// @Success 200 {object} models.Registry
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /registries/{server} [get]
+func (s *RegistryHandler) GetRegistryHandler(e echo.Context) error {
+	registry, err := s.registries.GetRegistry(e.Request().Context(), e.Param("server"))
+	if err != nil {
+		return registryErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, registry)
+}
+
+// @Summary Add registry credentials
+// @Description Store the credentials of a private registry. They are used by every pull of an image hosted on it.
+// @Tags registries
+// @Accept json
+// @Produce json
+// @Param registry body models.Registry true "Registry credentials"
+// @Success 201 {object} models.Registry
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /registries [post]
+func (s *RegistryHandler) CreateRegistryHandler(e echo.Context) error {
+	registry := new(models.Registry)
+	if err := e.Bind(registry); err != nil {
+		log.Warnf("ECHO: unable to bind payload due: %s", err)
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_PAYLOAD",
+			Message: "Unable to parse the registry payload",
+		})
+	}
+
+	if err := s.registries.CreateRegistry(e.Request().Context(), registry); err != nil {
+		return registryErrorResponse(e, err)
+	}
+
+	log.Infof("REGISTRIES: Credentials for '%s' stored", registry.Server)
+	return e.JSON(http.StatusCreated, registry)
+}
+
+// @Summary Update registry credentials
+// @Description Replace the credentials of a registry. Omitting both password and identity_token keeps the stored secret.
+// @Tags registries
+// @Accept json
+// @Produce json
+// @Param server path string true "Registry hostname"
+// @Param registry body models.Registry true "Registry credentials"
+// @Success 200 {object} models.Registry
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /registries/{server} [put]
+func (s *RegistryHandler) UpdateRegistryHandler(e echo.Context) error {
+	registry := new(models.Registry)
+	if err := e.Bind(registry); err != nil {
+		log.Warnf("ECHO: unable to bind payload due: %s", err)
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_PAYLOAD",
+			Message: "Unable to parse the registry payload",
+		})
+	}
+	registry.Server = e.Param("server")
+
+	if err := s.registries.UpdateRegistry(e.Request().Context(), registry); err != nil {
+		return registryErrorResponse(e, err)
+	}
+
+	log.Infof("REGISTRIES: Credentials for '%s' updated", registry.Server)
+	return e.JSON(http.StatusOK, registry)
+}
+
+// @Summary Delete registry credentials
+// @Description Delete the stored credentials of a registry. Its images are pulled anonymously afterwards.
+// @Tags registries
+// @Produce json
+// @Param server path string true "Registry hostname"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /registries/{server} [delete]
+func (s *RegistryHandler) DeleteRegistryHandler(e echo.Context) error {
+	server := e.Param("server")
+	if err := s.registries.DeleteRegistry(e.Request().Context(), server); err != nil {
+		return registryErrorResponse(e, err)
+	}
+
+	log.Infof("REGISTRIES: Credentials for '%s' deleted", server)
+	return e.JSON(http.StatusOK, models.SuccessResponse{
+		Message: fmt.Sprintf("deleted credentials of %s", server),
+	})
+}
+
+// @Summary Test registry login
+// @Description Log into the registry with the stored credentials through the daemon of the selected host.
+// @Description Plain HTTP registries such as a local registry:2 must be listed in the daemon insecure-registries.
+// @Tags registries
+// @Produce json
+// @Param server path string true "Registry hostname"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 200 {object} models.RegistryLoginResult
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /registries/{server}/login [post]
+func (s *RegistryHandler) LoginRegistryHandler(e echo.Context) error {
+	svc, err := resolveService(e, s.hosts)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.registries.Login(e.Request().Context(), svc, e.Param("server"))
+	if err != nil {
+		return registryErrorResponse(e, err)
+	}
+	result.Host = hostName(e)
+
+	return e.JSON(http.StatusOK, result)
+}
+
+func registryErrorResponse(e echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidRegistry):
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{Code: "INVALID_REGISTRY", Message: err.Error()})
+	case errors.Is(err, service.ErrRegistryNotFound):
+		return e.JSON(http.StatusNotFound, models.ErrorResponse{Code: "REGISTRY_NOT_FOUND", Message: "No credentials stored for this registry"})
+	case errors.Is(err, database.ErrConflict):
+		return e.JSON(http.StatusConflict, models.ErrorResponse{Code: "REGISTRY_ALREADY_EXISTS", Message: "Credentials for this registry already exist"})
+	default:
+		log.Warnf("REGISTRIES: Unable to handle registry request due: %s", err)
+		return e.JSON(http.StatusInternalServerError, models.ErrorResponse{Code: "INTERNAL_ERROR", Message: "internal server error"})
+	}
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"mineServers/internal/fakedocker"
+	"mineServers/internal/models"
+	"mineServers/internal/service"
+)
+
+func newTestRegistryHandler(t *testing.T) (*RegistryHandler, *ImageHandler, *service.PullManager, *fakedocker.Engine) {
+	t.Helper()
+
+	db := openTestDB(t)
+
+	box, err := service.NewSecretBox(make([]byte, 32))
+	if err != nil {
+		t.Fatalf("unable to create secret box: %s", err)
+	}
+	registries := service.NewRegistryManager(db, box)
+
+	hosts, engine := newTestHostManager(t)
+	// Stand-in for a registry:2 container protected with htpasswd.
+	engine.AddRegistry("localhost:5000", "admin", "s3cret")
+	pulls := service.NewPullManager(context.Background(), registries.AuthFor)
+
+	return NewRegistryHandler(hosts, registries), NewImageHandler(hosts, pulls), pulls, engine
+}
+
+func TestRegistryHandlers_CRUDNeverReturnsSecrets(t *testing.T) {
+	handler, _, _, _ := newTestRegistryHandler(t)
+
+	ctx, rec := newTestContext(http.MethodPost, "/registries", `{"server":"ghcr.io","username":"octocat","password":"ghp_token"}`)
+	if err := handler.CreateRegistryHandler(ctx); err != nil {
+		t.Fatalf("CreateRegistryHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if strings.Contains(rec.Body.String(), "ghp_token") {
+		t.Errorf("expected the password to be omitted, got %s", rec.Body.String())
+	}
+
+	ctx, rec = newTestContext(http.MethodPost, "/registries", `{"server":"ghcr.io","username":"octocat","password":"other"}`)
+	handler.CreateRegistryHandler(ctx)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected status 409 for a duplicated registry, got %d", rec.Code)
+	}
+
+	ctx, rec = newTestContext(http.MethodPost, "/registries", `{"server":"https://ghcr.io/","username":"octocat","password":"other"}`)
+	handler.CreateRegistryHandler(ctx)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected the server to be normalized before the conflict check, got %d", rec.Code)
+	}
+
+	ctx, rec = newTestContext(http.MethodGet, "/registries", "")
+	if err := handler.ListRegistriesHandler(ctx); err != nil {
+		t.Fatalf("ListRegistriesHandler() error = %v", err)
+	}
+	var registries []models.Registry
+	json.Unmarshal(rec.Body.Bytes(), &registries)
+	if len(registries) != 1 || registries[0].Server != "ghcr.io" || registries[0].Password != "" {
+		t.Errorf("unexpected registries %+v", registries)
+	}
+
+	ctx, rec = newTestContext(http.MethodDelete, "/registries/ghcr.io", "", "server", "ghcr.io")
+	if err := handler.DeleteRegistryHandler(ctx); err != nil {
+		t.Fatalf("DeleteRegistryHandler() error = %v", err)
+	}
+	ctx, rec = newTestContext(http.MethodGet, "/registries/ghcr.io", "", "server", "ghcr.io")
+	handler.GetRegistryHandler(ctx)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 after delete, got %d", rec.Code)
+	}
+}
+
+func TestRegistryHandlers_RejectsInvalidRegistry(t *testing.T) {
+	handler, _, _, _ := newTestRegistryHandler(t)
+
+	for _, body := range []string{
+		`{"server":"not a host","username":"u","password":"p"}`,
+		`{"server":"ghcr.io","password":"p"}`,
+		`{"server":"ghcr.io","username":"u"}`,
+	} {
+		ctx, rec := newTestContext(http.MethodPost, "/registries", body)
+		handler.CreateRegistryHandler(ctx)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for %s, got %d", body, rec.Code)
+		}
+	}
+}
+
+func TestLoginRegistryHandler_ReportsResult(t *testing.T) {
+	handler, _, _, _ := newTestRegistryHandler(t)
+
+	ctx, _ := newTestContext(http.MethodPost, "/registries", `{"server":"localhost:5000","username":"admin","password":"wrong"}`)
+	handler.CreateRegistryHandler(ctx)
+
+	login := func() models.RegistryLoginResult {
+		ctx, rec := newTestContext(http.MethodPost, "/registries/localhost:5000/login", "", "server", "localhost:5000")
+		if err := handler.LoginRegistryHandler(ctx); err != nil {
+			t.Fatalf("LoginRegistryHandler() error = %v", err)
+		}
+		var result models.RegistryLoginResult
+		json.Unmarshal(rec.Body.Bytes(), &result)
+		return result
+	}
+
+	if result := login(); result.Success || result.Error == "" {
+		t.Errorf("expected the login to fail with a wrong password, got %+v", result)
+	}
+
+	ctx, _ = newTestContext(http.MethodPut, "/registries/localhost:5000", `{"username":"admin","password":"s3cret"}`, "server", "localhost:5000")
+	handler.UpdateRegistryHandler(ctx)
+
+	if result := login(); !result.Success || result.Host != service.LocalHost {
+		t.Errorf("expected the login to succeed, got %+v", result)
+	}
+}
+
+func TestPullImageHandler_UsesRegistryCredentials(t *testing.T) {
+	handler, images, pulls, engine := newTestRegistryHandler(t)
+
+	pull := func() models.PullJob {
+		ctx, rec := newTestContext(http.MethodPost, "/images/pulls", `{"registry":"localhost:5000","image":"team/app","version":"1.0"}`)
+		if err := images.PullImageHandler(ctx); err != nil {
+			t.Fatalf("PullImageHandler() error = %v", err)
+		}
+		var job models.PullJob
+		json.Unmarshal(rec.Body.Bytes(), &job)
+		return waitForJob(t, pulls, job.ID)
+	}
+
+	if job := pull(); job.Status != service.PullFailed {
+		t.Fatalf("expected an anonymous pull to fail, got %+v", job)
+	}
+
+	ctx, _ := newTestContext(http.MethodPost, "/registries", `{"server":"localhost:5000","username":"admin","password":"s3cret"}`)
+	handler.CreateRegistryHandler(ctx)
+
+	if job := pull(); job.Status != service.PullCompleted {
+		t.Fatalf("expected the pull to succeed with credentials, got %+v", job)
+	}
+	if !engine.HasImage("localhost:5000/team/app:1.0") {
+		t.Errorf("expected image localhost:5000/team/app:1.0 to be pulled")
+	}
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"errors"
+	"mineServers/internal/models"
+	"mineServers/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+var scheduleNotFoundResponse = models.ErrorResponse{
+	Code:    "SCHEDULE_NOT_FOUND",
+	Message: "No schedule with this ID",
+}
+
+type ScheduleHandler struct {
+	schedules *service.ActionScheduleManager
+}
+
+func NewScheduleHandler(schedules *service.ActionScheduleManager) *ScheduleHandler {
+	return &ScheduleHandler{
+		schedules: schedules,
+	}
+}
+
+// @Summary List schedules
+// @Description List the container action schedules with their last and next runs
+// @Tags schedules
+// @Produce json
+// @Success 200 {array} models.ActionSchedule
+// @Failure 500 {object} models.ErrorResponse
+// @Router /schedules [get]
+func (s *ScheduleHandler) ListSchedulesHandler(e echo.Context) error {
+	schedules, err := s.schedules.ListSchedules(e.Request().Context())
+	if err != nil {
+		return scheduleErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, schedules)
+}
+
+// @Summary Create a schedule
+// @Description Start, stop, restart, recreate or run a command in a container periodically, for example "0 4 * * *" to restart nightly at 04:00
+// @Description or "0 20 * * 1-5" to stop at 20:00 on weekdays. Runs missed while the server was down are skipped or, with the run_once catch-up policy,
+// @Description run once at startup.
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param schedule body models.ActionScheduleDefinition true "Schedule"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 201 {object} models.ActionSchedule
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /schedules [post]
+func (s *ScheduleHandler) CreateScheduleHandler(e echo.Context) error {
+	def := new(models.ActionScheduleDefinition)
+	if err := e.Bind(def); err != nil {
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{Code: "INVALID_PAYLOAD", Message: "Unable to parse the schedule payload"})
+	}
+
+	schedule, err := s.schedules.CreateSchedule(e.Request().Context(), e.QueryParam("host"), def)
+	if err != nil {
+		return scheduleErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusCreated, schedule)
+}
+
+// @Summary Get a schedule
+// @Description Get a container action schedule by ID
+// @Tags schedules
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Success 200 {object} models.ActionSchedule
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /schedules/{id} [get]
+func (s *ScheduleHandler) GetScheduleHandler(e echo.Context) error {
+	id, err := strconv.ParseInt(e.Param("id"), 10, 64)
+	if err != nil {
+		return e.JSON(http.StatusNotFound, scheduleNotFoundResponse)
+	}
+
+	schedule, err := s.schedules.GetSchedule(e.Request().Context(), id)
+	if err != nil {
+		return scheduleErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, schedule)
+}
+
+// @Summary Update a schedule
+// @Description Replace the definition of a schedule. Its host and run history are kept.
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Param schedule body models.ActionScheduleDefinition true "Schedule"
+// @Success 200 {object} models.ActionSchedule
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /schedules/{id} [put]
+func (s *ScheduleHandler) UpdateScheduleHandler(e echo.Context) error {
+	id, err := strconv.ParseInt(e.Param("id"), 10, 64)
+	if err != nil {
+		return e.JSON(http.StatusNotFound, scheduleNotFoundResponse)
+	}
+
+	def := new(models.ActionScheduleDefinition)
+	if err := e.Bind(def); err != nil {
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{Code: "INVALID_PAYLOAD", Message: "Unable to parse the schedule payload"})
+	}
+
+	schedule, err := s.schedules.UpdateSchedule(e.Request().Context(), id, def)
+	if err != nil {
+		return scheduleErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, schedule)
+}
+
+// @Summary Delete a schedule
+// @Description Delete a schedule and its run history
+// @Tags schedules
+// @Param id path int true "Schedule ID"
+// @Success 204
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /schedules/{id} [delete]
+func (s *ScheduleHandler) DeleteScheduleHandler(e echo.Context) error {
+	id, err := strconv.ParseInt(e.Param("id"), 10, 64)
+	if err != nil {
+		return e.JSON(http.StatusNotFound, scheduleNotFoundResponse)
+	}
+
+	if err := s.schedules.DeleteSchedule(e.Request().Context(), id); err != nil {
+		return scheduleErrorResponse(e, err)
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// @Summary Run a schedule
+// @Description Run the action of a schedule right away, even when it is disabled. The run is recorded in its history.
+// @Tags schedules
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Success 200 {object} models.ActionScheduleRun
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /schedules/{id}/run [post]
+func (s *ScheduleHandler) RunScheduleHandler(e echo.Context) error {
+	id, err := strconv.ParseInt(e.Param("id"), 10, 64)
+	if err != nil {
+		return e.JSON(http.StatusNotFound, scheduleNotFoundResponse)
+	}
+
+	disableWriteTimeout(e)
+	run, err := s.schedules.RunSchedule(e.Request().Context(), id)
+	if err != nil {
+		return scheduleErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, run)
+}
+
+// @Summary List the runs of a schedule
+// @Description List the most recent runs of a schedule, newest first. The last 100 runs are kept.
+// @Tags schedules
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Param limit query int false "Maximum number of runs, defaults to 100"
+// @Success 200 {array} models.ActionScheduleRun
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /schedules/{id}/runs [get]
+func (s *ScheduleHandler) ListRunsHandler(e echo.Context) error {
+	id, err := strconv.ParseInt(e.Param("id"), 10, 64)
+	if err != nil {
+		return e.JSON(http.StatusNotFound, scheduleNotFoundResponse)
+	}
+	limit, _ := strconv.Atoi(e.QueryParam("limit"))
+
+	runs, err := s.schedules.ListRuns(e.Request().Context(), id, limit)
+	if err != nil {
+		return scheduleErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, runs)
+}
+
+func scheduleErrorResponse(e echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrActionScheduleNotFound):
+		return e.JSON(http.StatusNotFound, scheduleNotFoundResponse)
+	case errors.Is(err, service.ErrHostNotFound):
+		return e.JSON(http.StatusNotFound, hostNotFoundResponse)
+	case errors.Is(err, service.ErrActionScheduleInProgress):
+		return e.JSON(http.StatusConflict, models.ErrorResponse{Code: "SCHEDULE_IN_PROGRESS", Message: err.Error()})
+	default:
+		return containerErrorResponse(e, err, nil)
+	}
+}
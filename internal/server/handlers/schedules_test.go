@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"mineServers/internal/fakedocker"
+	"mineServers/internal/models"
+	"mineServers/internal/service"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func newTestScheduleHandler(t *testing.T) (*ScheduleHandler, *fakedocker.Engine) {
+	t.Helper()
+
+	db := openTestDB(t)
+
+	hosts, engine := newTestHostManager(t)
+	schedules := service.NewActionScheduleManager(context.Background(), db, hosts, service.NewPullManager(context.Background(), nil))
+
+	return NewScheduleHandler(schedules), engine
+}
+
+func TestScheduleHandlers_CreateRunAndHistory(t *testing.T) {
+	handler, engine := newTestScheduleHandler(t)
+	createTestContainer(t, engine, "db", true)
+
+	ctx, rec := newTestContext(http.MethodPost, "/schedules", `{"container":"db","action":"stop","cron":"0 20 * * 1-5","timezone":"Europe/Paris"}`)
+	if err := handler.CreateScheduleHandler(ctx); err != nil {
+		t.Fatalf("CreateScheduleHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var schedule models.ActionSchedule
+	if err := json.Unmarshal(rec.Body.Bytes(), &schedule); err != nil || schedule.Host != service.LocalHost || schedule.NextRunAt == nil {
+		t.Fatalf("schedule = %+v, %v", schedule, err)
+	}
+	id := strconv.FormatInt(schedule.ID, 10)
+
+	ctx, rec = newTestContext(http.MethodPost, "/schedules/"+id+"/run", "", "id", id)
+	if err := handler.RunScheduleHandler(ctx); err != nil {
+		t.Fatalf("RunScheduleHandler() error = %v", err)
+	}
+	var run models.ActionScheduleRun
+	if err := json.Unmarshal(rec.Body.Bytes(), &run); err != nil || rec.Code != http.StatusOK || run.Status != service.RunSucceeded {
+		t.Fatalf("status = %d, run = %+v, %v", rec.Code, run, err)
+	}
+	if db, _ := engine.Container("db"); db.State != "exited" {
+		t.Fatalf("container state = %s, want exited", db.State)
+	}
+
+	ctx, rec = newTestContext(http.MethodGet, "/schedules/"+id+"/runs?limit=5", "", "id", id)
+	if err := handler.ListRunsHandler(ctx); err != nil {
+		t.Fatalf("ListRunsHandler() error = %v", err)
+	}
+	var runs []models.ActionScheduleRun
+	if err := json.Unmarshal(rec.Body.Bytes(), &runs); err != nil || len(runs) != 1 || runs[0].Trigger != service.TriggerManual {
+		t.Fatalf("runs = %+v, %v", runs, err)
+	}
+
+	ctx, rec = newTestContext(http.MethodPost, "/schedules", `{"container":"db","action":"exec","cron":"@daily"}`)
+	if err := handler.CreateScheduleHandler(ctx); err != nil {
+		t.Fatalf("CreateScheduleHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400, body = %s", rec.Code, rec.Body.String())
+	}
+
+	ctx, rec = newTestContext(http.MethodDelete, "/schedules/"+id, "", "id", id)
+	if err := handler.DeleteScheduleHandler(ctx); err != nil {
+		t.Fatalf("DeleteScheduleHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204", rec.Code)
+	}
+	ctx, rec = newTestContext(http.MethodGet, "/schedules/"+id, "", "id", id)
+	if err := handler.GetScheduleHandler(ctx); err != nil {
+		t.Fatalf("GetScheduleHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", rec.Code)
+	}
+}
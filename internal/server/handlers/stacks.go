@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mineServers/internal/database"
+	"mineServers/internal/models"
+	"mineServers/internal/service"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/labstack/echo/v4"
+)
+
+// maxComposeSize bounds the compose files accepted by the stack endpoints.
+const maxComposeSize = 1 << 20
+
+var stackNotFoundResponse = models.ErrorResponse{
+	Code:    "STACK_NOT_FOUND",
+	Message: "No stack with this name",
+}
+
+type StackHandler struct {
+	stacks *service.StackManager
+}
+
+func NewStackHandler(stacks *service.StackManager) *StackHandler {
+	return &StackHandler{
+		stacks: stacks,
+	}
+}
+
+// @Summary List stacks
+// @Description List the stored compose stacks
+// @Tags stacks
+// @Produce json
+// @Success 200 {array} models.Stack
+// @Failure 500 {object} models.ErrorResponse
+// @Router /stacks [get]
+func (s *StackHandler) ListStacksHandler(e echo.Context) error {
+	stacks, err := s.stacks.ListStacks(e.Request().Context())
+	if err != nil {
+		return stackErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, stacks)
+}
+
+// @Summary Deploy a stack
+// @Description Store a docker-compose file and bring it up: networks, volumes and containers are created in dependency order and labelled with the stack name.
+// @Description Supported keys are services (image, container_name, command, entrypoint, environment, labels, ports, volumes, depends_on, networks, restart, working_dir, user, hostname, pull_policy, platform, healthcheck), networks and volumes.
+// @Description The file is sent either as JSON or as a raw YAML body (application/yaml) with the name in the query.
+// @Tags stacks
+// @Accept json
+// @Accept application/yaml
+// @Produce json
+// @Param stack body models.StackRequest true "Stack"
+// @Param name query string false "Stack name, for YAML bodies"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 201 {object} models.StackStatus
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /stacks [post]
+func (s *StackHandler) CreateStackHandler(e echo.Context) error {
+	req, err := stackRequest(e)
+	if err != nil {
+		return err
+	}
+
+	ctx := e.Request().Context()
+	stack, err := s.stacks.CreateStack(ctx, e.QueryParam("host"), req)
+	if err != nil {
+		return stackErrorResponse(e, err)
+	}
+
+	// Pulling the images can take longer than the server WriteTimeout.
+	disableWriteTimeout(e)
+	status, err := s.stacks.Up(ctx, stack.Name)
+	if err != nil {
+		return stackErrorResponse(e, err)
+	}
+
+	log.Infof("STACKS: Stack '%s' deployed on host '%s'", stack.Name, stack.Host)
+	return e.JSON(http.StatusCreated, status)
+}
+
+// @Summary Get a stack
+// @Description Get a stack with its compose file
+// @Tags stacks
+// @Produce json
+// @Param name path string true "Stack name"
+// @Success 200 {object} models.Stack
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /stacks/{name} [get]
+func (s *StackHandler) GetStackHandler(e echo.Context) error {
+	stack, err := s.stacks.GetStack(e.Request().Context(), e.Param("name"))
+	if err != nil {
+		return stackErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, stack)
+}
+
+// @Summary Update a stack
+// @Description Replace the compose file of a stack and bring it up again. Unchanged services keep their containers,
+// @Description changed ones are recreated and removed ones are deleted.
+// @Tags stacks
+// @Accept json
+// @Accept application/yaml
+// @Produce json
+// @Param name path string true "Stack name"
+// @Param stack body models.StackRequest true "Stack, the name is ignored"
+// @Success 200 {object} models.StackStatus
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /stacks/{name} [put]
+func (s *StackHandler) UpdateStackHandler(e echo.Context) error {
+	req, err := stackRequest(e)
+	if err != nil {
+		return err
+	}
+
+	ctx := e.Request().Context()
+	stack, err := s.stacks.UpdateStack(ctx, e.Param("name"), req.Compose)
+	if err != nil {
+		return stackErrorResponse(e, err)
+	}
+
+	disableWriteTimeout(e)
+	status, err := s.stacks.Up(ctx, stack.Name)
+	if err != nil {
+		return stackErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, status)
+}
+
+// @Summary Delete a stack
+// @Description Take a stack down and forget it
+// @Tags stacks
+// @Produce json
+// @Param name path string true "Stack name"
+// @Param volumes query bool false "Also remove the volumes of the stack"
+// @Success 204
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /stacks/{name} [delete]
+func (s *StackHandler) DeleteStackHandler(e echo.Context) error {
+	volumes, _ := strconv.ParseBool(e.QueryParam("volumes"))
+	if err := s.stacks.DeleteStack(e.Request().Context(), e.Param("name"), volumes); err != nil {
+		return stackErrorResponse(e, err)
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// @Summary Bring a stack up
+// @Description Create or start what is missing so every service of the stack runs its current configuration
+// @Tags stacks
+// @Produce json
+// @Param name path string true "Stack name"
+// @Success 200 {object} models.StackStatus
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /stacks/{name}/up [post]
+func (s *StackHandler) UpStackHandler(e echo.Context) error {
+	disableWriteTimeout(e)
+	status, err := s.stacks.Up(e.Request().Context(), e.Param("name"))
+	if err != nil {
+		return stackErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, status)
+}
+
+// @Summary Take a stack down
+// @Description Stop and remove the containers of a stack in reverse dependency order, then its networks
+// @Tags stacks
+// @Produce json
+// @Param name path string true "Stack name"
+// @Param volumes query bool false "Also remove the volumes of the stack"
+// @Success 200 {object} models.StackStatus
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /stacks/{name}/down [post]
+func (s *StackHandler) DownStackHandler(e echo.Context) error {
+	volumes, _ := strconv.ParseBool(e.QueryParam("volumes"))
+	status, err := s.stacks.Down(e.Request().Context(), e.Param("name"), volumes)
+	if err != nil {
+		return stackErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, status)
+}
+
+// @Summary Restart a stack
+// @Description Restart the containers of a stack in dependency order
+// @Tags stacks
+// @Produce json
+// @Param name path string true "Stack name"
+// @Success 200 {object} models.StackStatus
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /stacks/{name}/restart [post]
+func (s *StackHandler) RestartStackHandler(e echo.Context) error {
+	status, err := s.stacks.Restart(e.Request().Context(), e.Param("name"))
+	if err != nil {
+		return stackErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, status)
+}
+
+// @Summary Get the status of a stack
+// @Description Get the container of every service of a stack and the aggregate state: running, partial, stopped or down
+// @Tags stacks
+// @Produce json
+// @Param name path string true "Stack name"
+// @Success 200 {object} models.StackStatus
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /stacks/{name}/status [get]
+func (s *StackHandler) StackStatusHandler(e echo.Context) error {
+	status, err := s.stacks.Status(e.Request().Context(), e.Param("name"))
+	if err != nil {
+		return stackErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, status)
+}
+
+// @Summary Stream stack logs
+// @Description Server-Sent Events with the logs of every container of the stack, one "log" event per line tagged with its service
+// @Tags stacks
+// @Produce text/event-stream
+// @Param name path string true "Stack name"
+// @Success 200 {object} models.StackLogLine
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /stacks/{name}/logs [get]
+func (s *StackHandler) StreamStackLogsHandler(e echo.Context) error {
+	lines, err := s.stacks.Logs(e.Request().Context(), e.Param("name"))
+	if err != nil {
+		return stackErrorResponse(e, err)
+	}
+
+	disableWriteTimeout(e)
+	e.Response().Header().Set(echo.HeaderContentType, "text/event-stream")
+	e.Response().Header().Set("Cache-Control", "no-cache")
+	e.Response().Header().Set("Connection", "keep-alive")
+	e.Response().WriteHeader(http.StatusOK)
+	e.Response().Flush()
+
+	for line := range lines {
+		data, err := json.Marshal(line)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(e.Response(), "event: log\ndata: %s\n\n", data); err != nil {
+			return nil
+		}
+		e.Response().Flush()
+	}
+
+	return nil
+}
+
+// stackRequest reads a stack from a JSON body or from a raw YAML compose
+// file named through the "name" query parameter, writing the error
+// response when it cannot.
+func stackRequest(e echo.Context) (*models.StackRequest, error) {
+	if !strings.Contains(e.Request().Header.Get(echo.HeaderContentType), "yaml") {
+		req := new(models.StackRequest)
+		if err := e.Bind(req); err != nil {
+			log.Warnf("ECHO: unable to bind payload due: %s", err)
+			e.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    "INVALID_PAYLOAD",
+				Message: "Unable to parse the stack payload",
+			})
+			return nil, err
+		}
+		return req, nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(e.Request().Body, maxComposeSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxComposeSize {
+		e.JSON(http.StatusRequestEntityTooLarge, models.ErrorResponse{
+			Code:    "PAYLOAD_TOO_LARGE",
+			Message: "Compose files are limited to 1MB",
+		})
+		return nil, errors.New("compose file too large")
+	}
+
+	return &models.StackRequest{Name: e.QueryParam("name"), Compose: string(data)}, nil
+}
+
+func stackErrorResponse(e echo.Context, err error) error {
+	var verr *service.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		return e.JSON(http.StatusBadRequest, validationErrorResponse(err))
+	case errors.Is(err, service.ErrStackNotFound):
+		return e.JSON(http.StatusNotFound, stackNotFoundResponse)
+	case errors.Is(err, service.ErrHostNotFound):
+		return e.JSON(http.StatusNotFound, hostNotFoundResponse)
+	case errors.Is(err, database.ErrConflict):
+		return e.JSON(http.StatusConflict, models.ErrorResponse{Code: "STACK_ALREADY_EXISTS", Message: "A stack with this name already exists"})
+	default:
+		// Deployment errors name the failing service or resource, which the user needs.
+		log.Warnf("STACKS: Unable to handle stack request due: %s", err)
+		return e.JSON(http.StatusInternalServerError, models.ErrorResponse{Code: "STACK_OPERATION_FAILED", Message: err.Error()})
+	}
+}
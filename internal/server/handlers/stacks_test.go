@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"mineServers/internal/fakedocker"
+	"mineServers/internal/models"
+	"mineServers/internal/service"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/labstack/echo/v4"
+)
+
+const testCompose = `
+services:
+  web:
+    image: nginx
+    ports: ["8080:80"]
+    depends_on: [db]
+  db:
+    image: postgres:16
+    environment:
+      POSTGRES_PASSWORD: example
+    volumes: [data:/var/lib/postgresql/data]
+volumes:
+  data:
+`
+
+func newTestStackHandler(t *testing.T) (*StackHandler, *fakedocker.Engine) {
+	t.Helper()
+
+	db := openTestDB(t)
+
+	hosts, engine := newTestHostManager(t)
+	pulls := service.NewPullManager(context.Background(), nil)
+
+	return NewStackHandler(service.NewStackManager(db, hosts, pulls)), engine
+}
+
+func deployTestStack(t *testing.T, handler *StackHandler) models.StackStatus {
+	t.Helper()
+
+	ctx, rec := newTestContext(http.MethodPost, "/stacks?name=blog", testCompose)
+	ctx.Request().Header.Set(echo.HeaderContentType, "application/yaml")
+	if err := handler.CreateStackHandler(ctx); err != nil {
+		t.Fatalf("CreateStackHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var status models.StackStatus
+	json.Unmarshal(rec.Body.Bytes(), &status)
+	return status
+}
+
+func TestStackHandlers_Lifecycle(t *testing.T) {
+	handler, engine := newTestStackHandler(t)
+
+	status := deployTestStack(t, handler)
+	if status.State != service.ProjectRunning || len(status.Services) != 2 || status.Services[0].Service != "db" {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	web, ok := engine.Container("blog-web-1")
+	if !ok || web.Config.Labels[service.LabelProject] != "blog" || web.Config.Labels[service.LabelService] != "web" {
+		t.Fatalf("expected a labelled web container, got %+v", web.Config)
+	}
+
+	ctx, rec := newTestContext(http.MethodPost, "/stacks/blog/down", "", "name", "blog")
+	if err := handler.DownStackHandler(ctx); err != nil {
+		t.Fatalf("DownStackHandler() error = %v", err)
+	}
+	json.Unmarshal(rec.Body.Bytes(), &status)
+	if rec.Code != http.StatusOK || status.State != service.ProjectDown || engine.ContainerCount() != 0 {
+		t.Fatalf("expected the stack to be down, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	ctx, rec = newTestContext(http.MethodPost, "/stacks/blog/up", "", "name", "blog")
+	if err := handler.UpStackHandler(ctx); err != nil {
+		t.Fatalf("UpStackHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusOK || engine.ContainerCount() != 2 {
+		t.Fatalf("expected the stack to be up again, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	engine.ContainerStop(context.Background(), "blog-web-1", container.StopOptions{})
+	ctx, rec = newTestContext(http.MethodGet, "/stacks/blog/status", "", "name", "blog")
+	if err := handler.StackStatusHandler(ctx); err != nil {
+		t.Fatalf("StackStatusHandler() error = %v", err)
+	}
+	json.Unmarshal(rec.Body.Bytes(), &status)
+	if status.State != service.ProjectPartial {
+		t.Errorf("expected a partial stack, got %+v", status)
+	}
+
+	ctx, rec = newTestContext(http.MethodDelete, "/stacks/blog?volumes=true", "", "name", "blog")
+	if err := handler.DeleteStackHandler(ctx); err != nil {
+		t.Fatalf("DeleteStackHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusNoContent || engine.ContainerCount() != 0 || len(engine.Volumes()) != 0 {
+		t.Errorf("expected everything to be removed, got %d", rec.Code)
+	}
+}
+
+func TestCreateStackHandler_RejectsInvalidStacks(t *testing.T) {
+	handler, engine := newTestStackHandler(t)
+	deployTestStack(t, handler)
+
+	ctx, rec := newTestContext(http.MethodPost, "/stacks", `{"name":"blog","compose":"services:\n  web:\n    image: nginx\n"}`)
+	handler.CreateStackHandler(ctx)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected status 409 for a duplicated stack, got %d", rec.Code)
+	}
+
+	ctx, rec = newTestContext(http.MethodPost, "/stacks", `{"name":"shop","compose":"services:\n  web:\n    build: .\n"}`)
+	handler.CreateStackHandler(ctx)
+	var resp struct {
+		Details []models.FieldError `json:"details"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if rec.Code != http.StatusBadRequest || len(resp.Details) != 2 {
+		t.Errorf("expected status 400 with the build and image errors, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if engine.ContainerCount() != 2 {
+		t.Errorf("expected nothing to be deployed for invalid stacks")
+	}
+
+	ctx, rec = newTestContext(http.MethodGet, "/stacks/nope/status", "", "name", "nope")
+	handler.StackStatusHandler(ctx)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown stack, got %d", rec.Code)
+	}
+}
+
+func TestStreamStackLogsHandler_TagsLinesWithService(t *testing.T) {
+	handler, engine := newTestStackHandler(t)
+	deployTestStack(t, handler)
+
+	engine.AppendLogs("blog-web-1", stdcopy.Stdout, "GET / 200")
+	engine.AppendLogs("blog-db-1", stdcopy.Stderr, "database system is ready")
+	// Streams of stopped containers end once their logs are sent.
+	engine.ContainerStop(context.Background(), "blog-web-1", container.StopOptions{})
+	engine.ContainerStop(context.Background(), "blog-db-1", container.StopOptions{})
+
+	ctx, rec := newTestContext(http.MethodGet, "/stacks/blog/logs", "", "name", "blog")
+	if err := handler.StreamStackLogsHandler(ctx); err != nil {
+		t.Fatalf("StreamStackLogsHandler() error = %v", err)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{`"service":"web","line":"GET / 200"`, `"service":"db","line":"database system is ready"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected the stream to contain %s, got %q", want, body)
+		}
+	}
+}
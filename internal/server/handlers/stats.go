@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"mineServers/internal/models"
+	"mineServers/internal/service"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type StatsHandler struct {
+	stats *service.StatsCollector
+}
+
+func NewStatsHandler(stats *service.StatsCollector) *StatsHandler {
+	return &StatsHandler{
+		stats: stats,
+	}
+}
+
+// @Summary Get the stats history of a container
+// @Description Get the CPU, memory, network and block I/O stats of a container as a time series for charts. Stats of the running containers
+// @Description are sampled every STATS_INTERVAL (10s by default) and rolled up per minute and per hour; points are computed from the finest
+// @Description rollup still kept for the range. Without a step, one is chosen to return at most 300 points.
+// @Tags containers
+// @Produce json
+// @Param id path string true "Container ID or name"
+// @Param from query string false "Start of the range as an RFC 3339 time, defaults to an hour ago"
+// @Param to query string false "End of the range as an RFC 3339 time, defaults to now"
+// @Param step query string false "Duration between points, such as 30s, 5m or 1h"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 200 {object} models.StatsHistory
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /containers/{id}/stats/history [get]
+func (s *StatsHandler) StatsHistoryHandler(e echo.Context) error {
+	query := new(models.StatsHistoryQuery)
+	if err := e.Bind(query); err != nil {
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{Code: "INVALID_QUERY", Message: "Unable to parse the stats history query"})
+	}
+
+	history, err := s.stats.History(e.Request().Context(), e.QueryParam("host"), e.Param("id"), query)
+	if err != nil {
+		if errors.Is(err, service.ErrHostNotFound) {
+			return e.JSON(http.StatusNotFound, hostNotFoundResponse)
+		}
+		return containerErrorResponse(e, err, nil)
+	}
+
+	return e.JSON(http.StatusOK, history)
+}
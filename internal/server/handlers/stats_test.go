@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"mineServers/internal/models"
+	"mineServers/internal/service"
+)
+
+func TestStatsHistoryHandler(t *testing.T) {
+	db := openTestDB(t)
+
+	hosts, engine := newTestHostManager(t)
+	stats := service.NewStatsCollector(db, hosts, service.DefaultStatsHistoryConfig)
+	handler := NewStatsHandler(stats)
+	createTestContainer(t, engine, "mc", true)
+	stats.Sample(context.Background())
+
+	ctx, rec := newTestContext(http.MethodGet, "/containers/mc/stats/history?step=1m", "", "id", "mc")
+	if err := handler.StatsHistoryHandler(ctx); err != nil {
+		t.Fatalf("StatsHistoryHandler() error = %v", err)
+	}
+	var history models.StatsHistory
+	if err := json.Unmarshal(rec.Body.Bytes(), &history); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if history.Step != "1m0s" || len(history.Points) != 1 || history.Points[0].MemUsage != 64<<20 {
+		t.Fatalf("history = %+v", history)
+	}
+
+	for target, want := range map[string]int{
+		"/containers/mc/stats/history?step=1.5s":     http.StatusBadRequest,
+		"/containers/mc/stats/history?host=missing":  http.StatusNotFound,
+		"/containers/mc/stats/history?from=tomorrow": http.StatusBadRequest,
+	} {
+		ctx, rec := newTestContext(http.MethodGet, target, "", "id", "mc")
+		if err := handler.StatsHistoryHandler(ctx); err != nil {
+			t.Fatalf("StatsHistoryHandler(%s) error = %v", target, err)
+		}
+		if rec.Code != want {
+			t.Errorf("%s: status = %d, want %d, body = %s", target, rec.Code, want, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mineServers/internal/database"
+	"mineServers/internal/models"
+	"mineServers/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/charmbracelet/log"
+	"github.com/labstack/echo/v4"
+)
+
+// maxTemplateImportSize bounds the documents accepted by the import endpoint.
+const maxTemplateImportSize = 1 << 20
+
+var templateNotFoundResponse = models.ErrorResponse{
+	Code:    "TEMPLATE_NOT_FOUND",
+	Message: "No template with this ID",
+}
+
+type TemplateHandler struct {
+	hosts     *service.HostManager
+	pulls     *service.PullManager
+	templates *service.TemplateManager
+}
+
+func NewTemplateHandler(hosts *service.HostManager, pulls *service.PullManager, templates *service.TemplateManager) *TemplateHandler {
+	return &TemplateHandler{
+		hosts:     hosts,
+		pulls:     pulls,
+		templates: templates,
+	}
+}
+
+// @Summary List templates
+// @Description List the stored container templates
+// @Tags templates
+// @Produce json
+// @Success 200 {array} models.Template
+// @Failure 500 {object} models.ErrorResponse
+// @Router /templates [get]
+func (s *TemplateHandler) ListTemplatesHandler(e echo.Context) error {
+	templates, err := s.templates.ListTemplates(e.Request().Context())
+	if err != nil {
+		return templateErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, templates)
+}
+
+// @Summary Get a template
+// @Description Get a container template by ID
+// @Tags templates
+// @Produce json
+// @Param id path int true "Template ID"
+// @Success 200 {object} models.Template
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /templates/{id} [get]
+func (s *TemplateHandler) GetTemplateHandler(e echo.Context) error {
+	template, err := s.template(e)
+	if err != nil {
+		return templateErrorResponse(e, err)
+	}
+
+	return e.JSON(http.StatusOK, template)
+}
+
+// @Summary Create a template
+// @Description Store a creation spec whose strings may reference the declared parameters as ${NAME}
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param template body models.TemplateDefinition true "Template"
+// @Success 201 {object} models.Template
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /templates [post]
+func (s *TemplateHandler) CreateTemplateHandler(e echo.Context) error {
+	template := new(models.Template)
+	if err := e.Bind(&template.TemplateDefinition); err != nil {
+		log.Warnf("ECHO: unable to bind payload due: %s", err)
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_PAYLOAD",
+			Message: "Unable to parse the template payload",
+		})
+	}
+
+	if err := s.templates.CreateTemplate(e.Request().Context(), template); err != nil {
+		return templateErrorResponse(e, err)
+	}
+
+	log.Infof("TEMPLATES: Template '%s' created", template.Name)
+	return e.JSON(http.StatusCreated, template)
+}
+
+// @Summary Update a template
+// @Description Replace the definition of a template
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param id path int true "Template ID"
+// @Param template body models.TemplateDefinition true "Template"
+// @Success 200 {object} models.Template
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /templates/{id} [put]
+func (s *TemplateHandler) UpdateTemplateHandler(e echo.Context) error {
+	id, err := strconv.ParseInt(e.Param("id"), 10, 64)
+	if err != nil {
+		return e.JSON(http.StatusNotFound, templateNotFoundResponse)
+	}
+
+	template := &models.Template{ID: id}
+	if err := e.Bind(&template.TemplateDefinition); err != nil {
+		log.Warnf("ECHO: unable to bind payload due: %s", err)
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_PAYLOAD",
+			Message: "Unable to parse the template payload",
+		})
+	}
+
+	if err := s.templates.UpdateTemplate(e.Request().Context(), template); err != nil {
+		return templateErrorResponse(e, err)
+	}
+
+	log.Infof("TEMPLATES: Template '%s' updated", template.Name)
+	return e.JSON(http.StatusOK, template)
+}
+
+// @Summary Delete a template
+// @Description Delete a template. Containers created from it are left untouched.
+// @Tags templates
+// @Produce json
+// @Param id path int true "Template ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /templates/{id} [delete]
+func (s *TemplateHandler) DeleteTemplateHandler(e echo.Context) error {
+	id, err := strconv.ParseInt(e.Param("id"), 10, 64)
+	if err != nil {
+		return e.JSON(http.StatusNotFound, templateNotFoundResponse)
+	}
+
+	if err := s.templates.DeleteTemplate(e.Request().Context(), id); err != nil {
+		return templateErrorResponse(e, err)
+	}
+
+	log.Infof("TEMPLATES: Template %d deleted", id)
+	return e.JSON(http.StatusOK, models.SuccessResponse{
+		Message: fmt.Sprintf("deleted template %d", id),
+	})
+}
+
+// @Summary Create a container from a template
+// @Description Substitute the parameters into the template, validate the resulting options and create the container like POST /containers does
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param id path int true "Template ID"
+// @Param parameters body models.InstantiateRequest true "Parameter values"
+// @Param host query string false "Docker host name, defaults to local"
+// @Param async query bool false "Return the pull job instead of waiting for the creation"
+// @Success 201 {object} models.SuccessResponse
+// @Success 202 {object} models.PullJob
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /templates/{id}/instantiate [post]
+func (s *TemplateHandler) InstantiateTemplateHandler(e echo.Context) error {
+	req := new(models.InstantiateRequest)
+	if err := e.Bind(req); err != nil {
+		log.Warnf("ECHO: unable to bind payload due: %s", err)
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_PAYLOAD",
+			Message: "Unable to parse the parameters",
+		})
+	}
+
+	template, err := s.template(e)
+	if err != nil {
+		return templateErrorResponse(e, err)
+	}
+
+	opts, err := service.RenderTemplate(template, req)
+	if err != nil {
+		return templateErrorResponse(e, err)
+	}
+	if err := parseCreateOpts(opts); err != nil {
+		return e.JSON(http.StatusBadRequest, validationErrorResponse(err))
+	}
+
+	svc, err := resolveService(e, s.hosts)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("TEMPLATES: Creating container from template '%s'", template.Name)
+	return createContainer(e, svc, s.pulls, opts)
+}
+
+// @Summary Export a template
+// @Description Download the definition of a template as JSON or YAML
+// @Tags templates
+// @Produce json
+// @Produce application/yaml
+// @Param id path int true "Template ID"
+// @Param format query string false "json (default) or yaml"
+// @Success 200 {object} models.TemplateDefinition
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /templates/{id}/export [get]
+func (s *TemplateHandler) ExportTemplateHandler(e echo.Context) error {
+	template, err := s.template(e)
+	if err != nil {
+		return templateErrorResponse(e, err)
+	}
+
+	return exportTemplates(e, template.Name, template.TemplateDefinition)
+}
+
+// @Summary Export every template
+// @Description Download the definitions of every template as a JSON or YAML list
+// @Tags templates
+// @Produce json
+// @Produce application/yaml
+// @Param format query string false "json (default) or yaml"
+// @Success 200 {array} models.TemplateDefinition
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /templates/export [get]
+func (s *TemplateHandler) ExportTemplatesHandler(e echo.Context) error {
+	templates, err := s.templates.ListTemplates(e.Request().Context())
+	if err != nil {
+		return templateErrorResponse(e, err)
+	}
+
+	defs := make([]models.TemplateDefinition, 0, len(templates))
+	for _, template := range templates {
+		defs = append(defs, template.TemplateDefinition)
+	}
+	return exportTemplates(e, "templates", defs)
+}
+
+// @Summary Import templates
+// @Description Import a JSON or YAML document holding a template definition or a list of them.
+// @Description Nothing is stored if one is invalid. Templates with an existing name are rejected unless overwrite=true.
+// @Tags templates
+// @Accept json
+// @Accept application/yaml
+// @Produce json
+// @Param templates body models.TemplateDefinition true "Template definition or list of them"
+// @Param overwrite query bool false "Replace the templates with the same name"
+// @Success 201 {array} models.Template
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /templates/import [post]
+func (s *TemplateHandler) ImportTemplatesHandler(e echo.Context) error {
+	data, err := io.ReadAll(io.LimitReader(e.Request().Body, maxTemplateImportSize+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > maxTemplateImportSize {
+		return e.JSON(http.StatusRequestEntityTooLarge, models.ErrorResponse{
+			Code:    "PAYLOAD_TOO_LARGE",
+			Message: "Template documents are limited to 1MB",
+		})
+	}
+
+	defs, err := service.DecodeTemplates(data)
+	if err != nil {
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_PAYLOAD",
+			Message: fmt.Sprintf("Unable to parse the templates: %s", err),
+		})
+	}
+
+	overwrite, _ := strconv.ParseBool(e.QueryParam("overwrite"))
+	templates, err := s.templates.ImportTemplates(e.Request().Context(), defs, overwrite)
+	if err != nil {
+		return templateErrorResponse(e, err)
+	}
+
+	log.Infof("TEMPLATES: Imported %d templates", len(templates))
+	return e.JSON(http.StatusCreated, templates)
+}
+
+func (s *TemplateHandler) template(e echo.Context) (*models.Template, error) {
+	id, err := strconv.ParseInt(e.Param("id"), 10, 64)
+	if err != nil {
+		return nil, service.ErrTemplateNotFound
+	}
+
+	return s.templates.GetTemplate(e.Request().Context(), id)
+}
+
+// exportTemplates writes value, a definition or a list of them, as an
+// attachment in the requested format.
+func exportTemplates(e echo.Context, name string, value any) error {
+	format := e.QueryParam("format")
+	if format == "" {
+		format = service.FormatJSON
+	}
+
+	data, err := service.EncodeTemplates(value, format)
+	if err != nil {
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{Code: "INVALID_FORMAT", Message: err.Error()})
+	}
+
+	contentType := echo.MIMEApplicationJSON
+	if format == service.FormatYAML {
+		contentType = "application/yaml"
+	}
+	e.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", name+"."+format))
+
+	return e.Blob(http.StatusOK, contentType, data)
+}
+
+func templateErrorResponse(e echo.Context, err error) error {
+	var verr *service.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		return e.JSON(http.StatusBadRequest, validationErrorResponse(err))
+	case errors.Is(err, service.ErrTemplateNotFound):
+		return e.JSON(http.StatusNotFound, templateNotFoundResponse)
+	case errors.Is(err, database.ErrConflict):
+		return e.JSON(http.StatusConflict, models.ErrorResponse{Code: "TEMPLATE_ALREADY_EXISTS", Message: "A template with this name already exists"})
+	default:
+		log.Warnf("TEMPLATES: Unable to handle template request due: %s", err)
+		return e.JSON(http.StatusInternalServerError, models.ErrorResponse{Code: "INTERNAL_ERROR", Message: "internal server error"})
+	}
+}
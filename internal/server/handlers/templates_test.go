@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"mineServers/internal/fakedocker"
+	"mineServers/internal/models"
+	"mineServers/internal/service"
+)
+
+const paperTemplate = `{
+	"name": "paper",
+	"description": "Paper server",
+	"parameters": [
+		{"name": "NAME", "required": true},
+		{"name": "MEMORY", "default": "2G"},
+		{"name": "PORT", "default": "25565"}
+	],
+	"spec": {
+		"name": "mc-${NAME}",
+		"image": "itzg/minecraft-server",
+		"env": {"EULA": "TRUE", "MEMORY": "${MEMORY}"},
+		"ports": [{"container_port": 25565, "host_port": "${PORT}"}]
+	}
+}`
+
+func newTestTemplateHandler(t *testing.T) (*TemplateHandler, *fakedocker.Engine) {
+	t.Helper()
+
+	db := openTestDB(t)
+
+	hosts, engine := newTestHostManager(t)
+	pulls := service.NewPullManager(context.Background(), nil)
+
+	return NewTemplateHandler(hosts, pulls, service.NewTemplateManager(db)), engine
+}
+
+func createTestTemplate(t *testing.T, handler *TemplateHandler, body string) models.Template {
+	t.Helper()
+
+	ctx, rec := newTestContext(http.MethodPost, "/templates", body)
+	if err := handler.CreateTemplateHandler(ctx); err != nil {
+		t.Fatalf("CreateTemplateHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var template models.Template
+	json.Unmarshal(rec.Body.Bytes(), &template)
+	return template
+}
+
+func TestInstantiateTemplateHandler_CreatesContainer(t *testing.T) {
+	handler, engine := newTestTemplateHandler(t)
+	template := createTestTemplate(t, handler, paperTemplate)
+	id := fmt.Sprint(template.ID)
+
+	ctx, rec := newTestContext(http.MethodPost, "/templates/"+id+"/instantiate", `{"parameters":{"NAME":"survival","PORT":"25570"}}`, "id", id)
+	if err := handler.InstantiateTemplateHandler(ctx); err != nil {
+		t.Fatalf("InstantiateTemplateHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	c, ok := engine.Container("mc-survival")
+	if !ok {
+		t.Fatalf("expected container 'mc-survival' to exist")
+	}
+	if !strings.Contains(strings.Join(c.Config.Env, ","), "MEMORY=2G") {
+		t.Errorf("expected the MEMORY default in env, got %v", c.Config.Env)
+	}
+	if bindings := c.HostConfig.PortBindings["25565/tcp"]; len(bindings) != 1 || bindings[0].HostPort != "25570" {
+		t.Errorf("expected host port 25570, got %+v", bindings)
+	}
+}
+
+func TestInstantiateTemplateHandler_ValidatesParameters(t *testing.T) {
+	handler, engine := newTestTemplateHandler(t)
+	template := createTestTemplate(t, handler, paperTemplate)
+	id := fmt.Sprint(template.ID)
+
+	for _, body := range []string{
+		`{"parameters":{}}`,
+		`{"parameters":{"NAME":"a","PORT":"not-a-port"}}`,
+		`{"parameters":{"NAME":"a","PORT":"0"},"name":"bad name"}`,
+	} {
+		ctx, rec := newTestContext(http.MethodPost, "/templates/"+id+"/instantiate", body, "id", id)
+		handler.InstantiateTemplateHandler(ctx)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for %s, got %d: %s", body, rec.Code, rec.Body.String())
+		}
+	}
+	if engine.ContainerCount() != 0 {
+		t.Errorf("expected no container to be created")
+	}
+
+	ctx, rec := newTestContext(http.MethodPost, "/templates/99/instantiate", `{}`, "id", "99")
+	handler.InstantiateTemplateHandler(ctx)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown template, got %d", rec.Code)
+	}
+}
+
+func TestTemplateHandlers_RejectInvalidTemplates(t *testing.T) {
+	handler, _ := newTestTemplateHandler(t)
+	createTestTemplate(t, handler, paperTemplate)
+
+	ctx, rec := newTestContext(http.MethodPost, "/templates", paperTemplate)
+	handler.CreateTemplateHandler(ctx)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected status 409 for a duplicated name, got %d", rec.Code)
+	}
+
+	ctx, rec = newTestContext(http.MethodPost, "/templates", `{"name":"broken","spec":{"image":"${IMAGE}"}}`)
+	handler.CreateTemplateHandler(ctx)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "IMAGE") {
+		t.Errorf("expected status 400 for an undeclared parameter, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestTemplateHandlers_ExportImportYAML(t *testing.T) {
+	handler, _ := newTestTemplateHandler(t)
+	template := createTestTemplate(t, handler, paperTemplate)
+	id := fmt.Sprint(template.ID)
+
+	ctx, rec := newTestContext(http.MethodGet, "/templates/"+id+"/export?format=yaml", "", "id", id)
+	if err := handler.ExportTemplateHandler(ctx); err != nil {
+		t.Fatalf("ExportTemplateHandler() error = %v", err)
+	}
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/yaml") {
+		t.Fatalf("expected a YAML export, got %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	exported := rec.Body.String()
+	if strings.Contains(exported, "created_at") || !strings.Contains(exported, "name: paper") {
+		t.Errorf("expected only the definition to be exported, got:\n%s", exported)
+	}
+
+	ctx, rec = newTestContext(http.MethodPost, "/templates/import", exported)
+	handler.ImportTemplatesHandler(ctx)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected status 409 when importing an existing template, got %d", rec.Code)
+	}
+
+	renamed := strings.Replace(exported, "description: Paper server", "description: Imported", 1)
+	ctx, rec = newTestContext(http.MethodPost, "/templates/import?overwrite=true", renamed)
+	if err := handler.ImportTemplatesHandler(ctx); err != nil {
+		t.Fatalf("ImportTemplatesHandler() error = %v", err)
+	}
+	var imported []models.Template
+	json.Unmarshal(rec.Body.Bytes(), &imported)
+	if rec.Code != http.StatusCreated || len(imported) != 1 || imported[0].ID != template.ID || imported[0].Description != "Imported" {
+		t.Fatalf("expected the template to be overwritten, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	list := "- " + strings.ReplaceAll(strings.Replace(exported, "name: paper", "name: paper-2", 1), "\n", "\n  ")
+	ctx, rec = newTestContext(http.MethodPost, "/templates/import", list)
+	handler.ImportTemplatesHandler(ctx)
+	if rec.Code != http.StatusCreated {
+		t.Errorf("expected a YAML list to be imported, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	ctx, rec = newTestContext(http.MethodGet, "/templates/export", "")
+	handler.ExportTemplatesHandler(ctx)
+	var all []models.TemplateDefinition
+	if err := json.Unmarshal(rec.Body.Bytes(), &all); err != nil || len(all) != 2 {
+		t.Errorf("expected both templates in the JSON export, got %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"mineServers/internal/models"
+	"mineServers/internal/service"
+
+	"github.com/charmbracelet/log"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+// Types of the control messages of terminal WebSockets.
+const (
+	terminalInput  = "input"
+	terminalResize = "resize"
+	terminalDetach = "detach"
+	terminalExit   = "exit"
+	terminalError  = "error"
+)
+
+// errDetached ends a session on a detach message of the client.
+var errDetached = errors.New("detached")
+
+const (
+	wsPingInterval = 30 * time.Second
+	wsPongWait     = time.Minute
+	wsWriteWait    = 10 * time.Second
+	wsReadLimit    = 64 << 10
+)
+
+// upgrader accepts every origin, like the CORS policy of the API.
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 32 << 10,
+	CheckOrigin:     func(*http.Request) bool { return true },
+}
+
+// terminalStream is a process whose input and output are bridged to a
+// WebSocket.
+type terminalStream interface {
+	io.ReadWriter
+	Resize(ctx context.Context, rows, cols uint) error
+	ExitCode(ctx context.Context) (int, error)
+}
+
+// @Summary Open a web terminal
+// @Description Upgrade to a WebSocket running an interactive shell with a TTY inside a running container. The output
+// @Description is sent as binary frames. Clients send input as binary frames or as {"type":"input","data":"..."} and
+// @Description resize the TTY with {"type":"resize","rows":24,"cols":80}. When the shell exits the server sends
+// @Description {"type":"exit","exit_code":0} and closes the socket, closing the socket ends the shell.
+// @Tags containers
+// @Param id path string true "Container ID"
+// @Param cmd query []string false "Command, /bin/sh by default" collectionFormat(multi)
+// @Param user query string false "User running the command"
+// @Param workdir query string false "Working directory"
+// @Param rows query int false "Initial rows of the TTY"
+// @Param cols query int false "Initial columns of the TTY"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 101 {string} string "Switching Protocols"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /containers/{id}/terminal [get]
+func (s *ContainerHandler) TerminalHandler(e echo.Context) error {
+	opts := &models.TerminalOptions{
+		Cmd:        e.QueryParams()["cmd"],
+		User:       e.QueryParam("user"),
+		WorkingDir: e.QueryParam("workdir"),
+	}
+	var err error
+	if opts.Rows, err = parseSize(e.QueryParam("rows")); err == nil {
+		opts.Cols, err = parseSize(e.QueryParam("cols"))
+	}
+	if err != nil {
+		return e.JSON(http.StatusBadRequest, models.ErrorResponse{Code: "INVALID_OPTIONS", Message: "rows and cols must be positive numbers"})
+	}
+
+	svc, err := s.containerService(e)
+	if err != nil {
+		return err
+	}
+
+	ctx := e.Request().Context()
+	session, err := svc.OpenTerminal(ctx, e.Param("id"), opts)
+	if err != nil {
+		return containerErrorResponse(e, err, nil)
+	}
+	defer session.Close()
+
+	ws, err := upgrader.Upgrade(e.Response(), e.Request(), nil)
+	if err != nil {
+		// The upgrader already answered the request.
+		log.Warnf("CONTAINER-TERMINAL: Unable to upgrade connection due: %s", err)
+		return nil
+	}
+	defer ws.Close()
+
+	serveTerminal(ctx, ws, session)
+	return nil
+}
+
+// @Summary Attach to a container
+// @Description Upgrade to a WebSocket attached to the main process of a running container, such as the console of a
+// @Description game server. Its stdout and stderr are sent as binary frames. Clients send lines to its stdin as binary
+// @Description frames or as {"type":"input","data":"say hello\n"}, which requires a container created with stdin open.
+// @Description Observers attach with readonly=true. {"type":"detach"} or closing the socket detaches and leaves the
+// @Description process running, when it exits the server sends {"type":"exit","exit_code":0} and closes the socket.
+// @Tags containers
+// @Param id path string true "Container ID"
+// @Param readonly query bool false "Only receive the output"
+// @Param host query string false "Docker host name, defaults to local"
+// @Success 101 {string} string "Switching Protocols"
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /containers/{id}/attach [get]
+func (s *ContainerHandler) AttachHandler(e echo.Context) error {
+	readOnly, _ := strconv.ParseBool(e.QueryParam("readonly"))
+
+	svc, err := s.containerService(e)
+	if err != nil {
+		return err
+	}
+
+	ctx := e.Request().Context()
+	session, err := svc.AttachContainer(ctx, e.Param("id"), readOnly)
+	if err != nil {
+		return containerErrorResponse(e, err, nil)
+	}
+	defer session.Close()
+
+	ws, err := upgrader.Upgrade(e.Response(), e.Request(), nil)
+	if err != nil {
+		log.Warnf("CONTAINER-ATTACH: Unable to upgrade connection due: %s", err)
+		return nil
+	}
+	defer ws.Close()
+
+	serveTerminal(ctx, ws, session)
+	return nil
+}
+
+func parseSize(value string) (uint, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	size, err := strconv.ParseUint(value, 10, 32)
+	return uint(size), err
+}
+
+// serveTerminal pipes the WebSocket and the stream in both directions until
+// the process ends, reporting its exit code, or the client goes away.
+func serveTerminal(ctx context.Context, ws *websocket.Conn, stream terminalStream) {
+	w := &wsWriter{conn: ws}
+
+	ws.SetReadLimit(wsReadLimit)
+	ws.SetReadDeadline(time.Now().Add(wsPongWait))
+	ws.SetPongHandler(func(string) error {
+		return ws.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	output := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 32<<10)
+		for {
+			n, err := stream.Read(buf)
+			if n > 0 {
+				if err := w.write(websocket.BinaryMessage, buf[:n]); err != nil {
+					output <- err
+					return
+				}
+			}
+			if err != nil {
+				output <- err
+				return
+			}
+		}
+	}()
+
+	input := make(chan error, 1)
+	go func() {
+		input <- readTerminalInput(ctx, ws, w, stream)
+	}()
+
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-output:
+			msg := models.TerminalMessage{Type: terminalExit}
+			if code, err := stream.ExitCode(ctx); err == nil {
+				msg.ExitCode = &code
+			}
+			w.writeJSON(msg)
+			w.close(websocket.CloseNormalClosure, "process exited")
+			return
+		case err := <-input:
+			if errors.Is(err, errDetached) {
+				w.close(websocket.CloseNormalClosure, "detached")
+				return
+			}
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Warnf("CONTAINER-TERMINAL: Connection lost due: %s", err)
+			}
+			return
+		case <-ticker.C:
+			if err := w.ping(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readTerminalInput forwards the input of the client to the stream and
+// applies its control messages until the connection fails or is closed.
+func readTerminalInput(ctx context.Context, ws *websocket.Conn, w *wsWriter, stream terminalStream) error {
+	for {
+		typ, data, err := ws.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		if typ == websocket.BinaryMessage {
+			if err := writeTerminalInput(w, stream, data); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var msg models.TerminalMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			w.writeJSON(models.TerminalMessage{Type: terminalError, Data: "invalid message: " + err.Error()})
+			continue
+		}
+		switch msg.Type {
+		case terminalInput:
+			if err := writeTerminalInput(w, stream, []byte(msg.Data)); err != nil {
+				return err
+			}
+		case terminalDetach:
+			return errDetached
+		case terminalResize:
+			if err := stream.Resize(ctx, msg.Rows, msg.Cols); err != nil {
+				w.writeJSON(models.TerminalMessage{Type: terminalError, Data: err.Error()})
+			}
+		default:
+			w.writeJSON(models.TerminalMessage{Type: terminalError, Data: "unknown message type " + strconv.Quote(msg.Type)})
+		}
+	}
+}
+
+// writeTerminalInput writes the input to the stream, reporting to the client
+// that a read-only session refuses it.
+func writeTerminalInput(w *wsWriter, stream terminalStream, data []byte) error {
+	_, err := stream.Write(data)
+	if errors.Is(err, service.ErrReadOnlyAttach) {
+		return w.writeJSON(models.TerminalMessage{Type: terminalError, Data: err.Error()})
+	}
+
+	return err
+}
+
+// wsWriter serializes the writes to a WebSocket, which supports a single
+// concurrent writer.
+type wsWriter struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+func (w *wsWriter) write(typ int, data []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return w.conn.WriteMessage(typ, data)
+}
+
+func (w *wsWriter) writeJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return w.write(websocket.TextMessage, data)
+}
+
+func (w *wsWriter) ping() error {
+	return w.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait))
+}
+
+func (w *wsWriter) close(code int, reason string) error {
+	return w.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(wsWriteWait))
+}
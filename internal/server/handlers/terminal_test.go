@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mineServers/internal/models"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+// fakeShell echoes every line of input and exits with 3 on "exit".
+func fakeShell(ctx context.Context, opts container.ExecOptions, stdin io.Reader, stdout, stderr io.Writer) int {
+	io.WriteString(stdout, "$ ")
+	scanner := bufio.NewScanner(stdin)
+	for scanner.Scan() {
+		if scanner.Text() == "exit" {
+			return 3
+		}
+		fmt.Fprintf(stdout, "%s\r\n$ ", scanner.Text())
+	}
+	return 0
+}
+
+// readOutput reads binary frames until their content contains want.
+func readOutput(t *testing.T, ws *websocket.Conn, want string) {
+	t.Helper()
+
+	var out strings.Builder
+	ws.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for !strings.Contains(out.String(), want) {
+		typ, data, err := ws.ReadMessage()
+		if err != nil {
+			t.Fatalf("expected %q in the output, got %q: %v", want, out.String(), err)
+		}
+		if typ == websocket.BinaryMessage {
+			out.Write(data)
+		}
+	}
+}
+
+func TestTerminalHandler_PipesShell(t *testing.T) {
+	handler, engine := newTestHandler(t)
+	createTestContainer(t, engine, "shell", true)
+	engine.OnExec(fakeShell)
+
+	e := echo.New()
+	e.GET("/containers/:id/terminal", handler.TerminalHandler)
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/containers/"
+
+	ws, _, err := websocket.DefaultDialer.Dial(url+"shell/terminal?rows=24&cols=80", nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer ws.Close()
+	readOutput(t, ws, "$ ")
+
+	execs := engine.Execs("shell")
+	if len(execs) != 1 || engine.ExecSize(execs[0]) != [2]uint{24, 80} {
+		t.Fatalf("expected one 24x80 session, got %v", execs)
+	}
+
+	ws.WriteJSON(models.TerminalMessage{Type: "input", Data: "echo hi\n"})
+	readOutput(t, ws, "echo hi")
+
+	ws.WriteJSON(models.TerminalMessage{Type: "resize", Rows: 40, Cols: 120})
+	ws.WriteMessage(websocket.BinaryMessage, []byte("ls\n"))
+	readOutput(t, ws, "ls")
+	if size := engine.ExecSize(execs[0]); size != [2]uint{40, 120} {
+		t.Errorf("expected the TTY to be resized to 40x120, got %v", size)
+	}
+
+	ws.WriteMessage(websocket.BinaryMessage, []byte("exit\n"))
+	var msg models.TerminalMessage
+	for msg.Type != "exit" {
+		typ, data, err := ws.ReadMessage()
+		if err != nil {
+			t.Fatalf("expected an exit message, got %v", err)
+		}
+		if typ == websocket.TextMessage {
+			json.Unmarshal(data, &msg)
+		}
+	}
+	if msg.ExitCode == nil || *msg.ExitCode != 3 {
+		t.Errorf("expected exit code 3, got %+v", msg)
+	}
+	if _, _, err := ws.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		t.Errorf("expected a normal closure, got %v", err)
+	}
+
+	_, resp, err := websocket.DefaultDialer.Dial(url+"missing/terminal", nil)
+	if err == nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown container, got %v", err)
+	}
+}
+
+func TestAttachHandler_SendsInputAndDetaches(t *testing.T) {
+	handler, engine := newTestHandler(t)
+	engine.AddImage("docker.io/library/minecraft:latest")
+	created, _ := engine.ContainerCreate(context.Background(), &container.Config{Image: "docker.io/library/minecraft:latest", OpenStdin: true}, nil, nil, nil, "mc")
+	engine.ContainerStart(context.Background(), created.ID, container.StartOptions{})
+	createTestContainer(t, engine, "closed", true)
+
+	e := echo.New()
+	e.GET("/containers/:id/attach", handler.AttachHandler)
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/containers/"
+
+	player, _, err := websocket.DefaultDialer.Dial(url+"mc/attach", nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer player.Close()
+	observer, _, err := websocket.DefaultDialer.Dial(url+"mc/attach?readonly=true", nil)
+	if err != nil {
+		t.Fatalf("Dial() read-only error = %v", err)
+	}
+	defer observer.Close()
+
+	player.WriteJSON(models.TerminalMessage{Type: "input", Data: "say hello\n"})
+	for i := 0; engine.Stdin("mc") != "say hello\n"; i++ {
+		if i == 100 {
+			t.Fatalf("expected the line on stdin, got %q", engine.Stdin("mc"))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	engine.AppendLogs("mc", stdcopy.Stdout, "[Server] hello")
+	readOutput(t, player, "[Server] hello")
+	readOutput(t, observer, "[Server] hello")
+
+	observer.WriteMessage(websocket.BinaryMessage, []byte("stop\n"))
+	var msg models.TerminalMessage
+	observer.ReadJSON(&msg)
+	if msg.Type != "error" || engine.Stdin("mc") != "say hello\n" {
+		t.Errorf("expected the observer input to be refused, got %+v", msg)
+	}
+
+	player.WriteJSON(models.TerminalMessage{Type: "detach"})
+	if _, _, err := player.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		t.Errorf("expected a normal closure on detach, got %v", err)
+	}
+	if mc, _ := engine.Container("mc"); mc.State != "running" {
+		t.Errorf("expected the container to keep running after detaching, got %s", mc.State)
+	}
+
+	engine.ContainerStop(context.Background(), "mc", container.StopOptions{})
+	observer.ReadJSON(&msg)
+	if msg.Type != "exit" || msg.ExitCode == nil || *msg.ExitCode != 0 {
+		t.Errorf("expected the exit of the process, got %+v", msg)
+	}
+
+	_, resp, err := websocket.DefaultDialer.Dial(url+"closed/attach", nil)
+	if err == nil || resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected status 409 without stdin open, got %v", err)
+	}
+}
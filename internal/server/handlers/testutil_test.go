@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"mineServers/internal/database"
+	"mineServers/internal/fakedocker"
+	"mineServers/internal/service"
+)
+
+// openTestDB opens a database in a temporary directory, closed with the test.
+func openTestDB(t *testing.T) database.Service {
+	t.Helper()
+
+	db, err := database.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("unable to open database: %s", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+// newTestHostManager returns a HostManager serving only the local host,
+// backed by a fake engine.
+func newTestHostManager(t *testing.T) (*service.HostManager, *fakedocker.Engine) {
+	t.Helper()
+
+	engine := fakedocker.New()
+	svc := service.NewContainerService(context.Background(), engine)
+	return service.NewHostManager(context.Background(), svc, nil, nil), engine
+}
@@ -2,60 +2,134 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"io"
+	"mineServers/internal/models"
 	"mineServers/internal/service"
+	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/charmbracelet/log"
-	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/client"
+	"github.com/docker/docker/api/types/image"
+	"github.com/labstack/echo/v4"
 )
 
-func NewContainerHandler(ctx context.Context) *ContainerHandler {
-	svc := service.NewContainerService(ctx)
+func NewContainerHandler(hosts *service.HostManager, pulls *service.PullManager) *ContainerHandler {
 	return &ContainerHandler{
-		svc: svc,
+		hosts: hosts,
+		pulls: pulls,
 	}
 }
 
-func parseCreateOpts(opts *CreateOptions) error {
-	if opts.Registry == "" {
-		opts.Registry = "docker.io"
+// containerService resolves the service of the Docker host selected through
+// the "host" query parameter, writing the error response when it cannot.
+func (s *ContainerHandler) containerService(e echo.Context) (*service.ContainerService, error) {
+	return resolveService(e, s.hosts)
+}
+
+func resolveService(e echo.Context, hosts *service.HostManager) (*service.ContainerService, error) {
+	svc, err := hosts.Resolve(e.Request().Context(), e.QueryParam("host"))
+	if err != nil {
+		if errors.Is(err, service.ErrHostNotFound) {
+			e.JSON(http.StatusNotFound, hostNotFoundResponse)
+			return nil, err
+		}
+
+		e.JSON(http.StatusInternalServerError, dockerClientErrResponse)
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// hostName returns the name of the host selected through the "host" query parameter.
+func hostName(e echo.Context) string {
+	if host := e.QueryParam("host"); host != "" {
+		return host
+	}
+
+	return service.LocalHost
+}
+
+// disableWriteTimeout lifts the server WriteTimeout for long running
+// responses such as event streams; they end with the request context.
+func disableWriteTimeout(e echo.Context) {
+	rc := http.NewResponseController(e.Response())
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Warnf("ECHO: unable to lift write deadline due: %s", err)
 	}
+}
 
-	if opts.Version == "" {
-		opts.Version = "latest"
+// disableReadTimeout lifts the server ReadTimeout for large request bodies
+// such as uploads.
+func disableReadTimeout(e echo.Context) {
+	rc := http.NewResponseController(e.Response())
+	if err := rc.SetReadDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Warnf("ECHO: unable to lift read deadline due: %s", err)
 	}
+}
 
-	if opts.Image == "" {
-		log.Warn("CONTAINER: Image name cannot be empty")
-		return fmt.Errorf("Image name is required.")
+// parseCreateOpts validates the creation options. Images without a registry
+// or tag default to Docker Hub and "latest" when the reference is normalized.
+func parseCreateOpts(opts *models.CreateOptions) error {
+	if err := service.ValidateCreateOptions(opts); err != nil {
+		log.Warnf("CONTAINER: Invalid create options: %s", err)
+		return err
 	}
 	return nil
 }
 
-func newDockerClient(opts client.Opt) (*client.Client, error) {
-	cli, err := client.NewClientWithOpts(opts)
+// createContainer creates the container described by validated opts through
+// a pull job, returning the job right away when the "async" query parameter
+// is set and waiting for the container otherwise.
+func createContainer(e echo.Context, svc *service.ContainerService, pulls *service.PullManager, opts *models.CreateOptions) error {
+	imageName, _ := service.CreateImageReference(opts)
+	pullOpts := image.PullOptions{Platform: opts.Platform}
+	job := pulls.Start(svc, hostName(e), imageName, opts.PullPolicy, pullOpts, func(ctx context.Context) (string, error) {
+		return svc.CreatePulledContainer(ctx, opts)
+	})
+
+	if async, _ := strconv.ParseBool(e.QueryParam("async")); async {
+		return e.JSON(http.StatusAccepted, job)
+	}
+
+	// Large images can take longer than the server WriteTimeout to pull.
+	disableWriteTimeout(e)
+	job, err := pulls.Wait(e.Request().Context(), job.ID)
 	if err != nil {
-		log.Warnf("CONTAINER: Unable to create docker client due: %s", err)
-		return nil, err
+		return err
 	}
 
-	return cli, nil
+	if job.Status == service.PullFailed {
+		log.Warnf("CONTAINER: Unable to create container due: %s", job.Error)
+		e.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "internal server error.",
+		})
+
+		return errors.New(job.Error)
+	}
+
+	log.Info("CONTAINER: Container created sucessfully!")
+	e.JSON(http.StatusCreated, map[string]string{
+		"success": fmt.Sprintf("created container with ID: %s successfully!", job.ContainerID),
+	})
+
+	return nil
 }
 
-func createDockerContainer(ctx context.Context, client *client.Client, reader io.ReadCloser, opts *CreateOptions, imageName string) (*container.CreateResponse, error) {
-	io.Copy(io.Discard, reader)
-	config := &container.Config{
-		Image: imageName,
-		Cmd:   opts.Commands,
+// validationErrorResponse describes every invalid field reported by the service.
+func validationErrorResponse(err error) models.ErrorResponse {
+	resp := models.ErrorResponse{
+		Code:    "INVALID_OPTIONS",
+		Message: err.Error(),
 	}
 
-	resp, err := client.ContainerCreate(ctx, config, nil, nil, nil, opts.Name)
-	if err != nil {
-		log.Warnf("CONTAINER: Unable to create container due: %s", err)
-		return nil, err
+	var verr *service.ValidationError
+	if errors.As(err, &verr) {
+		resp.Message = "One or more fields are invalid"
+		resp.Details = verr.Fields
 	}
 
-	return &resp, nil
+	return resp
 }
@@ -1,7 +1,7 @@
 package server
 
 import (
-	"mineServers/internal/server/handlers"
+	"net"
 	"net/http"
 
 	"github.com/charmbracelet/log"
@@ -9,10 +9,12 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/swaggo/echo-swagger"
 	_ "mineServers/internal/docs"
+	"mineServers/internal/server/handlers"
 )
 
 func (s *Server) RegisterRoutes() http.Handler {
 	e := echo.New()
+	e.IPExtractor = ipExtractor(s.trustedProxies)
 
 	log.Info("ROUTES: Registering routes.")
 	e.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
@@ -23,7 +25,7 @@ func (s *Server) RegisterRoutes() http.Handler {
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins:     []string{"https://*", "http://*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowHeaders:     []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		AllowHeaders:     []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "Last-Event-ID"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
@@ -36,10 +38,11 @@ func (s *Server) RegisterRoutes() http.Handler {
 
 	log.Info("ROUTES-API: Registering API routes.")
 	api := e.Group("/api")
+	api.Use(handlers.AuditMiddleware(s.audit, s.trustedProxies))
 
 	log.Info("ROUTES-API: Registering CONTAINER routes.")
 
-	containerHandler := handlers.NewContainerHandler(s.ctx)
+	containerHandler := s.containersHandler
 
 	containers := api.Group("/containers")
 	containers.POST("/", containerHandler.CreateContainerHandler)
@@ -47,16 +50,142 @@ func (s *Server) RegisterRoutes() http.Handler {
 
 	// Container Specific Ops
 	containers.DELETE("/:id", containerHandler.DeleteContainerHandler)
+	containers.PATCH("/:id", containerHandler.UpdateContainerHandler)
 	containers.POST("/:id/start", containerHandler.StartContainer)
 	containers.POST("/:id/stop", containerHandler.StopContainer)
 	containers.POST("/:id/restart", containerHandler.RestartContainer)
+	containers.POST("/:id/kill", containerHandler.KillContainer)
+	containers.POST("/:id/pause", containerHandler.PauseContainer)
+	containers.POST("/:id/unpause", containerHandler.UnpauseContainer)
+	containers.POST("/:id/rename", containerHandler.RenameContainer)
+	containers.POST("/:id/exec", containerHandler.ExecContainerHandler)
+	containers.POST("/:id/recreate", containerHandler.RecreateContainerHandler)
+	containers.POST("/:id/clone", containerHandler.CloneContainerHandler)
+	containers.POST("/:id/commit", containerHandler.CommitContainerHandler)
+	containers.GET("/:id/export", containerHandler.ExportContainerHandler)
 	containers.GET("/:id/stats", containerHandler.GetContainerStats)
+	containers.GET("/:id/stats/history", s.statsHandler.StatsHistoryHandler)
 	containers.GET("/:id/credentials", containerHandler.GetContainerCredentails)
+	// Files
+	containers.GET("/:id/files", containerHandler.ListFilesHandler)
+	containers.GET("/:id/files/download", containerHandler.DownloadFilesHandler)
+	containers.POST("/:id/files/upload", containerHandler.UploadFilesHandler)
+	containers.GET("/:id/files/content", containerHandler.ReadFileHandler)
+	containers.PUT("/:id/files/content", containerHandler.WriteFileHandler)
+	// Backups
+	containers.POST("/:id/backups", s.backupsHandler.BackupContainerHandler)
+	// Events
+	containers.GET("/:id/timeline", s.eventsHandler.ContainerTimelineHandler)
 	// SSE
 	containers.GET("/:id/logs", containerHandler.StreamLogContainers)
+	// WebSocket
+	containers.GET("/:id/terminal", containerHandler.TerminalHandler)
+	containers.GET("/:id/attach", containerHandler.AttachHandler)
 
 	containers.GET("/:id/stats", containerHandler.StreamStatContainers)
 
+	log.Info("ROUTES-API: Registering HOST routes.")
+
+	hosts := api.Group("/hosts")
+	hosts.GET("/", s.hostsHandler.ListHostsHandler)
+	hosts.POST("/", s.hostsHandler.CreateHostHandler)
+	hosts.GET("/:name", s.hostsHandler.GetHostHandler)
+	hosts.PUT("/:name", s.hostsHandler.UpdateHostHandler)
+	hosts.DELETE("/:name", s.hostsHandler.DeleteHostHandler)
+
+	log.Info("ROUTES-API: Registering IMAGE routes.")
+
+	images := api.Group("/images")
+	images.POST("/pulls", s.imagesHandler.PullImageHandler)
+	images.POST("/import", s.imagesHandler.ImportImageHandler)
+	images.GET("/pulls/:id", s.imagesHandler.GetPullJobHandler)
+	// SSE
+	images.GET("/pulls/:id/events", s.imagesHandler.StreamPullJobHandler)
+
+	log.Info("ROUTES-API: Registering BACKUP routes.")
+
+	backups := api.Group("/backups")
+	backups.GET("/", s.backupsHandler.ListBackupsHandler)
+	backups.GET("/schedules", s.backupsHandler.ListSchedulesHandler)
+	backups.POST("/schedules", s.backupsHandler.CreateScheduleHandler)
+	backups.GET("/schedules/:id", s.backupsHandler.GetScheduleHandler)
+	backups.PUT("/schedules/:id", s.backupsHandler.UpdateScheduleHandler)
+	backups.DELETE("/schedules/:id", s.backupsHandler.DeleteScheduleHandler)
+	backups.POST("/schedules/:id/run", s.backupsHandler.RunScheduleHandler)
+	backups.GET("/:id", s.backupsHandler.GetBackupHandler)
+	backups.DELETE("/:id", s.backupsHandler.DeleteBackupHandler)
+	backups.GET("/:id/download", s.backupsHandler.DownloadBackupHandler)
+	backups.POST("/:id/restore", s.backupsHandler.RestoreBackupHandler)
+
+	log.Info("ROUTES-API: Registering SCHEDULE routes.")
+
+	schedules := api.Group("/schedules")
+	schedules.GET("/", s.schedulesHandler.ListSchedulesHandler)
+	schedules.POST("/", s.schedulesHandler.CreateScheduleHandler)
+	schedules.GET("/:id", s.schedulesHandler.GetScheduleHandler)
+	schedules.PUT("/:id", s.schedulesHandler.UpdateScheduleHandler)
+	schedules.DELETE("/:id", s.schedulesHandler.DeleteScheduleHandler)
+	schedules.POST("/:id/run", s.schedulesHandler.RunScheduleHandler)
+	schedules.GET("/:id/runs", s.schedulesHandler.ListRunsHandler)
+
+	log.Info("ROUTES-API: Registering AUDIT routes.")
+
+	audit := api.Group("/audit")
+	audit.GET("/", s.auditHandler.ListAuditHandler)
+	audit.GET("/export", s.auditHandler.ExportAuditHandler)
+
+	log.Info("ROUTES-API: Registering EVENT routes.")
+
+	api.GET("/events", s.eventsHandler.StreamEventsHandler)
+
+	log.Info("ROUTES-API: Registering REGISTRY routes.")
+
+	registries := api.Group("/registries")
+	registries.GET("/", s.registriesHandler.ListRegistriesHandler)
+	registries.POST("/", s.registriesHandler.CreateRegistryHandler)
+	registries.GET("/:server", s.registriesHandler.GetRegistryHandler)
+	registries.PUT("/:server", s.registriesHandler.UpdateRegistryHandler)
+	registries.DELETE("/:server", s.registriesHandler.DeleteRegistryHandler)
+	registries.POST("/:server/login", s.registriesHandler.LoginRegistryHandler)
+
+	log.Info("ROUTES-API: Registering TEMPLATE routes.")
+
+	templates := api.Group("/templates")
+	templates.GET("/", s.templatesHandler.ListTemplatesHandler)
+	templates.POST("/", s.templatesHandler.CreateTemplateHandler)
+	templates.GET("/export", s.templatesHandler.ExportTemplatesHandler)
+	templates.POST("/import", s.templatesHandler.ImportTemplatesHandler)
+	templates.GET("/:id", s.templatesHandler.GetTemplateHandler)
+	templates.PUT("/:id", s.templatesHandler.UpdateTemplateHandler)
+	templates.DELETE("/:id", s.templatesHandler.DeleteTemplateHandler)
+	templates.GET("/:id/export", s.templatesHandler.ExportTemplateHandler)
+	templates.POST("/:id/instantiate", s.templatesHandler.InstantiateTemplateHandler)
+
+	log.Info("ROUTES-API: Registering STACK routes.")
+
+	stacks := api.Group("/stacks")
+	stacks.GET("/", s.stacksHandler.ListStacksHandler)
+	stacks.POST("/", s.stacksHandler.CreateStackHandler)
+	stacks.GET("/:name", s.stacksHandler.GetStackHandler)
+	stacks.PUT("/:name", s.stacksHandler.UpdateStackHandler)
+	stacks.DELETE("/:name", s.stacksHandler.DeleteStackHandler)
+	stacks.POST("/:name/up", s.stacksHandler.UpStackHandler)
+	stacks.POST("/:name/down", s.stacksHandler.DownStackHandler)
+	stacks.POST("/:name/restart", s.stacksHandler.RestartStackHandler)
+	stacks.GET("/:name/status", s.stacksHandler.StackStatusHandler)
+	// SSE
+	stacks.GET("/:name/logs", s.stacksHandler.StreamStackLogsHandler)
+
+	log.Info("ROUTES-API: Registering PROJECT routes.")
+
+	projects := api.Group("/projects")
+	projects.GET("/", s.projectsHandler.ListProjectsHandler)
+	projects.GET("/:name", s.projectsHandler.GetProjectHandler)
+	projects.DELETE("/:name", s.projectsHandler.RemoveProjectHandler)
+	projects.POST("/:name/start", s.projectsHandler.StartProjectHandler)
+	projects.POST("/:name/stop", s.projectsHandler.StopProjectHandler)
+	projects.POST("/:name/restart", s.projectsHandler.RestartProjectHandler)
+
 	return e
 }
 
@@ -71,3 +200,18 @@ func (s *Server) HelloWorldHandler(c echo.Context) error {
 func (s *Server) healthHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, s.db.Health())
 }
+
+// ipExtractor takes the client IP from the X-Forwarded-For header when the
+// call comes from one of the trusted proxies, and from the peer otherwise.
+func ipExtractor(trustedProxies []*net.IPNet) echo.IPExtractor {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, network := range trustedProxies {
+		options = append(options, echo.TrustIPRange(network))
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...)
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"encoding/json"
 	"github.com/labstack/echo/v4"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
@@ -37,3 +38,21 @@ func TestHandler(t *testing.T) {
 		return
 	}
 }
+
+func TestIPExtractor(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.1.2.3:41000"
+	req.Header.Set(echo.HeaderXForwardedFor, "198.51.100.4")
+
+	if ip := ipExtractor(nil)(req); ip != "10.1.2.3" {
+		t.Errorf("ip without proxies = %s, want the peer", ip)
+	}
+	_, proxy, _ := net.ParseCIDR("10.0.0.0/8")
+	if ip := ipExtractor([]*net.IPNet{proxy})(req); ip != "198.51.100.4" {
+		t.Errorf("ip from a trusted proxy = %s, want the forwarded one", ip)
+	}
+	req.RemoteAddr = "203.0.113.7:41000"
+	if ip := ipExtractor([]*net.IPNet{proxy})(req); ip != "203.0.113.7" {
+		t.Errorf("ip from another peer = %s, want the peer", ip)
+	}
+}
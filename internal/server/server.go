@@ -3,9 +3,12 @@ package server
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/log"
@@ -13,13 +16,28 @@ import (
 
 	"mineServers/internal/database"
 	"mineServers/internal/server/handlers"
+	"mineServers/internal/service"
 )
 
 type Server struct {
 	port              int
 	ctx               context.Context
 	db                database.Service
+	hosts             *service.HostManager
+	audit             *service.AuditLog
+	trustedProxies    []*net.IPNet
 	containersHandler *handlers.ContainerHandler
+	hostsHandler      *handlers.HostHandler
+	imagesHandler     *handlers.ImageHandler
+	registriesHandler *handlers.RegistryHandler
+	templatesHandler  *handlers.TemplateHandler
+	stacksHandler     *handlers.StackHandler
+	projectsHandler   *handlers.ProjectHandler
+	backupsHandler    *handlers.BackupHandler
+	schedulesHandler  *handlers.ScheduleHandler
+	eventsHandler     *handlers.EventHandler
+	auditHandler      *handlers.AuditHandler
+	statsHandler      *handlers.StatsHandler
 }
 
 func NewServer() *http.Server {
@@ -31,6 +49,75 @@ func NewServer() *http.Server {
 		db:   database.New(),
 	}
 
+	// A single Docker client is shared by every request
+	cli, err := service.NewDockerClient()
+	if err != nil {
+		log.Fatalf("SERVER: Unable to create docker client due: %s", err)
+	}
+	containerSvc := service.NewContainerService(ctx, cli)
+	NewServer.hosts = service.NewHostManager(ctx, containerSvc, NewServer.db, nil)
+
+	// Registry secrets are encrypted with SECRET_KEY, or a key generated next to the database
+	key, err := service.LoadSecretKey(os.Getenv("SECRET_KEY"), filepath.Join(filepath.Dir(os.Getenv("BLUEPRINT_DB_URL")), "secret.key"))
+	if err != nil {
+		log.Fatalf("SERVER: Unable to load secret key due: %s", err)
+	}
+	box, err := service.NewSecretBox(key)
+	if err != nil {
+		log.Fatalf("SERVER: Unable to create secret box due: %s", err)
+	}
+	registries := service.NewRegistryManager(NewServer.db, box)
+
+	pulls := service.NewPullManager(ctx, registries.AuthFor)
+	NewServer.containersHandler = handlers.NewContainerHandler(NewServer.hosts, pulls)
+	NewServer.hostsHandler = handlers.NewHostHandler(NewServer.hosts)
+	NewServer.imagesHandler = handlers.NewImageHandler(NewServer.hosts, pulls)
+	NewServer.registriesHandler = handlers.NewRegistryHandler(NewServer.hosts, registries)
+	NewServer.templatesHandler = handlers.NewTemplateHandler(NewServer.hosts, pulls, service.NewTemplateManager(NewServer.db))
+	NewServer.stacksHandler = handlers.NewStackHandler(service.NewStackManager(NewServer.db, NewServer.hosts, pulls))
+	NewServer.projectsHandler = handlers.NewProjectHandler(NewServer.hosts)
+
+	// Backup archives are stored in BACKUP_DIR, or a directory next to the database
+	backupDir := os.Getenv("BACKUP_DIR")
+	if backupDir == "" {
+		backupDir = filepath.Join(filepath.Dir(os.Getenv("BLUEPRINT_DB_URL")), "backups")
+	}
+	backups := service.NewBackupManager(ctx, NewServer.db, NewServer.hosts, backupDir)
+	if err := backups.Start(ctx); err != nil {
+		log.Fatalf("SERVER: Unable to start backup schedules due: %s", err)
+	}
+	NewServer.backupsHandler = handlers.NewBackupHandler(backups)
+
+	schedules := service.NewActionScheduleManager(ctx, NewServer.db, NewServer.hosts, pulls)
+	if err := schedules.Start(ctx); err != nil {
+		log.Fatalf("SERVER: Unable to start container schedules due: %s", err)
+	}
+	NewServer.schedulesHandler = handlers.NewScheduleHandler(schedules)
+
+	// Events are kept for EVENT_RETENTION, a duration such as 720h, or forever when it is 0
+	history := service.NewEventRecorder(NewServer.db, NewServer.hosts, envDuration("EVENT_RETENTION", service.DefaultEventRetention))
+	history.Start(ctx)
+	NewServer.eventsHandler = handlers.NewEventHandler(NewServer.hosts, history)
+
+	// Stats are sampled every STATS_INTERVAL and every resolution is kept for its STATS_RETENTION_*
+	statsConfig := service.StatsHistoryConfig{
+		Interval:        envDuration("STATS_INTERVAL", service.DefaultStatsHistoryConfig.Interval),
+		RawRetention:    envDuration("STATS_RETENTION_RAW", service.DefaultStatsHistoryConfig.RawRetention),
+		MinuteRetention: envDuration("STATS_RETENTION_1M", service.DefaultStatsHistoryConfig.MinuteRetention),
+		HourRetention:   envDuration("STATS_RETENTION_1H", service.DefaultStatsHistoryConfig.HourRetention),
+	}
+	if statsConfig.Interval <= 0 {
+		log.Fatalf("SERVER: STATS_INTERVAL must be positive")
+	}
+	stats := service.NewStatsCollector(NewServer.db, NewServer.hosts, statsConfig)
+	stats.Start(ctx)
+	NewServer.statsHandler = handlers.NewStatsHandler(stats)
+
+	// Client IPs and audit actors are only taken from the headers of the proxies in TRUSTED_PROXIES
+	NewServer.trustedProxies = envNetworks("TRUSTED_PROXIES")
+	NewServer.audit = service.NewAuditLog(NewServer.db)
+	NewServer.auditHandler = handlers.NewAuditHandler(NewServer.audit)
+
 	// Declare Server config
 	log.Infof("SERVER: Running at port :%d", NewServer.port)
 	server := &http.Server{
@@ -40,6 +127,66 @@ func NewServer() *http.Server {
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 30 * time.Second,
 	}
+	server.RegisterOnShutdown(func() {
+		if err := backups.Close(); err != nil {
+			log.Warnf("SERVER: Unable to stop backup schedules due: %s", err)
+		}
+		if err := schedules.Close(); err != nil {
+			log.Warnf("SERVER: Unable to stop container schedules due: %s", err)
+		}
+		if err := stats.Close(); err != nil {
+			log.Warnf("SERVER: Unable to stop stats sampling due: %s", err)
+		}
+		if err := history.Close(); err != nil {
+			log.Warnf("SERVER: Unable to stop event recording due: %s", err)
+		}
+		if err := NewServer.hosts.Close(); err != nil {
+			log.Warnf("SERVER: Unable to close docker clients due: %s", err)
+		}
+	})
 
 	return server
 }
+
+// envDuration reads a duration such as 90s or 720h from the environment
+// variable, returning def when it is unset.
+func envDuration(name string, def time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("SERVER: Unable to parse %s due: %s", name, err)
+	}
+
+	return d
+}
+
+// envNetworks reads a comma-separated list of CIDR ranges or IP addresses
+// such as 10.0.0.0/8,127.0.0.1 from the environment variable.
+func envNetworks(name string) []*net.IPNet {
+	var networks []*net.IPNet
+	for _, value := range strings.Split(os.Getenv(name), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if ip := net.ParseIP(value); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			log.Fatalf("SERVER: Unable to parse %s due: %s", name, err)
+		}
+		networks = append(networks, network)
+	}
+
+	return networks
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+var ErrReadOnlyAttach = errors.New("the session is read-only")
+
+// AttachSession is attached to the main process of a container. Reads
+// return its combined stdout and stderr and writes go to its stdin, unless
+// the session is read-only.
+type AttachSession struct {
+	ContainerID string
+	Name        string
+	ReadOnly    bool
+
+	svc      *ContainerService
+	tty      bool
+	hijacked types.HijackedResponse
+	output   io.Reader
+}
+
+// AttachContainer attaches to the main process of the running container.
+// Writing requires a container created with stdin open, observers attach
+// read-only.
+func (c *ContainerService) AttachContainer(ctx context.Context, id string, readOnly bool) (*AttachSession, error) {
+	info, err := c.InspectContainer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimPrefix(info.Name, "/")
+	if !info.State.Running {
+		return nil, errdefs.Conflict(fmt.Errorf("container %s is not running", name))
+	}
+	if !readOnly && !info.Config.OpenStdin {
+		return nil, errdefs.Conflict(fmt.Errorf("container %s was not created with stdin open, attach read-only instead", name))
+	}
+
+	hijacked, err := c.cli.ContainerAttach(ctx, info.ID, container.AttachOptions{
+		Stream: true,
+		Stdin:  !readOnly,
+		Stdout: true,
+		Stderr: true,
+	})
+	if err != nil {
+		log.Warnf("CONTAINER-ATTACH: Unable to attach to container '%s' due: %s", name, err)
+		return nil, err
+	}
+
+	s := &AttachSession{
+		ContainerID: info.ID,
+		Name:        name,
+		ReadOnly:    readOnly,
+		svc:         c,
+		tty:         info.Config.Tty,
+		hijacked:    hijacked,
+		output:      hijacked.Reader,
+	}
+	// Without a TTY stdout and stderr are multiplexed, they are merged back.
+	if !s.tty {
+		pr, pw := io.Pipe()
+		go func() {
+			_, err := stdcopy.StdCopy(pw, pw, hijacked.Reader)
+			pw.CloseWithError(err)
+		}()
+		s.output = pr
+	}
+	log.Infof("CONTAINER-ATTACH: Attached to container '%s' (read-only: %t)", name, readOnly)
+
+	return s, nil
+}
+
+func (s *AttachSession) Read(p []byte) (int, error) {
+	return s.output.Read(p)
+}
+
+func (s *AttachSession) Write(p []byte) (int, error) {
+	if s.ReadOnly {
+		return 0, ErrReadOnlyAttach
+	}
+
+	return s.hijacked.Conn.Write(p)
+}
+
+// Resize changes the size of the TTY of the container.
+func (s *AttachSession) Resize(ctx context.Context, rows, cols uint) error {
+	v := &ValidationError{}
+	switch {
+	case !s.tty:
+		v.add("size", "the container has no TTY")
+	case rows == 0 || cols == 0 || rows > maxTerminalSize || cols > maxTerminalSize:
+		v.add("size", "rows and cols must be between 1 and %d", maxTerminalSize)
+	}
+	if err := v.err(); err != nil {
+		return err
+	}
+
+	return s.svc.cli.ContainerResize(ctx, s.ContainerID, container.ResizeOptions{Height: rows, Width: cols})
+}
+
+// ExitCode waits briefly for the container to stop once its output ended and
+// returns its exit code, -1 if it is still running.
+func (s *AttachSession) ExitCode(ctx context.Context) (int, error) {
+	for range execExitPolls {
+		info, err := s.svc.InspectContainer(ctx, s.ContainerID)
+		if err != nil {
+			return -1, err
+		}
+		if !info.State.Running {
+			return info.State.ExitCode, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return -1, ctx.Err()
+		case <-time.After(execExitInterval):
+		}
+	}
+
+	return -1, nil
+}
+
+// Close detaches from the container. The input of the process is left open
+// so it keeps running.
+func (s *AttachSession) Close() error {
+	s.hijacked.Close()
+	log.Infof("CONTAINER-ATTACH: Detached from container '%s'", s.Name)
+	return nil
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/errdefs"
+)
+
+func TestContainerService_AttachContainer(t *testing.T) {
+	svc, _, _, id := newTestRecreate(t)
+	ctx := context.Background()
+
+	if _, err := svc.AttachContainer(ctx, id, false); !errdefs.IsConflict(err) {
+		t.Errorf("expected a conflict without stdin open, got %v", err)
+	}
+
+	session, err := svc.AttachContainer(ctx, id, true)
+	if err != nil {
+		t.Fatalf("AttachContainer() error = %v", err)
+	}
+	if _, err := session.Write([]byte("stop\n")); !errors.Is(err, ErrReadOnlyAttach) {
+		t.Errorf("expected a read-only session to refuse input, got %v", err)
+	}
+	var verr *ValidationError
+	if err := session.Resize(ctx, 24, 80); !errors.As(err, &verr) {
+		t.Errorf("expected resizing a container without TTY to be rejected, got %v", err)
+	}
+	session.Close()
+
+	svc.ChangeContainerState(ctx, id, ActionStop, nil)
+	if _, err := svc.AttachContainer(ctx, id, true); !errdefs.IsConflict(err) {
+		t.Errorf("expected a conflict on a stopped container, got %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"mineServers/internal/database"
+	"mineServers/internal/models"
+
+	"github.com/charmbracelet/log"
+	"gopkg.in/yaml.v3"
+)
+
+// Results of an audited call.
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+const (
+	// AuditAnonymous is the actor of the calls made without a user.
+	AuditAnonymous = "anonymous"
+	// FormatCSV is the audit log export format besides FormatJSON.
+	FormatCSV = "csv"
+
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+	redactedValue     = "[REDACTED]"
+)
+
+var (
+	// sensitiveKeyRegex matches the fields and environment variables whose
+	// values are redacted from the audited payloads. Auth only matches as a
+	// whole word of the key, so that fields such as author are kept.
+	sensitiveKeyRegex = regexp.MustCompile(`(?i)(passw|pwd|secret|token|credential|private|api_?key|access_?key|authorization|(^|[_.-])auth([_.-]|$))`)
+	envEntryRegex     = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_.-]*)=`)
+)
+
+// AuditLog records the mutating API calls and queries them.
+type AuditLog struct {
+	store database.AuditStore
+}
+
+func NewAuditLog(store database.AuditStore) *AuditLog {
+	return &AuditLog{
+		store: store,
+	}
+}
+
+// Record stores an entry. Failures are only logged so the audited call is
+// not affected.
+func (a *AuditLog) Record(ctx context.Context, entry *models.AuditEntry) {
+	if err := a.store.CreateAuditEntry(ctx, entry); err != nil {
+		log.Warnf("AUDIT: Unable to record %s %s due: %s", entry.Method, entry.Path, err)
+	}
+}
+
+// List returns a page of the entries matching the query, newest first.
+func (a *AuditLog) List(ctx context.Context, q *models.AuditQuery) (*models.AuditPage, error) {
+	filter, err := auditFilter(q)
+	if err != nil {
+		return nil, err
+	}
+
+	// One more entry tells whether there is a next page.
+	limit := filter.Limit
+	filter.Limit++
+	entries, err := a.store.ListAuditEntries(ctx, filter)
+	if err != nil {
+		log.Warnf("AUDIT: Unable to list the audit log due: %s", err)
+		return nil, err
+	}
+
+	page := &models.AuditPage{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		page.Next = strconv.FormatInt(entries[limit-1].ID, 10)
+	}
+
+	return page, nil
+}
+
+// Export calls fn with every entry matching the query, newest first,
+// ignoring its limit.
+func (a *AuditLog) Export(ctx context.Context, q *models.AuditQuery, fn func(models.AuditEntry) error) error {
+	query := *q
+	query.Limit = maxAuditLimit
+	for {
+		page, err := a.List(ctx, &query)
+		if err != nil {
+			return err
+		}
+		for _, entry := range page.Entries {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+		if page.Next == "" {
+			return nil
+		}
+		query.Before = page.Next
+	}
+}
+
+// ValidateAuditQuery reports the invalid fields of the query.
+func ValidateAuditQuery(q *models.AuditQuery) error {
+	_, err := auditFilter(q)
+	return err
+}
+
+func auditFilter(q *models.AuditQuery) (*database.AuditFilter, error) {
+	filter := &database.AuditFilter{
+		Actor:  q.Actor,
+		Method: strings.ToUpper(q.Method),
+		Target: q.Target,
+		Host:   q.Host,
+		Result: q.Result,
+		Limit:  defaultAuditLimit,
+	}
+
+	v := &ValidationError{}
+	if q.Result != "" && q.Result != AuditSuccess && q.Result != AuditFailure {
+		v.add("result", "must be %s or %s", AuditSuccess, AuditFailure)
+	}
+	if q.From != "" {
+		from, err := time.Parse(time.RFC3339, q.From)
+		if err != nil {
+			v.add("from", "must be an RFC 3339 time")
+		}
+		filter.From = from
+	}
+	if q.To != "" {
+		to, err := time.Parse(time.RFC3339, q.To)
+		if err != nil {
+			v.add("to", "must be an RFC 3339 time")
+		}
+		filter.To = to
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		v.add("to", "must be after from")
+	}
+	if q.Before != "" {
+		before, err := strconv.ParseInt(q.Before, 10, 64)
+		if err != nil || before <= 0 {
+			v.add("before", "must be the next cursor of an audit page")
+		}
+		filter.Before = before
+	}
+	switch {
+	case q.Limit < 0 || q.Limit > maxAuditLimit:
+		v.add("limit", "must be between 1 and %d", maxAuditLimit)
+	case q.Limit > 0:
+		filter.Limit = q.Limit
+	}
+
+	return filter, v.err()
+}
+
+// RedactPayload returns a JSON body with the values of its sensitive
+// fields and environment variables replaced, or nil when it is not JSON.
+// The compose files of stacks are redacted the same way.
+func RedactPayload(body []byte) json.RawMessage {
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil
+	}
+
+	data, err := json.Marshal(redactValue("", value))
+	if err != nil {
+		return nil
+	}
+
+	return data
+}
+
+func redactValue(key string, value any) any {
+	if key != "" && sensitiveKeyRegex.MatchString(key) {
+		if value == nil || value == "" {
+			return value
+		}
+		return redactedValue
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for k, item := range v {
+			if compose, ok := item.(string); ok && k == "compose" {
+				v[k] = redactCompose(compose)
+				continue
+			}
+			v[k] = redactValue(k, item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue("", item)
+		}
+	case string:
+		// Environment variables, such as "MYSQL_ROOT_PASSWORD=secret".
+		if m := envEntryRegex.FindStringSubmatch(v); m != nil && sensitiveKeyRegex.MatchString(m[1]) {
+			return m[0] + redactedValue
+		}
+	}
+
+	return value
+}
+
+// redactCompose redacts the sensitive values of a compose file, such as the
+// environment of its services, or the whole file when it cannot be parsed.
+func redactCompose(compose string) string {
+	if strings.TrimSpace(compose) == "" {
+		return compose
+	}
+
+	var value any
+	if err := yaml.Unmarshal([]byte(compose), &value); err != nil {
+		return redactedValue
+	}
+
+	data, err := yaml.Marshal(redactValue("", value))
+	if err != nil {
+		return redactedValue
+	}
+
+	return string(data)
+}
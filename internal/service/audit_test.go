@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"mineServers/internal/models"
+)
+
+func TestRedactPayload(t *testing.T) {
+	body := `{"name":"db","image":"mysql:8","env":["MYSQL_ROOT_PASSWORD=hunter2","TZ=UTC"],"password":"s3cret","registry":{"identity_token":"abc","username":"bob"},"labels":{"api_key":"k","tier":"data"},"token":""}`
+	want := `{"env":["MYSQL_ROOT_PASSWORD=[REDACTED]","TZ=UTC"],"image":"mysql:8","labels":{"api_key":"[REDACTED]","tier":"data"},"name":"db","password":"[REDACTED]","registry":{"identity_token":"[REDACTED]","username":"bob"},"token":""}`
+	if got := string(RedactPayload([]byte(body))); got != want {
+		t.Fatalf("RedactPayload() = %s, want %s", got, want)
+	}
+
+	// Only whole words of the keys are taken for auth.
+	body = `{"message":"Snapshot","author":"bob","registry_auth":"abc","Authorization":"Basic Ym9i"}`
+	want = `{"Authorization":"[REDACTED]","author":"bob","message":"Snapshot","registry_auth":"[REDACTED]"}`
+	if got := string(RedactPayload([]byte(body))); got != want {
+		t.Fatalf("RedactPayload() = %s, want %s", got, want)
+	}
+
+	body = `{"name":"shop","compose":"services:\n  db:\n    image: mysql:8\n    environment:\n      MYSQL_ROOT_PASSWORD: hunter2\n      TZ: UTC\n  web:\n    image: nginx\n    environment:\n      - API_TOKEN=abc\n"}`
+	var stack models.StackRequest
+	if err := json.Unmarshal(RedactPayload([]byte(body)), &stack); err != nil {
+		t.Fatalf("RedactPayload(stack) error = %v", err)
+	}
+	if strings.Contains(stack.Compose, "hunter2") || strings.Contains(stack.Compose, "abc") ||
+		!strings.Contains(stack.Compose, "MYSQL_ROOT_PASSWORD: '[REDACTED]'") || !strings.Contains(stack.Compose, "TZ: UTC") || !strings.Contains(stack.Compose, "API_TOKEN=[REDACTED]") {
+		t.Fatalf("redacted compose = %s", stack.Compose)
+	}
+	if got := string(RedactPayload([]byte(`{"compose":"services: [unclosed"}`))); got != `{"compose":"[REDACTED]"}` {
+		t.Fatalf("RedactPayload(invalid compose) = %s", got)
+	}
+
+	if got := RedactPayload([]byte("services: {}")); got != nil {
+		t.Fatalf("RedactPayload(yaml) = %s, want nil", got)
+	}
+}
+
+func TestAuditLog_ListPages(t *testing.T) {
+	db := openTestDB(t)
+
+	audit := NewAuditLog(db)
+	ctx := context.Background()
+	for _, target := range []string{"a", "b", "c"} {
+		audit.Record(ctx, &models.AuditEntry{Actor: AuditAnonymous, Method: "POST", Route: "/api/containers/:id/start", Path: "/api/containers/" + target + "/start", Target: target, Status: 204, Result: AuditSuccess})
+	}
+
+	page, err := audit.List(ctx, &models.AuditQuery{Method: "post", Limit: 2})
+	if err != nil || len(page.Entries) != 2 || page.Entries[0].Target != "c" || page.Next == "" {
+		t.Fatalf("List() = %+v, %v", page, err)
+	}
+	page, err = audit.List(ctx, &models.AuditQuery{Limit: 2, Before: page.Next})
+	if err != nil || len(page.Entries) != 1 || page.Entries[0].Target != "a" || page.Next != "" {
+		t.Fatalf("List(before) = %+v, %v", page, err)
+	}
+
+	var exported []string
+	if err := audit.Export(ctx, &models.AuditQuery{Limit: 1}, func(entry models.AuditEntry) error {
+		exported = append(exported, entry.Target)
+		return nil
+	}); err != nil || len(exported) != 3 {
+		t.Fatalf("Export() = %v, %v", exported, err)
+	}
+
+	var verr *ValidationError
+	for _, q := range []models.AuditQuery{{Result: "ok"}, {From: "yesterday"}, {Limit: 5000}, {Before: "x"}} {
+		if _, err := audit.List(ctx, &q); !errors.As(err, &verr) {
+			t.Errorf("List(%+v) error = %v, want ValidationError", q, err)
+		}
+	}
+}
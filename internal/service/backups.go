@@ -0,0 +1,858 @@
+package service
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"mineServers/internal/database"
+	"mineServers/internal/models"
+
+	"github.com/charmbracelet/log"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/robfig/cron/v3"
+)
+
+// States of a backup.
+const (
+	BackupRunning   = "running"
+	BackupCompleted = "completed"
+	BackupFailed    = "failed"
+)
+
+// Modes keeping a container consistent while its mounts are archived or
+// restored.
+const (
+	QuiesceNone  = "none"
+	QuiescePause = "pause"
+	QuiesceStop  = "stop"
+)
+
+// restoreHelperImage runs the containers mounting the volumes backups are
+// restored into when no container of the backup mounts them. The helpers
+// are created but never started.
+const restoreHelperImage = "docker.io/library/busybox:latest"
+
+// restoreTarget is where helper containers mount the restored volume.
+const restoreTarget = "/restore"
+
+var (
+	ErrBackupNotFound         = errors.New("backup not found")
+	ErrBackupScheduleNotFound = errors.New("backup schedule not found")
+	ErrBackupInProgress       = errors.New("a backup or restore of the container is in progress")
+	ErrBackupUnavailable      = errors.New("backup cannot be restored")
+	ErrBackupFailed           = errors.New("backup failed")
+)
+
+// BackupManager archives the volumes and bind mounts of containers into
+// compressed tar files of a local directory, restores them and runs the
+// backup schedules.
+type BackupManager struct {
+	ctx   context.Context
+	store database.BackupStore
+	hosts *HostManager
+	dir   string
+
+	mu sync.Mutex
+	// busy holds the containers, as host/ID, being backed up or restored.
+	busy    map[string]bool
+	cron    *cron.Cron
+	entries map[int64]cron.EntryID
+}
+
+// NewBackupManager creates a manager storing archives under dir. Schedules
+// run with ctx once Start is called.
+func NewBackupManager(ctx context.Context, store database.BackupStore, hosts *HostManager, dir string) *BackupManager {
+	return &BackupManager{
+		ctx:     ctx,
+		store:   store,
+		hosts:   hosts,
+		dir:     dir,
+		busy:    make(map[string]bool),
+		cron:    cron.New(cron.WithParser(cronParser), cron.WithLocation(time.UTC)),
+		entries: make(map[int64]cron.EntryID),
+	}
+}
+
+// Start registers the stored schedules and starts running them.
+func (m *BackupManager) Start(ctx context.Context) error {
+	schedules, err := m.store.ListBackupSchedules(ctx)
+	if err != nil {
+		log.Warnf("BACKUPS: Unable to load backup schedules due: %s", err)
+		return err
+	}
+
+	for i := range schedules {
+		if err := m.register(&schedules[i]); err != nil {
+			log.Warnf("BACKUPS: Unable to register backup schedule %d due: %s", schedules[i].ID, err)
+		}
+	}
+	m.cron.Start()
+	log.Infof("BACKUPS: %d backup schedules loaded, archives stored in '%s'", len(schedules), m.dir)
+
+	return nil
+}
+
+// Close stops the schedules, waiting for the running backups.
+func (m *BackupManager) Close() error {
+	<-m.cron.Stop().Done()
+	return nil
+}
+
+// BackupContainer archives the selected mounts of the container, one backup
+// per mount. It fails with ErrBackupFailed, along with the records, when a
+// mount could not be archived.
+func (m *BackupManager) BackupContainer(ctx context.Context, host, id string, req *models.BackupRequest) ([]models.Backup, error) {
+	return m.backup(ctx, host, id, req.Mounts, req.Mode, nil)
+}
+
+func (m *BackupManager) ListBackups(ctx context.Context, host, container string) ([]models.Backup, error) {
+	backups, err := m.store.ListBackups(ctx, host, container)
+	if err != nil {
+		log.Warnf("BACKUPS: Unable to list backups due: %s", err)
+		return nil, err
+	}
+
+	return backups, nil
+}
+
+func (m *BackupManager) GetBackup(ctx context.Context, id int64) (*models.Backup, error) {
+	backup, err := m.store.GetBackup(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return nil, ErrBackupNotFound
+		}
+		log.Warnf("BACKUPS: Unable to get backup %d due: %s", id, err)
+		return nil, err
+	}
+
+	return backup, nil
+}
+
+// OpenBackup opens the archive of a completed backup.
+func (m *BackupManager) OpenBackup(ctx context.Context, id int64) (*os.File, *models.Backup, error) {
+	backup, err := m.GetBackup(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if backup.Status != BackupCompleted {
+		return nil, nil, fmt.Errorf("%w: it is %s", ErrBackupUnavailable, backup.Status)
+	}
+
+	f, err := os.Open(filepath.Join(m.dir, backup.File))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, fmt.Errorf("%w: its archive is missing", ErrBackupUnavailable)
+		}
+		return nil, nil, err
+	}
+
+	return f, backup, nil
+}
+
+// DeleteBackup removes the archive and the record of a backup.
+func (m *BackupManager) DeleteBackup(ctx context.Context, id int64) error {
+	backup, err := m.GetBackup(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(filepath.Join(m.dir, backup.File)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Warnf("BACKUPS: Unable to remove archive of backup %d due: %s", id, err)
+		return err
+	}
+	if err := m.store.DeleteBackup(ctx, id); err != nil {
+		log.Warnf("BACKUPS: Unable to delete backup %d due: %s", id, err)
+		return err
+	}
+	log.Infof("BACKUPS: Backup %d deleted", id)
+
+	return nil
+}
+
+// RestoreBackup extracts a backup into the mount it was taken from, found
+// through the container ID or, once recreated, its name. With req.Volume
+// set it is extracted into that volume through a helper container instead.
+// Files of the mount missing from the backup are kept.
+func (m *BackupManager) RestoreBackup(ctx context.Context, id int64, req *models.RestoreRequest) (*models.RestoreResult, error) {
+	v := &ValidationError{}
+	mode := validateQuiesceMode(v, req.Mode)
+	if req.Volume != "" && !volumeNameRegex.MatchString(req.Volume) {
+		v.add("volume", "must match %s", volumeNameRegex)
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	f, backup, err := m.OpenBackup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err := verifyChecksum(f, backup.Checksum); err != nil {
+		log.Warnf("BACKUPS: Backup %d is corrupted: %s", id, err)
+		return nil, err
+	}
+
+	svc, err := m.hosts.Resolve(ctx, backup.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	result := &models.RestoreResult{BackupID: backup.ID}
+	if req.Volume != "" {
+		if err := restoreIntoVolume(ctx, svc, req.Volume, f); err != nil {
+			log.Warnf("BACKUPS: Unable to restore backup %d into volume '%s' due: %s", id, req.Volume, err)
+			return nil, err
+		}
+		result.Volume = req.Volume
+	} else {
+		info, err := svc.InspectContainer(ctx, backup.ContainerID)
+		if errdefs.IsNotFound(err) {
+			info, err = svc.InspectContainer(ctx, backup.ContainerName)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		unlock, err := m.lock(backup.Host, info.ID)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+		resume, err := quiesce(ctx, svc, info, mode)
+		if err != nil {
+			return nil, err
+		}
+		// Archives of a file mount are extracted next to the file.
+		target := backup.Destination
+		if stat, err := svc.cli.ContainerStatPath(ctx, info.ID, target); err == nil && !stat.Mode.IsDir() {
+			target = path.Dir(target)
+		}
+		err = svc.cli.CopyToContainer(ctx, info.ID, target, f, container.CopyToContainerOptions{CopyUIDGID: true})
+		resume()
+		if err != nil {
+			log.Warnf("BACKUPS: Unable to restore backup %d into container '%s' due: %s", id, backup.ContainerName, err)
+			return nil, err
+		}
+
+		result.Container = strings.TrimPrefix(info.Name, "/")
+		result.Destination = backup.Destination
+		if backup.MountType == string(mount.TypeVolume) {
+			result.Volume = backup.Source
+		}
+	}
+	result.DurationMs = time.Since(start).Milliseconds()
+	log.Infof("BACKUPS: Backup %d restored in %s", id, time.Since(start).Round(time.Millisecond))
+
+	return result, nil
+}
+
+func (m *BackupManager) ListSchedules(ctx context.Context) ([]models.BackupSchedule, error) {
+	schedules, err := m.store.ListBackupSchedules(ctx)
+	if err != nil {
+		log.Warnf("BACKUPS: Unable to list backup schedules due: %s", err)
+		return nil, err
+	}
+
+	for i := range schedules {
+		setNextRun(&schedules[i])
+	}
+
+	return schedules, nil
+}
+
+func (m *BackupManager) GetSchedule(ctx context.Context, id int64) (*models.BackupSchedule, error) {
+	schedule, err := m.store.GetBackupSchedule(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return nil, ErrBackupScheduleNotFound
+		}
+		log.Warnf("BACKUPS: Unable to get backup schedule %d due: %s", id, err)
+		return nil, err
+	}
+	setNextRun(schedule)
+
+	return schedule, nil
+}
+
+// CreateSchedule stores and registers a schedule backing up a container of
+// host, which must exist.
+func (m *BackupManager) CreateSchedule(ctx context.Context, host string, def *models.BackupScheduleDefinition) (*models.BackupSchedule, error) {
+	if host == "" {
+		host = LocalHost
+	}
+	if err := m.validateSchedule(ctx, host, def); err != nil {
+		return nil, err
+	}
+
+	schedule := &models.BackupSchedule{Host: host, BackupScheduleDefinition: *def}
+	if err := m.store.CreateBackupSchedule(ctx, schedule); err != nil {
+		log.Warnf("BACKUPS: Unable to create backup schedule due: %s", err)
+		return nil, err
+	}
+	if err := m.register(schedule); err != nil {
+		return nil, err
+	}
+	setNextRun(schedule)
+	log.Infof("BACKUPS: Backup schedule %d of container '%s' created", schedule.ID, schedule.Container)
+
+	return schedule, nil
+}
+
+// UpdateSchedule replaces the definition of a schedule.
+func (m *BackupManager) UpdateSchedule(ctx context.Context, id int64, def *models.BackupScheduleDefinition) (*models.BackupSchedule, error) {
+	schedule, err := m.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.validateSchedule(ctx, schedule.Host, def); err != nil {
+		return nil, err
+	}
+
+	schedule.BackupScheduleDefinition = *def
+	if err := m.store.UpdateBackupSchedule(ctx, schedule); err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return nil, ErrBackupScheduleNotFound
+		}
+		log.Warnf("BACKUPS: Unable to update backup schedule %d due: %s", id, err)
+		return nil, err
+	}
+	if err := m.register(schedule); err != nil {
+		return nil, err
+	}
+	setNextRun(schedule)
+	log.Infof("BACKUPS: Backup schedule %d updated", id)
+
+	return schedule, nil
+}
+
+// DeleteSchedule removes a schedule. The backups it made are kept.
+func (m *BackupManager) DeleteSchedule(ctx context.Context, id int64) error {
+	if err := m.store.DeleteBackupSchedule(ctx, id); err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return ErrBackupScheduleNotFound
+		}
+		log.Warnf("BACKUPS: Unable to delete backup schedule %d due: %s", id, err)
+		return err
+	}
+	m.unregister(id)
+	log.Infof("BACKUPS: Backup schedule %d deleted", id)
+
+	return nil
+}
+
+// RunSchedule runs a schedule right away, applying its retention policy
+// once its backups completed.
+func (m *BackupManager) RunSchedule(ctx context.Context, id int64) ([]models.Backup, error) {
+	schedule, err := m.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	backups, err := m.backup(ctx, schedule.Host, schedule.Container, schedule.Mounts, schedule.Mode, &schedule.ID)
+	status := BackupCompleted
+	if err != nil {
+		status = BackupFailed
+	}
+	if rerr := m.store.RecordBackupScheduleRun(context.WithoutCancel(ctx), id, start, status); rerr != nil {
+		log.Warnf("BACKUPS: Unable to record run of backup schedule %d due: %s", id, rerr)
+	}
+	if err != nil {
+		return backups, err
+	}
+
+	for _, backup := range backups {
+		m.prune(ctx, schedule, &backup)
+	}
+
+	return backups, nil
+}
+
+func (m *BackupManager) validateSchedule(ctx context.Context, host string, def *models.BackupScheduleDefinition) error {
+	v := &ValidationError{}
+	if def.Container == "" {
+		v.add("container", "is required")
+	}
+	if def.Cron == "" {
+		v.add("cron", "is required")
+	} else if _, err := ParseCron(def.Cron, def.Timezone); err != nil {
+		v.add("cron", "%s", err)
+	}
+	def.Mode = validateQuiesceMode(v, def.Mode)
+	if def.Retention.KeepLast < 0 || def.Retention.KeepDaily < 0 {
+		v.add("retention", "must not be negative")
+	}
+	if err := v.err(); err != nil {
+		return err
+	}
+
+	svc, err := m.hosts.Resolve(ctx, host)
+	if err != nil {
+		return err
+	}
+	info, err := svc.InspectContainer(ctx, def.Container)
+	if err != nil {
+		return err
+	}
+	if _, err := selectMounts(info.Mounts, def.Mounts); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// register (re)schedules the runs of a schedule, none when disabled.
+func (m *BackupManager) register(schedule *models.BackupSchedule) error {
+	m.unregister(schedule.ID)
+	if schedule.Disabled {
+		return nil
+	}
+
+	parsed, err := ParseCron(schedule.Cron, schedule.Timezone)
+	if err != nil {
+		return err
+	}
+
+	id := schedule.ID
+	m.mu.Lock()
+	m.entries[id] = m.cron.Schedule(parsed, cron.FuncJob(func() {
+		if _, err := m.RunSchedule(m.ctx, id); err != nil {
+			log.Warnf("BACKUPS: Scheduled backup %d failed due: %s", id, err)
+		}
+	}))
+	m.mu.Unlock()
+
+	return nil
+}
+
+func (m *BackupManager) unregister(id int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if entry, ok := m.entries[id]; ok {
+		m.cron.Remove(entry)
+		delete(m.entries, id)
+	}
+}
+
+func (m *BackupManager) backup(ctx context.Context, host, id string, selectors []string, mode string, scheduleID *int64) ([]models.Backup, error) {
+	if host == "" {
+		host = LocalHost
+	}
+	v := &ValidationError{}
+	mode = validateQuiesceMode(v, mode)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	svc, err := m.hosts.Resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	info, err := svc.InspectContainer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	mounts, err := selectMounts(info.Mounts, selectors)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := m.lock(host, info.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	resume, err := quiesce(ctx, svc, info, mode)
+	if err != nil {
+		return nil, err
+	}
+	defer resume()
+
+	name := strings.TrimPrefix(info.Name, "/")
+	var (
+		backups []models.Backup
+		failed  error
+	)
+	for _, point := range mounts {
+		backup := &models.Backup{
+			Host:          host,
+			ContainerID:   info.ID,
+			ContainerName: name,
+			ScheduleID:    scheduleID,
+			MountType:     string(point.Type),
+			Source:        mountSource(point),
+			Destination:   point.Destination,
+			Mode:          mode,
+			File:          backupFile(host, name, point.Destination, time.Now()),
+			Status:        BackupRunning,
+		}
+		if err := m.store.CreateBackup(ctx, backup); err != nil {
+			log.Warnf("BACKUPS: Unable to record backup of container '%s' due: %s", name, err)
+			return backups, err
+		}
+
+		start := time.Now()
+		size, checksum, err := archiveMount(ctx, svc, info.ID, point.Destination, filepath.Join(m.dir, backup.File))
+		backup.DurationMs = time.Since(start).Milliseconds()
+		if err != nil {
+			log.Warnf("BACKUPS: Unable to back up '%s' of container '%s' due: %s", point.Destination, name, err)
+			backup.Status, backup.Error = BackupFailed, err.Error()
+			failed = fmt.Errorf("%w: %s: %s", ErrBackupFailed, point.Destination, err)
+		} else {
+			backup.Status, backup.Size, backup.Checksum = BackupCompleted, size, checksum
+			log.Infof("BACKUPS: Backed up '%s' of container '%s' (%d bytes) in %dms", point.Destination, name, size, backup.DurationMs)
+		}
+		if err := m.store.FinishBackup(context.WithoutCancel(ctx), backup); err != nil {
+			log.Warnf("BACKUPS: Unable to record outcome of backup %d due: %s", backup.ID, err)
+		}
+		backups = append(backups, *backup)
+	}
+
+	return backups, failed
+}
+
+// prune applies the retention policy of the schedule to the completed
+// backups it took of the mount of last, leaving manual backups and the ones
+// of other schedules alone. The container is matched by the full ID last
+// was taken from, as the schedule may name it by a short ID.
+func (m *BackupManager) prune(ctx context.Context, schedule *models.BackupSchedule, last *models.Backup) {
+	backups, err := m.store.ListBackups(ctx, last.Host, last.ContainerID)
+	if err != nil {
+		log.Warnf("BACKUPS: Unable to list backups to prune due: %s", err)
+		return
+	}
+
+	var completed []models.Backup
+	for _, backup := range backups {
+		if backup.Status == BackupCompleted && backup.Destination == last.Destination &&
+			backup.ScheduleID != nil && *backup.ScheduleID == schedule.ID {
+			completed = append(completed, backup)
+		}
+	}
+
+	loc := time.UTC
+	if schedule.Timezone != "" {
+		loc, _ = time.LoadLocation(schedule.Timezone)
+	}
+	for _, backup := range expiredBackups(completed, schedule.Retention, time.Now(), loc) {
+		if err := m.DeleteBackup(ctx, backup.ID); err != nil {
+			log.Warnf("BACKUPS: Unable to prune backup %d due: %s", backup.ID, err)
+		}
+	}
+}
+
+// lock marks the container as busy until the returned function is called.
+func (m *BackupManager) lock(host, id string) (func(), error) {
+	key := host + "/" + id
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.busy[key] {
+		return nil, ErrBackupInProgress
+	}
+	m.busy[key] = true
+
+	return func() {
+		m.mu.Lock()
+		delete(m.busy, key)
+		m.mu.Unlock()
+	}, nil
+}
+
+// expiredBackups returns the backups, newest first, the retention policy
+// does not keep. Days are evaluated in loc.
+func expiredBackups(backups []models.Backup, policy models.RetentionPolicy, now time.Time, loc *time.Location) []models.Backup {
+	if policy.KeepLast == 0 && policy.KeepDaily == 0 {
+		return nil
+	}
+
+	now = now.In(loc)
+	since := time.Date(now.Year(), now.Month(), now.Day()-policy.KeepDaily+1, 0, 0, 0, 0, loc)
+	days := make(map[string]bool)
+
+	var expired []models.Backup
+	for i, backup := range backups {
+		keep := i < policy.KeepLast
+		if created := backup.CreatedAt.In(loc); policy.KeepDaily > 0 && !created.Before(since) {
+			if day := created.Format(time.DateOnly); !days[day] {
+				days[day] = true
+				keep = true
+			}
+		}
+		if !keep {
+			expired = append(expired, backup)
+		}
+	}
+
+	return expired
+}
+
+func setNextRun(schedule *models.BackupSchedule) {
+	schedule.NextRunAt = nextRun(schedule.Cron, schedule.Timezone, schedule.Disabled)
+}
+
+func validateQuiesceMode(v *ValidationError, mode string) string {
+	switch mode {
+	case "":
+		return QuiesceNone
+	case QuiesceNone, QuiescePause, QuiesceStop:
+		return mode
+	default:
+		v.add("mode", "must be one of %s, %s or %s", QuiesceNone, QuiescePause, QuiesceStop)
+		return mode
+	}
+}
+
+// selectMounts returns the volumes and bind mounts matching the selectors,
+// destinations or volume names, all of them without selectors.
+func selectMounts(mounts []container.MountPoint, selectors []string) ([]container.MountPoint, error) {
+	var candidates []container.MountPoint
+	for _, point := range mounts {
+		if point.Type == mount.TypeVolume || point.Type == mount.TypeBind {
+			candidates = append(candidates, point)
+		}
+	}
+
+	v := &ValidationError{}
+	if len(candidates) == 0 {
+		v.add("mounts", "the container has no volume or bind mount")
+		return nil, v.err()
+	}
+	if len(selectors) == 0 {
+		return candidates, nil
+	}
+
+	var selected []container.MountPoint
+	for _, selector := range selectors {
+		found := false
+		for _, point := range candidates {
+			if point.Destination == path.Clean(selector) || (point.Name != "" && point.Name == selector) {
+				selected = append(selected, point)
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.add("mounts", "%q is not a volume or bind mount of the container", selector)
+		}
+	}
+
+	return selected, v.err()
+}
+
+// quiesce pauses or stops the running container according to mode and
+// returns the function bringing it back.
+func quiesce(ctx context.Context, svc *ContainerService, info container.InspectResponse, mode string) (func(), error) {
+	if mode == QuiesceNone || info.State == nil || !info.State.Running || info.State.Paused {
+		return func() {}, nil
+	}
+
+	// The container is resumed even when the request was canceled.
+	bg := context.WithoutCancel(ctx)
+	name := strings.TrimPrefix(info.Name, "/")
+	switch mode {
+	case QuiescePause:
+		if err := svc.cli.ContainerPause(ctx, info.ID); err != nil {
+			log.Warnf("BACKUPS: Unable to pause container '%s' due: %s", name, err)
+			return nil, err
+		}
+		return func() {
+			if err := svc.cli.ContainerUnpause(bg, info.ID); err != nil {
+				log.Warnf("BACKUPS: Unable to unpause container '%s' due: %s", name, err)
+			}
+		}, nil
+	default:
+		timeout := defaultStopTimeout
+		if err := svc.cli.ContainerStop(ctx, info.ID, container.StopOptions{Timeout: &timeout}); err != nil {
+			log.Warnf("BACKUPS: Unable to stop container '%s' due: %s", name, err)
+			return nil, err
+		}
+		return func() {
+			if err := svc.cli.ContainerStart(bg, info.ID, container.StartOptions{}); err != nil {
+				log.Warnf("BACKUPS: Unable to start container '%s' due: %s", name, err)
+			}
+		}, nil
+	}
+}
+
+// archiveMount writes the content of the mount as a gzip compressed tar
+// archive whose entries are relative to the mount, returning its size and
+// checksum.
+func archiveMount(ctx context.Context, svc *ContainerService, id, destination, file string) (int64, string, error) {
+	rc, _, err := svc.cli.CopyFromContainer(ctx, id, destination)
+	if err != nil {
+		return 0, "", err
+	}
+	defer rc.Close()
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+		return 0, "", err
+	}
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return 0, "", err
+	}
+
+	hash := sha256.New()
+	out := &countingWriter{w: io.MultiWriter(f, hash)}
+	gz := gzip.NewWriter(out)
+	err = rebaseArchive(gz, rc)
+	if cerr := gz.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(file)
+		return 0, "", err
+	}
+
+	return out.n, "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// rebaseArchive copies the archive of a directory, whose entries are named
+// after it, with entries relative to the directory instead. The archive of
+// a single file, such as a bind mounted configuration file, is copied as is
+// with the file named after its base name.
+func rebaseArchive(w io.Writer, r io.Reader) error {
+	tr := tar.NewReader(r)
+	tw := tar.NewWriter(w)
+	root := ""
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		name := strings.TrimSuffix(hdr.Name, "/")
+		if root == "" {
+			root = name + "/"
+			if hdr.Typeflag == tar.TypeDir {
+				continue
+			}
+			name = root + path.Base(name)
+		}
+		rel := strings.TrimPrefix(name, root)
+		if rel == name {
+			continue
+		}
+		hdr.Name = rel
+		if hdr.Typeflag == tar.TypeDir {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+
+	return tw.Close()
+}
+
+// restoreIntoVolume extracts the archive into the volume, created when
+// missing, through a helper container mounting it.
+func restoreIntoVolume(ctx context.Context, svc *ContainerService, name string, archive io.Reader) error {
+	if _, err := svc.cli.VolumeCreate(ctx, volume.CreateOptions{Name: name}); err != nil {
+		return err
+	}
+
+	present, err := svc.ImagePresent(ctx, restoreHelperImage, nil)
+	if err != nil {
+		return err
+	}
+	if !present {
+		reader, err := svc.PullContainerImage(ctx, restoreHelperImage, image.PullOptions{})
+		if err != nil {
+			return err
+		}
+		err = jsonmessage.DisplayJSONMessagesStream(reader, io.Discard, 0, false, nil)
+		reader.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	helper, err := svc.cli.ContainerCreate(ctx,
+		&container.Config{Image: restoreHelperImage, Cmd: []string{"true"}},
+		&container.HostConfig{Mounts: []mount.Mount{{Type: mount.TypeVolume, Source: name, Target: restoreTarget}}},
+		nil, nil, "")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := svc.cli.ContainerRemove(context.WithoutCancel(ctx), helper.ID, container.RemoveOptions{Force: true}); err != nil {
+			log.Warnf("BACKUPS: Unable to remove restore helper %s due: %s", shortID(helper.ID), err)
+		}
+	}()
+
+	return svc.cli.CopyToContainer(ctx, helper.ID, restoreTarget, archive, container.CopyToContainerOptions{CopyUIDGID: true})
+}
+
+// verifyChecksum checks the archive against its recorded checksum and
+// rewinds it.
+func verifyChecksum(f *os.File, checksum string) error {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return err
+	}
+	if got := "sha256:" + hex.EncodeToString(hash.Sum(nil)); got != checksum {
+		return fmt.Errorf("%w: checksum %s does not match %s", ErrBackupUnavailable, got, checksum)
+	}
+
+	_, err := f.Seek(0, io.SeekStart)
+	return err
+}
+
+// backupFile names the archive of a mount, relative to the backup directory.
+func backupFile(host, container, destination string, at time.Time) string {
+	mountName := strings.ReplaceAll(strings.Trim(destination, "/"), "/", "_")
+	if mountName == "" {
+		mountName = "root"
+	}
+
+	return filepath.Join(host, container, at.UTC().Format("20060102T150405.000000Z")+"-"+mountName+".tar.gz")
+}
+
+func mountSource(point container.MountPoint) string {
+	if point.Type == mount.TypeVolume {
+		return point.Name
+	}
+	return point.Source
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
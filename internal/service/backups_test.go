@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"mineServers/internal/fakedocker"
+	"mineServers/internal/models"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+)
+
+func newTestBackups(t *testing.T) (*BackupManager, *fakedocker.Engine, string) {
+	t.Helper()
+
+	db := openTestDB(t)
+
+	svc, _, engine, id := newTestRecreate(t)
+	ctx := context.Background()
+	backups := NewBackupManager(ctx, db, NewHostManager(ctx, svc, nil, nil), t.TempDir())
+	t.Cleanup(func() { backups.Close() })
+
+	return backups, engine, id
+}
+
+func TestBackupManager_BackupAndRestore(t *testing.T) {
+	backups, engine, id := newTestBackups(t)
+	engine.WriteFile(id, "/usr/share/nginx/html/index.html", []byte("v1"), 0o644)
+	engine.WriteFile(id, "/usr/share/nginx/html/css/site.css", []byte("body{}"), 0o644)
+	ctx := context.Background()
+
+	taken, err := backups.BackupContainer(ctx, "", "web", &models.BackupRequest{Mode: QuiescePause})
+	if err != nil {
+		t.Fatalf("BackupContainer() error = %v", err)
+	}
+	if len(taken) != 1 {
+		t.Fatalf("backups = %+v", taken)
+	}
+	backup := taken[0]
+	if backup.Status != BackupCompleted || backup.MountType != "volume" || backup.Source != "html" || backup.Destination != "/usr/share/nginx/html" ||
+		backup.ContainerName != "web" || backup.Size == 0 || len(backup.Checksum) != len("sha256:")+64 {
+		t.Fatalf("backup = %+v", backup)
+	}
+	if web, _ := engine.Container("web"); web.State != "running" {
+		t.Fatalf("container state = %s, want running", web.State)
+	}
+
+	engine.WriteFile(id, "/usr/share/nginx/html/index.html", []byte("v2"), 0o644)
+	result, err := backups.RestoreBackup(ctx, backup.ID, &models.RestoreRequest{Mode: QuiesceStop})
+	if err != nil {
+		t.Fatalf("RestoreBackup() error = %v", err)
+	}
+	if result.Container != "web" || result.Volume != "html" || result.Destination != "/usr/share/nginx/html" {
+		t.Fatalf("result = %+v", result)
+	}
+	if data, _ := engine.ReadFile(id, "/usr/share/nginx/html/index.html"); string(data) != "v1" {
+		t.Fatalf("index.html = %q, want v1", data)
+	}
+	if web, _ := engine.Container("web"); web.State != "running" {
+		t.Fatalf("container state = %s, want running", web.State)
+	}
+
+	if _, err := backups.RestoreBackup(ctx, backup.ID, &models.RestoreRequest{Volume: "html-copy"}); err != nil {
+		t.Fatalf("RestoreBackup(volume) error = %v", err)
+	}
+	if data, ok := engine.ReadVolumeFile("html-copy", "css/site.css"); !ok || string(data) != "body{}" {
+		t.Fatalf("restored site.css = %q, %v", data, ok)
+	}
+
+	archive := filepath.Join(backups.dir, backup.File)
+	if err := os.WriteFile(archive, []byte("corrupted"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := backups.RestoreBackup(ctx, backup.ID, &models.RestoreRequest{}); !errors.Is(err, ErrBackupUnavailable) {
+		t.Fatalf("RestoreBackup(corrupted) error = %v, want ErrBackupUnavailable", err)
+	}
+
+	if err := backups.DeleteBackup(ctx, backup.ID); err != nil {
+		t.Fatalf("DeleteBackup() error = %v", err)
+	}
+	if _, err := os.Stat(archive); !os.IsNotExist(err) {
+		t.Fatalf("archive was not removed: %v", err)
+	}
+	if _, err := backups.GetBackup(ctx, backup.ID); !errors.Is(err, ErrBackupNotFound) {
+		t.Fatalf("GetBackup() error = %v, want ErrBackupNotFound", err)
+	}
+
+	var verr *ValidationError
+	if _, err := backups.BackupContainer(ctx, "", "web", &models.BackupRequest{Mounts: []string{"/missing"}}); !errors.As(err, &verr) {
+		t.Fatalf("BackupContainer(unknown mount) error = %v, want ValidationError", err)
+	}
+	if _, err := backups.BackupContainer(ctx, "", "web", &models.BackupRequest{Mode: "freeze"}); !errors.As(err, &verr) {
+		t.Fatalf("BackupContainer(bad mode) error = %v, want ValidationError", err)
+	}
+}
+
+func TestBackupManager_BacksUpFileMounts(t *testing.T) {
+	backups, engine, _ := newTestBackups(t)
+	ctx := context.Background()
+
+	resp, err := engine.ContainerCreate(ctx, &container.Config{Image: "docker.io/library/nginx:1.26"}, &container.HostConfig{
+		Mounts: []mount.Mount{{Type: mount.TypeBind, Source: "/srv/mc/server.properties", Target: "/data/server.properties"}},
+	}, nil, nil, "mc")
+	if err != nil {
+		t.Fatalf("ContainerCreate() error = %v", err)
+	}
+	engine.WriteFile(resp.ID, "/data/server.properties", []byte("motd=v1"), 0o644)
+
+	taken, err := backups.BackupContainer(ctx, "", "mc", &models.BackupRequest{})
+	if err != nil {
+		t.Fatalf("BackupContainer() error = %v", err)
+	}
+	if len(taken) != 1 || taken[0].Status != BackupCompleted || taken[0].MountType != "bind" {
+		t.Fatalf("backups = %+v", taken)
+	}
+
+	engine.WriteFile(resp.ID, "/data/server.properties", []byte("motd=v2"), 0o644)
+	if _, err := backups.RestoreBackup(ctx, taken[0].ID, &models.RestoreRequest{}); err != nil {
+		t.Fatalf("RestoreBackup() error = %v", err)
+	}
+	if data, _ := engine.ReadFile(resp.ID, "/data/server.properties"); string(data) != "motd=v1" {
+		t.Fatalf("server.properties = %q, want motd=v1", data)
+	}
+}
+
+func TestBackupManager_SchedulesApplyRetention(t *testing.T) {
+	backups, engine, id := newTestBackups(t)
+	engine.WriteFile(id, "/usr/share/nginx/html/index.html", []byte("v1"), 0o644)
+	ctx := context.Background()
+
+	var verr *ValidationError
+	if _, err := backups.CreateSchedule(ctx, "", &models.BackupScheduleDefinition{Container: "web", Cron: "0 4 * *"}); !errors.As(err, &verr) {
+		t.Fatalf("CreateSchedule(bad cron) error = %v, want ValidationError", err)
+	}
+	if _, err := backups.CreateSchedule(ctx, "", &models.BackupScheduleDefinition{Container: "web", Cron: "@daily", Timezone: "Mars/Olympus"}); !errors.As(err, &verr) {
+		t.Fatalf("CreateSchedule(bad timezone) error = %v, want ValidationError", err)
+	}
+
+	manual, err := backups.BackupContainer(ctx, "", "web", &models.BackupRequest{})
+	if err != nil {
+		t.Fatalf("BackupContainer() error = %v", err)
+	}
+
+	// Schedules may name their container by a short ID.
+	schedule, err := backups.CreateSchedule(ctx, "", &models.BackupScheduleDefinition{
+		Container: shortID(id),
+		Cron:      "0 4 * * *",
+		Timezone:  "Europe/Berlin",
+		Retention: models.RetentionPolicy{KeepLast: 1},
+	})
+	if err != nil {
+		t.Fatalf("CreateSchedule() error = %v", err)
+	}
+	if schedule.Host != LocalHost || schedule.Mode != QuiesceNone || schedule.NextRunAt == nil || !schedule.NextRunAt.After(time.Now()) {
+		t.Fatalf("schedule = %+v", schedule)
+	}
+
+	var last []models.Backup
+	for range 2 {
+		if last, err = backups.RunSchedule(ctx, schedule.ID); err != nil {
+			t.Fatalf("RunSchedule() error = %v", err)
+		}
+	}
+	// The manual backup is not the schedule's to prune.
+	kept, err := backups.ListBackups(ctx, LocalHost, "web")
+	if err != nil || len(kept) != 2 || kept[0].ID != last[0].ID || *kept[0].ScheduleID != schedule.ID || kept[1].ID != manual[0].ID {
+		t.Fatalf("backups after retention = %+v, %v", kept, err)
+	}
+
+	schedule, err = backups.GetSchedule(ctx, schedule.ID)
+	if err != nil || schedule.LastRunAt == nil || schedule.LastStatus != BackupCompleted {
+		t.Fatalf("GetSchedule() = %+v, %v", schedule, err)
+	}
+
+	if err := backups.DeleteSchedule(ctx, schedule.ID); err != nil {
+		t.Fatalf("DeleteSchedule() error = %v", err)
+	}
+	if _, err := backups.RunSchedule(ctx, schedule.ID); !errors.Is(err, ErrBackupScheduleNotFound) {
+		t.Fatalf("RunSchedule(deleted) error = %v, want ErrBackupScheduleNotFound", err)
+	}
+	if kept, _ := backups.ListBackups(ctx, "", ""); len(kept) != 2 || kept[0].ScheduleID != nil {
+		t.Fatalf("backups after schedule deletion = %+v", kept)
+	}
+}
+
+func TestExpiredBackups(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	at := func(days, hours int) time.Time {
+		return now.AddDate(0, 0, -days).Add(-time.Duration(hours) * time.Hour)
+	}
+	// Newest first, as listed by the store.
+	backups := []models.Backup{
+		{ID: 7, CreatedAt: at(0, 1)},
+		{ID: 6, CreatedAt: at(0, 2)},
+		{ID: 5, CreatedAt: at(1, 0)},
+		{ID: 4, CreatedAt: at(1, 3)},
+		{ID: 3, CreatedAt: at(2, 0)},
+		{ID: 2, CreatedAt: at(5, 0)},
+		{ID: 1, CreatedAt: at(9, 0)},
+	}
+	ids := func(backups []models.Backup) []int64 {
+		var ids []int64
+		for _, b := range backups {
+			ids = append(ids, b.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name   string
+		policy models.RetentionPolicy
+		want   []int64
+	}{
+		{"keep everything", models.RetentionPolicy{}, nil},
+		{"keep last", models.RetentionPolicy{KeepLast: 2}, []int64{5, 4, 3, 2, 1}},
+		{"keep daily", models.RetentionPolicy{KeepDaily: 3}, []int64{6, 4, 2, 1}},
+		{"keep both", models.RetentionPolicy{KeepLast: 2, KeepDaily: 7}, []int64{4, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(expiredBackups(backups, tt.policy, now, time.UTC)); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expired = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
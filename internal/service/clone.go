@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"mineServers/internal/models"
+
+	"github.com/charmbracelet/log"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+)
+
+// How a clone handles the host ports of its source that are already used.
+const (
+	PortConflictRemap  = "remap"
+	PortConflictReject = "reject"
+)
+
+var ErrPortConflict = errors.New("host port already in use")
+
+// CloneContainer creates a copy of the container with the same image,
+// configuration, host configuration and networks, applying the overrides of
+// req. The compose labels of the source are dropped so the copy doesn't
+// join its project, and so are its static IPs, network aliases and MAC
+// address. The copy is only started when req asks for it, and removed when
+// it fails to start.
+func (c *ContainerService) CloneContainer(ctx context.Context, id string, req *models.CloneRequest) (*models.CloneResult, error) {
+	v := &ValidationError{}
+	if req.Name != "" && !containerNameRegex.MatchString(req.Name) {
+		v.add("name", "must match %s", containerNameRegex)
+	}
+	env := buildEnv(v, req.Env)
+	exposed, bindings := buildPorts(v, req.Ports)
+	for key := range req.Labels {
+		if strings.TrimSpace(key) == "" {
+			v.add("labels", "label keys cannot be empty")
+		}
+	}
+	switch req.PortConflict {
+	case "", PortConflictRemap, PortConflictReject:
+	default:
+		v.add("port_conflict", "must be one of %s or %s", PortConflictRemap, PortConflictReject)
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	info, err := c.InspectContainer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := specFromInspect(info, nil)
+	source := spec.Name
+	spec.Name = req.Name
+	if spec.Name == "" {
+		spec.Name = source + "-copy"
+	}
+	// The copy runs the exact image of the source even if its tag moved since.
+	if img, err := c.cli.ImageInspect(ctx, spec.Config.Image); err != nil || img.ID != info.Image {
+		spec.Config.Image = info.Image
+	}
+
+	spec.Config.Env = mergeEnv(spec.Config.Env, env)
+	for key := range spec.Config.Labels {
+		if strings.HasPrefix(key, "com.docker.compose.") {
+			delete(spec.Config.Labels, key)
+		}
+	}
+	// Static addresses, aliases and the MAC address belong to the source, the
+	// copy would clash with it on the network or take its traffic.
+	spec.Config.MacAddress = ""
+	if spec.Networking != nil {
+		for _, ep := range spec.Networking.EndpointsConfig {
+			ep.IPAMConfig = nil
+			ep.Aliases = nil
+		}
+	}
+	if len(req.Labels) > 0 {
+		if spec.Config.Labels == nil {
+			spec.Config.Labels = map[string]string{}
+		}
+		maps.Copy(spec.Config.Labels, req.Labels)
+	}
+	if req.Ports != nil {
+		spec.HostConfig.PortBindings = bindings
+		if spec.Config.ExposedPorts == nil {
+			spec.Config.ExposedPorts = nat.PortSet{}
+		}
+		maps.Copy(spec.Config.ExposedPorts, exposed)
+	}
+
+	remapped, err := c.assignHostPorts(ctx, info, spec.HostConfig.PortBindings, req.PortConflict == PortConflictReject)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.cli.ContainerCreate(ctx, spec.Config, spec.HostConfig, spec.Networking, nil, spec.Name)
+	if err != nil {
+		log.Warnf("CONTAINER-CLONE: Unable to create clone of '%s' due: %s", source, err)
+		return nil, err
+	}
+	log.Infof("CONTAINER-CLONE: Container '%s' cloned as '%s'", source, spec.Name)
+
+	result := &models.CloneResult{
+		ContainerID: resp.ID,
+		Name:        spec.Name,
+		SourceID:    info.ID,
+		Ports:       portBindings(spec.HostConfig.PortBindings),
+		Remapped:    remapped,
+	}
+	if req.Start {
+		if err := c.StartContainer(ctx, resp.ID); err != nil {
+			// Keeping the copy would make retrying fail on its name.
+			if err := c.cli.ContainerRemove(context.WithoutCancel(ctx), resp.ID, container.RemoveOptions{Force: true}); err != nil {
+				log.Warnf("CONTAINER-CLONE: Unable to remove clone '%s' that failed to start due: %s", spec.Name, err)
+			}
+			return nil, err
+		}
+		result.Started = true
+	}
+
+	return result, nil
+}
+
+// assignHostPorts checks that the host ports of bindings are free on the
+// host, remapping the used ones to the next free port unless reject is set.
+func (c *ContainerService) assignHostPorts(ctx context.Context, source container.InspectResponse, bindings nat.PortMap, reject bool) ([]models.PortRemap, error) {
+	used, err := c.usedHostPorts(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	var remapped []models.PortRemap
+	var conflicts []string
+	for _, port := range sortedKeys(bindings) {
+		bindings[port] = slices.Clone(bindings[port])
+		for i, b := range bindings[port] {
+			// Empty ports are picked by the daemon, ranges are left to it.
+			hostPort, err := strconv.Atoi(b.HostPort)
+			if err != nil || hostPort == 0 {
+				continue
+			}
+
+			key := fmt.Sprintf("%d/%s", hostPort, port.Proto())
+			owner, taken := used[key]
+			if !taken {
+				used[key] = "the clone"
+				continue
+			}
+			if reject {
+				conflicts = append(conflicts, fmt.Sprintf("%s is used by %s", key, owner))
+				continue
+			}
+
+			free := nextFreePort(used, hostPort, port.Proto())
+			if free == 0 {
+				conflicts = append(conflicts, fmt.Sprintf("no free port after %s", key))
+				continue
+			}
+			bindings[port][i].HostPort = strconv.Itoa(free)
+			used[fmt.Sprintf("%d/%s", free, port.Proto())] = "the clone"
+			remapped = append(remapped, models.PortRemap{
+				ContainerPort: uint16(port.Int()),
+				Protocol:      port.Proto(),
+				HostPort:      uint16(hostPort),
+				NewHostPort:   uint16(free),
+			})
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrPortConflict, strings.Join(conflicts, ", "))
+	}
+
+	return remapped, nil
+}
+
+// usedHostPorts returns the host ports published by running containers and
+// by source, which takes them back once started, with the container using them.
+func (c *ContainerService) usedHostPorts(ctx context.Context, source container.InspectResponse) (map[string]string, error) {
+	list, err := c.cli.ContainerList(ctx, container.ListOptions{})
+	if err != nil {
+		log.Warnf("CONTAINER-CLIENT: Unable to get containers due: %s", err)
+		return nil, err
+	}
+
+	used := map[string]string{}
+	for _, box := range list {
+		name := box.ID
+		if len(box.Names) > 0 {
+			name = strings.TrimPrefix(box.Names[0], "/")
+		}
+		for _, p := range box.Ports {
+			if p.PublicPort != 0 {
+				used[fmt.Sprintf("%d/%s", p.PublicPort, p.Type)] = name
+			}
+		}
+	}
+	if source.ContainerJSONBase != nil && source.HostConfig != nil {
+		for port, bindings := range source.HostConfig.PortBindings {
+			for _, b := range bindings {
+				if b.HostPort != "" {
+					used[b.HostPort+"/"+port.Proto()] = strings.TrimPrefix(source.Name, "/")
+				}
+			}
+		}
+	}
+
+	return used, nil
+}
+
+// nextFreePort returns the first port above port that is not used for proto,
+// or 0 when there is none.
+func nextFreePort(used map[string]string, port int, proto string) int {
+	for candidate := port + 1; candidate <= 65535; candidate++ {
+		if _, ok := used[fmt.Sprintf("%d/%s", candidate, proto)]; !ok {
+			return candidate
+		}
+	}
+
+	return 0
+}
+
+// mergeEnv overrides or adds the KEY=value entries of overrides to env.
+func mergeEnv(env, overrides []string) []string {
+	if len(overrides) == 0 {
+		return env
+	}
+
+	keys := map[string]bool{}
+	for _, entry := range overrides {
+		key, _, _ := strings.Cut(entry, "=")
+		keys[key] = true
+	}
+
+	var out []string
+	for _, entry := range env {
+		if key, _, _ := strings.Cut(entry, "="); !keys[key] {
+			out = append(out, entry)
+		}
+	}
+
+	return append(out, overrides...)
+}
+
+// portBindings converts Docker port bindings back into the API model.
+func portBindings(bindings nat.PortMap) []models.PortBinding {
+	out := []models.PortBinding{}
+	for _, port := range sortedKeys(bindings) {
+		for _, b := range bindings[port] {
+			hostPort, _ := strconv.Atoi(b.HostPort)
+			out = append(out, models.PortBinding{
+				ContainerPort: uint16(port.Int()),
+				HostIP:        b.HostIP,
+				HostPort:      uint16(hostPort),
+				Protocol:      port.Proto(),
+			})
+		}
+	}
+
+	return out
+}
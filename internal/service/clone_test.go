@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"mineServers/internal/models"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+)
+
+func TestContainerService_CloneRemapsUsedPorts(t *testing.T) {
+	svc, _, engine, id := newTestRecreate(t)
+	ctx := context.Background()
+
+	result, err := svc.CloneContainer(ctx, id, &models.CloneRequest{
+		Env:    map[string]string{"MODE": "staging", "DEBUG": "1"},
+		Labels: map[string]string{"env": "staging"},
+	})
+	if err != nil {
+		t.Fatalf("CloneContainer() error = %v", err)
+	}
+
+	want := []models.PortRemap{{ContainerPort: 80, Protocol: "tcp", HostPort: 8080, NewHostPort: 8081}}
+	if result.Name != "web-copy" || result.Started || !reflect.DeepEqual(result.Remapped, want) {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	clone, ok := engine.Container("web-copy")
+	if !ok || clone.State != "created" || clone.HostConfig.PortBindings["80/tcp"][0].HostPort != "8081" {
+		t.Fatalf("expected a created clone on port 8081, got %+v", clone)
+	}
+	if !reflect.DeepEqual(clone.Config.Env, []string{"DEBUG=1", "MODE=staging"}) || clone.Config.Labels["env"] != "staging" {
+		t.Errorf("expected the overrides to be applied, got %v %v", clone.Config.Env, clone.Config.Labels)
+	}
+	if len(clone.HostConfig.Mounts) != 1 || clone.HostConfig.Mounts[0].Source != "html" {
+		t.Errorf("expected the volume of the source, got %+v", clone.HostConfig.Mounts)
+	}
+	if source, _ := engine.Container("web"); source.HostConfig.PortBindings["80/tcp"][0].HostPort != "8080" {
+		t.Errorf("expected the source to keep its port")
+	}
+
+	_, err = svc.CloneContainer(ctx, id, &models.CloneRequest{Name: "web-2", PortConflict: PortConflictReject})
+	if !errors.Is(err, ErrPortConflict) {
+		t.Errorf("expected ErrPortConflict, got %v", err)
+	}
+
+	result, err = svc.CloneContainer(ctx, id, &models.CloneRequest{Name: "web-3", Ports: []models.PortBinding{{ContainerPort: 80, HostPort: 9090}}, Start: true})
+	if err != nil || !result.Started || len(result.Remapped) != 0 || result.Ports[0].HostPort != 9090 {
+		t.Errorf("expected a started clone on port 9090, got %+v, %v", result, err)
+	}
+}
+
+func TestContainerService_CloneDropsStaticAddresses(t *testing.T) {
+	svc, _, engine, _ := newTestRecreate(t)
+	ctx := context.Background()
+
+	resp, err := engine.ContainerCreate(ctx, &container.Config{Image: "docker.io/library/nginx:1.26", MacAddress: "02:42:ac:11:00:02"}, nil, &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{"bridge": {
+			IPAMConfig: &network.EndpointIPAMConfig{IPv4Address: "172.17.0.10"},
+			Aliases:    []string{"db"},
+		}},
+	}, nil, "db")
+	if err != nil {
+		t.Fatalf("ContainerCreate() error = %v", err)
+	}
+
+	if _, err := svc.CloneContainer(ctx, resp.ID, &models.CloneRequest{}); err != nil {
+		t.Fatalf("CloneContainer() error = %v", err)
+	}
+	clone, ok := engine.Container("db-copy")
+	if !ok {
+		t.Fatalf("expected a db-copy container")
+	}
+	ep, ok := clone.Networking.EndpointsConfig["bridge"]
+	if !ok || ep.IPAMConfig != nil || len(ep.Aliases) != 0 || clone.Config.MacAddress != "" {
+		t.Errorf("expected the clone on bridge without the addresses of the source, got %+v %q", ep, clone.Config.MacAddress)
+	}
+	if source, _ := engine.Container("db"); source.Networking.EndpointsConfig["bridge"].IPAMConfig == nil || len(source.Networking.EndpointsConfig["bridge"].Aliases) != 1 {
+		t.Errorf("expected the source to keep its addresses")
+	}
+}
+
+func TestContainerService_CloneRemovesCopyThatFailsToStart(t *testing.T) {
+	svc, _, engine, id := newTestRecreate(t)
+	ctx := context.Background()
+
+	engine.FailOn("ContainerStart", errors.New("address already in use"))
+	if _, err := svc.CloneContainer(ctx, id, &models.CloneRequest{Start: true}); err == nil {
+		t.Fatalf("expected CloneContainer() to fail")
+	}
+	if _, ok := engine.Container("web-copy"); ok {
+		t.Fatalf("expected the clone that failed to start to be removed")
+	}
+
+	engine.FailOn("ContainerStart", nil)
+	if result, err := svc.CloneContainer(ctx, id, &models.CloneRequest{Start: true}); err != nil || !result.Started {
+		t.Errorf("expected the retry to start web-copy, got %+v, %v", result, err)
+	}
+}
+
+func TestMergeEnv(t *testing.T) {
+	got := mergeEnv([]string{"A=1", "B=2", "PATH=/bin"}, []string{"B=3", "C=4"})
+	if want := []string{"A=1", "PATH=/bin", "B=3", "C=4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeEnv() = %v, want %v", got, want)
+	}
+}
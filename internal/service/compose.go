@@ -0,0 +1,949 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"mineServers/internal/models"
+
+	"github.com/docker/docker/api/types/network"
+	"gopkg.in/yaml.v3"
+)
+
+// Labels docker compose puts on the resources of a project. Stacks use the
+// same ones so they are recognized like any other compose project.
+const (
+	LabelProject         = "com.docker.compose.project"
+	LabelService         = "com.docker.compose.service"
+	LabelContainerNumber = "com.docker.compose.container-number"
+	LabelOneoff          = "com.docker.compose.oneoff"
+	LabelDependsOn       = "com.docker.compose.depends_on"
+	LabelConfigHash      = "com.docker.compose.config-hash"
+	LabelNetwork         = "com.docker.compose.network"
+	LabelVolume          = "com.docker.compose.volume"
+	LabelWorkingDir      = "com.docker.compose.project.working_dir"
+	LabelConfigFiles     = "com.docker.compose.project.config_files"
+)
+
+// Conditions a service can wait for on its dependencies before starting.
+const (
+	ConditionStarted   = "service_started"
+	ConditionHealthy   = "service_healthy"
+	ConditionCompleted = "service_completed_successfully"
+)
+
+// defaultNetwork is the network services join when they list none.
+const defaultNetwork = "default"
+
+var (
+	projectNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+	serviceNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+	composeKeys = keySet("version", "name", "services", "networks", "volumes")
+	serviceKeys = keySet("image", "container_name", "command", "entrypoint", "environment", "labels",
+		"ports", "volumes", "depends_on", "networks", "restart", "working_dir", "user", "hostname",
+		"pull_policy", "platform", "healthcheck")
+	resourceKeys = keySet("name", "driver", "external", "labels")
+)
+
+// composeProject is a validated compose file, ready to be deployed.
+type composeProject struct {
+	Name string
+	// Services are sorted so every service comes after its dependencies.
+	Services []*projectService
+	Networks []projectResource
+	Volumes  []projectResource
+}
+
+// projectService is a compose service converted into creation options.
+type projectService struct {
+	Name      string
+	Image     string
+	Options   *models.CreateOptions
+	DependsOn map[string]string
+	Spec      *containerSpec
+}
+
+// projectResource is a network or a volume of the project. External ones
+// must exist and are never created nor removed.
+type projectResource struct {
+	Key      string
+	Name     string
+	Driver   string
+	External bool
+	Labels   map[string]string
+}
+
+type composeFile struct {
+	Name     string                      `yaml:"name"`
+	Services map[string]*composeService  `yaml:"services"`
+	Networks map[string]*composeResource `yaml:"networks"`
+	Volumes  map[string]*composeResource `yaml:"volumes"`
+}
+
+type composeResource struct {
+	Name     string    `yaml:"name"`
+	Driver   string    `yaml:"driver"`
+	External bool      `yaml:"external"`
+	Labels   yaml.Node `yaml:"labels"`
+}
+
+// composeService holds the fields accepting several syntaxes as raw nodes,
+// so each can be converted while reporting errors by field.
+type composeService struct {
+	Image         string              `yaml:"image"`
+	ContainerName string              `yaml:"container_name"`
+	Command       yaml.Node           `yaml:"command"`
+	Entrypoint    yaml.Node           `yaml:"entrypoint"`
+	Environment   yaml.Node           `yaml:"environment"`
+	Labels        yaml.Node           `yaml:"labels"`
+	Ports         []yaml.Node         `yaml:"ports"`
+	Volumes       []yaml.Node         `yaml:"volumes"`
+	DependsOn     yaml.Node           `yaml:"depends_on"`
+	Networks      yaml.Node           `yaml:"networks"`
+	Restart       string              `yaml:"restart"`
+	WorkingDir    string              `yaml:"working_dir"`
+	User          string              `yaml:"user"`
+	Hostname      string              `yaml:"hostname"`
+	PullPolicy    string              `yaml:"pull_policy"`
+	Platform      string              `yaml:"platform"`
+	Healthcheck   *composeHealthcheck `yaml:"healthcheck"`
+}
+
+type composeHealthcheck struct {
+	Test        yaml.Node `yaml:"test"`
+	Interval    string    `yaml:"interval"`
+	Timeout     string    `yaml:"timeout"`
+	StartPeriod string    `yaml:"start_period"`
+	Retries     int       `yaml:"retries"`
+	Disable     bool      `yaml:"disable"`
+}
+
+type composePort struct {
+	Target    uint16 `yaml:"target"`
+	Published string `yaml:"published"`
+	HostIP    string `yaml:"host_ip"`
+	Protocol  string `yaml:"protocol"`
+}
+
+type composeMount struct {
+	Type     string `yaml:"type"`
+	Source   string `yaml:"source"`
+	Target   string `yaml:"target"`
+	ReadOnly bool   `yaml:"read_only"`
+}
+
+// ProjectName returns the name declared by a compose file, if any.
+func ProjectName(data []byte) string {
+	var file composeFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return ""
+	}
+
+	return file.Name
+}
+
+// parseCompose validates a compose file and converts it into the resources
+// of the named project. Only a subset of the specification is supported:
+// unknown keys are reported instead of being silently ignored, and
+// variables are not interpolated.
+func parseCompose(project string, data []byte) (*composeProject, error) {
+	v := &ValidationError{}
+	if !projectNameRegex.MatchString(project) {
+		v.add("name", "must match %s", projectNameRegex)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		v.add("compose", "%s", err)
+		return nil, v
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		v.add("compose", "must be a mapping")
+		return nil, v
+	}
+	checkKeys(v, root.Content[0], "", composeKeys)
+
+	var file composeFile
+	if err := root.Decode(&file); err != nil {
+		v.add("compose", "%s", err)
+		return nil, v
+	}
+	if len(file.Services) == 0 {
+		v.add("services", "at least one service is required")
+	}
+	if services := mappingValue(root.Content[0], "services"); services != nil {
+		for i := 0; i+1 < len(services.Content); i += 2 {
+			checkKeys(v, services.Content[i+1], "services."+services.Content[i].Value+".", serviceKeys)
+		}
+	}
+	for _, kind := range []string{"networks", "volumes"} {
+		if resources := mappingValue(root.Content[0], kind); resources != nil {
+			for i := 0; i+1 < len(resources.Content); i += 2 {
+				checkKeys(v, resources.Content[i+1], kind+"."+resources.Content[i].Value+".", resourceKeys)
+			}
+		}
+	}
+
+	p := &composeProject{Name: project}
+	networks := buildProjectResources(v, project, "networks", LabelNetwork, file.Networks)
+	volumes := buildProjectResources(v, project, "volumes", LabelVolume, file.Volumes)
+
+	usesDefault := false
+	services := map[string]*projectService{}
+	for _, name := range sortedKeys(file.Services) {
+		field := "services." + name
+		if !serviceNameRegex.MatchString(name) {
+			v.add(field, "service names must match %s", serviceNameRegex)
+			continue
+		}
+		if file.Services[name] == nil {
+			v.add(field, "must be a mapping")
+			continue
+		}
+
+		s, joined := buildService(v, field, project, name, file.Services[name], networks, volumes)
+		for _, n := range joined {
+			if n == defaultNetwork {
+				usesDefault = true
+			}
+		}
+		services[name] = s
+	}
+
+	// Like compose, the default network exists as soon as a service uses it.
+	if _, ok := networks[defaultNetwork]; !ok && usesDefault {
+		networks[defaultNetwork] = &projectResource{
+			Key:    defaultNetwork,
+			Name:   project + "_" + defaultNetwork,
+			Labels: map[string]string{LabelProject: project, LabelNetwork: defaultNetwork},
+		}
+	}
+	for _, key := range sortedKeys(networks) {
+		p.Networks = append(p.Networks, *networks[key])
+	}
+	for _, key := range sortedKeys(volumes) {
+		p.Volumes = append(p.Volumes, *volumes[key])
+	}
+
+	p.Services = sortServices(v, services)
+
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	for _, s := range p.Services {
+		s.Spec.Config.Labels[LabelDependsOn] = dependsOnLabel(s.DependsOn)
+		s.Spec.Config.Labels[LabelConfigHash] = configHash(s.Spec)
+	}
+
+	return p, nil
+}
+
+// buildService converts a compose service into creation options and the
+// Docker spec, returning the keys of the networks it joins.
+func buildService(v *ValidationError, field, project, name string, cs *composeService, networks, volumes map[string]*projectResource) (*projectService, []string) {
+	s := &projectService{Name: name, DependsOn: map[string]string{}}
+
+	if cs.Image == "" {
+		v.add(field+".image", "is required, building images is not supported")
+	}
+	containerName := cs.ContainerName
+	if containerName == "" {
+		containerName = fmt.Sprintf("%s-%s-1", project, name)
+	}
+
+	opts := &models.CreateOptions{
+		Name:        containerName,
+		Reference:   cs.Image,
+		PullPolicy:  composePullPolicy(v, field+".pull_policy", cs.PullPolicy),
+		Platform:    cs.Platform,
+		Commands:    composeCommand(v, field+".command", &cs.Command),
+		Entrypoint:  composeCommand(v, field+".entrypoint", &cs.Entrypoint),
+		Env:         composeMapping(v, field+".environment", &cs.Environment),
+		Labels:      composeMapping(v, field+".labels", &cs.Labels),
+		WorkingDir:  cs.WorkingDir,
+		User:        cs.User,
+		Hostname:    cs.Hostname,
+		Healthcheck: composeHealth(v, field+".healthcheck", cs.Healthcheck),
+	}
+	if opts.Labels == nil {
+		opts.Labels = map[string]string{}
+	}
+	opts.Labels[LabelProject] = project
+	opts.Labels[LabelService] = name
+	opts.Labels[LabelContainerNumber] = "1"
+	opts.Labels[LabelOneoff] = "False"
+
+	for i := range cs.Ports {
+		opts.Ports = append(opts.Ports, composePorts(v, fmt.Sprintf("%s.ports[%d]", field, i), &cs.Ports[i])...)
+	}
+	for i := range cs.Volumes {
+		if m, ok := composeVolume(v, fmt.Sprintf("%s.volumes[%d]", field, i), &cs.Volumes[i], volumes); ok {
+			opts.Mounts = append(opts.Mounts, m)
+		}
+	}
+	if cs.Restart != "" {
+		opts.RestartPolicy = composeRestart(v, field+".restart", cs.Restart)
+	}
+
+	for dep, condition := range composeDependsOn(v, field+".depends_on", &cs.DependsOn) {
+		s.DependsOn[dep] = condition
+	}
+
+	joined := composeNetworks(v, field+".networks", name, &cs.Networks)
+	keys := sortedKeys(joined)
+	for _, key := range keys {
+		if _, ok := networks[key]; !ok && key != defaultNetwork {
+			v.add(field+".networks", "network %s is not declared in the top-level networks", key)
+		}
+	}
+
+	imageName, err := ImageReference(cs.Image, "", "", "")
+	if err != nil && cs.Image != "" {
+		v.add(field+".image", "%s", err)
+	}
+	if len(keys) > 0 {
+		opts.Network = networkName(project, keys[0], networks)
+		opts.NetworkAliases = joined[keys[0]]
+	}
+
+	spec, err := buildContainerSpec(opts, imageName)
+	if err != nil {
+		var verr *ValidationError
+		if errors.As(err, &verr) {
+			for _, f := range verr.Fields {
+				// The image is reported once above with the compose field name.
+				if f.Field == "reference" || f.Field == "image" {
+					continue
+				}
+				v.add(field+"."+f.Field, "%s", f.Message)
+			}
+		}
+		return s, keys
+	}
+
+	// Compose attaches the container to every network at creation.
+	for _, key := range keys[1:] {
+		spec.Networking.EndpointsConfig[networkName(project, key, networks)] = &network.EndpointSettings{Aliases: joined[key]}
+	}
+
+	s.Image = imageName
+	s.Options = opts
+	s.Spec = spec
+
+	return s, keys
+}
+
+func buildProjectResources(v *ValidationError, project, kind, label string, defs map[string]*composeResource) map[string]*projectResource {
+	out := map[string]*projectResource{}
+	for _, key := range sortedKeys(defs) {
+		field := kind + "." + key
+		if !volumeNameRegex.MatchString(key) {
+			v.add(field, "names must match %s", volumeNameRegex)
+			continue
+		}
+
+		// A resource declared without options ("data:") is a default one.
+		def := defs[key]
+		if def == nil {
+			def = &composeResource{}
+		}
+
+		r := &projectResource{
+			Key:      key,
+			Name:     def.Name,
+			Driver:   def.Driver,
+			External: def.External,
+			Labels:   composeMapping(v, field+".labels", &def.Labels),
+		}
+		if r.Name == "" && r.External {
+			r.Name = key
+		} else if r.Name == "" {
+			r.Name = project + "_" + key
+		}
+		if !volumeNameRegex.MatchString(r.Name) {
+			v.add(field+".name", "must match %s", volumeNameRegex)
+		}
+		if r.External && (def.Driver != "" || len(r.Labels) > 0) {
+			v.add(field, "external %s cannot set a driver or labels", kind)
+		}
+		if !r.External {
+			if r.Labels == nil {
+				r.Labels = map[string]string{}
+			}
+			r.Labels[LabelProject] = project
+			r.Labels[label] = key
+		}
+
+		out[key] = r
+	}
+
+	return out
+}
+
+func networkName(project, key string, networks map[string]*projectResource) string {
+	if n, ok := networks[key]; ok {
+		return n.Name
+	}
+
+	return project + "_" + key
+}
+
+// sortServices orders services so every one comes after its dependencies,
+// alphabetically among independent ones.
+func sortServices(v *ValidationError, services map[string]*projectService) []*projectService {
+	pending := map[string]int{}
+	dependents := map[string][]string{}
+	for _, name := range sortedKeys(services) {
+		pending[name] = 0
+		for dep := range services[name].DependsOn {
+			if _, ok := services[dep]; !ok {
+				v.add("services."+name+".depends_on", "service %s is not defined", dep)
+				continue
+			}
+			if dep == name {
+				v.add("services."+name+".depends_on", "a service cannot depend on itself")
+				continue
+			}
+			pending[name]++
+			dependents[dep] = append(dependents[dep], name)
+		}
+	}
+
+	var ready []string
+	for _, name := range sortedKeys(pending) {
+		if pending[name] == 0 {
+			ready = append(ready, name)
+		}
+	}
+
+	out := make([]*projectService, 0, len(services))
+	for len(ready) > 0 {
+		name := ready[0]
+		ready = ready[1:]
+		out = append(out, services[name])
+
+		for _, dependent := range dependents[name] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+				sort.Strings(ready)
+			}
+		}
+	}
+
+	if len(out) < len(services) {
+		var cycle []string
+		for _, name := range sortedKeys(pending) {
+			if pending[name] > 0 {
+				cycle = append(cycle, name)
+			}
+		}
+		v.add("services", "dependency cycle between %s", strings.Join(cycle, ", "))
+	}
+
+	return out
+}
+
+func composePullPolicy(v *ValidationError, field, policy string) string {
+	switch policy {
+	case "":
+		return ""
+	case "always":
+		return PullAlways
+	case "never":
+		return PullNever
+	case "missing", "if_not_present":
+		return PullIfNotPresent
+	}
+
+	v.add(field, "must be one of always, missing or never")
+	return ""
+}
+
+// composeCommand accepts a list or a string split like a shell would.
+func composeCommand(v *ValidationError, field string, node *yaml.Node) []string {
+	switch node.Kind {
+	case 0:
+		return nil
+	case yaml.ScalarNode:
+		args, err := splitCommand(node.Value)
+		if err != nil {
+			v.add(field, "%s", err)
+		}
+		return args
+	case yaml.SequenceNode:
+		var args []string
+		if err := node.Decode(&args); err != nil {
+			v.add(field, "must be a list of strings")
+		}
+		return args
+	}
+
+	v.add(field, "must be a string or a list")
+	return nil
+}
+
+// splitCommand splits a command line on spaces outside of quotes.
+func splitCommand(line string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		quote   rune
+		inArg   bool
+		escaped bool
+	)
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 || escaped {
+		return nil, errors.New("unterminated quote or escape")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
+
+// composeMapping accepts a mapping or a list of KEY=VALUE entries. Keys
+// without a value are skipped since variables aren't taken from the server
+// environment.
+func composeMapping(v *ValidationError, field string, node *yaml.Node) map[string]string {
+	out := map[string]string{}
+
+	switch node.Kind {
+	case 0:
+		return nil
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if value.Kind != yaml.ScalarNode {
+				v.add(field+"."+key.Value, "must be a scalar")
+				continue
+			}
+			if value.Tag == "!!null" {
+				continue
+			}
+			out[key.Value] = value.Value
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				v.add(fmt.Sprintf("%s[%d]", field, i), "must be a KEY=VALUE string")
+				continue
+			}
+			if key, value, ok := strings.Cut(item.Value, "="); ok {
+				out[key] = value
+			}
+		}
+	default:
+		v.add(field, "must be a mapping or a list")
+	}
+
+	return out
+}
+
+// composePorts accepts the short syntax ("[HOST_IP:][HOST_PORT:]PORT[/PROTOCOL]",
+// where ports can be ranges) and the long one.
+func composePorts(v *ValidationError, field string, node *yaml.Node) []models.PortBinding {
+	if node.Kind == yaml.MappingNode {
+		var p composePort
+		if err := node.Decode(&p); err != nil {
+			v.add(field, "%s", err)
+			return nil
+		}
+		if p.Target == 0 {
+			v.add(field+".target", "is required")
+			return nil
+		}
+		binding := models.PortBinding{ContainerPort: p.Target, HostIP: p.HostIP, Protocol: p.Protocol}
+		if p.Published != "" {
+			port, err := parsePort(p.Published)
+			if err != nil {
+				v.add(field+".published", "%s", err)
+				return nil
+			}
+			binding.HostPort = port
+		}
+		return []models.PortBinding{binding}
+	}
+	if node.Kind != yaml.ScalarNode {
+		v.add(field, "must be a string or a mapping")
+		return nil
+	}
+
+	spec, proto, _ := strings.Cut(node.Value, "/")
+
+	var hostIP string
+	if strings.HasPrefix(spec, "[") {
+		end := strings.Index(spec, "]:")
+		if end < 0 {
+			v.add(field, "invalid IPv6 address in %q", node.Value)
+			return nil
+		}
+		hostIP, spec = spec[1:end], spec[end+2:]
+	} else if parts := strings.Split(spec, ":"); len(parts) == 3 {
+		hostIP, spec = parts[0], parts[1]+":"+parts[2]
+	}
+
+	hostRange, containerRange, published := strings.Cut(spec, ":")
+	if !published {
+		hostRange, containerRange = "", hostRange
+	}
+
+	containerPorts, err := parsePortRange(containerRange)
+	if err != nil {
+		v.add(field, "%s", err)
+		return nil
+	}
+	var hostPorts []uint16
+	if hostRange != "" {
+		if hostPorts, err = parsePortRange(hostRange); err != nil {
+			v.add(field, "%s", err)
+			return nil
+		}
+		if len(hostPorts) != len(containerPorts) {
+			v.add(field, "host and container port ranges must have the same length")
+			return nil
+		}
+	}
+
+	out := make([]models.PortBinding, 0, len(containerPorts))
+	for i, port := range containerPorts {
+		binding := models.PortBinding{ContainerPort: port, HostIP: hostIP, Protocol: proto}
+		if hostPorts != nil {
+			binding.HostPort = hostPorts[i]
+		}
+		out = append(out, binding)
+	}
+
+	return out
+}
+
+func parsePortRange(value string) ([]uint16, error) {
+	start, end, isRange := strings.Cut(value, "-")
+	first, err := parsePort(start)
+	if err != nil {
+		return nil, err
+	}
+	if !isRange {
+		return []uint16{first}, nil
+	}
+
+	last, err := parsePort(end)
+	if err != nil {
+		return nil, err
+	}
+	if last < first {
+		return nil, fmt.Errorf("invalid port range %s", value)
+	}
+
+	out := make([]uint16, 0, last-first+1)
+	for port := int(first); port <= int(last); port++ {
+		out = append(out, uint16(port))
+	}
+
+	return out, nil
+}
+
+func parsePort(value string) (uint16, error) {
+	port, err := strconv.ParseUint(value, 10, 16)
+	if err != nil || port == 0 {
+		return 0, fmt.Errorf("invalid port %q", value)
+	}
+
+	return uint16(port), nil
+}
+
+// composeVolume accepts the short syntax ("[SOURCE:]TARGET[:ro|rw]") and the
+// long one. Named volumes must be declared in the top-level volumes and bind
+// mounts need absolute host paths since there is no project directory.
+func composeVolume(v *ValidationError, field string, node *yaml.Node, volumes map[string]*projectResource) (models.Mount, bool) {
+	var m composeMount
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		if err := node.Decode(&m); err != nil {
+			v.add(field, "%s", err)
+			return models.Mount{}, false
+		}
+	case yaml.ScalarNode:
+		parts := strings.Split(node.Value, ":")
+		switch len(parts) {
+		case 1:
+			m.Target = parts[0]
+		case 2, 3:
+			m.Source, m.Target = parts[0], parts[1]
+			if len(parts) == 3 {
+				switch parts[2] {
+				case "ro":
+					m.ReadOnly = true
+				case "rw":
+				default:
+					v.add(field, "unsupported mode %q, use ro or rw", parts[2])
+					return models.Mount{}, false
+				}
+			}
+		default:
+			v.add(field, "must be [SOURCE:]TARGET[:MODE]")
+			return models.Mount{}, false
+		}
+		m.Type = "volume"
+		if path.IsAbs(m.Source) || strings.HasPrefix(m.Source, ".") || strings.HasPrefix(m.Source, "~") {
+			m.Type = "bind"
+		}
+	default:
+		v.add(field, "must be a string or a mapping")
+		return models.Mount{}, false
+	}
+
+	switch m.Type {
+	case "bind":
+		if !path.IsAbs(m.Source) {
+			v.add(field, "relative host paths are not supported, use an absolute path")
+			return models.Mount{}, false
+		}
+	case "volume":
+		if m.Source != "" {
+			vol, ok := volumes[m.Source]
+			if !ok {
+				v.add(field, "volume %s is not declared in the top-level volumes", m.Source)
+				return models.Mount{}, false
+			}
+			m.Source = vol.Name
+		}
+	default:
+		v.add(field+".type", "must be bind or volume")
+		return models.Mount{}, false
+	}
+
+	return models.Mount{Type: m.Type, Source: m.Source, Target: m.Target, ReadOnly: m.ReadOnly}, true
+}
+
+func composeRestart(v *ValidationError, field, restart string) *models.RestartPolicy {
+	name, retries, hasRetries := strings.Cut(restart, ":")
+	policy := &models.RestartPolicy{Name: name}
+
+	if hasRetries {
+		if name != "on-failure" {
+			v.add(field, "only on-failure accepts a retry count")
+			return nil
+		}
+		count, err := strconv.Atoi(retries)
+		if err != nil || count < 0 {
+			v.add(field, "invalid retry count %q", retries)
+			return nil
+		}
+		policy.MaximumRetryCount = count
+	}
+
+	return policy
+}
+
+func composeHealth(v *ValidationError, field string, hc *composeHealthcheck) *models.Healthcheck {
+	if hc == nil {
+		return nil
+	}
+	if hc.Disable {
+		return &models.Healthcheck{Test: []string{"NONE"}}
+	}
+
+	out := &models.Healthcheck{
+		Interval:    hc.Interval,
+		Timeout:     hc.Timeout,
+		StartPeriod: hc.StartPeriod,
+		Retries:     hc.Retries,
+	}
+
+	switch hc.Test.Kind {
+	case yaml.ScalarNode:
+		out.Test = []string{"CMD-SHELL", hc.Test.Value}
+	case yaml.SequenceNode:
+		if err := hc.Test.Decode(&out.Test); err != nil {
+			v.add(field+".test", "must be a list of strings")
+		}
+	default:
+		v.add(field+".test", "must be a string or a list")
+	}
+
+	return out
+}
+
+// composeDependsOn accepts a list of services or a mapping of services to
+// their condition.
+func composeDependsOn(v *ValidationError, field string, node *yaml.Node) map[string]string {
+	out := map[string]string{}
+
+	switch node.Kind {
+	case 0:
+	case yaml.SequenceNode:
+		var deps []string
+		if err := node.Decode(&deps); err != nil {
+			v.add(field, "must be a list of service names")
+		}
+		for _, dep := range deps {
+			out[dep] = ConditionStarted
+		}
+	case yaml.MappingNode:
+		var deps map[string]*struct {
+			Condition string `yaml:"condition"`
+		}
+		if err := node.Decode(&deps); err != nil {
+			v.add(field, "%s", err)
+		}
+		for _, dep := range sortedKeys(deps) {
+			condition := ConditionStarted
+			if deps[dep] != nil && deps[dep].Condition != "" {
+				condition = deps[dep].Condition
+			}
+			switch condition {
+			case ConditionStarted, ConditionHealthy, ConditionCompleted:
+				out[dep] = condition
+			default:
+				v.add(field+"."+dep+".condition", "must be one of %s, %s or %s", ConditionStarted, ConditionHealthy, ConditionCompleted)
+			}
+		}
+	default:
+		v.add(field, "must be a list or a mapping")
+	}
+
+	return out
+}
+
+// composeNetworks returns the aliases of the service on every network it
+// joins, keyed by network. The service name is always an alias.
+func composeNetworks(v *ValidationError, field, service string, node *yaml.Node) map[string][]string {
+	out := map[string][]string{}
+
+	switch node.Kind {
+	case 0:
+		out[defaultNetwork] = []string{service}
+	case yaml.SequenceNode:
+		var names []string
+		if err := node.Decode(&names); err != nil {
+			v.add(field, "must be a list of network names")
+		}
+		for _, name := range names {
+			out[name] = []string{service}
+		}
+	case yaml.MappingNode:
+		var networks map[string]*struct {
+			Aliases []string `yaml:"aliases"`
+		}
+		if err := node.Decode(&networks); err != nil {
+			v.add(field, "%s", err)
+		}
+		for name, def := range networks {
+			out[name] = []string{service}
+			if def != nil {
+				out[name] = append(out[name], def.Aliases...)
+			}
+		}
+	default:
+		v.add(field, "must be a list or a mapping")
+	}
+
+	return out
+}
+
+// dependsOnLabel formats dependencies like compose does: "db:service_healthy:false,...".
+func dependsOnLabel(deps map[string]string) string {
+	entries := make([]string, 0, len(deps))
+	for _, dep := range sortedKeys(deps) {
+		entries = append(entries, dep+":"+deps[dep]+":false")
+	}
+
+	return strings.Join(entries, ",")
+}
+
+// configHash identifies the configuration of a container so unchanged
+// services are kept as is on the next deployment.
+func configHash(spec *containerSpec) string {
+	data, _ := json.Marshal(struct {
+		Config     any
+		HostConfig any
+		Networking any
+		Platform   any
+	}{spec.Config, spec.HostConfig, spec.Networking, spec.Platform})
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// checkKeys reports the keys of a mapping that aren't supported. Extension
+// keys starting with "x-" are always allowed.
+func checkKeys(v *ValidationError, node *yaml.Node, prefix string, allowed map[string]bool) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		if !allowed[key] && !strings.HasPrefix(key, "x-") {
+			v.add(prefix+key, "is not supported")
+		}
+	}
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key && node.Content[i+1].Kind == yaml.MappingNode {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+func keySet(keys ...string) map[string]bool {
+	out := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		out[key] = true
+	}
+
+	return out
+}
+
+func sortedKeys[K ~string, T any](m map[K]T) []K {
+	keys := make([]K, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const testCompose = `
+version: "3.8"
+services:
+  web:
+    image: nginx:1.27
+    ports:
+      - "8080:80"
+      - "127.0.0.1:9000-9001:9000-9001/udp"
+    environment:
+      - MODE=production
+    depends_on:
+      api:
+        condition: service_healthy
+    networks: [front]
+    restart: on-failure:3
+  api:
+    image: ghcr.io/acme/api
+    command: serve --addr ":8000"
+    environment:
+      DB_HOST: db
+      DEBUG: false
+    depends_on: [db]
+    networks:
+      front:
+      back:
+        aliases: [backend]
+    healthcheck:
+      test: curl -f http://localhost:8000/health
+      interval: 10s
+  db:
+    image: postgres:16
+    volumes:
+      - data:/var/lib/postgresql/data
+      - /srv/backups:/backups:ro
+    networks: [back]
+    restart: unless-stopped
+networks:
+  front:
+  back:
+    driver: bridge
+volumes:
+  data:
+x-common: ignored
+`
+
+func TestParseCompose_OrdersAndConvertsServices(t *testing.T) {
+	project, err := parseCompose("shop", []byte(testCompose))
+	if err != nil {
+		t.Fatalf("parseCompose() error = %v", err)
+	}
+
+	var order []string
+	for _, s := range project.Services {
+		order = append(order, s.Name)
+	}
+	if !reflect.DeepEqual(order, []string{"db", "api", "web"}) {
+		t.Fatalf("expected dependencies first, got %v", order)
+	}
+
+	db, api, web := project.Services[0], project.Services[1], project.Services[2]
+	if db.Spec.Name != "shop-db-1" || db.Image != "docker.io/library/postgres:16" {
+		t.Errorf("unexpected db container %s from %s", db.Spec.Name, db.Image)
+	}
+	if mounts := db.Spec.HostConfig.Mounts; len(mounts) != 2 || mounts[0].Source != "shop_data" || mounts[1].Type != "bind" || !mounts[1].ReadOnly {
+		t.Errorf("unexpected db mounts %+v", mounts)
+	}
+
+	labels := api.Spec.Config.Labels
+	if labels[LabelProject] != "shop" || labels[LabelService] != "api" || labels[LabelDependsOn] != "db:service_started:false" || labels[LabelConfigHash] == "" {
+		t.Errorf("unexpected api labels %v", labels)
+	}
+	if !reflect.DeepEqual([]string(api.Spec.Config.Cmd), []string{"serve", "--addr", ":8000"}) {
+		t.Errorf("expected the command to be split, got %q", api.Spec.Config.Cmd)
+	}
+	if !reflect.DeepEqual(api.Spec.Config.Env, []string{"DB_HOST=db", "DEBUG=false"}) {
+		t.Errorf("unexpected api env %v", api.Spec.Config.Env)
+	}
+	if api.Spec.Config.Healthcheck == nil || api.Spec.Config.Healthcheck.Test[0] != "CMD-SHELL" {
+		t.Errorf("expected a CMD-SHELL healthcheck, got %+v", api.Spec.Config.Healthcheck)
+	}
+	endpoints := api.Spec.Networking.EndpointsConfig
+	if len(endpoints) != 2 || !reflect.DeepEqual(endpoints["shop_back"].Aliases, []string{"api", "backend"}) {
+		t.Errorf("expected api on both networks, got %+v", endpoints)
+	}
+
+	if web.DependsOn["api"] != ConditionHealthy || web.Spec.HostConfig.RestartPolicy.MaximumRetryCount != 3 {
+		t.Errorf("unexpected web dependencies %v or restart policy %+v", web.DependsOn, web.Spec.HostConfig.RestartPolicy)
+	}
+	if len(web.Spec.HostConfig.PortBindings) != 3 || web.Spec.HostConfig.PortBindings["9001/udp"][0].HostIP != "127.0.0.1" {
+		t.Errorf("unexpected web ports %v", web.Spec.HostConfig.PortBindings)
+	}
+
+	var networks, volumes []string
+	for _, n := range project.Networks {
+		networks = append(networks, n.Name)
+	}
+	for _, v := range project.Volumes {
+		volumes = append(volumes, v.Name)
+	}
+	if !reflect.DeepEqual(networks, []string{"shop_back", "shop_front"}) || !reflect.DeepEqual(volumes, []string{"shop_data"}) {
+		t.Errorf("unexpected resources %v %v", networks, volumes)
+	}
+}
+
+func TestParseCompose_DefaultNetwork(t *testing.T) {
+	project, err := parseCompose("blog", []byte("services:\n  web:\n    image: nginx\n"))
+	if err != nil {
+		t.Fatalf("parseCompose() error = %v", err)
+	}
+
+	if len(project.Networks) != 1 || project.Networks[0].Name != "blog_default" {
+		t.Fatalf("expected the default network, got %+v", project.Networks)
+	}
+	if _, ok := project.Services[0].Spec.Networking.EndpointsConfig["blog_default"]; !ok {
+		t.Errorf("expected web on the default network")
+	}
+}
+
+func TestParseCompose_ReportsEveryInvalidField(t *testing.T) {
+	compose := `
+services:
+  web:
+    build: .
+    ports: ["http"]
+    volumes: ["./html:/usr/share/nginx/html", "cache:/cache"]
+    depends_on: [api]
+    networks: [front]
+  api:
+    image: api
+    depends_on: [worker]
+  worker:
+    image: worker
+    depends_on: [api]
+`
+	_, err := parseCompose("Shop", []byte(compose))
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+
+	fields := map[string]bool{}
+	for _, f := range verr.Fields {
+		fields[f.Field] = true
+	}
+	for _, field := range []string{"name", "services.web.build", "services.web.image", "services.web.ports[0]", "services.web.volumes[0]", "services.web.volumes[1]", "services.web.networks", "services"} {
+		if !fields[field] {
+			t.Errorf("expected an error on %s, got %v", field, verr.Fields)
+		}
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	tests := map[string][]string{
+		`java -Xmx2G -jar server.jar`: {"java", "-Xmx2G", "-jar", "server.jar"},
+		`sh -c "echo 'hi there'"`:     {"sh", "-c", "echo 'hi there'"},
+		`echo a\ b ""`:                {"echo", "a b", ""},
+	}
+
+	for line, want := range tests {
+		got, err := splitCommand(line)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("splitCommand(%q) = %q, %v, want %q", line, got, err, want)
+		}
+	}
+
+	if _, err := splitCommand(`echo "open`); err == nil {
+		t.Errorf("expected an error for an unterminated quote")
+	}
+}
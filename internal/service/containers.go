@@ -2,27 +2,59 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"io"
+	"mineServers/internal/models"
 	"strconv"
 
 	"github.com/charmbracelet/log"
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
-	"github.com/docker/docker/client"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/pkg/stdcopy"
 )
 
 type ContainerService struct {
 	ctx context.Context
+	cli DockerAPI
 }
 
-func NewContainerService(ctx context.Context) *ContainerService {
+func NewContainerService(ctx context.Context, cli DockerAPI) *ContainerService {
 	return &ContainerService{
 		ctx: ctx,
+		cli: cli,
 	}
 }
 
-func (c *ContainerService) PullContainerImage(cli *client.Client, ctx context.Context, imageName string, pullOpt image.PullOptions) (io.ReadCloser, error) {
-	reader, err := cli.ImagePull(ctx, imageName, image.PullOptions{})
+// Close releases the underlying Docker client.
+func (c *ContainerService) Close() error {
+	return c.cli.Close()
+}
+
+// Ping checks that the daemon is reachable.
+func (c *ContainerService) Ping(ctx context.Context) (types.Ping, error) {
+	return c.cli.Ping(ctx)
+}
+
+// DaemonHost returns the endpoint the client connects to.
+func (c *ContainerService) DaemonHost() string {
+	return c.cli.DaemonHost()
+}
+
+// RegistryLogin validates credentials against a registry through the daemon.
+func (c *ContainerService) RegistryLogin(ctx context.Context, auth registry.AuthConfig) (registry.AuthenticateOKBody, error) {
+	resp, err := c.cli.RegistryLogin(ctx, auth)
+	if err != nil {
+		log.Warnf("CONTAINER-CLIENT: Unable to log into registry '%s' due: %s", auth.ServerAddress, err)
+		return registry.AuthenticateOKBody{}, err
+	}
+
+	return resp, nil
+}
+
+func (c *ContainerService) PullContainerImage(ctx context.Context, imageName string, pullOpt image.PullOptions) (io.ReadCloser, error) {
+	reader, err := c.cli.ImagePull(ctx, imageName, pullOpt)
 	if err != nil {
 		log.Warnf("CONTAINER-READER: Unable to pull docker image '%s' due: %s", imageName, err)
 		return nil, err
@@ -31,6 +63,205 @@ func (c *ContainerService) PullContainerImage(cli *client.Client, ctx context.Co
 	return reader, nil
 }
 
+// CreatePulledContainer creates and starts a container from an image that is
+// already available on the host. It returns the ID of the new container.
+func (c *ContainerService) CreatePulledContainer(ctx context.Context, opts *models.CreateOptions) (string, error) {
+	imageName, err := CreateImageReference(opts)
+	if err != nil {
+		return "", err
+	}
+
+	spec, err := buildContainerSpec(opts, imageName)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.cli.ContainerCreate(ctx, spec.Config, spec.HostConfig, spec.Networking, spec.Platform, spec.Name)
+	if err != nil {
+		log.Warnf("CONTAINER: Unable to create container due: %s", err)
+		return "", err
+	}
+
+	log.Infof("CONTAINER: Container ID: %s", resp.ID)
+	if err := c.cli.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+		log.Warnf("CONTAINER: Unable to start container due: %s", err)
+		return resp.ID, err
+	}
+
+	return resp.ID, nil
+}
+
+func (c *ContainerService) ListContainers(ctx context.Context) ([]models.Container, error) {
+	containers, err := c.cli.ContainerList(ctx, container.ListOptions{All: true})
+	if err != nil {
+		log.Warnf("CONTAINER-CLIENT: Unable to get containers due: %s", err)
+		return nil, err
+	}
+
+	var out []models.Container
+	for _, box := range containers {
+		stats, err := c.ContainerStats(ctx, box.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		curr := models.Container{
+			ID:      box.ID,
+			Names:   box.Names,
+			Image:   box.Image,
+			Command: box.Command,
+			Created: box.Created,
+			Labels:  box.Labels,
+			State:   box.State,
+			Status:  box.Status,
+			Ports:   c.ParsePorts(box.Ports),
+			Stats:   *stats,
+		}
+		out = append(out, curr)
+	}
+
+	return out, nil
+}
+
+// ContainerStats takes a single stats sample of the container.
+func (c *ContainerService) ContainerStats(ctx context.Context, id string) (*models.ContainerStats, error) {
+	statsReader, err := c.cli.ContainerStats(ctx, id, false)
+	if err != nil {
+		log.Warnf("CONTAINER-CLIENT: Unable to get container stats: %s", err)
+		return nil, err
+	}
+	defer statsReader.Body.Close()
+
+	var containerStats container.StatsResponse
+	if err := json.NewDecoder(statsReader.Body).Decode(&containerStats); err != nil {
+		log.Warnf("CONTAINER-CLIENT: Unable to decode stats: %s", err)
+		return nil, err
+	}
+
+	return &models.ContainerStats{
+		CpuUsage: containerStats.CPUStats.CPUUsage.TotalUsage,
+		MemUsage: containerStats.MemoryStats.Usage,
+		CpuTotal: containerStats.CPUStats.SystemUsage,
+		MemTotal: containerStats.MemoryStats.Limit,
+	}, nil
+}
+
+// StreamContainerStats returns the live stats stream of the container.
+// The caller is responsible for closing it.
+func (c *ContainerService) StreamContainerStats(ctx context.Context, id string) (io.ReadCloser, error) {
+	stats, err := c.cli.ContainerStats(ctx, id, true)
+	if err != nil {
+		log.Warnf("CONTAINER-CLIENT: Unable to create docker reader due: %s", err)
+		return nil, err
+	}
+
+	return stats.Body, nil
+}
+
+// StreamContainerLogs follows the container logs starting from the last lines.
+// Output of non-TTY containers is demultiplexed so the reader yields plain text.
+// The caller is responsible for closing the reader.
+func (c *ContainerService) StreamContainerLogs(ctx context.Context, id string) (io.ReadCloser, error) {
+	info, err := c.InspectContainer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	options := container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+		Tail:       "20",
+	}
+
+	reader, err := c.cli.ContainerLogs(ctx, id, options)
+	if err != nil {
+		log.Warnf("CONTAINER-CLIENT: Unable to create docker reader due: %s", err)
+		return nil, err
+	}
+
+	if info.Config != nil && info.Config.Tty {
+		return reader, nil
+	}
+
+	return demuxLogs(reader), nil
+}
+
+// demuxLogs strips the stdout/stderr stream headers Docker adds to the logs
+// of containers running without a TTY.
+func demuxLogs(reader io.ReadCloser) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := stdcopy.StdCopy(pw, pw, reader)
+		pw.CloseWithError(err)
+	}()
+
+	return &demuxReader{PipeReader: pr, src: reader}
+}
+
+type demuxReader struct {
+	*io.PipeReader
+	src io.Closer
+}
+
+func (r *demuxReader) Close() error {
+	r.PipeReader.Close()
+	return r.src.Close()
+}
+
+func (c *ContainerService) StartContainer(ctx context.Context, id string) error {
+	if err := c.cli.ContainerStart(ctx, id, container.StartOptions{}); err != nil {
+		log.Warnf("CONTAINER-CLIENT: Unable to start docker container due: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+func (c *ContainerService) StopContainer(ctx context.Context, id string) error {
+	timeout := defaultStopTimeout
+	if err := c.cli.ContainerStop(ctx, id, container.StopOptions{Timeout: &timeout}); err != nil {
+		log.Warnf("CONTAINER-CLIENT: Unable to stop docker container due: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+func (c *ContainerService) RestartContainer(ctx context.Context, id string) error {
+	timeout := defaultStopTimeout
+	if err := c.cli.ContainerRestart(ctx, id, container.StopOptions{Timeout: &timeout}); err != nil {
+		log.Warnf("CONTAINER-RESTART: Unable to restart docker container due: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+func (c *ContainerService) RemoveContainer(ctx context.Context, id string) error {
+	removeOptions := container.RemoveOptions{
+		Force:         true,
+		RemoveVolumes: false,
+	}
+
+	if err := c.cli.ContainerRemove(ctx, id, removeOptions); err != nil {
+		log.Warnf("CONTAINER-DELETE: Unable to delete container due: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+func (c *ContainerService) InspectContainer(ctx context.Context, id string) (container.InspectResponse, error) {
+	containerJSON, err := c.cli.ContainerInspect(ctx, id)
+	if err != nil {
+		log.Warnf("CONTAINER-CLIENT: Unable to inspect container data due: %s", err)
+		return container.InspectResponse{}, err
+	}
+
+	return containerJSON, nil
+}
+
 func (c *ContainerService) ParsePorts(ports []container.Port) map[string]string {
 	parsedPorts := make(map[string]string, len(ports))
 	for _, v := range ports {
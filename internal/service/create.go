@@ -0,0 +1,405 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"mineServers/internal/models"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+	"github.com/docker/go-units"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// minMemory is the lowest memory limit accepted by the daemon.
+const minMemory = 6 * 1024 * 1024
+
+var (
+	containerNameRegex = regexp.MustCompile(`^/?[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+	volumeNameRegex    = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+	hostnameRegex      = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+)
+
+// ValidationError lists every invalid field of a request.
+type ValidationError struct {
+	Fields []models.FieldError
+}
+
+func (v *ValidationError) Error() string {
+	msgs := make([]string, 0, len(v.Fields))
+	for _, f := range v.Fields {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", f.Field, f.Message))
+	}
+
+	return "invalid options: " + strings.Join(msgs, "; ")
+}
+
+func (v *ValidationError) add(field, format string, args ...any) {
+	v.Fields = append(v.Fields, models.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// err returns nil when no field was reported so it can be returned directly.
+func (v *ValidationError) err() error {
+	if len(v.Fields) == 0 {
+		return nil
+	}
+
+	return v
+}
+
+// containerSpec holds everything ContainerCreate needs.
+type containerSpec struct {
+	Name       string
+	Config     *container.Config
+	HostConfig *container.HostConfig
+	Networking *network.NetworkingConfig
+	Platform   *ocispec.Platform
+}
+
+// ValidateCreateOptions checks the creation options, reporting every
+// invalid field at once.
+func ValidateCreateOptions(opts *models.CreateOptions) error {
+	_, err := buildContainerSpec(opts, "")
+	return err
+}
+
+// buildContainerSpec validates opts and converts them into the Docker
+// creation structs for the given image reference.
+func buildContainerSpec(opts *models.CreateOptions, imageName string) (*containerSpec, error) {
+	v := &ValidationError{}
+
+	if _, err := CreateImageReference(opts); err != nil {
+		field := "image"
+		if opts.Reference != "" {
+			field = "reference"
+		}
+		v.add(field, "%s", err)
+	}
+	if err := validatePullPolicy(opts.PullPolicy); err != nil {
+		v.add("pull_policy", "%s", err)
+	}
+	platform, err := ParsePlatform(opts.Platform)
+	if err != nil {
+		v.add("platform", "%s", err)
+	}
+	if opts.Name != "" && !containerNameRegex.MatchString(opts.Name) {
+		v.add("name", "must match %s", containerNameRegex)
+	}
+
+	config := &container.Config{
+		Image:      imageName,
+		Cmd:        opts.Commands,
+		Entrypoint: opts.Entrypoint,
+		Env:        buildEnv(v, opts.Env),
+		Labels:     opts.Labels,
+		WorkingDir: opts.WorkingDir,
+		User:       opts.User,
+		Hostname:   opts.Hostname,
+	}
+	hostConfig := &container.HostConfig{}
+
+	for key := range opts.Labels {
+		if strings.TrimSpace(key) == "" {
+			v.add("labels", "label keys cannot be empty")
+		}
+	}
+	if opts.WorkingDir != "" && !path.IsAbs(opts.WorkingDir) {
+		v.add("working_dir", "must be an absolute path")
+	}
+	if opts.Hostname != "" && !hostnameRegex.MatchString(opts.Hostname) {
+		v.add("hostname", "must be a valid RFC 1123 hostname")
+	}
+
+	config.ExposedPorts, hostConfig.PortBindings = buildPorts(v, opts.Ports)
+	hostConfig.Mounts = buildMounts(v, opts.Mounts)
+	hostConfig.Resources = buildResources(v, opts.Resources)
+	hostConfig.RestartPolicy = buildRestartPolicy(v, opts.RestartPolicy)
+	config.Healthcheck = buildHealthcheck(v, opts.Healthcheck)
+
+	var networking *network.NetworkingConfig
+	if opts.Network != "" {
+		if !volumeNameRegex.MatchString(opts.Network) {
+			v.add("network", "must match %s", volumeNameRegex)
+		}
+		hostConfig.NetworkMode = container.NetworkMode(opts.Network)
+		networking = &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				opts.Network: {Aliases: opts.NetworkAliases},
+			},
+		}
+	} else if len(opts.NetworkAliases) > 0 {
+		v.add("network_aliases", "aliases require a network")
+	}
+
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	return &containerSpec{
+		Name:       opts.Name,
+		Config:     config,
+		HostConfig: hostConfig,
+		Networking: networking,
+		Platform:   platform,
+	}, nil
+}
+
+func buildEnv(v *ValidationError, env map[string]string) []string {
+	if len(env) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		if key == "" || strings.ContainsAny(key, "= \t\n") {
+			v.add("env", "invalid variable name %q", key)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	out := make([]string, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, key+"="+env[key])
+	}
+
+	return out
+}
+
+func buildPorts(v *ValidationError, ports []models.PortBinding) (nat.PortSet, nat.PortMap) {
+	if len(ports) == 0 {
+		return nil, nil
+	}
+
+	exposed := nat.PortSet{}
+	bindings := nat.PortMap{}
+	used := map[string]int{}
+
+	for i, p := range ports {
+		field := fmt.Sprintf("ports[%d]", i)
+
+		proto := strings.ToLower(p.Protocol)
+		if proto == "" {
+			proto = "tcp"
+		}
+		if proto != "tcp" && proto != "udp" && proto != "sctp" {
+			v.add(field+".protocol", "must be one of tcp, udp or sctp")
+			continue
+		}
+		if p.ContainerPort == 0 {
+			v.add(field+".container_port", "must be between 1 and 65535")
+			continue
+		}
+		if p.HostIP != "" && net.ParseIP(p.HostIP) == nil {
+			v.add(field+".host_ip", "%q is not a valid IP address", p.HostIP)
+			continue
+		}
+
+		if p.HostPort != 0 {
+			key := fmt.Sprintf("%s:%d/%s", p.HostIP, p.HostPort, proto)
+			if prev, ok := used[key]; ok {
+				v.add(field+".host_port", "port %d/%s is already bound by ports[%d]", p.HostPort, proto, prev)
+				continue
+			}
+			used[key] = i
+		}
+
+		port := nat.Port(fmt.Sprintf("%d/%s", p.ContainerPort, proto))
+		exposed[port] = struct{}{}
+
+		binding := nat.PortBinding{HostIP: p.HostIP}
+		if p.HostPort != 0 {
+			binding.HostPort = fmt.Sprintf("%d", p.HostPort)
+		}
+		bindings[port] = append(bindings[port], binding)
+	}
+
+	return exposed, bindings
+}
+
+func buildMounts(v *ValidationError, mounts []models.Mount) []mount.Mount {
+	if len(mounts) == 0 {
+		return nil
+	}
+
+	out := make([]mount.Mount, 0, len(mounts))
+	targets := map[string]int{}
+
+	for i, m := range mounts {
+		field := fmt.Sprintf("mounts[%d]", i)
+
+		if !path.IsAbs(m.Target) {
+			v.add(field+".target", "must be an absolute path")
+			continue
+		}
+		target := path.Clean(m.Target)
+		if prev, ok := targets[target]; ok {
+			v.add(field+".target", "%s is already mounted by mounts[%d]", target, prev)
+			continue
+		}
+		targets[target] = i
+
+		switch m.Type {
+		case string(mount.TypeBind):
+			if !path.IsAbs(m.Source) {
+				v.add(field+".source", "bind mounts need an absolute host path")
+				continue
+			}
+		case string(mount.TypeVolume):
+			if m.Source != "" && !volumeNameRegex.MatchString(m.Source) {
+				v.add(field+".source", "volume names must match %s", volumeNameRegex)
+				continue
+			}
+		default:
+			v.add(field+".type", "must be bind or volume")
+			continue
+		}
+
+		out = append(out, mount.Mount{
+			Type:     mount.Type(m.Type),
+			Source:   m.Source,
+			Target:   target,
+			ReadOnly: m.ReadOnly,
+		})
+	}
+
+	return out
+}
+
+func buildResources(v *ValidationError, res *models.Resources) container.Resources {
+	var out container.Resources
+	if res == nil {
+		return out
+	}
+
+	if res.CPUs < 0 {
+		v.add("resources.cpus", "cannot be negative")
+	}
+	out.NanoCPUs = int64(res.CPUs * 1e9)
+
+	if res.CPUShares < 0 {
+		v.add("resources.cpu_shares", "cannot be negative")
+	}
+	out.CPUShares = res.CPUShares
+
+	if res.Memory != "" {
+		memory, err := units.RAMInBytes(res.Memory)
+		switch {
+		case err != nil:
+			v.add("resources.memory", "%s", err)
+		case memory < minMemory:
+			v.add("resources.memory", "must be at least 6MB")
+		}
+		out.Memory = memory
+	}
+
+	if res.MemorySwap != "" {
+		swap, err := parseMemorySwap(res.MemorySwap)
+		switch {
+		case err != nil:
+			v.add("resources.memory_swap", "%s", err)
+		case res.Memory == "":
+			v.add("resources.memory_swap", "requires a memory limit")
+		case swap != -1 && swap < out.Memory:
+			v.add("resources.memory_swap", "must be -1 or greater than or equal to memory")
+		}
+		out.MemorySwap = swap
+	}
+
+	if res.PidsLimit < -1 {
+		v.add("resources.pids_limit", "must be -1 (unlimited) or positive")
+	}
+	if res.PidsLimit != 0 {
+		limit := res.PidsLimit
+		out.PidsLimit = &limit
+	}
+
+	return out
+}
+
+// parseMemorySwap accepts "-1" for unlimited swap besides regular sizes.
+func parseMemorySwap(value string) (int64, error) {
+	if value == "-1" {
+		return -1, nil
+	}
+
+	return units.RAMInBytes(value)
+}
+
+func buildRestartPolicy(v *ValidationError, policy *models.RestartPolicy) container.RestartPolicy {
+	if policy == nil {
+		return container.RestartPolicy{}
+	}
+
+	out := container.RestartPolicy{
+		Name:              container.RestartPolicyMode(policy.Name),
+		MaximumRetryCount: policy.MaximumRetryCount,
+	}
+	if err := container.ValidateRestartPolicy(out); err != nil {
+		v.add("restart_policy", "%s", strings.TrimPrefix(err.Error(), "invalid restart policy: "))
+	}
+
+	return out
+}
+
+func buildHealthcheck(v *ValidationError, hc *models.Healthcheck) *container.HealthConfig {
+	if hc == nil {
+		return nil
+	}
+
+	if len(hc.Test) == 0 {
+		v.add("healthcheck.test", "is required")
+		return nil
+	}
+	switch hc.Test[0] {
+	case "NONE":
+		if len(hc.Test) > 1 {
+			v.add("healthcheck.test", "NONE takes no arguments")
+		}
+	case "CMD", "CMD-SHELL":
+		if len(hc.Test) < 2 {
+			v.add("healthcheck.test", "%s needs a command", hc.Test[0])
+		}
+	default:
+		v.add("healthcheck.test", "must start with NONE, CMD or CMD-SHELL")
+	}
+
+	if hc.Retries < 0 {
+		v.add("healthcheck.retries", "cannot be negative")
+	}
+
+	return &container.HealthConfig{
+		Test:        hc.Test,
+		Interval:    parseHealthDuration(v, "healthcheck.interval", hc.Interval),
+		Timeout:     parseHealthDuration(v, "healthcheck.timeout", hc.Timeout),
+		StartPeriod: parseHealthDuration(v, "healthcheck.start_period", hc.StartPeriod),
+		Retries:     hc.Retries,
+	}
+}
+
+func parseHealthDuration(v *ValidationError, field, value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		v.add(field, "%q is not a valid duration", value)
+		return 0
+	}
+	// The daemon rejects anything shorter than a millisecond.
+	if d < time.Millisecond {
+		v.add(field, "must be at least 1ms")
+	}
+
+	return d
+}
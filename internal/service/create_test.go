@@ -0,0 +1,121 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"mineServers/internal/models"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/go-connections/nat"
+)
+
+func TestBuildContainerSpec_MapsOptions(t *testing.T) {
+	opts := &models.CreateOptions{
+		Name:       "survival",
+		Image:      "itzg/minecraft-server",
+		Commands:   []string{"--nogui"},
+		Entrypoint: []string{"/start"},
+		Env:        map[string]string{"MEMORY": "2G", "EULA": "TRUE"},
+		Ports: []models.PortBinding{
+			{ContainerPort: 25565, HostPort: 25565},
+			{ContainerPort: 19132, HostIP: "127.0.0.1", Protocol: "udp"},
+		},
+		Mounts: []models.Mount{
+			{Type: "volume", Source: "survival-data", Target: "/data"},
+			{Type: "bind", Source: "/srv/plugins", Target: "/plugins/", ReadOnly: true},
+		},
+		Resources:      &models.Resources{CPUs: 1.5, Memory: "3g", MemorySwap: "-1", PidsLimit: 256},
+		RestartPolicy:  &models.RestartPolicy{Name: "on-failure", MaximumRetryCount: 3},
+		Labels:         map[string]string{"game": "minecraft"},
+		WorkingDir:     "/data",
+		User:           "1000:1000",
+		Hostname:       "survival",
+		Network:        "games",
+		NetworkAliases: []string{"mc"},
+		Healthcheck: &models.Healthcheck{
+			Test:     []string{"CMD", "mc-health"},
+			Interval: "30s",
+			Retries:  3,
+		},
+	}
+
+	spec, err := buildContainerSpec(opts, "docker.io/itzg/minecraft-server:latest")
+	if err != nil {
+		t.Fatalf("buildContainerSpec() error = %v", err)
+	}
+
+	if want := []string{"EULA=TRUE", "MEMORY=2G"}; !reflect.DeepEqual(spec.Config.Env, want) {
+		t.Errorf("Env = %v, want %v", spec.Config.Env, want)
+	}
+	if _, ok := spec.Config.ExposedPorts["19132/udp"]; !ok {
+		t.Errorf("expected 19132/udp to be exposed, got %v", spec.Config.ExposedPorts)
+	}
+	wantBindings := nat.PortMap{
+		"25565/tcp": {{HostPort: "25565"}},
+		"19132/udp": {{HostIP: "127.0.0.1"}},
+	}
+	if !reflect.DeepEqual(spec.HostConfig.PortBindings, wantBindings) {
+		t.Errorf("PortBindings = %v, want %v", spec.HostConfig.PortBindings, wantBindings)
+	}
+	if spec.HostConfig.Mounts[1].Target != "/plugins" || spec.HostConfig.Mounts[1].Type != mount.TypeBind || !spec.HostConfig.Mounts[1].ReadOnly {
+		t.Errorf("unexpected bind mount: %+v", spec.HostConfig.Mounts[1])
+	}
+	if spec.HostConfig.NanoCPUs != 1_500_000_000 || spec.HostConfig.Memory != 3<<30 || spec.HostConfig.MemorySwap != -1 || *spec.HostConfig.PidsLimit != 256 {
+		t.Errorf("unexpected resources: %+v", spec.HostConfig.Resources)
+	}
+	if spec.HostConfig.RestartPolicy.Name != container.RestartPolicyOnFailure || spec.HostConfig.RestartPolicy.MaximumRetryCount != 3 {
+		t.Errorf("unexpected restart policy: %+v", spec.HostConfig.RestartPolicy)
+	}
+	if spec.HostConfig.NetworkMode != "games" || spec.Networking.EndpointsConfig["games"].Aliases[0] != "mc" {
+		t.Errorf("unexpected network config: %v %+v", spec.HostConfig.NetworkMode, spec.Networking)
+	}
+	if spec.Config.Healthcheck.Interval != 30*time.Second {
+		t.Errorf("unexpected healthcheck: %+v", spec.Config.Healthcheck)
+	}
+}
+
+func TestBuildContainerSpec_ReportsEveryInvalidField(t *testing.T) {
+	opts := &models.CreateOptions{
+		Name:  "bad name",
+		Image: "nginx",
+		Env:   map[string]string{"A=B": "c"},
+		Ports: []models.PortBinding{
+			{ContainerPort: 80, HostPort: 8080},
+			{ContainerPort: 81, HostPort: 8080},
+			{ContainerPort: 82, Protocol: "icmp"},
+		},
+		Mounts: []models.Mount{
+			{Type: "bind", Source: "relative", Target: "/data"},
+			{Type: "tmpfs", Target: "/tmp"},
+		},
+		Resources:     &models.Resources{Memory: "1m", MemorySwap: "512k"},
+		RestartPolicy: &models.RestartPolicy{Name: "always", MaximumRetryCount: 2},
+		WorkingDir:    "data",
+		Healthcheck:   &models.Healthcheck{Test: []string{"curl"}, Interval: "often"},
+	}
+
+	_, err := buildContainerSpec(opts, "docker.io/nginx:latest")
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+
+	fields := map[string]bool{}
+	for _, f := range verr.Fields {
+		fields[f.Field] = true
+	}
+	for _, field := range []string{
+		"name", "env", "ports[1].host_port", "ports[2].protocol", "mounts[0].source", "mounts[1].type",
+		"resources.memory", "resources.memory_swap", "restart_policy", "working_dir",
+		"healthcheck.test", "healthcheck.interval",
+	} {
+		if !fields[field] {
+			t.Errorf("expected an error for %s, got %+v", field, verr.Fields)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"mineServers/internal/models"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// eventTypes are the event types the daemon reports.
+var eventTypes = map[string]bool{
+	string(events.BuilderEventType):   true,
+	string(events.ConfigEventType):    true,
+	string(events.ContainerEventType): true,
+	string(events.DaemonEventType):    true,
+	string(events.ImageEventType):     true,
+	string(events.NetworkEventType):   true,
+	string(events.NodeEventType):      true,
+	string(events.PluginEventType):    true,
+	string(events.SecretEventType):    true,
+	string(events.ServiceEventType):   true,
+	string(events.VolumeEventType):    true,
+}
+
+// StreamEvents follows the daemon events matching the filter. When after is
+// the ID of an event, the events since then are replayed first so a client
+// can resume without gaps. The events channel is closed with the stream;
+// the error channel reports why it ended.
+func (c *ContainerService) StreamEvents(ctx context.Context, filter *models.EventFilter, after int64) (<-chan models.Event, <-chan error, error) {
+	v := &ValidationError{}
+	args := filters.NewArgs()
+	for _, typ := range filter.Types {
+		if !eventTypes[typ] {
+			v.add("type", "unknown event type %q", typ)
+		}
+		args.Add("type", typ)
+	}
+	for _, action := range filter.Actions {
+		args.Add("event", action)
+	}
+	for _, container := range filter.Containers {
+		args.Add("container", container)
+	}
+	for _, label := range filter.Labels {
+		if label == "" {
+			v.add("label", "must not be empty")
+		}
+		args.Add("label", label)
+	}
+	if after < 0 {
+		v.add("last_event_id", "must be a positive event ID")
+	}
+	if err := v.err(); err != nil {
+		return nil, nil, err
+	}
+
+	options := events.ListOptions{Filters: args}
+	if after > 0 {
+		options.Since = fmt.Sprintf("%d.%09d", after/int64(time.Second), after%int64(time.Second))
+	}
+	messages, errs := c.cli.Events(ctx, options)
+
+	out := make(chan models.Event)
+	done := make(chan error, 1)
+	go func() {
+		defer close(out)
+
+		for {
+			select {
+			case msg := <-messages:
+				// Since has a precision of a second on some daemons, the
+				// events up to the resumed one were already sent.
+				if msg.TimeNano <= after {
+					continue
+				}
+				select {
+				case out <- eventFromMessage(msg):
+				case <-ctx.Done():
+					done <- ctx.Err()
+					return
+				}
+			case err := <-errs:
+				done <- err
+				return
+			case <-ctx.Done():
+				done <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return out, done, nil
+}
+
+func eventFromMessage(msg events.Message) models.Event {
+	timeNano := msg.TimeNano
+	if timeNano == 0 {
+		timeNano = msg.Time * int64(time.Second)
+	}
+
+	return models.Event{
+		ID:         strconv.FormatInt(timeNano, 10),
+		Type:       string(msg.Type),
+		Action:     string(msg.Action),
+		ActorID:    msg.Actor.ID,
+		Name:       msg.Actor.Attributes["name"],
+		Attributes: msg.Actor.Attributes,
+		Time:       time.Unix(0, timeNano).UTC(),
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"mineServers/internal/fakedocker"
+	"mineServers/internal/models"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+)
+
+func TestStreamEvents_FiltersAndResumes(t *testing.T) {
+	engine := fakedocker.New()
+	svc := NewContainerService(context.Background(), engine)
+	engine.AddImage("docker.io/library/alpine:latest")
+
+	create := func(name string, labels map[string]string) string {
+		t.Helper()
+		resp, err := engine.ContainerCreate(context.Background(), &container.Config{Image: "docker.io/library/alpine:latest", Labels: labels}, nil, nil, nil, name)
+		if err != nil {
+			t.Fatalf("ContainerCreate(%s) error = %v", name, err)
+		}
+		return resp.ID
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, _, err := svc.StreamEvents(ctx, &models.EventFilter{Types: []string{"container"}, Labels: []string{"app=shop"}}, 0)
+	if err != nil {
+		t.Fatalf("StreamEvents() error = %v", err)
+	}
+
+	create("other", nil)
+	id := create("web", map[string]string{"app": "shop"})
+	if err := engine.ContainerStart(context.Background(), id, container.StartOptions{}); err != nil {
+		t.Fatalf("ContainerStart() error = %v", err)
+	}
+	engine.Emit(events.Message{Type: events.ContainerEventType, Action: events.ActionOOM, Actor: events.Actor{ID: id, Attributes: map[string]string{"name": "web", "app": "shop"}}})
+
+	var got []models.Event
+	for len(got) < 3 {
+		select {
+		case event := <-stream:
+			got = append(got, event)
+		case <-ctx.Done():
+			t.Fatalf("events = %+v, want 3", got)
+		}
+	}
+	if got[0].Action != "create" || got[1].Action != "start" || got[2].Action != "oom" || got[0].Name != "web" || got[0].ActorID != id {
+		t.Fatalf("events = %+v", got)
+	}
+
+	// Resuming after the create event replays the events that followed it.
+	after, _ := strconv.ParseInt(got[0].ID, 10, 64)
+	resumed, _, err := svc.StreamEvents(ctx, &models.EventFilter{Containers: []string{"web"}, Actions: []string{"start", "oom"}}, after)
+	if err != nil {
+		t.Fatalf("StreamEvents(resume) error = %v", err)
+	}
+	for _, want := range []string{"start", "oom"} {
+		select {
+		case event := <-resumed:
+			if event.Action != want {
+				t.Fatalf("resumed event = %+v, want %s", event, want)
+			}
+		case <-ctx.Done():
+			t.Fatalf("no resumed %s event", want)
+		}
+	}
+
+	var verr *ValidationError
+	if _, _, err := svc.StreamEvents(ctx, &models.EventFilter{Types: []string{"vm"}}, 0); !errors.As(err, &verr) {
+		t.Fatalf("StreamEvents(unknown type) error = %v, want ValidationError", err)
+	}
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"mineServers/internal/models"
+
+	"github.com/charmbracelet/log"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/docker/go-units"
+)
+
+const (
+	defaultExecTimeout   = 30 * time.Second
+	maxExecTimeout       = 10 * time.Minute
+	defaultExecMaxOutput = 1 << 20
+	maxExecMaxOutput     = 16 << 20
+	// execExitPolls bounds how long the exit code of a finished command is
+	// waited for once its output is closed.
+	execExitPolls    = 20
+	execExitInterval = 50 * time.Millisecond
+)
+
+// ExecCommand runs a command inside the running container and returns its
+// exit code and output. When the timeout expires the output is no longer
+// read and TimedOut is set, the daemon offers no way to stop the command.
+func (c *ContainerService) ExecCommand(ctx context.Context, id string, req *models.ExecRequest) (*models.ExecResult, error) {
+	v := &ValidationError{}
+	if len(req.Cmd) == 0 {
+		v.add("cmd", "is required")
+	}
+	env := buildEnv(v, req.Env)
+	timeout := defaultExecTimeout
+	if req.Timeout != "" {
+		d, err := time.ParseDuration(req.Timeout)
+		switch {
+		case err != nil:
+			v.add("timeout", "%s", err)
+		case d <= 0 || d > maxExecTimeout:
+			v.add("timeout", "must be positive and at most %s", maxExecTimeout)
+		default:
+			timeout = d
+		}
+	}
+	limit := int64(defaultExecMaxOutput)
+	if req.MaxOutput != "" {
+		size, err := units.RAMInBytes(req.MaxOutput)
+		switch {
+		case err != nil:
+			v.add("max_output", "%s", err)
+		case size <= 0 || size > maxExecMaxOutput:
+			v.add("max_output", "must be positive and at most %s", units.BytesSize(maxExecMaxOutput))
+		default:
+			limit = size
+		}
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	exec, err := c.cli.ContainerExecCreate(ctx, id, container.ExecOptions{
+		Cmd:          req.Cmd,
+		Env:          env,
+		User:         req.User,
+		WorkingDir:   req.WorkingDir,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		log.Warnf("CONTAINER-EXEC: Unable to create exec in container '%s' due: %s", id, err)
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	resp, err := c.cli.ContainerExecAttach(runCtx, exec.ID, container.ExecAttachOptions{})
+	if err != nil {
+		log.Warnf("CONTAINER-EXEC: Unable to start exec in container '%s' due: %s", id, err)
+		return nil, err
+	}
+	defer resp.Close()
+
+	stdout := &cappedBuffer{limit: int(limit)}
+	stderr := &cappedBuffer{limit: int(limit)}
+	done := make(chan error, 1)
+	go func() {
+		_, err := stdcopy.StdCopy(stdout, stderr, resp.Reader)
+		done <- err
+	}()
+
+	result := &models.ExecResult{ExitCode: -1}
+	select {
+	case err = <-done:
+	case <-runCtx.Done():
+		resp.Close()
+		<-done
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		result.TimedOut = true
+		log.Warnf("CONTAINER-EXEC: Command %v in container '%s' timed out after %s", req.Cmd, id, timeout)
+	}
+	result.Duration = time.Since(start).Round(time.Millisecond).String()
+	result.Stdout, result.Stderr = stdout.buf.String(), stderr.buf.String()
+	result.Truncated = stdout.truncated || stderr.truncated
+
+	if result.TimedOut {
+		return result, nil
+	}
+	if err != nil {
+		log.Warnf("CONTAINER-EXEC: Unable to read exec output in container '%s' due: %s", id, err)
+		return nil, err
+	}
+
+	if result.ExitCode, err = c.execExitCode(ctx, exec.ID); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// execExitCode waits briefly for the exec to be reported as finished and
+// returns its exit code, -1 if it is still running.
+func (c *ContainerService) execExitCode(ctx context.Context, execID string) (int, error) {
+	for range execExitPolls {
+		inspect, err := c.cli.ContainerExecInspect(ctx, execID)
+		if err != nil {
+			log.Warnf("CONTAINER-EXEC: Unable to inspect exec due: %s", err)
+			return -1, err
+		}
+		if !inspect.Running {
+			return inspect.ExitCode, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return -1, ctx.Err()
+		case <-time.After(execExitInterval):
+		}
+	}
+
+	return -1, nil
+}
+
+// cappedBuffer keeps the first limit bytes written to it and drops the rest
+// so the output keeps being drained.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); len(p) > room {
+		b.truncated = true
+		b.buf.Write(p[:max(room, 0)])
+		return len(p), nil
+	}
+
+	return b.buf.Write(p)
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"mineServers/internal/models"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/errdefs"
+)
+
+func TestContainerService_ExecCommand(t *testing.T) {
+	svc, _, engine, id := newTestRecreate(t)
+	engine.OnExec(func(ctx context.Context, opts container.ExecOptions, stdin io.Reader, stdout, stderr io.Writer) int {
+		switch opts.Cmd[0] {
+		case "env":
+			fmt.Fprintf(stdout, "%s %s %s", strings.Join(opts.Env, ","), opts.User, opts.WorkingDir)
+			return 0
+		case "noisy":
+			io.WriteString(stdout, strings.Repeat("a", 4096))
+			io.WriteString(stderr, "done")
+			return 0
+		case "hang":
+			<-ctx.Done()
+			return 137
+		default:
+			fmt.Fprintf(stderr, "%s: not found", opts.Cmd[0])
+			return 127
+		}
+	})
+	ctx := context.Background()
+
+	result, err := svc.ExecCommand(ctx, id, &models.ExecRequest{Cmd: []string{"env"}, Env: map[string]string{"A": "1"}, User: "app", WorkingDir: "/srv"})
+	if err != nil {
+		t.Fatalf("ExecCommand() error = %v", err)
+	}
+	if result.ExitCode != 0 || result.Stdout != "A=1 app /srv" || result.Stderr != "" {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	result, err = svc.ExecCommand(ctx, id, &models.ExecRequest{Cmd: []string{"migrate"}})
+	if err != nil || result.ExitCode != 127 || result.Stderr != "migrate: not found" {
+		t.Errorf("expected the exit code and stderr of a failed command, got %+v, %v", result, err)
+	}
+
+	result, err = svc.ExecCommand(ctx, id, &models.ExecRequest{Cmd: []string{"noisy"}, MaxOutput: "1k"})
+	if err != nil || !result.Truncated || len(result.Stdout) != 1024 || result.Stderr != "done" {
+		t.Errorf("expected stdout to be capped at 1k, got %d bytes, %v", len(result.Stdout), err)
+	}
+
+	result, err = svc.ExecCommand(ctx, id, &models.ExecRequest{Cmd: []string{"hang"}, Timeout: "50ms"})
+	if err != nil || !result.TimedOut || result.ExitCode != -1 {
+		t.Errorf("expected the command to time out, got %+v, %v", result, err)
+	}
+}
+
+func TestContainerService_ExecCommandErrors(t *testing.T) {
+	svc, _, _, id := newTestRecreate(t)
+	ctx := context.Background()
+
+	_, err := svc.ExecCommand(ctx, id, &models.ExecRequest{Timeout: "1h", MaxOutput: "1g"})
+	var verr *ValidationError
+	if !errors.As(err, &verr) || len(verr.Fields) != 3 {
+		t.Errorf("expected the cmd, timeout and max_output errors, got %v", err)
+	}
+
+	svc.ChangeContainerState(ctx, id, ActionStop, nil)
+	if _, err := svc.ExecCommand(ctx, id, &models.ExecRequest{Cmd: []string{"ls"}}); !errdefs.IsConflict(err) {
+		t.Errorf("expected a conflict on a stopped container, got %v", err)
+	}
+}
@@ -0,0 +1,526 @@
+package service
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"mineServers/internal/models"
+
+	"github.com/charmbracelet/log"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/errdefs"
+)
+
+// maxTextFileSize is the largest file that can be read or written as text.
+const maxTextFileSize = 1 << 20
+
+// Archive formats of downloads.
+const (
+	ArchiveTar = "tar"
+	ArchiveZip = "zip"
+)
+
+var (
+	ErrPathNotFound = errors.New("path not found in container")
+	ErrNotDirectory = errors.New("path is not a directory")
+	ErrNotTextFile  = errors.New("not a text file")
+	ErrFileTooLarge = errors.New("file is too large to edit")
+)
+
+// UploadFile is a file uploaded into a container.
+type UploadFile struct {
+	Name string
+	Size int64
+	Open func() (multipart.File, error)
+}
+
+// ListFiles lists the directory of the container. It works on stopped
+// containers too since it reads an archive of the directory.
+func (c *ContainerService) ListFiles(ctx context.Context, id, dir string) (*models.DirectoryListing, error) {
+	v := &ValidationError{}
+	dir = validateContainerPath(v, "path", dir)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	info, err := c.InspectContainer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rc, stat, err := c.cli.CopyFromContainer(ctx, info.ID, dir)
+	if err != nil {
+		return nil, pathError(err, dir)
+	}
+	defer rc.Close()
+	if !stat.Mode.IsDir() {
+		return nil, fmt.Errorf("%w: %s", ErrNotDirectory, dir)
+	}
+
+	listing := &models.DirectoryListing{Path: dir, Entries: []models.FileEntry{}}
+	root := ""
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Warnf("CONTAINER-FILES: Unable to read archive of '%s' due: %s", dir, err)
+			return nil, err
+		}
+
+		// The first entry is the directory itself, the others are named
+		// after it.
+		name := strings.TrimSuffix(hdr.Name, "/")
+		if root == "" {
+			root = name + "/"
+			continue
+		}
+		rel := strings.TrimPrefix(name, root)
+		if rel == name || rel == "" || strings.Contains(rel, "/") {
+			continue
+		}
+		listing.Entries = append(listing.Entries, fileEntry(hdr, path.Join(dir, rel)))
+	}
+
+	sort.Slice(listing.Entries, func(i, j int) bool {
+		a, b := listing.Entries[i], listing.Entries[j]
+		if (a.Type == "dir") != (b.Type == "dir") {
+			return a.Type == "dir"
+		}
+		return a.Name < b.Name
+	})
+
+	return listing, nil
+}
+
+// DownloadFiles returns a tar archive of the file or directory of the
+// container with its stat.
+func (c *ContainerService) DownloadFiles(ctx context.Context, id, p string) (io.ReadCloser, container.PathStat, error) {
+	v := &ValidationError{}
+	p = validateContainerPath(v, "path", p)
+	if err := v.err(); err != nil {
+		return nil, container.PathStat{}, err
+	}
+
+	info, err := c.InspectContainer(ctx, id)
+	if err != nil {
+		return nil, container.PathStat{}, err
+	}
+
+	rc, stat, err := c.cli.CopyFromContainer(ctx, info.ID, p)
+	if err != nil {
+		return nil, container.PathStat{}, pathError(err, p)
+	}
+
+	return rc, stat, nil
+}
+
+// UploadFiles writes the files into the directory of the container. With
+// extract set, tar, tar.gz and zip archives are extracted instead of copied.
+func (c *ContainerService) UploadFiles(ctx context.Context, id, dir string, files []UploadFile, extract bool) (*models.UploadResult, error) {
+	v := &ValidationError{}
+	dir = validateContainerPath(v, "path", dir)
+	if len(files) == 0 {
+		v.add("files", "at least one file is required")
+	}
+	for _, f := range files {
+		if name := path.Base(f.Name); name == "." || name == "/" || name == ".." {
+			v.add("files", "invalid file name %q", f.Name)
+		}
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	info, err := c.InspectContainer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkDirectory(ctx, info.ID, dir); err != nil {
+		return nil, err
+	}
+
+	var written []string
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		tw := tar.NewWriter(pw)
+		err := func() error {
+			for _, f := range files {
+				names, err := writeUpload(tw, f, extract)
+				if err != nil {
+					return fmt.Errorf("%s: %w", f.Name, err)
+				}
+				for _, name := range names {
+					written = append(written, path.Join(dir, name))
+				}
+			}
+			return tw.Close()
+		}()
+		pw.CloseWithError(err)
+		done <- err
+	}()
+
+	err = c.cli.CopyToContainer(ctx, info.ID, dir, pr, container.CopyToContainerOptions{})
+	pr.CloseWithError(err)
+	if werr := <-done; werr != nil && !errors.Is(werr, io.ErrClosedPipe) && err == nil {
+		err = werr
+	}
+	if err != nil {
+		log.Warnf("CONTAINER-FILES: Unable to upload into '%s' of container '%s' due: %s", dir, id, err)
+		return nil, err
+	}
+	log.Infof("CONTAINER-FILES: Uploaded %d entries into '%s' of container '%s'", len(written), dir, id)
+
+	return &models.UploadResult{Path: dir, Files: written}, nil
+}
+
+// ReadTextFile returns the content of a small text file of the container.
+func (c *ContainerService) ReadTextFile(ctx context.Context, id, p string) (*models.FileContent, error) {
+	v := &ValidationError{}
+	p = validateContainerPath(v, "path", p)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	info, err := c.InspectContainer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rc, stat, err := c.cli.CopyFromContainer(ctx, info.ID, p)
+	if err != nil {
+		return nil, pathError(err, p)
+	}
+	defer rc.Close()
+
+	switch {
+	case !stat.Mode.IsRegular():
+		return nil, fmt.Errorf("%w: %s is not a regular file", ErrNotTextFile, p)
+	case stat.Size > maxTextFileSize:
+		return nil, fmt.Errorf("%w: %s is larger than 1MB", ErrFileTooLarge, p)
+	}
+
+	tr := tar.NewReader(rc)
+	hdr, err := tr.Next()
+	if err != nil {
+		log.Warnf("CONTAINER-FILES: Unable to read archive of '%s' due: %s", p, err)
+		return nil, err
+	}
+	data, err := io.ReadAll(io.LimitReader(tr, maxTextFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if !isText(data) {
+		return nil, fmt.Errorf("%w: %s looks binary", ErrNotTextFile, p)
+	}
+
+	entry := fileEntry(hdr, p)
+	return &models.FileContent{Path: p, Content: string(data), Size: entry.Size, Mode: entry.Mode, ModTime: entry.ModTime}, nil
+}
+
+// WriteTextFile creates or overwrites a text file of the container, keeping
+// the mode and owner of an existing file.
+func (c *ContainerService) WriteTextFile(ctx context.Context, id, p, content string) (*models.FileContent, error) {
+	v := &ValidationError{}
+	p = validateContainerPath(v, "path", p)
+	if p == "/" {
+		v.add("path", "must be a file")
+	}
+	if len(content) > maxTextFileSize {
+		v.add("content", "must be at most 1MB")
+	}
+	if !utf8.ValidString(content) {
+		v.add("content", "must be valid UTF-8")
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	info, err := c.InspectContainer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	dir := path.Dir(p)
+	if err := c.checkDirectory(ctx, info.ID, dir); err != nil {
+		return nil, err
+	}
+
+	hdr := &tar.Header{Typeflag: tar.TypeReg, Name: path.Base(p), Mode: 0o644}
+	if rc, stat, err := c.cli.CopyFromContainer(ctx, info.ID, p); err == nil {
+		existing, err := tar.NewReader(rc).Next()
+		rc.Close()
+		switch {
+		case !stat.Mode.IsRegular():
+			return nil, fmt.Errorf("%w: %s is not a regular file", ErrNotTextFile, p)
+		case err == nil:
+			hdr.Mode, hdr.Uid, hdr.Gid = existing.Mode, existing.Uid, existing.Gid
+		}
+	} else if !errdefs.IsNotFound(err) {
+		log.Warnf("CONTAINER-FILES: Unable to read '%s' of container '%s' due: %s", p, id, err)
+		return nil, err
+	}
+	hdr.Size = int64(len(content))
+	hdr.ModTime = time.Now()
+
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+	if err := tw.WriteHeader(hdr); err != nil {
+		return nil, err
+	}
+	io.WriteString(tw, content)
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+
+	// The owner of the file is kept from the archive.
+	if err := c.cli.CopyToContainer(ctx, info.ID, dir, buf, container.CopyToContainerOptions{CopyUIDGID: true}); err != nil {
+		log.Warnf("CONTAINER-FILES: Unable to write '%s' of container '%s' due: %s", p, id, err)
+		return nil, err
+	}
+	log.Infof("CONTAINER-FILES: File '%s' of container '%s' written", p, id)
+
+	entry := fileEntry(hdr, p)
+	return &models.FileContent{Path: p, Content: content, Size: entry.Size, Mode: entry.Mode, ModTime: entry.ModTime}, nil
+}
+
+// TarToZip converts a tar archive into a zip archive written to w.
+func TarToZip(w io.Writer, r io.Reader) error {
+	zw := zip.NewWriter(w)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		fh, err := zip.FileInfoHeader(hdr.FileInfo())
+		if err != nil {
+			return err
+		}
+		fh.Name = hdr.Name
+		fh.Modified = hdr.ModTime
+		if hdr.Typeflag == tar.TypeDir {
+			fh.Name = strings.TrimSuffix(hdr.Name, "/") + "/"
+		} else {
+			fh.Method = zip.Deflate
+		}
+
+		out, err := zw.CreateHeader(fh)
+		if err != nil {
+			return err
+		}
+		switch hdr.Typeflag {
+		case tar.TypeSymlink:
+			_, err = io.WriteString(out, hdr.Linkname)
+		case tar.TypeReg:
+			_, err = io.Copy(out, tr)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+func (c *ContainerService) checkDirectory(ctx context.Context, id, dir string) error {
+	stat, err := c.cli.ContainerStatPath(ctx, id, dir)
+	if err != nil {
+		return pathError(err, dir)
+	}
+	if !stat.Mode.IsDir() {
+		return fmt.Errorf("%w: %s", ErrNotDirectory, dir)
+	}
+
+	return nil
+}
+
+// writeUpload adds the file, or the entries of the archive when extract is
+// set, to tw and returns the names written.
+func writeUpload(tw *tar.Writer, f UploadFile, extract bool) ([]string, error) {
+	src, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	name := strings.ToLower(f.Name)
+	switch {
+	case extract && strings.HasSuffix(name, ".zip"):
+		return copyZip(tw, src, f.Size)
+	case extract && (strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz")):
+		gz, err := gzip.NewReader(src)
+		if err != nil {
+			return nil, err
+		}
+		return copyTar(tw, gz)
+	case extract && strings.HasSuffix(name, ".tar"):
+		return copyTar(tw, src)
+	}
+
+	base := path.Base(f.Name)
+	hdr := &tar.Header{Typeflag: tar.TypeReg, Name: base, Mode: 0o644, Size: f.Size, ModTime: time.Now()}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(tw, src); err != nil {
+		return nil, err
+	}
+
+	return []string{base}, nil
+}
+
+func copyTar(tw *tar.Writer, r io.Reader) ([]string, error) {
+	var names []string
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return names, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name, ok := archiveEntryName(hdr.Name)
+		if !ok {
+			continue
+		}
+		hdr.Name = name
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return nil, err
+		}
+		names = append(names, strings.TrimSuffix(name, "/"))
+	}
+}
+
+func copyZip(tw *tar.Writer, r io.ReaderAt, size int64) ([]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, zf := range zr.File {
+		name, ok := archiveEntryName(zf.Name)
+		if !ok {
+			continue
+		}
+
+		hdr, err := tar.FileInfoHeader(zf.FileInfo(), "")
+		if err != nil {
+			return nil, err
+		}
+		hdr.Name = name
+		if zf.FileInfo().IsDir() {
+			hdr.Name = strings.TrimSuffix(name, "/") + "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		if zf.FileInfo().Mode().IsRegular() {
+			src, err := zf.Open()
+			if err != nil {
+				return nil, err
+			}
+			_, err = io.Copy(tw, src)
+			src.Close()
+			if err != nil {
+				return nil, err
+			}
+		}
+		names = append(names, strings.TrimSuffix(name, "/"))
+	}
+
+	return names, nil
+}
+
+// archiveEntryName keeps the entries of an archive inside the upload
+// directory, dropping the ones escaping it.
+func archiveEntryName(name string) (string, bool) {
+	clean := path.Clean("/" + name)
+	if clean == "/" || slices.Contains(strings.Split(name, "/"), "..") {
+		return "", false
+	}
+
+	out := strings.TrimPrefix(clean, "/")
+	if strings.HasSuffix(name, "/") {
+		out += "/"
+	}
+	return out, true
+}
+
+// validateContainerPath checks the path is absolute and cleans it, "/" by
+// default.
+func validateContainerPath(v *ValidationError, field, p string) string {
+	if p == "" {
+		return "/"
+	}
+	if !path.IsAbs(p) {
+		v.add(field, "must be an absolute path")
+	}
+
+	return path.Clean(p)
+}
+
+// pathError reports paths missing from the container as ErrPathNotFound.
+func pathError(err error, p string) error {
+	if errdefs.IsNotFound(err) {
+		return fmt.Errorf("%w: %s", ErrPathNotFound, p)
+	}
+
+	log.Warnf("CONTAINER-FILES: Unable to access '%s' due: %s", p, err)
+	return err
+}
+
+func fileEntry(hdr *tar.Header, p string) models.FileEntry {
+	mode := hdr.FileInfo().Mode()
+	entry := models.FileEntry{
+		Name:       path.Base(p),
+		Path:       p,
+		Size:       hdr.Size,
+		Mode:       mode.String(),
+		ModTime:    hdr.ModTime.UTC(),
+		LinkTarget: hdr.Linkname,
+	}
+	switch {
+	case mode.IsDir():
+		entry.Type = "dir"
+	case mode&os.ModeSymlink != 0:
+		entry.Type = "symlink"
+	case mode.IsRegular():
+		entry.Type = "file"
+	default:
+		entry.Type = "other"
+	}
+
+	return entry
+}
+
+// isText reports whether data is UTF-8 without NUL bytes.
+func isText(data []byte) bool {
+	return utf8.Valid(data) && !bytes.ContainsRune(data, 0)
+}
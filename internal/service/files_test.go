@@ -0,0 +1,199 @@
+package service
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+type memFile struct{ *bytes.Reader }
+
+func (memFile) Close() error { return nil }
+
+func uploadFile(name string, data []byte) UploadFile {
+	return UploadFile{Name: name, Size: int64(len(data)), Open: func() (multipart.File, error) {
+		return memFile{bytes.NewReader(data)}, nil
+	}}
+}
+
+func TestContainerService_ListFiles(t *testing.T) {
+	svc, _, engine, id := newTestRecreate(t)
+	engine.WriteFile(id, "/data/server.properties", []byte("motd=hello"), 0o644)
+	engine.WriteFile(id, "/data/world/level.dat", []byte("level"), 0o600)
+	engine.WriteFile(id, "/data/banned.json", []byte("[]"), 0o644)
+	ctx := context.Background()
+
+	listing, err := svc.ListFiles(ctx, "web", "/data/")
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+
+	var names []string
+	for _, entry := range listing.Entries {
+		names = append(names, entry.Name+":"+entry.Type)
+	}
+	if got := strings.Join(names, ","); got != "world:dir,banned.json:file,server.properties:file" {
+		t.Fatalf("entries = %s", got)
+	}
+	props := listing.Entries[2]
+	if listing.Path != "/data" || props.Path != "/data/server.properties" || props.Size != 10 || props.Mode != "-rw-r--r--" || props.ModTime.IsZero() {
+		t.Fatalf("listing = %+v", listing)
+	}
+
+	root, err := svc.ListFiles(ctx, id, "")
+	if err != nil || len(root.Entries) != 2 || root.Entries[0].Path != "/data" || root.Entries[1].Path != "/usr" {
+		t.Fatalf("ListFiles(/) = %+v, %v", root, err)
+	}
+
+	if _, err := svc.ListFiles(ctx, id, "/data/banned.json"); !errors.Is(err, ErrNotDirectory) {
+		t.Fatalf("ListFiles(file) error = %v, want ErrNotDirectory", err)
+	}
+	if _, err := svc.ListFiles(ctx, id, "/missing"); !errors.Is(err, ErrPathNotFound) {
+		t.Fatalf("ListFiles(missing) error = %v, want ErrPathNotFound", err)
+	}
+	var verr *ValidationError
+	if _, err := svc.ListFiles(ctx, id, "data"); !errors.As(err, &verr) {
+		t.Fatalf("ListFiles(relative) error = %v, want ValidationError", err)
+	}
+}
+
+func TestContainerService_DownloadFilesAsZip(t *testing.T) {
+	svc, _, engine, id := newTestRecreate(t)
+	engine.WriteFile(id, "/data/world/level.dat", []byte("level"), 0o600)
+	if err := engine.ContainerStop(context.Background(), id, container.StopOptions{}); err != nil {
+		t.Fatalf("ContainerStop() error = %v", err)
+	}
+
+	rc, stat, err := svc.DownloadFiles(context.Background(), id, "/data/world")
+	if err != nil {
+		t.Fatalf("DownloadFiles() error = %v", err)
+	}
+	defer rc.Close()
+	if stat.Name != "world" || !stat.Mode.IsDir() {
+		t.Fatalf("stat = %+v", stat)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := TarToZip(buf, rc); err != nil {
+		t.Fatalf("TarToZip() error = %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error = %v", err)
+	}
+	if len(zr.File) != 2 || zr.File[0].Name != "world/" || zr.File[1].Name != "world/level.dat" {
+		t.Fatalf("zip entries = %v", zr.File)
+	}
+	f, _ := zr.File[1].Open()
+	if data, _ := io.ReadAll(f); string(data) != "level" {
+		t.Fatalf("level.dat = %q", data)
+	}
+}
+
+func TestContainerService_UploadFiles(t *testing.T) {
+	svc, _, engine, id := newTestRecreate(t)
+	engine.WriteFile(id, "/data/old.txt", []byte("old"), 0o644)
+	ctx := context.Background()
+
+	tgz := new(bytes.Buffer)
+	gz := gzip.NewWriter(tgz)
+	tw := tar.NewWriter(gz)
+	for name, data := range map[string]string{"plugins/a.jar": "jar", "../escape": "no"} {
+		tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0o644, Size: int64(len(data))})
+		io.WriteString(tw, data)
+	}
+	tw.Close()
+	gz.Close()
+
+	zipped := new(bytes.Buffer)
+	zw := zip.NewWriter(zipped)
+	w, _ := zw.Create("config/b.yml")
+	io.WriteString(w, "b: 1")
+	zw.Close()
+
+	result, err := svc.UploadFiles(ctx, id, "/data", []UploadFile{
+		uploadFile("dir/notes.txt", []byte("notes")),
+		uploadFile("plugins.tar.gz", tgz.Bytes()),
+		uploadFile("config.zip", zipped.Bytes()),
+	}, true)
+	if err != nil {
+		t.Fatalf("UploadFiles() error = %v", err)
+	}
+	if got := strings.Join(result.Files, ","); got != "/data/notes.txt,/data/plugins/a.jar,/data/config/b.yml" {
+		t.Fatalf("files = %s", got)
+	}
+	for name, want := range map[string]string{"/data/notes.txt": "notes", "/data/plugins/a.jar": "jar", "/data/config/b.yml": "b: 1", "/data/old.txt": "old"} {
+		if data, ok := engine.ReadFile(id, name); !ok || string(data) != want {
+			t.Fatalf("%s = %q, %v, want %q", name, data, ok, want)
+		}
+	}
+	if _, ok := engine.ReadFile(id, "/escape"); ok {
+		t.Fatalf("archive entry escaped the upload directory")
+	}
+
+	if _, err := svc.UploadFiles(ctx, id, "/data", []UploadFile{uploadFile("a.zip", zipped.Bytes())}, false); err != nil {
+		t.Fatalf("UploadFiles(no extract) error = %v", err)
+	}
+	if _, ok := engine.ReadFile(id, "/data/a.zip"); !ok {
+		t.Fatalf("archive was not copied as is")
+	}
+
+	if _, err := svc.UploadFiles(ctx, id, "/missing", []UploadFile{uploadFile("a.txt", nil)}, false); !errors.Is(err, ErrPathNotFound) {
+		t.Fatalf("UploadFiles(missing) error = %v, want ErrPathNotFound", err)
+	}
+	var verr *ValidationError
+	if _, err := svc.UploadFiles(ctx, id, "/data", nil, false); !errors.As(err, &verr) {
+		t.Fatalf("UploadFiles(no files) error = %v, want ValidationError", err)
+	}
+}
+
+func TestContainerService_ReadWriteTextFile(t *testing.T) {
+	svc, _, engine, id := newTestRecreate(t)
+	engine.WriteFile(id, "/data/server.properties", []byte("motd=hello\n"), 0o600)
+	engine.WriteFile(id, "/data/level.dat", []byte{0x1f, 0x00, 0x8b}, 0o644)
+	engine.WriteFile(id, "/data/huge.log", bytes.Repeat([]byte("a"), maxTextFileSize+1), 0o644)
+	ctx := context.Background()
+
+	content, err := svc.ReadTextFile(ctx, id, "/data/server.properties")
+	if err != nil {
+		t.Fatalf("ReadTextFile() error = %v", err)
+	}
+	if content.Content != "motd=hello\n" || content.Mode != "-rw-------" || content.Size != 11 {
+		t.Fatalf("content = %+v", content)
+	}
+
+	if _, err := svc.WriteTextFile(ctx, id, "/data/server.properties", "motd=bye\n"); err != nil {
+		t.Fatalf("WriteTextFile() error = %v", err)
+	}
+	content, err = svc.ReadTextFile(ctx, id, "/data/server.properties")
+	if err != nil || content.Content != "motd=bye\n" || content.Mode != "-rw-------" {
+		t.Fatalf("ReadTextFile() after write = %+v, %v", content, err)
+	}
+
+	created, err := svc.WriteTextFile(ctx, id, "/data/ops.json", "[]")
+	if err != nil || created.Mode != "-rw-r--r--" {
+		t.Fatalf("WriteTextFile(new) = %+v, %v", created, err)
+	}
+
+	if _, err := svc.ReadTextFile(ctx, id, "/data/level.dat"); !errors.Is(err, ErrNotTextFile) {
+		t.Fatalf("ReadTextFile(binary) error = %v, want ErrNotTextFile", err)
+	}
+	if _, err := svc.ReadTextFile(ctx, id, "/data"); !errors.Is(err, ErrNotTextFile) {
+		t.Fatalf("ReadTextFile(dir) error = %v, want ErrNotTextFile", err)
+	}
+	if _, err := svc.ReadTextFile(ctx, id, "/data/huge.log"); !errors.Is(err, ErrFileTooLarge) {
+		t.Fatalf("ReadTextFile(huge) error = %v, want ErrFileTooLarge", err)
+	}
+	if _, err := svc.WriteTextFile(ctx, id, "/missing/a.txt", "a"); !errors.Is(err, ErrPathNotFound) {
+		t.Fatalf("WriteTextFile(missing dir) error = %v, want ErrPathNotFound", err)
+	}
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mineServers/internal/database"
+	"mineServers/internal/models"
+
+	"github.com/charmbracelet/log"
+	"github.com/docker/docker/errdefs"
+)
+
+// DefaultEventRetention is how long recorded events are kept by default.
+const DefaultEventRetention = 30 * 24 * time.Hour
+
+const (
+	defaultTimelineLimit = 100
+	maxTimelineLimit     = 1000
+)
+
+var (
+	// eventHostsInterval is how often the recorder picks up added and
+	// deleted hosts.
+	eventHostsInterval = 30 * time.Second
+	// eventRetryDelay is how long the recorder waits before following the
+	// events of a host again once its stream failed.
+	eventRetryDelay = 5 * time.Second
+	// eventPruneInterval is how often the events past retention are deleted.
+	eventPruneInterval = time.Hour
+)
+
+// EventRecorder records the events of every host into the event history,
+// so what happened to a container can be looked up once it is gone. After
+// a restart or a lost connection it resumes after the last recorded event.
+type EventRecorder struct {
+	store     database.EventStore
+	hosts     *HostManager
+	retention time.Duration
+
+	mu        sync.Mutex
+	followers map[string]context.CancelFunc
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// NewEventRecorder creates a recorder keeping events for retention, or
+// forever when it is zero.
+func NewEventRecorder(store database.EventStore, hosts *HostManager, retention time.Duration) *EventRecorder {
+	return &EventRecorder{
+		store:     store,
+		hosts:     hosts,
+		retention: retention,
+		followers: make(map[string]context.CancelFunc),
+	}
+}
+
+// Start follows the events of the hosts in the background until Close.
+func (r *EventRecorder) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.sync(ctx)
+	r.prune(ctx)
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		hosts := time.NewTicker(eventHostsInterval)
+		defer hosts.Stop()
+		prune := time.NewTicker(eventPruneInterval)
+		defer prune.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hosts.C:
+				r.sync(ctx)
+			case <-prune.C:
+				r.prune(ctx)
+			}
+		}
+	}()
+}
+
+// Close stops recording, waiting for the events being stored.
+func (r *EventRecorder) Close() error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+	return nil
+}
+
+// Timeline returns a page of the recorded events of a container, newest
+// first. Containers that no longer exist are looked up by the name or ID
+// they had.
+func (r *EventRecorder) Timeline(ctx context.Context, host, ref string, q *models.TimelineQuery) (*models.Timeline, error) {
+	query := &database.EventQuery{Host: host, ContainerID: ref, Name: ref, Limit: defaultTimelineLimit}
+	if host == "" {
+		query.Host = LocalHost
+	}
+
+	v := &ValidationError{}
+	if q.From != "" {
+		from, err := time.Parse(time.RFC3339, q.From)
+		if err != nil {
+			v.add("from", "must be an RFC 3339 time")
+		}
+		query.From = from
+	}
+	if q.To != "" {
+		to, err := time.Parse(time.RFC3339, q.To)
+		if err != nil {
+			v.add("to", "must be an RFC 3339 time")
+		}
+		query.To = to
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		v.add("to", "must be after from")
+	}
+	if q.Before != "" {
+		before, err := strconv.ParseInt(q.Before, 10, 64)
+		if err != nil || before <= 0 {
+			v.add("before", "must be the next cursor of a timeline")
+		}
+		query.Before = before
+	}
+	switch {
+	case q.Limit < 0 || q.Limit > maxTimelineLimit:
+		v.add("limit", "must be between 1 and %d", maxTimelineLimit)
+	case q.Limit > 0:
+		query.Limit = q.Limit
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	svc, err := r.hosts.Resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	info, err := svc.cli.ContainerInspect(ctx, ref)
+	switch {
+	case err == nil:
+		query.ContainerID = info.ID
+		query.Name = strings.TrimPrefix(info.Name, "/")
+	case !errdefs.IsNotFound(err):
+		return nil, err
+	}
+
+	// One more event tells whether there is a next page.
+	limit := query.Limit
+	query.Limit++
+	events, err := r.store.ListContainerEvents(ctx, query)
+	if err != nil {
+		log.Warnf("EVENTS: Unable to list the events of container %s due: %s", ref, err)
+		return nil, err
+	}
+
+	timeline := &models.Timeline{Events: events}
+	if len(events) > limit {
+		timeline.Events = events[:limit]
+		timeline.Next = events[limit-1].ID
+	}
+
+	return timeline, nil
+}
+
+// sync follows the hosts added since the last call and stops following the
+// deleted ones.
+func (r *EventRecorder) sync(ctx context.Context) {
+	hosts, err := r.hosts.ListHosts(ctx)
+	if err != nil {
+		log.Warnf("EVENTS: Unable to list hosts due: %s", err)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		seen[host.Name] = true
+		if _, ok := r.followers[host.Name]; ok {
+			continue
+		}
+
+		followCtx, cancel := context.WithCancel(ctx)
+		r.followers[host.Name] = cancel
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			r.follow(followCtx, host.Name)
+		}()
+	}
+	for name, cancel := range r.followers {
+		if !seen[name] {
+			cancel()
+			delete(r.followers, name)
+		}
+	}
+}
+
+// follow records the events of a host until ctx is done, following them
+// again whenever the stream fails.
+func (r *EventRecorder) follow(ctx context.Context, host string) {
+	for {
+		if err := r.record(ctx, host); err != nil && ctx.Err() == nil {
+			log.Warnf("EVENTS: Unable to follow the events of host %s due: %s", host, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(eventRetryDelay):
+		}
+	}
+}
+
+func (r *EventRecorder) record(ctx context.Context, host string) error {
+	svc, err := r.hosts.Resolve(ctx, host)
+	if err != nil {
+		return err
+	}
+	after, err := r.store.LastEventID(ctx, host)
+	if err != nil {
+		return err
+	}
+	events, errs, err := svc.StreamEvents(ctx, &models.EventFilter{}, after)
+	if err != nil {
+		return err
+	}
+
+	// The daemon reports an oom before the die of the container it killed.
+	oomKilled := map[string]bool{}
+	for event := range events {
+		recorded := &models.RecordedEvent{Event: event, Host: host}
+		if event.Type == "container" {
+			switch event.Action {
+			case "oom":
+				oomKilled[event.ActorID] = true
+				recorded.OOMKilled = true
+			case "die":
+				if code, err := strconv.Atoi(event.Attributes["exitCode"]); err == nil {
+					recorded.ExitCode = &code
+				}
+				recorded.OOMKilled = oomKilled[event.ActorID]
+				delete(oomKilled, event.ActorID)
+			}
+		}
+		if err := r.store.RecordEvent(ctx, recorded); err != nil && ctx.Err() == nil {
+			log.Warnf("EVENTS: Unable to record %s %s event of host %s due: %s", event.Type, event.Action, host, err)
+		}
+	}
+
+	return <-errs
+}
+
+// prune deletes the events past retention.
+func (r *EventRecorder) prune(ctx context.Context) {
+	if r.retention <= 0 {
+		return
+	}
+
+	n, err := r.store.PruneEvents(ctx, time.Now().Add(-r.retention))
+	if err != nil {
+		log.Warnf("EVENTS: Unable to prune events due: %s", err)
+		return
+	}
+	if n > 0 {
+		log.Infof("EVENTS: %d events past retention pruned", n)
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"mineServers/internal/models"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+)
+
+func TestEventRecorder_RecordsTimeline(t *testing.T) {
+	db := openTestDB(t)
+
+	hosts, engine := newTestHostManager(t)
+	ctx := context.Background()
+	history := NewEventRecorder(db, hosts, 0)
+
+	// The recorder resumes after the last recorded event, so the events
+	// below are recorded even when they happen before it follows the host.
+	seed := &models.RecordedEvent{Event: models.Event{ID: strconv.FormatInt(time.Now().UnixNano(), 10), Type: "daemon", Action: "reload"}, Host: LocalHost}
+	if err := db.RecordEvent(ctx, seed); err != nil {
+		t.Fatalf("RecordEvent() error = %v", err)
+	}
+	history.Start(ctx)
+	t.Cleanup(func() { history.Close() })
+
+	engine.AddImage("docker.io/library/alpine:latest")
+	resp, err := engine.ContainerCreate(ctx, &container.Config{Image: "docker.io/library/alpine:latest"}, nil, nil, nil, "mc")
+	if err != nil {
+		t.Fatalf("ContainerCreate() error = %v", err)
+	}
+	if err := engine.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+		t.Fatalf("ContainerStart() error = %v", err)
+	}
+	engine.Emit(events.Message{Type: events.ContainerEventType, Action: events.ActionOOM, Actor: events.Actor{ID: resp.ID, Attributes: map[string]string{"name": "mc"}}})
+	if err := engine.ContainerKill(ctx, resp.ID, "SIGKILL"); err != nil {
+		t.Fatalf("ContainerKill() error = %v", err)
+	}
+	if err := engine.ContainerRemove(ctx, resp.ID, container.RemoveOptions{}); err != nil {
+		t.Fatalf("ContainerRemove() error = %v", err)
+	}
+
+	// create, start, oom, kill, die and destroy
+	var timeline *models.Timeline
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		timeline, err = history.Timeline(ctx, "", "mc", &models.TimelineQuery{})
+		if err != nil {
+			t.Fatalf("Timeline() error = %v", err)
+		}
+		if len(timeline.Events) == 6 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(timeline.Events) != 6 || timeline.Next != "" {
+		t.Fatalf("timeline = %+v", timeline)
+	}
+	die := timeline.Events[1]
+	if die.Action != "die" || die.ExitCode == nil || *die.ExitCode != 137 || !die.OOMKilled || die.Host != LocalHost {
+		t.Fatalf("die event = %+v", die)
+	}
+	if timeline.Events[0].Action != "destroy" || timeline.Events[5].Action != "create" {
+		t.Fatalf("timeline = %+v", timeline.Events)
+	}
+
+	page, err := history.Timeline(ctx, "", resp.ID, &models.TimelineQuery{Limit: 4})
+	if err != nil || len(page.Events) != 4 || page.Next != page.Events[3].ID {
+		t.Fatalf("Timeline(limit) = %+v, %v", page, err)
+	}
+	page, err = history.Timeline(ctx, "", resp.ID, &models.TimelineQuery{Limit: 4, Before: page.Next})
+	if err != nil || len(page.Events) != 2 || page.Next != "" || page.Events[1].Action != "create" {
+		t.Fatalf("Timeline(before) = %+v, %v", page, err)
+	}
+	page, err = history.Timeline(ctx, "", "mc", &models.TimelineQuery{To: timeline.Events[5].Time.Add(-time.Second).Format(time.RFC3339)})
+	if err != nil || len(page.Events) != 0 {
+		t.Fatalf("Timeline(to) = %+v, %v", page, err)
+	}
+
+	var verr *ValidationError
+	for _, q := range []models.TimelineQuery{{From: "yesterday"}, {Before: "abc"}, {Limit: 5000}, {From: "2026-01-02T00:00:00Z", To: "2026-01-01T00:00:00Z"}} {
+		if _, err := history.Timeline(ctx, "", "mc", &q); !errors.As(err, &verr) {
+			t.Errorf("Timeline(%+v) error = %v, want ValidationError", q, err)
+		}
+	}
+	if _, err := history.Timeline(ctx, "remote", "mc", &models.TimelineQuery{}); !errors.Is(err, ErrHostNotFound) {
+		t.Errorf("Timeline(unknown host) error = %v, want ErrHostNotFound", err)
+	}
+}
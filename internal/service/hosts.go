@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sync"
+	"time"
+
+	"mineServers/internal/database"
+	"mineServers/internal/models"
+
+	"github.com/charmbracelet/log"
+	"github.com/docker/docker/client"
+)
+
+// LocalHost is the name of the daemon configured through the environment.
+// It always exists and cannot be modified through the API.
+const LocalHost = "local"
+
+var (
+	ErrHostNotFound = errors.New("host not found")
+	ErrHostReadOnly = errors.New("the local host cannot be modified")
+	ErrInvalidHost  = errors.New("invalid host")
+
+	hostNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+)
+
+// HostDialer creates a Docker client for a registered host.
+type HostDialer func(host models.Host) (DockerAPI, error)
+
+// HostManager keeps one ContainerService per Docker host, creating the
+// clients of registered hosts lazily on first use.
+type HostManager struct {
+	ctx   context.Context
+	local *ContainerService
+	store database.HostStore
+	dial  HostDialer
+
+	mu       sync.Mutex
+	services map[string]*ContainerService
+}
+
+// NewHostManager creates a manager serving local as the default host. A nil
+// dial uses NewHostClient, and a nil store only exposes the local host.
+func NewHostManager(ctx context.Context, local *ContainerService, store database.HostStore, dial HostDialer) *HostManager {
+	if dial == nil {
+		dial = NewHostClient
+	}
+
+	return &HostManager{
+		ctx:      ctx,
+		local:    local,
+		store:    store,
+		dial:     dial,
+		services: make(map[string]*ContainerService),
+	}
+}
+
+// Local returns the service of the daemon configured through the environment.
+func (h *HostManager) Local() *ContainerService {
+	return h.local
+}
+
+// Resolve returns the ContainerService of the named host. An empty name
+// selects the local host.
+func (h *HostManager) Resolve(ctx context.Context, name string) (*ContainerService, error) {
+	if name == "" || name == LocalHost {
+		return h.local, nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if svc, ok := h.services[name]; ok {
+		return svc, nil
+	}
+
+	host, err := h.getHost(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	cli, err := h.dial(*host)
+	if err != nil {
+		log.Warnf("HOSTS: Unable to create docker client for host '%s' due: %s", name, err)
+		return nil, err
+	}
+
+	svc := NewContainerService(h.ctx, cli)
+	h.services[name] = svc
+
+	return svc, nil
+}
+
+func (h *HostManager) ListHosts(ctx context.Context) ([]models.Host, error) {
+	hosts := []models.Host{h.localHost()}
+	if h.store == nil {
+		return hosts, nil
+	}
+
+	stored, err := h.store.ListHosts(ctx)
+	if err != nil {
+		log.Warnf("HOSTS: Unable to list hosts due: %s", err)
+		return nil, err
+	}
+
+	return append(hosts, stored...), nil
+}
+
+func (h *HostManager) GetHost(ctx context.Context, name string) (*models.Host, error) {
+	if name == LocalHost {
+		host := h.localHost()
+		return &host, nil
+	}
+
+	return h.getHost(ctx, name)
+}
+
+func (h *HostManager) CreateHost(ctx context.Context, host *models.Host) error {
+	if err := validateHost(host); err != nil {
+		return err
+	}
+	if h.store == nil {
+		return ErrHostReadOnly
+	}
+
+	return h.store.CreateHost(ctx, host)
+}
+
+// UpdateHost stores the new endpoint configuration and drops the cached
+// client so the next request reconnects with it.
+func (h *HostManager) UpdateHost(ctx context.Context, host *models.Host) error {
+	if host.Name == LocalHost || h.store == nil {
+		return ErrHostReadOnly
+	}
+	if err := validateHost(host); err != nil {
+		return err
+	}
+
+	if err := h.store.UpdateHost(ctx, host); err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return ErrHostNotFound
+		}
+		return err
+	}
+	h.forget(host.Name)
+
+	stored, err := h.store.GetHost(ctx, host.Name)
+	if err != nil {
+		return err
+	}
+	*host = *stored
+
+	return nil
+}
+
+func (h *HostManager) DeleteHost(ctx context.Context, name string) error {
+	if name == LocalHost || h.store == nil {
+		return ErrHostReadOnly
+	}
+
+	if err := h.store.DeleteHost(ctx, name); err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return ErrHostNotFound
+		}
+		return err
+	}
+	h.forget(name)
+
+	return nil
+}
+
+// Status pings the daemon of the host and reports whether it is reachable.
+func (h *HostManager) Status(ctx context.Context, host models.Host) models.HostStatus {
+	status := models.HostStatus{Host: host, Status: "down"}
+
+	svc, err := h.Resolve(ctx, host.Name)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	ping, err := svc.Ping(ctx)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+
+	status.Status = "up"
+	status.APIVersion = ping.APIVersion
+	status.OSType = ping.OSType
+
+	return status
+}
+
+// Services returns the ContainerService of every known host, keyed by name.
+// Hosts whose client cannot be created are skipped.
+func (h *HostManager) Services(ctx context.Context) map[string]*ContainerService {
+	out := map[string]*ContainerService{LocalHost: h.local}
+
+	hosts, err := h.ListHosts(ctx)
+	if err != nil {
+		return out
+	}
+	for _, host := range hosts {
+		if svc, err := h.Resolve(ctx, host.Name); err == nil {
+			out[host.Name] = svc
+		}
+	}
+
+	return out
+}
+
+// Close releases the clients of every host, including the local one.
+func (h *HostManager) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for name, svc := range h.services {
+		svc.Close()
+		delete(h.services, name)
+	}
+
+	return h.local.Close()
+}
+
+func (h *HostManager) getHost(ctx context.Context, name string) (*models.Host, error) {
+	if h.store == nil {
+		return nil, ErrHostNotFound
+	}
+
+	host, err := h.store.GetHost(ctx, name)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return nil, ErrHostNotFound
+		}
+		log.Warnf("HOSTS: Unable to get host '%s' due: %s", name, err)
+		return nil, err
+	}
+
+	return host, nil
+}
+
+func (h *HostManager) forget(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if svc, ok := h.services[name]; ok {
+		svc.Close()
+		delete(h.services, name)
+	}
+}
+
+func (h *HostManager) localHost() models.Host {
+	endpoint := client.DefaultDockerHost
+	if env := h.local.DaemonHost(); env != "" {
+		endpoint = env
+	}
+
+	return models.Host{Name: LocalHost, URL: endpoint}
+}
+
+func validateHost(host *models.Host) error {
+	if !hostNameRegex.MatchString(host.Name) || host.Name == LocalHost {
+		return fmt.Errorf("%w: name must be alphanumeric and cannot be '%s'", ErrInvalidHost, LocalHost)
+	}
+
+	u, err := url.Parse(host.URL)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidHost, err)
+	}
+
+	switch u.Scheme {
+	case "unix":
+		if u.Path == "" {
+			return fmt.Errorf("%w: unix endpoints need a socket path", ErrInvalidHost)
+		}
+	case "tcp":
+		if u.Host == "" {
+			return fmt.Errorf("%w: tcp endpoints need a host and port", ErrInvalidHost)
+		}
+	case "ssh":
+		if u.Hostname() == "" {
+			return fmt.Errorf("%w: ssh endpoints need a host", ErrInvalidHost)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported scheme '%s', use unix, tcp or ssh", ErrInvalidHost, u.Scheme)
+	}
+
+	hasTLS := host.TLSCACert != "" || host.TLSCert != "" || host.TLSKey != ""
+	if hasTLS && u.Scheme != "tcp" {
+		return fmt.Errorf("%w: TLS certificates are only supported for tcp endpoints", ErrInvalidHost)
+	}
+	if (host.TLSCert == "") != (host.TLSKey == "") {
+		return fmt.Errorf("%w: tls_cert and tls_key must be set together", ErrInvalidHost)
+	}
+
+	return nil
+}
+
+// NewHostClient creates a client for a registered host. Unlike
+// NewDockerClient it ignores the DOCKER_* environment variables.
+func NewHostClient(host models.Host) (DockerAPI, error) {
+	opts := []client.Opt{client.WithAPIVersionNegotiation()}
+
+	u, err := url.Parse(host.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "ssh":
+		// The client still needs an HTTP host, the connection itself is
+		// tunnelled through ssh.
+		opts = append(opts, client.WithHost("http://docker.example.com"), client.WithDialContext(sshDialer(u)))
+	default:
+		opts = append(opts, client.WithHost(host.URL))
+	}
+
+	if host.TLSCACert != "" || host.TLSCert != "" {
+		opts = append(opts, client.WithTLSClientConfig(host.TLSCACert, host.TLSCert, host.TLSKey))
+	}
+
+	cli, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return cli, nil
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"mineServers/internal/models"
+
+	"github.com/charmbracelet/log"
+	"github.com/docker/docker/api/types/container"
+)
+
+// Operations that change the state of a container.
+const (
+	ActionStop    = "stop"
+	ActionRestart = "restart"
+	ActionKill    = "kill"
+	ActionPause   = "pause"
+	ActionUnpause = "unpause"
+	ActionRename  = "rename"
+)
+
+// defaultStopTimeout is how many seconds stop and restart wait before
+// killing a container when the caller gives no timeout.
+const defaultStopTimeout = 10
+
+// maxSignal is the highest real-time signal number on Linux.
+const maxSignal = 64
+
+// signalNumbers maps the Linux signal names, without their SIG prefix, to
+// their numbers.
+var signalNumbers = map[string]int{
+	"HUP": 1, "INT": 2, "QUIT": 3, "ILL": 4, "TRAP": 5, "ABRT": 6, "BUS": 7, "FPE": 8,
+	"KILL": 9, "USR1": 10, "SEGV": 11, "USR2": 12, "PIPE": 13, "ALRM": 14, "TERM": 15,
+	"STKFLT": 16, "CHLD": 17, "CONT": 18, "STOP": 19, "TSTP": 20, "TTIN": 21, "TTOU": 22,
+	"URG": 23, "XCPU": 24, "XFSZ": 25, "VTALRM": 26, "PROF": 27, "WINCH": 28, "IO": 29,
+	"PWR": 30, "SYS": 31,
+}
+
+// ChangeContainerState runs a stop, restart, kill, pause or unpause on the
+// container and reports its state before and after.
+func (c *ContainerService) ChangeContainerState(ctx context.Context, id, action string, opts *models.StateChange) (*models.StateTransition, error) {
+	if opts == nil {
+		opts = &models.StateChange{}
+	}
+
+	v := &ValidationError{}
+	signal := ""
+	if opts.Signal != "" {
+		var err error
+		if signal, err = parseSignal(opts.Signal); err != nil {
+			v.add("signal", "%s", err)
+		}
+	}
+	switch action {
+	case ActionStop, ActionRestart:
+		if opts.Timeout != nil && *opts.Timeout < -1 {
+			v.add("timeout", "must be -1 (wait forever) or a number of seconds")
+		}
+	case ActionKill:
+		if opts.Timeout != nil {
+			v.add("timeout", "is not supported by %s", action)
+		}
+		if signal == "" {
+			signal = "SIGKILL"
+		}
+	case ActionPause, ActionUnpause:
+		if opts.Timeout != nil {
+			v.add("timeout", "is not supported by %s", action)
+		}
+		if opts.Signal != "" {
+			v.add("signal", "is not supported by %s", action)
+		}
+	default:
+		return nil, fmt.Errorf("unknown container action %q", action)
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	info, err := c.InspectContainer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	transition := &models.StateTransition{
+		ID:     info.ID,
+		Name:   strings.TrimPrefix(info.Name, "/"),
+		Action: action,
+		Signal: signal,
+		From:   info.State.Status,
+	}
+
+	stop := container.StopOptions{Signal: signal, Timeout: opts.Timeout}
+	switch action {
+	case ActionStop:
+		err = c.cli.ContainerStop(ctx, info.ID, stop)
+	case ActionRestart:
+		err = c.cli.ContainerRestart(ctx, info.ID, stop)
+	case ActionKill:
+		err = c.cli.ContainerKill(ctx, info.ID, signal)
+	case ActionPause:
+		err = c.cli.ContainerPause(ctx, info.ID)
+	case ActionUnpause:
+		err = c.cli.ContainerUnpause(ctx, info.ID)
+	}
+	if err != nil {
+		log.Warnf("CONTAINER-STATE: Unable to %s container '%s' due: %s", action, transition.Name, err)
+		return nil, err
+	}
+
+	if transition.To, err = c.containerState(ctx, info.ID); err != nil {
+		return nil, err
+	}
+	log.Infof("CONTAINER-STATE: Container '%s' %s: %s -> %s", transition.Name, action, transition.From, transition.To)
+
+	return transition, nil
+}
+
+// RenameContainer gives the container a new name.
+func (c *ContainerService) RenameContainer(ctx context.Context, id, name string) (*models.StateTransition, error) {
+	v := &ValidationError{}
+	name = strings.TrimPrefix(name, "/")
+	if !containerNameRegex.MatchString(name) {
+		v.add("name", "must match %s", containerNameRegex)
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	info, err := c.InspectContainer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	transition := &models.StateTransition{
+		ID:           info.ID,
+		Name:         name,
+		PreviousName: strings.TrimPrefix(info.Name, "/"),
+		Action:       ActionRename,
+		From:         info.State.Status,
+	}
+	if transition.PreviousName == name {
+		v.add("name", "is already the name of the container")
+		return nil, v.err()
+	}
+
+	if err := c.cli.ContainerRename(ctx, info.ID, name); err != nil {
+		log.Warnf("CONTAINER-RENAME: Unable to rename container '%s' due: %s", transition.PreviousName, err)
+		return nil, err
+	}
+
+	if transition.To, err = c.containerState(ctx, info.ID); err != nil {
+		return nil, err
+	}
+	log.Infof("CONTAINER-RENAME: Container '%s' renamed to '%s'", transition.PreviousName, name)
+
+	return transition, nil
+}
+
+func (c *ContainerService) containerState(ctx context.Context, id string) (string, error) {
+	info, err := c.InspectContainer(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	return info.State.Status, nil
+}
+
+// parseSignal accepts a signal name with or without its SIG prefix, in any
+// case, or a signal number. Names are returned as SIGNAME.
+func parseSignal(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if n, err := strconv.Atoi(value); err == nil {
+		if n < 1 || n > maxSignal {
+			return "", fmt.Errorf("signal number must be between 1 and %d", maxSignal)
+		}
+		return value, nil
+	}
+
+	name := strings.TrimPrefix(strings.ToUpper(value), "SIG")
+	if _, ok := signalNumbers[name]; !ok {
+		return "", fmt.Errorf("unknown signal %q", value)
+	}
+
+	return "SIG" + name, nil
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"mineServers/internal/models"
+
+	"github.com/docker/docker/errdefs"
+)
+
+func TestContainerService_ChangeContainerState(t *testing.T) {
+	svc, _, engine, id := newTestRecreate(t)
+	ctx := context.Background()
+
+	timeout := 30
+	steps := []struct {
+		action   string
+		opts     *models.StateChange
+		from, to string
+	}{
+		{ActionKill, &models.StateChange{Signal: "hup"}, "running", "running"},
+		{ActionPause, nil, "running", "paused"},
+		{ActionUnpause, nil, "paused", "running"},
+		{ActionRestart, &models.StateChange{Timeout: &timeout}, "running", "running"},
+		{ActionStop, &models.StateChange{Signal: "SIGQUIT"}, "running", "exited"},
+	}
+	for _, step := range steps {
+		transition, err := svc.ChangeContainerState(ctx, "web", step.action, step.opts)
+		if err != nil {
+			t.Fatalf("%s: ChangeContainerState() error = %v", step.action, err)
+		}
+		if transition.ID != id || transition.Action != step.action || transition.From != step.from || transition.To != step.to {
+			t.Errorf("%s: unexpected transition %+v", step.action, transition)
+		}
+	}
+
+	if web, _ := engine.Container(id); len(web.Signals) != 2 || web.Signals[0] != "SIGHUP" || web.Signals[1] != "SIGQUIT" {
+		t.Errorf("expected SIGHUP then SIGQUIT, got %v", web.Signals)
+	}
+
+	if _, err := svc.ChangeContainerState(ctx, id, ActionPause, nil); !errdefs.IsConflict(err) {
+		t.Errorf("expected pausing a stopped container to conflict, got %v", err)
+	}
+}
+
+func TestContainerService_ChangeContainerStateValidatesOptions(t *testing.T) {
+	svc, _, _, id := newTestRecreate(t)
+	timeout := -5
+
+	_, err := svc.ChangeContainerState(context.Background(), id, ActionStop, &models.StateChange{Timeout: &timeout, Signal: "SIGNOPE"})
+	var verr *ValidationError
+	if !errors.As(err, &verr) || len(verr.Fields) != 2 {
+		t.Errorf("expected the timeout and signal errors, got %v", err)
+	}
+
+	_, err = svc.ChangeContainerState(context.Background(), id, ActionPause, &models.StateChange{Signal: "SIGHUP"})
+	if !errors.As(err, &verr) {
+		t.Errorf("expected pause to refuse a signal, got %v", err)
+	}
+}
+
+func TestContainerService_RenameContainer(t *testing.T) {
+	svc, _, engine, id := newTestRecreate(t)
+	ctx := context.Background()
+
+	transition, err := svc.RenameContainer(ctx, "web", "web-old")
+	if err != nil {
+		t.Fatalf("RenameContainer() error = %v", err)
+	}
+	if transition.Name != "web-old" || transition.PreviousName != "web" || transition.From != "running" || transition.To != "running" {
+		t.Errorf("unexpected transition %+v", transition)
+	}
+	if web, ok := engine.Container(id); !ok || web.Name != "web-old" {
+		t.Errorf("expected the container to be renamed, got %+v", web)
+	}
+
+	var verr *ValidationError
+	if _, err := svc.RenameContainer(ctx, id, "web-old"); !errors.As(err, &verr) {
+		t.Errorf("expected renaming to the current name to be rejected, got %v", err)
+	}
+}
+
+func TestParseSignal(t *testing.T) {
+	for value, want := range map[string]string{"SIGHUP": "SIGHUP", "hup": "SIGHUP", "sigusr1": "SIGUSR1", "9": "9", "0": "", "65": "", "FOO": ""} {
+		got, err := parseSignal(value)
+		if got != want || (err == nil) != (want != "") {
+			t.Errorf("parseSignal(%q) = %q, %v, want %q", value, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"mineServers/internal/models"
+
+	"github.com/charmbracelet/log"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/errdefs"
+)
+
+// Aggregate states of a compose project, stacks included.
+const (
+	ProjectRunning = "running"
+	ProjectPartial = "partial"
+	ProjectStopped = "stopped"
+	ProjectDown    = "down"
+)
+
+var ErrProjectNotFound = errors.New("project not found")
+
+// ListProjects groups the containers of the host carrying compose labels by
+// project, whether they were started by docker compose or as a stack.
+func (c *ContainerService) ListProjects(ctx context.Context) ([]models.Project, error) {
+	list, err := c.projectContainerList(ctx, filters.NewArgs(filters.Arg("label", LabelProject)))
+	if err != nil {
+		return nil, err
+	}
+
+	grouped := map[string][]container.Summary{}
+	for _, box := range list {
+		name := box.Labels[LabelProject]
+		grouped[name] = append(grouped[name], box)
+	}
+
+	out := make([]models.Project, 0, len(grouped))
+	for _, name := range sortedKeys(grouped) {
+		out = append(out, buildProject(name, grouped[name]))
+	}
+
+	return out, nil
+}
+
+// GetProject returns the containers of a compose project in dependency order.
+func (c *ContainerService) GetProject(ctx context.Context, name string) (*models.Project, error) {
+	list, err := c.orderedProject(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	project := buildProject(name, list)
+	return &project, nil
+}
+
+// StartProject starts the stopped containers of a project, dependencies first.
+func (c *ContainerService) StartProject(ctx context.Context, name string) (*models.Project, error) {
+	list, err := c.orderedProject(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, box := range list {
+		if box.State == "running" || box.State == "paused" {
+			continue
+		}
+		if err := c.StartContainer(ctx, box.ID); err != nil {
+			return nil, fmt.Errorf("service %s: %w", box.Labels[LabelService], err)
+		}
+	}
+
+	log.Infof("PROJECTS: Project '%s' started", name)
+	return c.GetProject(ctx, name)
+}
+
+// StopProject stops the running containers of a project, dependents first.
+func (c *ContainerService) StopProject(ctx context.Context, name string) (*models.Project, error) {
+	list, err := c.orderedProject(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.stopContainers(ctx, list); err != nil {
+		return nil, err
+	}
+
+	log.Infof("PROJECTS: Project '%s' stopped", name)
+	return c.GetProject(ctx, name)
+}
+
+// RestartProject restarts every container of a project, dependencies first.
+func (c *ContainerService) RestartProject(ctx context.Context, name string) (*models.Project, error) {
+	list, err := c.orderedProject(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.restartContainers(ctx, list); err != nil {
+		return nil, err
+	}
+
+	log.Infof("PROJECTS: Project '%s' restarted", name)
+	return c.GetProject(ctx, name)
+}
+
+// RemoveProject stops and removes the containers of a project, dependents
+// first, then its networks and, when asked to, its volumes, like
+// `docker compose down`.
+func (c *ContainerService) RemoveProject(ctx context.Context, name string, removeVolumes bool) error {
+	list, err := c.orderedProject(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if err := c.downProject(ctx, name, list, removeVolumes); err != nil {
+		return err
+	}
+
+	log.Infof("PROJECTS: Project '%s' removed", name)
+	return nil
+}
+
+// orderedProject returns the containers of the project in dependency
+// order, failing with ErrProjectNotFound when there is none.
+func (c *ContainerService) orderedProject(ctx context.Context, name string) ([]container.Summary, error) {
+	list, err := c.projectContainerList(ctx, projectFilter(name))
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, ErrProjectNotFound
+	}
+
+	return orderByDependencies(list), nil
+}
+
+func (c *ContainerService) projectContainerList(ctx context.Context, args filters.Args) ([]container.Summary, error) {
+	list, err := c.cli.ContainerList(ctx, container.ListOptions{All: true, Filters: args})
+	if err != nil {
+		log.Warnf("PROJECTS: Unable to list project containers due: %s", err)
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// stopContainers stops the running containers of list in reverse order.
+func (c *ContainerService) stopContainers(ctx context.Context, list []container.Summary) error {
+	for i := len(list) - 1; i >= 0; i-- {
+		if list[i].State != "running" && list[i].State != "paused" {
+			continue
+		}
+		if err := c.StopContainer(ctx, list[i].ID); err != nil {
+			return fmt.Errorf("service %s: %w", list[i].Labels[LabelService], err)
+		}
+	}
+
+	return nil
+}
+
+func (c *ContainerService) restartContainers(ctx context.Context, list []container.Summary) error {
+	for _, box := range list {
+		if err := c.RestartContainer(ctx, box.ID); err != nil {
+			return fmt.Errorf("service %s: %w", box.Labels[LabelService], err)
+		}
+	}
+
+	return nil
+}
+
+// downProject stops and removes list, the ordered containers of the
+// project, then the networks and optionally the volumes labelled with it.
+func (c *ContainerService) downProject(ctx context.Context, name string, list []container.Summary, removeVolumes bool) error {
+	if err := c.stopContainers(ctx, list); err != nil {
+		return err
+	}
+	for i := len(list) - 1; i >= 0; i-- {
+		if err := c.RemoveContainer(ctx, list[i].ID); err != nil {
+			return fmt.Errorf("service %s: %w", list[i].Labels[LabelService], err)
+		}
+	}
+
+	networks, err := c.cli.NetworkList(ctx, network.ListOptions{Filters: projectFilter(name)})
+	if err != nil {
+		log.Warnf("PROJECTS: Unable to list networks due: %s", err)
+		return err
+	}
+	for _, n := range networks {
+		if err := c.cli.NetworkRemove(ctx, n.ID); err != nil && !errdefs.IsNotFound(err) {
+			log.Warnf("PROJECTS: Unable to remove network '%s' due: %s", n.Name, err)
+			return err
+		}
+	}
+
+	if !removeVolumes {
+		return nil
+	}
+
+	volumes, err := c.cli.VolumeList(ctx, volume.ListOptions{Filters: projectFilter(name)})
+	if err != nil {
+		log.Warnf("PROJECTS: Unable to list volumes due: %s", err)
+		return err
+	}
+	for _, vol := range volumes.Volumes {
+		if err := c.cli.VolumeRemove(ctx, vol.Name, false); err != nil && !errdefs.IsNotFound(err) {
+			log.Warnf("PROJECTS: Unable to remove volume '%s' due: %s", vol.Name, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func buildProject(name string, list []container.Summary) models.Project {
+	project := models.Project{
+		Name:       name,
+		Services:   []string{},
+		Containers: make([]models.ProjectContainer, 0, len(list)),
+	}
+
+	seen := map[string]bool{}
+	states := make([]string, 0, len(list))
+	for _, box := range list {
+		service := box.Labels[LabelService]
+		if !seen[service] {
+			seen[service] = true
+			project.Services = append(project.Services, service)
+		}
+		if project.WorkingDir == "" {
+			project.WorkingDir = box.Labels[LabelWorkingDir]
+			project.ConfigFiles = box.Labels[LabelConfigFiles]
+		}
+
+		number, _ := strconv.Atoi(box.Labels[LabelContainerNumber])
+		pc := models.ProjectContainer{
+			ID:        box.ID,
+			Service:   service,
+			Number:    number,
+			Image:     box.Image,
+			State:     box.State,
+			Status:    box.Status,
+			DependsOn: sortedKeys(dependsOn(box.Labels)),
+		}
+		if len(box.Names) > 0 {
+			pc.Name = strings.TrimPrefix(box.Names[0], "/")
+		}
+		project.Containers = append(project.Containers, pc)
+		states = append(states, box.State)
+	}
+	project.State = aggregateState(states)
+
+	return project
+}
+
+// orderByDependencies sorts containers so the services they depend on,
+// read from the compose depends_on label, come first. Services caught in a
+// cycle come last; replicas of a service follow their number.
+func orderByDependencies(list []container.Summary) []container.Summary {
+	byService := map[string][]container.Summary{}
+	deps := map[string]map[string]string{}
+	for _, box := range list {
+		service := box.Labels[LabelService]
+		byService[service] = append(byService[service], box)
+		if deps[service] == nil {
+			deps[service] = map[string]string{}
+		}
+		for dep, condition := range dependsOn(box.Labels) {
+			deps[service][dep] = condition
+		}
+	}
+
+	var order []string
+	done := map[string]bool{}
+	for len(order) < len(byService) {
+		progressed := false
+		for _, service := range sortedKeys(byService) {
+			if done[service] {
+				continue
+			}
+			ready := true
+			for dep := range deps[service] {
+				// Dependencies without containers don't block anything.
+				if _, ok := byService[dep]; ok && !done[dep] && dep != service {
+					ready = false
+				}
+			}
+			if ready {
+				order = append(order, service)
+				done[service] = true
+				progressed = true
+			}
+		}
+		if !progressed {
+			for _, service := range sortedKeys(byService) {
+				if !done[service] {
+					order = append(order, service)
+					done[service] = true
+				}
+			}
+		}
+	}
+
+	out := make([]container.Summary, 0, len(list))
+	for _, service := range order {
+		replicas := byService[service]
+		sort.SliceStable(replicas, func(i, j int) bool {
+			a, _ := strconv.Atoi(replicas[i].Labels[LabelContainerNumber])
+			b, _ := strconv.Atoi(replicas[j].Labels[LabelContainerNumber])
+			return a < b
+		})
+		out = append(out, replicas...)
+	}
+
+	return out
+}
+
+// dependsOn parses the compose depends_on label ("db:service_healthy:false,cache")
+// into the condition of every dependency.
+func dependsOn(labels map[string]string) map[string]string {
+	out := map[string]string{}
+	for _, entry := range strings.Split(labels[LabelDependsOn], ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		service, rest, _ := strings.Cut(entry, ":")
+		condition, _, _ := strings.Cut(rest, ":")
+		if condition == "" {
+			condition = ConditionStarted
+		}
+		out[service] = condition
+	}
+
+	return out
+}
+
+// aggregateState summarizes the states of the containers of a project.
+func aggregateState(states []string) string {
+	if len(states) == 0 {
+		return ProjectDown
+	}
+
+	running := 0
+	for _, state := range states {
+		if state == "running" {
+			running++
+		}
+	}
+
+	switch running {
+	case len(states):
+		return ProjectRunning
+	case 0:
+		return ProjectStopped
+	}
+
+	return ProjectPartial
+}
+
+func projectFilter(project string) filters.Args {
+	return filters.NewArgs(filters.Arg("label", LabelProject+"="+project))
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"mineServers/internal/fakedocker"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// createComposeContainer creates a container labelled the way docker
+// compose does, without going through a stack.
+func createComposeContainer(t *testing.T, engine *fakedocker.Engine, project, service, dependsOn string) {
+	t.Helper()
+
+	engine.AddImage(service)
+	config := &container.Config{Image: service, Labels: map[string]string{
+		LabelProject:         project,
+		LabelService:         service,
+		LabelContainerNumber: "1",
+		LabelDependsOn:       dependsOn,
+		LabelWorkingDir:      "/srv/" + project,
+	}}
+	if _, err := engine.ContainerCreate(context.Background(), config, &container.HostConfig{}, nil, nil, project+"-"+service+"-1"); err != nil {
+		t.Fatalf("ContainerCreate() error = %v", err)
+	}
+}
+
+func TestOrderByDependencies(t *testing.T) {
+	summary := func(service, number, dependsOn string) container.Summary {
+		return container.Summary{Labels: map[string]string{
+			LabelService:         service,
+			LabelContainerNumber: number,
+			LabelDependsOn:       dependsOn,
+		}}
+	}
+	list := []container.Summary{
+		summary("web", "2", "api:service_started:false"),
+		summary("web", "1", "api:service_started:false"),
+		// cache has no container: it must not block api.
+		summary("api", "1", "db:service_healthy:false,cache:service_started:true"),
+		summary("db", "1", ""),
+		summary("loop", "1", "cycle:service_started:false"),
+		summary("cycle", "1", "loop:service_started:false"),
+	}
+
+	var got []string
+	for _, box := range orderByDependencies(list) {
+		got = append(got, box.Labels[LabelService]+"-"+box.Labels[LabelContainerNumber])
+	}
+	want := []string{"db-1", "api-1", "web-1", "web-2", "cycle-1", "loop-1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("orderByDependencies() = %v, want %v", got, want)
+	}
+}
+
+func TestAggregateState(t *testing.T) {
+	tests := []struct {
+		states []string
+		want   string
+	}{
+		{nil, ProjectDown},
+		{[]string{"running", "running"}, ProjectRunning},
+		{[]string{"running", "exited"}, ProjectPartial},
+		{[]string{"exited", "created"}, ProjectStopped},
+	}
+
+	for _, tt := range tests {
+		if got := aggregateState(tt.states); got != tt.want {
+			t.Errorf("aggregateState(%v) = %s, want %s", tt.states, got, tt.want)
+		}
+	}
+}
+
+func TestContainerService_ProjectOperations(t *testing.T) {
+	engine := fakedocker.New()
+	ctx := context.Background()
+	svc := NewContainerService(ctx, engine)
+
+	createComposeContainer(t, engine, "blog", "web", "db:service_started:false")
+	createComposeContainer(t, engine, "blog", "db", "")
+	createComposeContainer(t, engine, "wiki", "app", "")
+	engine.AddImage("nginx")
+	engine.ContainerCreate(ctx, &container.Config{Image: "nginx"}, &container.HostConfig{}, nil, nil, "standalone")
+
+	projects, err := svc.ListProjects(ctx)
+	if err != nil {
+		t.Fatalf("ListProjects() error = %v", err)
+	}
+	if len(projects) != 2 || projects[0].Name != "blog" || projects[0].State != ProjectStopped || projects[0].WorkingDir != "/srv/blog" {
+		t.Fatalf("unexpected projects %+v", projects)
+	}
+
+	project, err := svc.StartProject(ctx, "blog")
+	if err != nil {
+		t.Fatalf("StartProject() error = %v", err)
+	}
+	if project.State != ProjectRunning || !reflect.DeepEqual(project.Services, []string{"db", "web"}) {
+		t.Errorf("unexpected project %+v", project)
+	}
+	db, _ := engine.Container("blog-db-1")
+	web, _ := engine.Container("blog-web-1")
+	if web.StartedAt.Before(db.StartedAt) {
+		t.Errorf("expected db to start before web")
+	}
+
+	project, err = svc.StopProject(ctx, "blog")
+	if err != nil {
+		t.Fatalf("StopProject() error = %v", err)
+	}
+	db, _ = engine.Container("blog-db-1")
+	web, _ = engine.Container("blog-web-1")
+	if project.State != ProjectStopped || db.FinishedAt.Before(web.FinishedAt) {
+		t.Errorf("expected web to stop before db, got %+v", project)
+	}
+
+	if err := svc.RemoveProject(ctx, "blog", false); err != nil {
+		t.Fatalf("RemoveProject() error = %v", err)
+	}
+	if engine.ContainerCount() != 2 {
+		t.Errorf("expected only the blog containers to be removed, got %d left", engine.ContainerCount())
+	}
+	if _, err := svc.GetProject(ctx, "blog"); !errors.Is(err, ErrProjectNotFound) {
+		t.Errorf("expected ErrProjectNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,407 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"mineServers/internal/models"
+
+	"github.com/charmbracelet/log"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+const (
+	PullPending   = "pending"
+	PullPulling   = "pulling"
+	PullCreating  = "creating"
+	PullCompleted = "completed"
+	PullFailed    = "failed"
+)
+
+// pullJobRetention is how long finished jobs stay queryable.
+const pullJobRetention = time.Hour
+
+var ErrPullJobNotFound = errors.New("pull job not found")
+
+// PullThen runs once the image of a pull job is available, typically to
+// create a container from it. It returns the ID of the created container.
+type PullThen func(ctx context.Context) (string, error)
+
+// PullManager runs image pulls in the background and tracks their progress
+// so clients can follow them instead of holding a request open.
+type PullManager struct {
+	ctx  context.Context
+	auth RegistryAuthFunc
+
+	mu   sync.Mutex
+	jobs map[string]*pullJob
+}
+
+type pullJob struct {
+	mu     sync.Mutex
+	state  models.PullJob
+	layers map[string]*models.LayerProgress
+	order  []string
+	subs   map[chan models.PullJob]struct{}
+	done   chan struct{}
+}
+
+// NewPullManager creates a manager whose jobs live as long as ctx. Unless
+// the pull options already carry credentials, auth provides them for the
+// registry of every pulled image; a nil auth pulls anonymously.
+func NewPullManager(ctx context.Context, auth RegistryAuthFunc) *PullManager {
+	return &PullManager{
+		ctx:  ctx,
+		auth: auth,
+		jobs: make(map[string]*pullJob),
+	}
+}
+
+// Start pulls imageName on the host served by svc in the background,
+// skipping the pull when the policy allows it and the image is present.
+// When then is set it runs once the image is available and the job only
+// completes once it returns.
+func (p *PullManager) Start(svc *ContainerService, host, imageName, policy string, opts image.PullOptions, then PullThen) models.PullJob {
+	if policy == "" {
+		policy = PullIfNotPresent
+	}
+
+	job := &pullJob{
+		state: models.PullJob{
+			ID:         newJobID(),
+			Host:       host,
+			Image:      imageName,
+			Platform:   opts.Platform,
+			PullPolicy: policy,
+			Status:     PullPending,
+			Layers:     []models.LayerProgress{},
+			CreatedAt:  time.Now().UTC(),
+		},
+		layers: make(map[string]*models.LayerProgress),
+		subs:   make(map[chan models.PullJob]struct{}),
+		done:   make(chan struct{}),
+	}
+
+	p.mu.Lock()
+	p.gc()
+	p.jobs[job.state.ID] = job
+	p.mu.Unlock()
+
+	log.Infof("PULLS: Pulling '%s' on host '%s' as job %s", imageName, host, job.state.ID)
+	go p.run(svc, job, opts, then)
+
+	return job.snapshot()
+}
+
+// Get returns the current state of a job.
+func (p *PullManager) Get(id string) (models.PullJob, error) {
+	job, err := p.job(id)
+	if err != nil {
+		return models.PullJob{}, err
+	}
+
+	return job.snapshot(), nil
+}
+
+// Wait blocks until the job finishes or ctx is done and returns its state.
+func (p *PullManager) Wait(ctx context.Context, id string) (models.PullJob, error) {
+	job, err := p.job(id)
+	if err != nil {
+		return models.PullJob{}, err
+	}
+
+	select {
+	case <-job.done:
+		return job.snapshot(), nil
+	case <-ctx.Done():
+		return job.snapshot(), ctx.Err()
+	}
+}
+
+// Subscribe returns a channel receiving the state of the job on every
+// progress update. Slow readers only miss intermediate states; the channel
+// is closed once the job finishes. The returned function unsubscribes.
+func (p *PullManager) Subscribe(id string) (<-chan models.PullJob, func(), error) {
+	job, err := p.job(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch := make(chan models.PullJob, 16)
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	select {
+	case <-job.done:
+		close(ch)
+		return ch, func() {}, nil
+	default:
+	}
+	job.subs[ch] = struct{}{}
+
+	unsubscribe := func() {
+		job.mu.Lock()
+		defer job.mu.Unlock()
+
+		if _, ok := job.subs[ch]; ok {
+			delete(job.subs, ch)
+			close(ch)
+		}
+	}
+
+	return ch, unsubscribe, nil
+}
+
+func (p *PullManager) job(id string) (*pullJob, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	job, ok := p.jobs[id]
+	if !ok {
+		return nil, ErrPullJobNotFound
+	}
+
+	return job, nil
+}
+
+// gc drops jobs finished for longer than the retention. Callers must hold p.mu.
+func (p *PullManager) gc() {
+	cutoff := time.Now().Add(-pullJobRetention)
+	for id, job := range p.jobs {
+		state := job.snapshot()
+		if state.FinishedAt != nil && state.FinishedAt.Before(cutoff) {
+			delete(p.jobs, id)
+		}
+	}
+}
+
+func (p *PullManager) run(svc *ContainerService, job *pullJob, opts image.PullOptions, then PullThen) {
+	platform, err := ParsePlatform(opts.Platform)
+	if err != nil {
+		job.finish(err)
+		return
+	}
+
+	pull, err := svc.NeedsPull(p.ctx, job.state.Image, job.state.PullPolicy, platform)
+	if err != nil {
+		job.finish(err)
+		return
+	}
+	if pull {
+		if err := p.pull(svc, job, opts); err != nil {
+			job.finish(err)
+			return
+		}
+	} else {
+		job.update(func(s *models.PullJob) {
+			s.Message = "Image is up to date"
+			s.Percent = 100
+		})
+	}
+
+	if then != nil {
+		job.update(func(s *models.PullJob) {
+			s.Status = PullCreating
+			s.Message = "Creating container"
+		})
+
+		id, err := then(p.ctx)
+		// Containers created without a name get one from the daemon.
+		var name string
+		if id != "" {
+			if info, err := svc.cli.ContainerInspect(p.ctx, id); err == nil {
+				name = strings.TrimPrefix(info.Name, "/")
+			}
+		}
+		job.update(func(s *models.PullJob) {
+			s.ContainerID = id
+			s.ContainerName = name
+		})
+		if err != nil {
+			job.finish(err)
+			return
+		}
+	}
+
+	job.finish(nil)
+}
+
+func (p *PullManager) pull(svc *ContainerService, job *pullJob, opts image.PullOptions) error {
+	job.update(func(s *models.PullJob) { s.Status = PullPulling })
+
+	if opts.RegistryAuth == "" && p.auth != nil {
+		auth, err := p.auth(p.ctx, job.state.Image)
+		if err != nil {
+			log.Warnf("PULLS: Unable to get credentials for '%s' due: %s", job.state.Image, err)
+			return err
+		}
+		opts.RegistryAuth = auth
+	}
+
+	reader, err := svc.PullContainerImage(p.ctx, job.state.Image, opts)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if err := job.consume(reader); err != nil {
+		log.Warnf("PULLS: Pull of '%s' failed due: %s", job.state.Image, err)
+		return err
+	}
+
+	return nil
+}
+
+// consume decodes the progress messages of the pull and aggregates them.
+func (j *pullJob) consume(reader io.Reader) error {
+	decoder := json.NewDecoder(reader)
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := decoder.Decode(&msg); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		if msg.Error != nil {
+			return msg.Error
+		}
+
+		j.update(func(s *models.PullJob) { j.apply(s, msg) })
+	}
+}
+
+// apply folds a progress message into the job state. Callers must hold j.mu.
+func (j *pullJob) apply(s *models.PullJob, msg jsonmessage.JSONMessage) {
+	s.Message = msg.Status
+	if msg.ID == "" {
+		return
+	}
+
+	layer, ok := j.layers[msg.ID]
+	if !ok {
+		// The first message of a pull carries the tag as ID, not a layer.
+		if msg.Status != "Pulling fs layer" && msg.Status != "Waiting" && msg.Status != "Already exists" {
+			return
+		}
+		layer = &models.LayerProgress{ID: msg.ID}
+		j.layers[msg.ID] = layer
+		j.order = append(j.order, msg.ID)
+	}
+	layer.Status = msg.Status
+
+	switch msg.Status {
+	case "Downloading":
+		if msg.Progress != nil {
+			layer.Downloaded = msg.Progress.Current
+			if msg.Progress.Total > 0 {
+				layer.Size = msg.Progress.Total
+			}
+		}
+	case "Verifying Checksum", "Download complete":
+		layer.Downloaded = layer.Size
+	case "Extracting":
+		layer.Downloaded = layer.Size
+		if msg.Progress != nil {
+			layer.Extracted = msg.Progress.Current
+			if msg.Progress.Total > 0 {
+				layer.Size = msg.Progress.Total
+			}
+		}
+	case "Pull complete":
+		layer.Downloaded = layer.Size
+		layer.Extracted = layer.Size
+	}
+
+	s.Layers = make([]models.LayerProgress, 0, len(j.order))
+	s.Downloaded, s.Extracted, s.Total = 0, 0, 0
+	complete := 0
+	for _, id := range j.order {
+		l := j.layers[id]
+		s.Layers = append(s.Layers, *l)
+		s.Downloaded += l.Downloaded
+		s.Extracted += l.Extracted
+		s.Total += l.Size
+		if l.Status == "Pull complete" || l.Status == "Already exists" {
+			complete++
+		}
+	}
+
+	// Downloading and extracting weigh the same; layers of unknown size only
+	// count once they are complete.
+	switch {
+	case s.Total > 0:
+		s.Percent = float64(s.Downloaded+s.Extracted) / float64(2*s.Total) * 100
+	case len(j.order) > 0:
+		s.Percent = float64(complete) / float64(len(j.order)) * 100
+	}
+}
+
+func (j *pullJob) snapshot() models.PullJob {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.copyState()
+}
+
+// copyState returns a copy safe to hand to other goroutines. Callers must hold j.mu.
+func (j *pullJob) copyState() models.PullJob {
+	state := j.state
+	state.Layers = append([]models.LayerProgress{}, j.state.Layers...)
+
+	return state
+}
+
+// update applies fn to the state and notifies subscribers.
+func (j *pullJob) update(fn func(s *models.PullJob)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	fn(&j.state)
+	state := j.copyState()
+	for ch := range j.subs {
+		select {
+		case ch <- state:
+		default:
+		}
+	}
+}
+
+// finish marks the job as done, closing every subscription.
+func (j *pullJob) finish(err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now().UTC()
+	j.state.FinishedAt = &now
+	if err != nil {
+		j.state.Status = PullFailed
+		j.state.Error = err.Error()
+	} else {
+		j.state.Status = PullCompleted
+		j.state.Percent = 100
+		j.state.Message = "Completed"
+	}
+
+	for ch := range j.subs {
+		close(ch)
+		delete(j.subs, ch)
+	}
+	close(j.done)
+}
+
+func newJobID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
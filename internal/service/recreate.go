@@ -0,0 +1,393 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"mineServers/internal/models"
+
+	"github.com/charmbracelet/log"
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+)
+
+// Outcomes of a recreation.
+const (
+	RecreateDone       = "recreated"
+	RecreateRolledBack = "rolled_back"
+	RecreateFailed     = "failed"
+)
+
+const (
+	stepDone   = "done"
+	stepFailed = "failed"
+)
+
+// defaultRecreateTimeout bounds the wait for the healthcheck of a recreated
+// container. Containers without one must still run after startGracePeriod.
+const (
+	defaultRecreateTimeout = time.Minute
+	startGracePeriod       = 5 * time.Second
+)
+
+var ErrRecreateFailed = errors.New("unable to recreate container")
+
+// recreation tracks what was changed so far so it can be undone.
+type recreation struct {
+	svc     *ContainerService
+	info    container.InspectResponse
+	name    string
+	result  *models.RecreateResult
+	running bool
+	renamed bool
+	newID   string
+}
+
+// RecreateContainer replaces the container with a new one created from the
+// same configuration, mounts, networks and name on another image, pulled
+// through pulls. The old container is only removed once the new one runs
+// and, when it has a healthcheck, is healthy; otherwise it is restored.
+// Every step is reported in the result, returned along ErrRecreateFailed.
+func (c *ContainerService) RecreateContainer(ctx context.Context, pulls *PullManager, host, id string, req *models.RecreateRequest) (*models.RecreateResult, error) {
+	v := &ValidationError{}
+	if req.Reference != "" && req.Version != "" {
+		v.add("version", "cannot be combined with reference")
+	}
+	if err := validatePullPolicy(req.PullPolicy); err != nil {
+		v.add("pull_policy", "%s", err)
+	}
+	platform, err := ParsePlatform(req.Platform)
+	if err != nil {
+		v.add("platform", "%s", err)
+	}
+	timeout := defaultRecreateTimeout
+	if req.Timeout != "" {
+		d, err := time.ParseDuration(req.Timeout)
+		if err != nil || d <= 0 {
+			v.add("timeout", "must be a positive duration such as 30s")
+		}
+		timeout = d
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	info, err := c.InspectContainer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	imageName, err := recreateImage(info.Config.Image, req)
+	if err != nil {
+		v.add("reference", "%s", err)
+		return nil, v.err()
+	}
+
+	r := &recreation{
+		svc:     c,
+		info:    info,
+		name:    strings.TrimPrefix(info.Name, "/"),
+		running: info.State != nil && info.State.Running,
+		result: &models.RecreateResult{
+			Name:          strings.TrimPrefix(info.Name, "/"),
+			Status:        RecreateFailed,
+			OldID:         info.ID,
+			Image:         imageName,
+			PreviousImage: info.Config.Image,
+			Steps:         []models.RecreateStep{},
+		},
+	}
+
+	policy := req.PullPolicy
+	if policy == "" {
+		policy = PullAlways
+	}
+	job := pulls.Start(c, host, imageName, policy, image.PullOptions{Platform: req.Platform}, nil)
+	job, err = pulls.Wait(ctx, job.ID)
+	if err == nil && job.Status == PullFailed {
+		err = errors.New(job.Error)
+	}
+	if err := r.step("pull", err, "%s is available", imageName); err != nil {
+		return r.result, r.fail(err)
+	}
+
+	// What the container inherited from its old image is left to the new one.
+	var imageConfig *container.Config
+	if img, err := c.cli.ImageInspect(ctx, info.Image); err == nil {
+		imageConfig = img.Config
+	} else {
+		log.Warnf("CONTAINER-RECREATE: Unable to inspect image '%s' due: %s", info.Image, err)
+	}
+	spec := specFromInspect(info, imageConfig)
+	remountAnonymousVolumes(spec, info)
+	spec.Config.Image = imageName
+	spec.Platform = platform
+
+	if r.running {
+		if err := r.step("stop", c.StopContainer(ctx, info.ID), "stopped %s", shortID(info.ID)); err != nil {
+			return r.result, r.rollback(ctx, err)
+		}
+	}
+
+	backup := fmt.Sprintf("%s-old-%s", r.name, shortID(info.ID))
+	err = c.cli.ContainerRename(ctx, info.ID, backup)
+	if err := r.step("rename", err, "renamed %s to %s", r.name, backup); err != nil {
+		return r.result, r.rollback(ctx, err)
+	}
+	r.renamed = true
+
+	resp, err := c.cli.ContainerCreate(ctx, spec.Config, spec.HostConfig, spec.Networking, spec.Platform, r.name)
+	if err := r.step("create", err, "created %s", shortID(resp.ID)); err != nil {
+		return r.result, r.rollback(ctx, err)
+	}
+	r.newID = resp.ID
+	r.result.ContainerID = resp.ID
+
+	if err := r.step("start", c.StartContainer(ctx, resp.ID), "started %s", shortID(resp.ID)); err != nil {
+		return r.result, r.rollback(ctx, err)
+	}
+
+	message, err := c.waitStarted(ctx, resp.ID, timeout)
+	if err := r.step("health", err, "%s", message); err != nil {
+		return r.result, r.rollback(ctx, err)
+	}
+
+	// The new container is in place: failing to clean up doesn't undo it.
+	err = c.RemoveContainer(ctx, info.ID)
+	r.step("cleanup", err, "removed %s", shortID(info.ID))
+	r.result.Status = RecreateDone
+
+	log.Infof("CONTAINER-RECREATE: Container '%s' recreated from '%s'", r.name, imageName)
+	return r.result, nil
+}
+
+// step records the outcome of a step and returns its error.
+func (r *recreation) step(name string, err error, format string, args ...any) error {
+	step := models.RecreateStep{Step: name, Status: stepDone, Message: fmt.Sprintf(format, args...)}
+	if err != nil {
+		step.Status = stepFailed
+		step.Message = err.Error()
+	}
+	r.result.Steps = append(r.result.Steps, step)
+
+	return err
+}
+
+func (r *recreation) fail(err error) error {
+	log.Warnf("CONTAINER-RECREATE: Unable to recreate container '%s' due: %s", r.name, err)
+	return fmt.Errorf("%w %s: %s", ErrRecreateFailed, r.name, err)
+}
+
+// rollback removes the new container and puts the old one back as it was.
+func (r *recreation) rollback(ctx context.Context, cause error) error {
+	// The old container must come back even if the client went away.
+	ctx = context.WithoutCancel(ctx)
+
+	var errs []error
+	if r.newID != "" {
+		errs = append(errs, r.svc.RemoveContainer(ctx, r.newID))
+	}
+	if r.renamed {
+		errs = append(errs, r.svc.cli.ContainerRename(ctx, r.info.ID, r.name))
+	}
+	if r.running {
+		errs = append(errs, r.svc.StartContainer(ctx, r.info.ID))
+	}
+
+	if err := r.step("rollback", errors.Join(errs...), "restored %s", shortID(r.info.ID)); err == nil {
+		r.result.Status = RecreateRolledBack
+	}
+
+	return r.fail(cause)
+}
+
+// waitStarted checks that a started container keeps running and, when it
+// has a healthcheck, waits up to timeout for it to become healthy.
+func (c *ContainerService) waitStarted(ctx context.Context, id string, timeout time.Duration) (string, error) {
+	start := time.Now()
+	for {
+		info, err := c.InspectContainer(ctx, id)
+		if err != nil {
+			return "", err
+		}
+
+		state := info.State
+		wait := min(timeout, startGracePeriod)
+		switch {
+		case state == nil:
+		case !state.Running:
+			return "", fmt.Errorf("container exited with code %d", state.ExitCode)
+		case state.Health != nil && state.Health.Status == container.Healthy:
+			return "container is healthy", nil
+		case state.Health != nil && state.Health.Status == container.Unhealthy:
+			return "", errors.New("container is unhealthy")
+		case state.Health != nil:
+			wait = timeout
+		}
+
+		elapsed := time.Since(start)
+		if elapsed >= wait {
+			if state != nil && state.Health != nil {
+				return "", fmt.Errorf("container is not healthy after %s", timeout)
+			}
+			return fmt.Sprintf("container is running after %s", wait), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(min(dependencyPollInterval, wait-elapsed)):
+		}
+	}
+}
+
+// recreateImage returns the image a container currently created from
+// current is recreated with.
+func recreateImage(current string, req *models.RecreateRequest) (string, error) {
+	if req.Reference != "" {
+		return ImageReference(req.Reference, "", "", "")
+	}
+
+	named, err := reference.ParseNormalizedNamed(current)
+	if err != nil {
+		return "", fmt.Errorf("the container image %s is not a reference, give one", current)
+	}
+	if req.Version != "" {
+		return ImageReference("", "", reference.TrimNamed(named).String(), req.Version)
+	}
+
+	return reference.TagNameOnly(named).String(), nil
+}
+
+// specFromInspect rebuilds the creation spec of an existing container. What
+// it only inherited from imageConfig, when known, is left out so another
+// image can bring its own defaults.
+func specFromInspect(info container.InspectResponse, imageConfig *container.Config) *containerSpec {
+	config := *info.Config
+	config.Labels = maps.Clone(config.Labels)
+	config.ExposedPorts = maps.Clone(config.ExposedPorts)
+	config.Volumes = maps.Clone(config.Volumes)
+	// The daemon names containers after their ID unless told otherwise.
+	if config.Hostname == shortID(info.ID) {
+		config.Hostname = ""
+	}
+	if imageConfig != nil {
+		stripImageDefaults(&config, imageConfig)
+	}
+
+	hostConfig := &container.HostConfig{}
+	if info.ContainerJSONBase != nil && info.HostConfig != nil {
+		copied := *info.HostConfig
+		hostConfig = &copied
+	}
+	hostConfig.Mounts = slices.Clone(hostConfig.Mounts)
+	hostConfig.PortBindings = maps.Clone(hostConfig.PortBindings)
+
+	var networking *network.NetworkingConfig
+	if info.NetworkSettings != nil && len(info.NetworkSettings.Networks) > 0 {
+		networking = &network.NetworkingConfig{EndpointsConfig: map[string]*network.EndpointSettings{}}
+		for name, ep := range info.NetworkSettings.Networks {
+			settings := &network.EndpointSettings{}
+			if ep != nil {
+				settings.IPAMConfig = ep.IPAMConfig
+				settings.Links = ep.Links
+				settings.DriverOpts = ep.DriverOpts
+				for _, alias := range ep.Aliases {
+					// The daemon adds the short ID of the container itself.
+					if alias != shortID(info.ID) {
+						settings.Aliases = append(settings.Aliases, alias)
+					}
+				}
+			}
+			networking.EndpointsConfig[name] = settings
+		}
+	}
+
+	return &containerSpec{
+		Name:       strings.TrimPrefix(info.Name, "/"),
+		Config:     &config,
+		HostConfig: hostConfig,
+		Networking: networking,
+	}
+}
+
+// remountAnonymousVolumes mounts the anonymous volumes of the container
+// described by info on spec so a replacement keeps their data.
+func remountAnonymousVolumes(spec *containerSpec, info container.InspectResponse) {
+	covered := map[string]bool{}
+	for _, m := range spec.HostConfig.Mounts {
+		covered[m.Target] = true
+	}
+	for _, bind := range spec.HostConfig.Binds {
+		if parts := strings.Split(bind, ":"); len(parts) > 1 {
+			covered[parts[1]] = true
+		}
+	}
+
+	for _, m := range info.Mounts {
+		if m.Type == mount.TypeVolume && m.Name != "" && !covered[m.Destination] {
+			spec.HostConfig.Mounts = append(spec.HostConfig.Mounts, mount.Mount{Type: mount.TypeVolume, Source: m.Name, Target: m.Destination, ReadOnly: !m.RW})
+		}
+	}
+}
+
+// stripImageDefaults removes from config the values equal to the ones of
+// the image it was created from.
+func stripImageDefaults(config, imageConfig *container.Config) {
+	var env []string
+	for _, e := range config.Env {
+		if !slices.Contains(imageConfig.Env, e) {
+			env = append(env, e)
+		}
+	}
+	config.Env = env
+
+	for key, value := range imageConfig.Labels {
+		if config.Labels[key] == value {
+			delete(config.Labels, key)
+		}
+	}
+	for port := range imageConfig.ExposedPorts {
+		delete(config.ExposedPorts, port)
+	}
+	for volume := range imageConfig.Volumes {
+		delete(config.Volumes, volume)
+	}
+
+	if slices.Equal(config.Cmd, imageConfig.Cmd) {
+		config.Cmd = nil
+	}
+	if slices.Equal(config.Entrypoint, imageConfig.Entrypoint) {
+		config.Entrypoint = nil
+	}
+	if config.WorkingDir == imageConfig.WorkingDir {
+		config.WorkingDir = ""
+	}
+	if config.User == imageConfig.User {
+		config.User = ""
+	}
+	if config.StopSignal == imageConfig.StopSignal {
+		config.StopSignal = ""
+	}
+	if reflect.DeepEqual(config.Healthcheck, imageConfig.Healthcheck) {
+		config.Healthcheck = nil
+	}
+}
+
+// shortID returns the 12 characters form of a container or image ID.
+func shortID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+
+	return id
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"mineServers/internal/fakedocker"
+	"mineServers/internal/models"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+)
+
+func newTestRecreate(t *testing.T) (*ContainerService, *PullManager, *fakedocker.Engine, string) {
+	t.Helper()
+
+	engine := fakedocker.New()
+	ctx := context.Background()
+	svc := NewContainerService(ctx, engine)
+
+	engine.AddImage("docker.io/library/nginx:1.26")
+	id, err := svc.CreatePulledContainer(ctx, &models.CreateOptions{
+		Name:    "web",
+		Image:   "nginx",
+		Version: "1.26",
+		Env:     map[string]string{"MODE": "production"},
+		Ports:   []models.PortBinding{{ContainerPort: 80, HostPort: 8080}},
+		Mounts:  []models.Mount{{Type: "volume", Source: "html", Target: "/usr/share/nginx/html"}},
+	})
+	if err != nil {
+		t.Fatalf("CreatePulledContainer() error = %v", err)
+	}
+
+	return svc, NewPullManager(ctx, nil), engine, id
+}
+
+func TestContainerService_RecreateUpgradesImage(t *testing.T) {
+	svc, pulls, engine, id := newTestRecreate(t)
+
+	result, err := svc.RecreateContainer(context.Background(), pulls, LocalHost, "web", &models.RecreateRequest{Version: "1.27", Timeout: "10ms"})
+	if err != nil {
+		t.Fatalf("RecreateContainer() error = %v", err)
+	}
+
+	var steps []string
+	for _, s := range result.Steps {
+		steps = append(steps, s.Step)
+	}
+	if result.Status != RecreateDone || !reflect.DeepEqual(steps, []string{"pull", "stop", "rename", "create", "start", "health", "cleanup"}) {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	web, ok := engine.Container("web")
+	if !ok || web.ID == id || web.ID != result.ContainerID || web.Image != "docker.io/library/nginx:1.27" || web.State != "running" {
+		t.Fatalf("expected a new running web container on 1.27, got %+v", web)
+	}
+	if !reflect.DeepEqual(web.Config.Env, []string{"MODE=production"}) || len(web.HostConfig.Mounts) != 1 || web.HostConfig.PortBindings["80/tcp"][0].HostPort != "8080" {
+		t.Errorf("expected the configuration to be kept, got %+v %+v", web.Config, web.HostConfig)
+	}
+	if engine.ContainerCount() != 1 {
+		t.Errorf("expected the old container to be removed")
+	}
+}
+
+func TestContainerService_RecreateRollsBackCrashingImage(t *testing.T) {
+	svc, pulls, engine, id := newTestRecreate(t)
+	engine.CrashOnStart("docker.io/library/nginx:1.28", 1)
+
+	result, err := svc.RecreateContainer(context.Background(), pulls, LocalHost, id, &models.RecreateRequest{Reference: "nginx:1.28"})
+	if !errors.Is(err, ErrRecreateFailed) {
+		t.Fatalf("expected ErrRecreateFailed, got %v", err)
+	}
+	if result.Status != RecreateRolledBack || result.Steps[len(result.Steps)-2].Status != stepFailed {
+		t.Errorf("expected the failed health step to be rolled back, got %+v", result.Steps)
+	}
+
+	web, ok := engine.Container("web")
+	if !ok || web.ID != id || web.State != "running" || engine.ContainerCount() != 1 {
+		t.Errorf("expected the old container to be restored, got %+v", web)
+	}
+}
+
+func TestContainerService_RecreateValidatesRequest(t *testing.T) {
+	svc, pulls, _, _ := newTestRecreate(t)
+
+	_, err := svc.RecreateContainer(context.Background(), pulls, LocalHost, "web", &models.RecreateRequest{Reference: "nginx", Version: "1.27", Timeout: "soon"})
+	var verr *ValidationError
+	if !errors.As(err, &verr) || len(verr.Fields) != 2 {
+		t.Errorf("expected the version and timeout errors, got %v", err)
+	}
+}
+
+func TestSpecFromInspect_LeavesImageDefaultsOutAndKeepsAnonymousVolumes(t *testing.T) {
+	id := "0123456789abcdef0123"
+	info := container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{
+			ID:         id,
+			Name:       "/web",
+			HostConfig: &container.HostConfig{Binds: []string{"/srv/conf:/etc/nginx/conf.d"}},
+		},
+		Config: &container.Config{
+			Hostname: "0123456789ab",
+			Image:    "nginx:1.26",
+			Env:      []string{"PATH=/usr/bin", "MODE=production"},
+			Cmd:      []string{"nginx", "-g", "daemon off;"},
+			Labels:   map[string]string{"maintainer": "nginx", "team": "web"},
+		},
+		Mounts: []container.MountPoint{
+			{Type: mount.TypeBind, Source: "/srv/conf", Destination: "/etc/nginx/conf.d", RW: true},
+			{Type: mount.TypeVolume, Name: "3f2a", Destination: "/var/cache/nginx", RW: true},
+		},
+		NetworkSettings: &container.NetworkSettings{Networks: map[string]*network.EndpointSettings{
+			"front": {Aliases: []string{"0123456789ab", "www"}, IPAddress: "172.18.0.2"},
+		}},
+	}
+	imageConfig := &container.Config{
+		Env:    []string{"PATH=/usr/bin"},
+		Cmd:    []string{"nginx", "-g", "daemon off;"},
+		Labels: map[string]string{"maintainer": "nginx"},
+	}
+
+	spec := specFromInspect(info, imageConfig)
+	remountAnonymousVolumes(spec, info)
+
+	if spec.Name != "web" || spec.Config.Hostname != "" || spec.Config.Cmd != nil {
+		t.Errorf("unexpected name %s, hostname %s or command %v", spec.Name, spec.Config.Hostname, spec.Config.Cmd)
+	}
+	if !reflect.DeepEqual(spec.Config.Env, []string{"MODE=production"}) || !reflect.DeepEqual(spec.Config.Labels, map[string]string{"team": "web"}) {
+		t.Errorf("expected only the container env and labels, got %v %v", spec.Config.Env, spec.Config.Labels)
+	}
+	if info.Config.Labels["maintainer"] != "nginx" {
+		t.Errorf("expected the inspected labels to be left untouched")
+	}
+	if mounts := spec.HostConfig.Mounts; len(mounts) != 1 || mounts[0].Source != "3f2a" || mounts[0].Target != "/var/cache/nginx" {
+		t.Errorf("expected the anonymous volume to be mounted again, got %+v", mounts)
+	}
+	if ep := spec.Networking.EndpointsConfig["front"]; ep == nil || !reflect.DeepEqual(ep.Aliases, []string{"www"}) || ep.IPAddress != "" {
+		t.Errorf("expected the network aliases without the runtime settings, got %+v", ep)
+	}
+}
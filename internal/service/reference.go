@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"mineServers/internal/models"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/errdefs"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Pull policies decide whether creating a container pulls its image first.
+const (
+	PullAlways       = "always"
+	PullIfNotPresent = "if-not-present"
+	PullNever        = "never"
+)
+
+var (
+	ErrImageNotPresent = errors.New("image not present on the host")
+
+	platformRegex = regexp.MustCompile(`^[a-z0-9_-]+/[a-z0-9_-]+(/[a-z0-9_.-]+)?$`)
+)
+
+// ImageReference normalizes an image into a full reference such as
+// "docker.io/library/nginx:latest". Either ref holds the whole reference
+// or the image is given through its parts: image may itself carry a
+// registry, tag or digest as long as registry and version don't contradict
+// them, and version can be a tag or a digest.
+func ImageReference(ref, registry, image, version string) (string, error) {
+	if ref != "" {
+		if registry != "" || image != "" || version != "" {
+			return "", errors.New("reference cannot be combined with registry, image or version")
+		}
+		named, err := reference.ParseNormalizedNamed(ref)
+		if err != nil {
+			return "", err
+		}
+		return reference.TagNameOnly(named).String(), nil
+	}
+
+	if image == "" {
+		return "", errors.New("image name is required")
+	}
+
+	name := image
+	if registry != "" {
+		registry = NormalizeRegistry(registry)
+		if domain, ok := explicitDomain(image); ok {
+			if NormalizeRegistry(domain) != registry {
+				return "", fmt.Errorf("registry %s conflicts with the registry %s of the image", registry, domain)
+			}
+		} else {
+			name = registry + "/" + image
+		}
+	}
+
+	named, err := reference.ParseNormalizedNamed(name)
+	if err != nil {
+		return "", err
+	}
+
+	if version != "" {
+		_, tagged := named.(reference.Tagged)
+		_, digested := named.(reference.Digested)
+		if tagged || digested {
+			return "", errors.New("version conflicts with the tag or digest of the image")
+		}
+
+		sep := ":"
+		if strings.Contains(version, ":") {
+			sep = "@"
+		}
+		if named, err = reference.ParseNormalizedNamed(named.String() + sep + version); err != nil {
+			return "", fmt.Errorf("invalid version %q: %w", version, err)
+		}
+	}
+
+	return reference.TagNameOnly(named).String(), nil
+}
+
+// CreateImageReference returns the normalized image reference of opts.
+func CreateImageReference(opts *models.CreateOptions) (string, error) {
+	return ImageReference(opts.Reference, opts.Registry, opts.Image, opts.Version)
+}
+
+// explicitDomain returns the registry of image when its first component
+// names one, following the same rules as the Docker CLI.
+func explicitDomain(image string) (string, bool) {
+	first, _, found := strings.Cut(image, "/")
+	if !found {
+		return "", false
+	}
+	if strings.ContainsAny(first, ".:") || first == "localhost" || strings.ToLower(first) != first {
+		return first, true
+	}
+
+	return "", false
+}
+
+// ParsePlatform parses an "os/arch[/variant]" selector such as
+// "linux/arm64/v8". An empty value selects the platform of the daemon.
+func ParsePlatform(value string) (*ocispec.Platform, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if !platformRegex.MatchString(value) {
+		return nil, fmt.Errorf("%q must be formatted as os/arch[/variant], e.g. linux/arm64", value)
+	}
+
+	parts := strings.Split(value, "/")
+	platform := &ocispec.Platform{OS: parts[0], Architecture: parts[1]}
+	if len(parts) == 3 {
+		platform.Variant = parts[2]
+	}
+
+	return platform, nil
+}
+
+func validatePullPolicy(policy string) error {
+	switch policy {
+	case "", PullAlways, PullIfNotPresent, PullNever:
+		return nil
+	default:
+		return fmt.Errorf("must be one of %s, %s or %s", PullAlways, PullIfNotPresent, PullNever)
+	}
+}
+
+// ImagePresent reports whether imageName is available on the host, for the
+// given platform when one is selected.
+func (c *ContainerService) ImagePresent(ctx context.Context, imageName string, platform *ocispec.Platform) (bool, error) {
+	img, err := c.cli.ImageInspect(ctx, imageName)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if platform != nil && (img.Os != platform.OS || img.Architecture != platform.Architecture ||
+		(platform.Variant != "" && img.Variant != platform.Variant)) {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// NeedsPull applies the pull policy, an empty policy meaning
+// PullIfNotPresent. It fails with ErrImageNotPresent when the policy
+// forbids pulling a missing image.
+func (c *ContainerService) NeedsPull(ctx context.Context, imageName, policy string, platform *ocispec.Platform) (bool, error) {
+	if policy == PullAlways {
+		return true, nil
+	}
+
+	present, err := c.ImagePresent(ctx, imageName, platform)
+	if err != nil {
+		return false, err
+	}
+	if present {
+		return false, nil
+	}
+	if policy == PullNever {
+		return false, fmt.Errorf("%w: %s is missing and the pull policy is %s", ErrImageNotPresent, imageName, PullNever)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,68 @@
+package service
+
+import "testing"
+
+func TestImageReference(t *testing.T) {
+	const digest = "sha256:4f8a8b0f6a1d1dbd3b2e3c5c2ce3a4c4b0c7e5e1a8b1d0e8c3f7a9b6d2e1c0f9"
+
+	cases := []struct {
+		name                          string
+		ref, registry, image, version string
+		want                          string
+	}{
+		{name: "defaults", image: "nginx", want: "docker.io/library/nginx:latest"},
+		{name: "parts", registry: "docker.io", image: "itzg/minecraft-server", version: "java21", want: "docker.io/itzg/minecraft-server:java21"},
+		{name: "registry port", registry: "localhost:5000", image: "team/app", version: "1.0", want: "localhost:5000/team/app:1.0"},
+		{name: "tag in image", image: "redis:7", want: "docker.io/library/redis:7"},
+		{name: "registry in image", registry: "ghcr.io", image: "ghcr.io/owner/app:2", want: "ghcr.io/owner/app:2"},
+		{name: "digest version", image: "nginx", version: digest, want: "docker.io/library/nginx@" + digest},
+		{name: "digest in image", image: "localhost:5000/app@" + digest, want: "localhost:5000/app@" + digest},
+		{name: "full reference", ref: "ghcr.io/owner/app", want: "ghcr.io/owner/app:latest"},
+		{name: "full reference with digest", ref: "quay.io/org/app:1@" + digest, want: "quay.io/org/app:1@" + digest},
+	}
+	for _, tc := range cases {
+		got, err := ImageReference(tc.ref, tc.registry, tc.image, tc.version)
+		if err != nil {
+			t.Errorf("%s: ImageReference() error = %v", tc.name, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s: ImageReference() = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestImageReference_RejectsConflicts(t *testing.T) {
+	cases := map[string][4]string{
+		"missing image":       {"", "", "", ""},
+		"reference and parts": {"nginx", "", "nginx", ""},
+		"tag twice":           {"", "", "nginx:1", "2"},
+		"other registry":      {"", "quay.io", "ghcr.io/owner/app", ""},
+		"uppercase":           {"", "", "Nginx", ""},
+		"bad digest":          {"", "", "nginx", "sha256:nope"},
+	}
+	for name, tc := range cases {
+		if _, err := ImageReference(tc[0], tc[1], tc[2], tc[3]); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestParsePlatform(t *testing.T) {
+	got, err := ParsePlatform("linux/arm64/v8")
+	if err != nil {
+		t.Fatalf("ParsePlatform() error = %v", err)
+	}
+	if got.OS != "linux" || got.Architecture != "arm64" || got.Variant != "v8" {
+		t.Errorf("ParsePlatform() = %+v", got)
+	}
+
+	if got, err := ParsePlatform(""); got != nil || err != nil {
+		t.Errorf("expected no platform for an empty selector, got %+v, %v", got, err)
+	}
+	for _, value := range []string{"linux", "linux/", "Linux/AMD64", "linux/arm64/v8/extra"} {
+		if _, err := ParsePlatform(value); err == nil {
+			t.Errorf("expected %q to be rejected", value)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"mineServers/internal/database"
+	"mineServers/internal/models"
+
+	"github.com/charmbracelet/log"
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types/registry"
+)
+
+// DockerHub is the server name credentials for Docker Hub are stored under.
+const DockerHub = "docker.io"
+
+// dockerHubAuthServer is the address the daemon expects in Docker Hub auth configs.
+const dockerHubAuthServer = "https://index.docker.io/v1/"
+
+var (
+	ErrRegistryNotFound = errors.New("registry not found")
+	ErrInvalidRegistry  = errors.New("invalid registry")
+
+	registryServerRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9.-]*[a-zA-Z0-9])?(:[0-9]{1,5})?$`)
+)
+
+// RegistryAuthFunc returns the encoded credentials to pull imageName with,
+// or an empty string to pull anonymously.
+type RegistryAuthFunc func(ctx context.Context, imageName string) (string, error)
+
+// RegistryManager stores private registry credentials, encrypting the
+// secrets with a server-side key, and provides them to image pulls.
+type RegistryManager struct {
+	store database.RegistryStore
+	box   *SecretBox
+}
+
+func NewRegistryManager(store database.RegistryStore, box *SecretBox) *RegistryManager {
+	return &RegistryManager{
+		store: store,
+		box:   box,
+	}
+}
+
+// ListRegistries returns every registry without its secrets.
+func (r *RegistryManager) ListRegistries(ctx context.Context) ([]models.Registry, error) {
+	registries, err := r.store.ListRegistries(ctx)
+	if err != nil {
+		log.Warnf("REGISTRIES: Unable to list registries due: %s", err)
+		return nil, err
+	}
+
+	for i := range registries {
+		redact(&registries[i])
+	}
+
+	return registries, nil
+}
+
+// GetRegistry returns the registry without its secrets.
+func (r *RegistryManager) GetRegistry(ctx context.Context, server string) (*models.Registry, error) {
+	reg, err := r.getRegistry(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+	redact(reg)
+
+	return reg, nil
+}
+
+func (r *RegistryManager) CreateRegistry(ctx context.Context, reg *models.Registry) error {
+	if err := validateRegistry(reg, true); err != nil {
+		return err
+	}
+
+	sealed, err := r.seal(*reg)
+	if err != nil {
+		return err
+	}
+	if err := r.store.CreateRegistry(ctx, &sealed); err != nil {
+		return err
+	}
+
+	*reg = sealed
+	redact(reg)
+
+	return nil
+}
+
+// UpdateRegistry replaces the credentials of a registry. Omitted secrets
+// keep their stored value since they are never returned to clients.
+func (r *RegistryManager) UpdateRegistry(ctx context.Context, reg *models.Registry) error {
+	if err := validateRegistry(reg, false); err != nil {
+		return err
+	}
+
+	stored, err := r.store.GetRegistry(ctx, reg.Server)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return ErrRegistryNotFound
+		}
+		return err
+	}
+
+	sealed, err := r.seal(*reg)
+	if err != nil {
+		return err
+	}
+	if reg.Password == "" && reg.IdentityToken == "" {
+		sealed.Password = stored.Password
+		sealed.IdentityToken = stored.IdentityToken
+	}
+
+	if err := r.store.UpdateRegistry(ctx, &sealed); err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return ErrRegistryNotFound
+		}
+		return err
+	}
+
+	sealed.ID = stored.ID
+	sealed.CreatedAt = stored.CreatedAt
+	*reg = sealed
+	redact(reg)
+
+	return nil
+}
+
+func (r *RegistryManager) DeleteRegistry(ctx context.Context, server string) error {
+	if err := r.store.DeleteRegistry(ctx, NormalizeRegistry(server)); err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return ErrRegistryNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// AuthFor returns the encoded credentials of the registry imageName is
+// pulled from. Images of registries without credentials are pulled
+// anonymously.
+func (r *RegistryManager) AuthFor(ctx context.Context, imageName string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidRegistry, err)
+	}
+
+	reg, err := r.getRegistry(ctx, reference.Domain(named))
+	if err != nil {
+		if errors.Is(err, ErrRegistryNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	auth, err := r.authConfig(reg)
+	if err != nil {
+		return "", err
+	}
+
+	return registry.EncodeAuthConfig(auth)
+}
+
+// Login checks the stored credentials of server against the daemon of svc,
+// which authenticates with the registry itself.
+func (r *RegistryManager) Login(ctx context.Context, svc *ContainerService, server string) (*models.RegistryLoginResult, error) {
+	reg, err := r.getRegistry(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := r.authConfig(reg)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.RegistryLoginResult{Server: reg.Server}
+	resp, err := svc.RegistryLogin(ctx, auth)
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	result.Success = true
+	result.Status = resp.Status
+
+	return result, nil
+}
+
+func (r *RegistryManager) getRegistry(ctx context.Context, server string) (*models.Registry, error) {
+	reg, err := r.store.GetRegistry(ctx, NormalizeRegistry(server))
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return nil, ErrRegistryNotFound
+		}
+		log.Warnf("REGISTRIES: Unable to get registry '%s' due: %s", server, err)
+		return nil, err
+	}
+
+	return reg, nil
+}
+
+func (r *RegistryManager) authConfig(reg *models.Registry) (registry.AuthConfig, error) {
+	password, err := r.box.Open(reg.Password)
+	if err != nil {
+		return registry.AuthConfig{}, fmt.Errorf("registry '%s': %w", reg.Server, err)
+	}
+	token, err := r.box.Open(reg.IdentityToken)
+	if err != nil {
+		return registry.AuthConfig{}, fmt.Errorf("registry '%s': %w", reg.Server, err)
+	}
+
+	server := reg.Server
+	if server == DockerHub {
+		server = dockerHubAuthServer
+	}
+
+	return registry.AuthConfig{
+		Username:      reg.Username,
+		Password:      password,
+		IdentityToken: token,
+		ServerAddress: server,
+	}, nil
+}
+
+// seal returns a copy of reg with its secrets encrypted.
+func (r *RegistryManager) seal(reg models.Registry) (models.Registry, error) {
+	var err error
+	if reg.Password, err = r.box.Seal(reg.Password); err != nil {
+		return reg, err
+	}
+	if reg.IdentityToken, err = r.box.Seal(reg.IdentityToken); err != nil {
+		return reg, err
+	}
+
+	return reg, nil
+}
+
+func redact(reg *models.Registry) {
+	reg.Password = ""
+	reg.IdentityToken = ""
+}
+
+// NormalizeRegistry reduces a registry address such as
+// "https://ghcr.io/v2/" to the hostname images reference it by, mapping
+// the Docker Hub aliases to DockerHub.
+func NormalizeRegistry(server string) string {
+	server = strings.TrimSpace(strings.ToLower(server))
+	if u, err := url.Parse(server); err == nil && u.Host != "" {
+		server = u.Host
+	}
+	server, _, _ = strings.Cut(server, "/")
+
+	switch server {
+	case "index.docker.io", "registry-1.docker.io", "registry.hub.docker.com":
+		return DockerHub
+	}
+
+	return server
+}
+
+// validateRegistry normalises the server of reg and checks its credentials.
+// Secrets are optional on updates, where they default to the stored ones.
+func validateRegistry(reg *models.Registry, requireSecret bool) error {
+	reg.Server = NormalizeRegistry(reg.Server)
+	if !registryServerRegex.MatchString(reg.Server) {
+		return fmt.Errorf("%w: server must be a hostname with an optional port, e.g. ghcr.io or localhost:5000", ErrInvalidRegistry)
+	}
+	if reg.IdentityToken == "" && reg.Username == "" {
+		return fmt.Errorf("%w: username is required unless an identity_token is set", ErrInvalidRegistry)
+	}
+	if requireSecret && reg.Password == "" && reg.IdentityToken == "" {
+		return fmt.Errorf("%w: password or identity_token is required", ErrInvalidRegistry)
+	}
+
+	return nil
+}
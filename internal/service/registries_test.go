@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mineServers/internal/database"
+	"mineServers/internal/models"
+
+	"github.com/docker/docker/api/types/registry"
+)
+
+func newTestRegistries(t *testing.T) (*RegistryManager, database.Service) {
+	t.Helper()
+
+	db := openTestDB(t)
+
+	key, err := LoadSecretKey("", filepath.Join(t.TempDir(), "secret.key"))
+	if err != nil {
+		t.Fatalf("LoadSecretKey() error = %v", err)
+	}
+	box, err := NewSecretBox(key)
+	if err != nil {
+		t.Fatalf("NewSecretBox() error = %v", err)
+	}
+
+	return NewRegistryManager(db, box), db
+}
+
+func TestSecretBox_RoundTrip(t *testing.T) {
+	box, _ := NewSecretBox(make([]byte, 32))
+
+	sealed, err := box.Seal("hunter2")
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if !strings.HasPrefix(sealed, sealedPrefix) || strings.Contains(sealed, "hunter2") {
+		t.Fatalf("expected an encrypted value, got %q", sealed)
+	}
+
+	plain, err := box.Open(sealed)
+	if err != nil || plain != "hunter2" {
+		t.Fatalf("Open() = %q, %v", plain, err)
+	}
+
+	other, _ := NewSecretBox([]byte(strings.Repeat("k", 32)))
+	if _, err := other.Open(sealed); err != ErrInvalidSecret {
+		t.Errorf("expected ErrInvalidSecret with another key, got %v", err)
+	}
+}
+
+func TestLoadSecretKey_PersistsGeneratedKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "secret.key")
+
+	first, err := LoadSecretKey("", path)
+	if err != nil {
+		t.Fatalf("LoadSecretKey() error = %v", err)
+	}
+	second, err := LoadSecretKey("", path)
+	if err != nil {
+		t.Fatalf("LoadSecretKey() error = %v", err)
+	}
+	if len(first) != 32 || string(first) != string(second) {
+		t.Errorf("expected the generated key to be reused")
+	}
+}
+
+func TestNormalizeRegistry(t *testing.T) {
+	cases := map[string]string{
+		"ghcr.io":                     "ghcr.io",
+		"https://GHCR.io/v2/":         "ghcr.io",
+		"localhost:5000":              "localhost:5000",
+		"http://localhost:5000":       "localhost:5000",
+		"https://index.docker.io/v1/": DockerHub,
+		"registry-1.docker.io":        DockerHub,
+	}
+	for in, want := range cases {
+		if got := NormalizeRegistry(in); got != want {
+			t.Errorf("NormalizeRegistry(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRegistryManager_EncryptsAndMatchesByHostname(t *testing.T) {
+	registries, db := newTestRegistries(t)
+	ctx := context.Background()
+
+	reg := &models.Registry{Server: "https://localhost:5000/", Username: "admin", Password: "s3cret"}
+	if err := registries.CreateRegistry(ctx, reg); err != nil {
+		t.Fatalf("CreateRegistry() error = %v", err)
+	}
+	if reg.Server != "localhost:5000" || reg.Password != "" {
+		t.Errorf("expected a normalized server without secrets, got %+v", reg)
+	}
+
+	stored, err := db.GetRegistry(ctx, "localhost:5000")
+	if err != nil {
+		t.Fatalf("GetRegistry() error = %v", err)
+	}
+	if stored.Password == "" || strings.Contains(stored.Password, "s3cret") {
+		t.Errorf("expected the password to be encrypted at rest, got %q", stored.Password)
+	}
+
+	encoded, err := registries.AuthFor(ctx, "localhost:5000/team/app:1.0")
+	if err != nil {
+		t.Fatalf("AuthFor() error = %v", err)
+	}
+	auth, err := registry.DecodeAuthConfig(encoded)
+	if err != nil {
+		t.Fatalf("DecodeAuthConfig() error = %v", err)
+	}
+	if auth.Username != "admin" || auth.Password != "s3cret" || auth.ServerAddress != "localhost:5000" {
+		t.Errorf("unexpected auth config %+v", auth)
+	}
+
+	if encoded, err := registries.AuthFor(ctx, "docker.io/library/nginx:latest"); err != nil || encoded != "" {
+		t.Errorf("expected anonymous pulls for other registries, got %q, %v", encoded, err)
+	}
+
+	// Updates without secrets keep the stored password.
+	if err := registries.UpdateRegistry(ctx, &models.Registry{Server: "localhost:5000", Username: "root"}); err != nil {
+		t.Fatalf("UpdateRegistry() error = %v", err)
+	}
+	encoded, _ = registries.AuthFor(ctx, "localhost:5000/team/app:1.0")
+	auth, _ = registry.DecodeAuthConfig(encoded)
+	if auth.Username != "root" || auth.Password != "s3cret" {
+		t.Errorf("expected the password to be kept on update, got %+v", auth)
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	// Time zones of schedules must resolve on hosts without a zoneinfo
+	// database, such as minimal container images.
+	_ "time/tzdata"
+
+	"github.com/robfig/cron/v3"
+)
+
+// cronParser accepts five field expressions and descriptors such as
+// "@daily" or "@every 6h".
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ParseCron parses a cron expression evaluated in the IANA time zone tz,
+// UTC when empty.
+func ParseCron(expr, tz string) (cron.Schedule, error) {
+	loc := time.UTC
+	if tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return nil, fmt.Errorf("unknown time zone %q", tz)
+		}
+	}
+	if strings.HasPrefix(expr, "TZ=") || strings.HasPrefix(expr, "CRON_TZ=") {
+		return nil, fmt.Errorf("set the time zone through the timezone field")
+	}
+
+	schedule, err := cronParser.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	if spec, ok := schedule.(*cron.SpecSchedule); ok {
+		spec.Location = loc
+	}
+
+	return schedule, nil
+}
+
+// nextRun returns when a schedule runs next, nil when it is disabled or
+// its expression is invalid.
+func nextRun(expr, tz string, disabled bool) *time.Time {
+	if disabled {
+		return nil
+	}
+
+	schedule, err := ParseCron(expr, tz)
+	if err != nil {
+		return nil
+	}
+	next := schedule.Next(time.Now()).UTC()
+
+	return &next
+}
@@ -0,0 +1,462 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"mineServers/internal/database"
+	"mineServers/internal/models"
+
+	"github.com/charmbracelet/log"
+	"github.com/robfig/cron/v3"
+)
+
+// Actions run by schedules besides the state changes.
+const (
+	ActionStart    = "start"
+	ActionExec     = "exec"
+	ActionRecreate = "recreate"
+)
+
+// Outcomes of a scheduled run.
+const (
+	RunSucceeded = "succeeded"
+	RunFailed    = "failed"
+	RunSkipped   = "skipped"
+)
+
+// What started a run.
+const (
+	TriggerCron    = "cron"
+	TriggerCatchUp = "catch_up"
+	TriggerManual  = "manual"
+)
+
+// Policies for the runs missed while the server was down.
+const (
+	CatchUpSkip    = "skip"
+	CatchUpRunOnce = "run_once"
+)
+
+const (
+	// maxScheduleRuns is how many runs of a schedule are kept.
+	maxScheduleRuns = 100
+	// maxRunOutput bounds the output of exec actions kept in a run.
+	maxRunOutput = 4 << 10
+)
+
+var (
+	ErrActionScheduleNotFound   = errors.New("schedule not found")
+	ErrActionScheduleInProgress = errors.New("a run of the schedule is in progress")
+)
+
+// ActionScheduleManager runs start, stop, restart, exec and recreate actions
+// on containers according to cron expressions and keeps their history.
+type ActionScheduleManager struct {
+	ctx   context.Context
+	store database.ActionScheduleStore
+	hosts *HostManager
+	pulls *PullManager
+
+	mu      sync.Mutex
+	running map[int64]bool
+	cron    *cron.Cron
+	entries map[int64]cron.EntryID
+	// catchUps tracks the runs caught up at startup so Close waits for them.
+	catchUps sync.WaitGroup
+}
+
+// NewActionScheduleManager creates a manager whose actions run with ctx once
+// Start is called. Recreations pull their images through pulls.
+func NewActionScheduleManager(ctx context.Context, store database.ActionScheduleStore, hosts *HostManager, pulls *PullManager) *ActionScheduleManager {
+	return &ActionScheduleManager{
+		ctx:     ctx,
+		store:   store,
+		hosts:   hosts,
+		pulls:   pulls,
+		running: make(map[int64]bool),
+		cron:    cron.New(cron.WithParser(cronParser), cron.WithLocation(time.UTC)),
+		entries: make(map[int64]cron.EntryID),
+	}
+}
+
+// Start registers the stored schedules, applies their catch-up policy to the
+// runs missed while the server was down and starts running them.
+func (m *ActionScheduleManager) Start(ctx context.Context) error {
+	schedules, err := m.store.ListActionSchedules(ctx)
+	if err != nil {
+		log.Warnf("SCHEDULES: Unable to load schedules due: %s", err)
+		return err
+	}
+
+	now := time.Now()
+	for i := range schedules {
+		schedule := &schedules[i]
+		if err := m.register(schedule); err != nil {
+			log.Warnf("SCHEDULES: Unable to register schedule %d due: %s", schedule.ID, err)
+			continue
+		}
+		m.catchUps.Add(1)
+		go func() {
+			defer m.catchUps.Done()
+			m.catchUp(schedule, now)
+		}()
+	}
+	m.cron.Start()
+	log.Infof("SCHEDULES: %d schedules loaded", len(schedules))
+
+	return nil
+}
+
+// Close stops the schedules, waiting for the running actions.
+func (m *ActionScheduleManager) Close() error {
+	<-m.cron.Stop().Done()
+	m.catchUps.Wait()
+	return nil
+}
+
+func (m *ActionScheduleManager) ListSchedules(ctx context.Context) ([]models.ActionSchedule, error) {
+	schedules, err := m.store.ListActionSchedules(ctx)
+	if err != nil {
+		log.Warnf("SCHEDULES: Unable to list schedules due: %s", err)
+		return nil, err
+	}
+
+	for i := range schedules {
+		schedules[i].NextRunAt = nextRun(schedules[i].Cron, schedules[i].Timezone, schedules[i].Disabled)
+	}
+
+	return schedules, nil
+}
+
+func (m *ActionScheduleManager) GetSchedule(ctx context.Context, id int64) (*models.ActionSchedule, error) {
+	schedule, err := m.store.GetActionSchedule(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return nil, ErrActionScheduleNotFound
+		}
+		log.Warnf("SCHEDULES: Unable to get schedule %d due: %s", id, err)
+		return nil, err
+	}
+	schedule.NextRunAt = nextRun(schedule.Cron, schedule.Timezone, schedule.Disabled)
+
+	return schedule, nil
+}
+
+// CreateSchedule stores and registers a schedule running an action on a
+// container of host, which must exist.
+func (m *ActionScheduleManager) CreateSchedule(ctx context.Context, host string, def *models.ActionScheduleDefinition) (*models.ActionSchedule, error) {
+	if host == "" {
+		host = LocalHost
+	}
+	if err := m.validateSchedule(ctx, host, def); err != nil {
+		return nil, err
+	}
+
+	schedule := &models.ActionSchedule{Host: host, ActionScheduleDefinition: *def}
+	if err := m.store.CreateActionSchedule(ctx, schedule); err != nil {
+		log.Warnf("SCHEDULES: Unable to create schedule due: %s", err)
+		return nil, err
+	}
+	if err := m.register(schedule); err != nil {
+		return nil, err
+	}
+	schedule.NextRunAt = nextRun(schedule.Cron, schedule.Timezone, schedule.Disabled)
+	log.Infof("SCHEDULES: Schedule %d to %s container '%s' created", schedule.ID, schedule.Action, schedule.Container)
+
+	return schedule, nil
+}
+
+// UpdateSchedule replaces the definition of a schedule. The runs missed
+// before the update are not caught up.
+func (m *ActionScheduleManager) UpdateSchedule(ctx context.Context, id int64, def *models.ActionScheduleDefinition) (*models.ActionSchedule, error) {
+	schedule, err := m.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.validateSchedule(ctx, schedule.Host, def); err != nil {
+		return nil, err
+	}
+
+	schedule.ActionScheduleDefinition = *def
+	if err := m.store.UpdateActionSchedule(ctx, schedule); err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return nil, ErrActionScheduleNotFound
+		}
+		log.Warnf("SCHEDULES: Unable to update schedule %d due: %s", id, err)
+		return nil, err
+	}
+	if err := m.register(schedule); err != nil {
+		return nil, err
+	}
+	schedule.NextRunAt = nextRun(schedule.Cron, schedule.Timezone, schedule.Disabled)
+	log.Infof("SCHEDULES: Schedule %d updated", id)
+
+	return schedule, nil
+}
+
+// DeleteSchedule removes a schedule and its history.
+func (m *ActionScheduleManager) DeleteSchedule(ctx context.Context, id int64) error {
+	if err := m.store.DeleteActionSchedule(ctx, id); err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return ErrActionScheduleNotFound
+		}
+		log.Warnf("SCHEDULES: Unable to delete schedule %d due: %s", id, err)
+		return err
+	}
+	m.unregister(id)
+	log.Infof("SCHEDULES: Schedule %d deleted", id)
+
+	return nil
+}
+
+// RunSchedule runs the action of a schedule right away, even when disabled.
+// It fails with ErrActionScheduleInProgress while another run is going on.
+func (m *ActionScheduleManager) RunSchedule(ctx context.Context, id int64) (*models.ActionScheduleRun, error) {
+	schedule, err := m.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.run(ctx, schedule, TriggerManual)
+}
+
+// ListRuns returns the most recent runs of a schedule, newest first.
+func (m *ActionScheduleManager) ListRuns(ctx context.Context, id int64, limit int) ([]models.ActionScheduleRun, error) {
+	if _, err := m.GetSchedule(ctx, id); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > maxScheduleRuns {
+		limit = maxScheduleRuns
+	}
+
+	runs, err := m.store.ListActionScheduleRuns(ctx, id, limit)
+	if err != nil {
+		log.Warnf("SCHEDULES: Unable to list runs of schedule %d due: %s", id, err)
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+func (m *ActionScheduleManager) validateSchedule(ctx context.Context, host string, def *models.ActionScheduleDefinition) error {
+	v := &ValidationError{}
+	if def.Container == "" {
+		v.add("container", "is required")
+	}
+	switch def.Action {
+	case ActionStart, ActionStop, ActionRestart, ActionExec, ActionRecreate:
+	case "":
+		v.add("action", "is required")
+	default:
+		v.add("action", "must be one of %s, %s, %s, %s or %s", ActionStart, ActionStop, ActionRestart, ActionExec, ActionRecreate)
+	}
+	if def.Action == ActionExec {
+		if def.Exec == nil || len(def.Exec.Cmd) == 0 {
+			v.add("exec.cmd", "is required by the exec action")
+		} else if def.Exec.Timeout != "" {
+			if _, err := time.ParseDuration(def.Exec.Timeout); err != nil {
+				v.add("exec.timeout", "%s", err)
+			}
+		}
+	} else if def.Exec != nil {
+		v.add("exec", "is only supported by the exec action")
+	}
+	if def.Action != ActionRecreate && def.Recreate != nil {
+		v.add("recreate", "is only supported by the recreate action")
+	}
+	if def.Cron == "" {
+		v.add("cron", "is required")
+	} else if _, err := ParseCron(def.Cron, def.Timezone); err != nil {
+		v.add("cron", "%s", err)
+	}
+	switch def.CatchUp {
+	case "":
+		def.CatchUp = CatchUpSkip
+	case CatchUpSkip, CatchUpRunOnce:
+	default:
+		v.add("catch_up", "must be %s or %s", CatchUpSkip, CatchUpRunOnce)
+	}
+	if err := v.err(); err != nil {
+		return err
+	}
+
+	svc, err := m.hosts.Resolve(ctx, host)
+	if err != nil {
+		return err
+	}
+	if _, err := svc.InspectContainer(ctx, def.Container); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// register (re)schedules the runs of a schedule, none when disabled.
+func (m *ActionScheduleManager) register(schedule *models.ActionSchedule) error {
+	m.unregister(schedule.ID)
+	if schedule.Disabled {
+		return nil
+	}
+
+	parsed, err := ParseCron(schedule.Cron, schedule.Timezone)
+	if err != nil {
+		return err
+	}
+
+	id := schedule.ID
+	m.mu.Lock()
+	m.entries[id] = m.cron.Schedule(parsed, cron.FuncJob(func() {
+		schedule, err := m.GetSchedule(m.ctx, id)
+		if err != nil {
+			log.Warnf("SCHEDULES: Unable to run schedule %d due: %s", id, err)
+			return
+		}
+		m.run(m.ctx, schedule, TriggerCron)
+	}))
+	m.mu.Unlock()
+
+	return nil
+}
+
+func (m *ActionScheduleManager) unregister(id int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if entry, ok := m.entries[id]; ok {
+		m.cron.Remove(entry)
+		delete(m.entries, id)
+	}
+}
+
+// catchUp applies the catch-up policy of the schedule when a run was due
+// since its last run, or its last update, and now.
+func (m *ActionScheduleManager) catchUp(schedule *models.ActionSchedule, now time.Time) {
+	if schedule.Disabled {
+		return
+	}
+	parsed, err := ParseCron(schedule.Cron, schedule.Timezone)
+	if err != nil {
+		return
+	}
+
+	since := schedule.UpdatedAt
+	if schedule.LastRunAt != nil && schedule.LastRunAt.After(since) {
+		since = *schedule.LastRunAt
+	}
+	missed := parsed.Next(since)
+	if !missed.Before(now) {
+		return
+	}
+
+	if schedule.CatchUp == CatchUpRunOnce {
+		log.Infof("SCHEDULES: Catching up the run of schedule %d missed at %s", schedule.ID, missed.UTC().Format(time.RFC3339))
+		m.run(m.ctx, schedule, TriggerCatchUp)
+		return
+	}
+	m.record(&models.ActionScheduleRun{
+		ScheduleID: schedule.ID,
+		Action:     schedule.Action,
+		Trigger:    TriggerCatchUp,
+		Status:     RunSkipped,
+		Error:      fmt.Sprintf("run due at %s was missed while the server was down", missed.UTC().Format(time.RFC3339)),
+		StartedAt:  now,
+	})
+}
+
+// run performs the action of the schedule and records its outcome. Runs
+// overlapping a previous one are skipped, or rejected when manual.
+func (m *ActionScheduleManager) run(ctx context.Context, schedule *models.ActionSchedule, trigger string) (*models.ActionScheduleRun, error) {
+	run := &models.ActionScheduleRun{
+		ScheduleID: schedule.ID,
+		Action:     schedule.Action,
+		Trigger:    trigger,
+		StartedAt:  time.Now(),
+	}
+
+	m.mu.Lock()
+	busy := m.running[schedule.ID]
+	if !busy {
+		m.running[schedule.ID] = true
+	}
+	m.mu.Unlock()
+	if busy {
+		if trigger == TriggerManual {
+			return nil, ErrActionScheduleInProgress
+		}
+		run.Status, run.Error = RunSkipped, ErrActionScheduleInProgress.Error()
+		m.record(run)
+		return run, nil
+	}
+	defer func() {
+		m.mu.Lock()
+		delete(m.running, schedule.ID)
+		m.mu.Unlock()
+	}()
+
+	output, err := m.perform(ctx, schedule)
+	run.DurationMs = time.Since(run.StartedAt).Milliseconds()
+	run.Output = output
+	if err != nil {
+		run.Status, run.Error = RunFailed, err.Error()
+		log.Warnf("SCHEDULES: Unable to %s container '%s' for schedule %d due: %s", schedule.Action, schedule.Container, schedule.ID, err)
+	} else {
+		run.Status = RunSucceeded
+		log.Infof("SCHEDULES: Schedule %d ran %s on container '%s' in %dms", schedule.ID, schedule.Action, schedule.Container, run.DurationMs)
+	}
+	m.record(run)
+
+	return run, nil
+}
+
+// perform runs the action of the schedule, returning the output of exec
+// actions.
+func (m *ActionScheduleManager) perform(ctx context.Context, schedule *models.ActionSchedule) (string, error) {
+	svc, err := m.hosts.Resolve(ctx, schedule.Host)
+	if err != nil {
+		return "", err
+	}
+
+	switch schedule.Action {
+	case ActionStart:
+		return "", svc.StartContainer(ctx, schedule.Container)
+	case ActionStop, ActionRestart:
+		_, err := svc.ChangeContainerState(ctx, schedule.Container, schedule.Action, nil)
+		return "", err
+	case ActionExec:
+		result, err := svc.ExecCommand(ctx, schedule.Container, schedule.Exec)
+		if err != nil {
+			return "", err
+		}
+		output := result.Stdout + result.Stderr
+		if len(output) > maxRunOutput {
+			output = output[:maxRunOutput]
+		}
+		switch {
+		case result.TimedOut:
+			return output, fmt.Errorf("command timed out after %s", result.Duration)
+		case result.ExitCode != 0:
+			return output, fmt.Errorf("command exited with code %d", result.ExitCode)
+		}
+		return output, nil
+	case ActionRecreate:
+		req := schedule.Recreate
+		if req == nil {
+			req = &models.RecreateRequest{}
+		}
+		_, err := svc.RecreateContainer(ctx, m.pulls, schedule.Host, schedule.Container, req)
+		return "", err
+	default:
+		return "", fmt.Errorf("unknown action %q", schedule.Action)
+	}
+}
+
+func (m *ActionScheduleManager) record(run *models.ActionScheduleRun) {
+	// The outcome is kept even when the run was canceled.
+	if err := m.store.RecordActionScheduleRun(context.WithoutCancel(m.ctx), run, maxScheduleRuns); err != nil {
+		log.Warnf("SCHEDULES: Unable to record run of schedule %d due: %s", run.ScheduleID, err)
+	}
+}
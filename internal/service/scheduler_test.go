@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"mineServers/internal/fakedocker"
+	"mineServers/internal/models"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/errdefs"
+)
+
+func newTestScheduler(t *testing.T) (*ActionScheduleManager, *fakedocker.Engine) {
+	t.Helper()
+
+	db := openTestDB(t)
+
+	svc, pulls, engine, _ := newTestRecreate(t)
+	ctx := context.Background()
+	schedules := NewActionScheduleManager(ctx, db, NewHostManager(ctx, svc, nil, nil), pulls)
+	t.Cleanup(func() { schedules.Close() })
+
+	return schedules, engine
+}
+
+func TestActionScheduleManager_ValidatesSchedules(t *testing.T) {
+	schedules, _ := newTestScheduler(t)
+	ctx := context.Background()
+
+	invalid := []models.ActionScheduleDefinition{
+		{Container: "web", Action: "reboot", Cron: "@daily"},
+		{Container: "web", Action: ActionExec, Cron: "@daily"},
+		{Container: "web", Action: ActionRestart, Cron: "@daily", Exec: &models.ExecRequest{Cmd: []string{"true"}}},
+		{Container: "web", Action: ActionRestart, Cron: "0 4 * *"},
+		{Container: "web", Action: ActionRestart, Cron: "TZ=UTC 0 4 * * *"},
+		{Container: "web", Action: ActionRestart, Cron: "@daily", CatchUp: "always"},
+		{Action: ActionRestart, Cron: "@daily"},
+	}
+	for _, def := range invalid {
+		var verr *ValidationError
+		if _, err := schedules.CreateSchedule(ctx, "", &def); !errors.As(err, &verr) {
+			t.Errorf("CreateSchedule(%+v) error = %v, want ValidationError", def, err)
+		}
+	}
+	if _, err := schedules.CreateSchedule(ctx, "", &models.ActionScheduleDefinition{Container: "missing", Action: ActionStart, Cron: "@daily"}); !errdefs.IsNotFound(err) {
+		t.Errorf("CreateSchedule(missing container) error = %v, want not found", err)
+	}
+	if _, err := schedules.CreateSchedule(ctx, "remote", &models.ActionScheduleDefinition{Container: "web", Action: ActionStart, Cron: "@daily"}); !errors.Is(err, ErrHostNotFound) {
+		t.Errorf("CreateSchedule(unknown host) error = %v, want ErrHostNotFound", err)
+	}
+
+	schedule, err := schedules.CreateSchedule(ctx, "", &models.ActionScheduleDefinition{
+		Container: "web",
+		Action:    ActionStop,
+		Cron:      "0 20 * * 1-5",
+		Timezone:  "America/New_York",
+	})
+	if err != nil {
+		t.Fatalf("CreateSchedule() error = %v", err)
+	}
+	if schedule.CatchUp != CatchUpSkip || schedule.NextRunAt == nil {
+		t.Fatalf("schedule = %+v", schedule)
+	}
+	next := schedule.NextRunAt.In(mustLoadLocation(t, "America/New_York"))
+	if next.Hour() != 20 || next.Minute() != 0 || next.Weekday() == time.Saturday || next.Weekday() == time.Sunday {
+		t.Fatalf("next run = %s", next)
+	}
+
+	schedule, err = schedules.UpdateSchedule(ctx, schedule.ID, &models.ActionScheduleDefinition{Container: "web", Action: ActionStop, Cron: "@daily", Disabled: true})
+	if err != nil || schedule.NextRunAt != nil {
+		t.Fatalf("UpdateSchedule(disabled) = %+v, %v", schedule, err)
+	}
+	if err := schedules.DeleteSchedule(ctx, schedule.ID); err != nil {
+		t.Fatalf("DeleteSchedule() error = %v", err)
+	}
+	if _, err := schedules.GetSchedule(ctx, schedule.ID); !errors.Is(err, ErrActionScheduleNotFound) {
+		t.Fatalf("GetSchedule() after delete error = %v, want ErrActionScheduleNotFound", err)
+	}
+}
+
+func TestActionScheduleManager_RunRecordsHistory(t *testing.T) {
+	schedules, engine := newTestScheduler(t)
+	engine.OnExec(func(ctx context.Context, opts container.ExecOptions, stdin io.Reader, stdout, stderr io.Writer) int {
+		io.WriteString(stdout, "saving")
+		return 3
+	})
+	ctx := context.Background()
+
+	stop, err := schedules.CreateSchedule(ctx, "", &models.ActionScheduleDefinition{Container: "web", Action: ActionStop, Cron: "@daily"})
+	if err != nil {
+		t.Fatalf("CreateSchedule(stop) error = %v", err)
+	}
+	run, err := schedules.RunSchedule(ctx, stop.ID)
+	if err != nil {
+		t.Fatalf("RunSchedule(stop) error = %v", err)
+	}
+	if run.Status != RunSucceeded || run.Trigger != TriggerManual || run.Action != ActionStop {
+		t.Fatalf("run = %+v", run)
+	}
+	if web, _ := engine.Container("web"); web.State != "exited" {
+		t.Fatalf("container state = %s, want exited", web.State)
+	}
+
+	start, err := schedules.CreateSchedule(ctx, "", &models.ActionScheduleDefinition{Container: "web", Action: ActionStart, Cron: "@daily"})
+	if err != nil {
+		t.Fatalf("CreateSchedule(start) error = %v", err)
+	}
+	if run, err := schedules.RunSchedule(ctx, start.ID); err != nil || run.Status != RunSucceeded {
+		t.Fatalf("RunSchedule(start) = %+v, %v", run, err)
+	}
+
+	exec, err := schedules.CreateSchedule(ctx, "", &models.ActionScheduleDefinition{
+		Container: "web",
+		Action:    ActionExec,
+		Cron:      "@hourly",
+		Exec:      &models.ExecRequest{Cmd: []string{"rcon-cli", "save-all"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateSchedule(exec) error = %v", err)
+	}
+	run, err = schedules.RunSchedule(ctx, exec.ID)
+	if err != nil {
+		t.Fatalf("RunSchedule(exec) error = %v", err)
+	}
+	if run.Status != RunFailed || run.Error != "command exited with code 3" || run.Output != "saving" {
+		t.Fatalf("run = %+v", run)
+	}
+
+	runs, err := schedules.ListRuns(ctx, exec.ID, 0)
+	if err != nil || len(runs) != 1 || runs[0].ID != run.ID {
+		t.Fatalf("ListRuns() = %+v, %v", runs, err)
+	}
+	stored, err := schedules.GetSchedule(ctx, exec.ID)
+	if err != nil || stored.LastStatus != RunFailed || stored.LastRunAt == nil {
+		t.Fatalf("GetSchedule() = %+v, %v", stored, err)
+	}
+}
+
+func TestActionScheduleManager_CatchUp(t *testing.T) {
+	schedules, engine := newTestScheduler(t)
+	ctx := context.Background()
+
+	skip, err := schedules.CreateSchedule(ctx, "", &models.ActionScheduleDefinition{Container: "web", Action: ActionStop, Cron: "0 * * * *"})
+	if err != nil {
+		t.Fatalf("CreateSchedule(skip) error = %v", err)
+	}
+	once, err := schedules.CreateSchedule(ctx, "", &models.ActionScheduleDefinition{Container: "web", Action: ActionStop, Cron: "0 * * * *", CatchUp: CatchUpRunOnce})
+	if err != nil {
+		t.Fatalf("CreateSchedule(run_once) error = %v", err)
+	}
+
+	// Nothing was missed yet.
+	schedules.catchUp(skip, skip.UpdatedAt.Add(time.Second))
+	if runs, _ := schedules.ListRuns(ctx, skip.ID, 0); len(runs) != 0 {
+		t.Fatalf("runs before a missed run = %+v", runs)
+	}
+
+	later := time.Now().Add(3 * time.Hour)
+	schedules.catchUp(skip, later)
+	runs, _ := schedules.ListRuns(ctx, skip.ID, 0)
+	if len(runs) != 1 || runs[0].Status != RunSkipped || runs[0].Trigger != TriggerCatchUp {
+		t.Fatalf("skipped runs = %+v", runs)
+	}
+	if web, _ := engine.Container("web"); web.State != "running" {
+		t.Fatalf("container state = %s, want running", web.State)
+	}
+
+	schedules.catchUp(once, later)
+	runs, _ = schedules.ListRuns(ctx, once.ID, 0)
+	if len(runs) != 1 || runs[0].Status != RunSucceeded || runs[0].Trigger != TriggerCatchUp {
+		t.Fatalf("caught up runs = %+v", runs)
+	}
+	if web, _ := engine.Container("web"); web.State != "exited" {
+		t.Fatalf("container state = %s, want exited", web.State)
+	}
+}
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("LoadLocation(%s) error = %v", name, err)
+	}
+	return loc
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"mineServers/internal/models"
+
+	"github.com/charmbracelet/log"
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// commitInstructions are the Dockerfile instructions the daemon accepts as
+// changes of a commit or an import.
+var commitInstructions = map[string]bool{
+	"CMD": true, "ENTRYPOINT": true, "ENV": true, "EXPOSE": true, "LABEL": true,
+	"ONBUILD": true, "STOPSIGNAL": true, "USER": true, "VOLUME": true, "WORKDIR": true,
+}
+
+// CommitContainer creates an image from the current state of the container,
+// pausing it meanwhile unless req disables it.
+func (c *ContainerService) CommitContainer(ctx context.Context, id string, req *models.CommitRequest) (*models.ImageSnapshot, error) {
+	v := &ValidationError{}
+	ref := snapshotReference(v, req.Reference)
+	validateImageChanges(v, req.Changes)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	info, err := c.InspectContainer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	pause := req.Pause == nil || *req.Pause
+	resp, err := c.cli.ContainerCommit(ctx, info.ID, container.CommitOptions{
+		Reference: ref,
+		Comment:   req.Message,
+		Author:    req.Author,
+		Changes:   req.Changes,
+		Pause:     pause,
+	})
+	if err != nil {
+		log.Warnf("CONTAINER-SNAPSHOT: Unable to commit container '%s' due: %s", id, err)
+		return nil, err
+	}
+	log.Infof("CONTAINER-SNAPSHOT: Container '%s' committed to image %s", id, shortID(resp.ID))
+
+	return &models.ImageSnapshot{
+		ID:        resp.ID,
+		Reference: ref,
+		Container: strings.TrimPrefix(info.Name, "/"),
+		Message:   req.Message,
+		Changes:   req.Changes,
+	}, nil
+}
+
+// ExportContainer returns a tar archive of the filesystem of the container
+// with its name. Volumes are not part of it.
+func (c *ContainerService) ExportContainer(ctx context.Context, id string) (io.ReadCloser, string, error) {
+	info, err := c.InspectContainer(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rc, err := c.cli.ContainerExport(ctx, info.ID)
+	if err != nil {
+		log.Warnf("CONTAINER-SNAPSHOT: Unable to export container '%s' due: %s", id, err)
+		return nil, "", err
+	}
+
+	return rc, strings.TrimPrefix(info.Name, "/"), nil
+}
+
+// ImportImage creates an image from a tarball of a filesystem, such as the
+// export of a container.
+func (c *ContainerService) ImportImage(ctx context.Context, src io.Reader, req *models.ImportRequest) (*models.ImageSnapshot, error) {
+	v := &ValidationError{}
+	ref := snapshotReference(v, req.Reference)
+	validateImageChanges(v, req.Changes)
+	if _, err := ParsePlatform(req.Platform); err != nil {
+		v.add("platform", "%s", err)
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	rc, err := c.cli.ImageImport(ctx, image.ImportSource{Source: src, SourceName: "-"}, ref, image.ImportOptions{
+		Message:  req.Message,
+		Changes:  req.Changes,
+		Platform: req.Platform,
+	})
+	if err != nil {
+		log.Warnf("IMAGE-IMPORT: Unable to import image due: %s", err)
+		return nil, err
+	}
+	defer rc.Close()
+
+	// The daemon reports the ID of the image as the status of the last
+	// message.
+	var id string
+	dec := json.NewDecoder(rc)
+	for {
+		var msg jsonmessage.JSONMessage
+		if err := dec.Decode(&msg); err == io.EOF {
+			break
+		} else if err != nil {
+			log.Warnf("IMAGE-IMPORT: Unable to read import progress due: %s", err)
+			return nil, err
+		}
+		if msg.Error != nil {
+			log.Warnf("IMAGE-IMPORT: Unable to import image due: %s", msg.Error.Message)
+			return nil, msg.Error
+		}
+		if strings.HasPrefix(msg.Status, "sha256:") {
+			id = msg.Status
+		}
+	}
+	if id == "" {
+		return nil, errors.New("import finished without an image ID")
+	}
+	log.Infof("IMAGE-IMPORT: Image %s imported", shortID(id))
+
+	return &models.ImageSnapshot{ID: id, Reference: ref, Message: req.Message, Changes: req.Changes}, nil
+}
+
+// snapshotReference normalizes the optional reference of a new image,
+// which cannot carry a digest.
+func snapshotReference(v *ValidationError, ref string) string {
+	if ref == "" {
+		return ""
+	}
+
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		v.add("reference", "%s", err)
+		return ""
+	}
+	if _, ok := named.(reference.Digested); ok {
+		v.add("reference", "cannot contain a digest")
+		return ""
+	}
+
+	return reference.TagNameOnly(named).String()
+}
+
+func validateImageChanges(v *ValidationError, changes []string) {
+	for _, change := range changes {
+		instruction, args, _ := strings.Cut(strings.TrimSpace(change), " ")
+		if !commitInstructions[strings.ToUpper(instruction)] {
+			v.add("changes", "%q is not a supported instruction", instruction)
+		} else if strings.TrimSpace(args) == "" {
+			v.add("changes", "%s requires arguments", strings.ToUpper(instruction))
+		}
+	}
+}
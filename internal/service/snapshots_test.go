@@ -0,0 +1,121 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"mineServers/internal/models"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/errdefs"
+)
+
+func TestContainerService_CommitContainer(t *testing.T) {
+	svc, _, engine, id := newTestRecreate(t)
+	engine.WriteFile(id, "/etc/nginx/nginx.conf", []byte("worker_processes 4;"), 0o644)
+	ctx := context.Background()
+
+	snapshot, err := svc.CommitContainer(ctx, "web", &models.CommitRequest{
+		Reference: "web-snapshots:before-upgrade",
+		Message:   "tuned workers",
+		Changes:   []string{"ENV MODE=debug", `cmd ["nginx", "-g", "daemon off;"]`},
+	})
+	if err != nil {
+		t.Fatalf("CommitContainer() error = %v", err)
+	}
+	if snapshot.Reference != "docker.io/library/web-snapshots:before-upgrade" || snapshot.Container != "web" || snapshot.ID == "" {
+		t.Fatalf("snapshot = %+v", snapshot)
+	}
+
+	img, ok := engine.Image(snapshot.Reference)
+	if !ok || img.ID != snapshot.ID || img.Comment != "tuned workers" || len(img.Changes) != 2 || !img.Paused {
+		t.Fatalf("image = %+v, %v", img, ok)
+	}
+
+	// Containers of the snapshot start with the committed filesystem.
+	resp, err := engine.ContainerCreate(ctx, &container.Config{Image: snapshot.Reference}, nil, nil, nil, "restored")
+	if err != nil {
+		t.Fatalf("ContainerCreate() error = %v", err)
+	}
+	if data, ok := engine.ReadFile(resp.ID, "/etc/nginx/nginx.conf"); !ok || string(data) != "worker_processes 4;" {
+		t.Fatalf("nginx.conf = %q, %v", data, ok)
+	}
+
+	pause := false
+	untagged, err := svc.CommitContainer(ctx, id, &models.CommitRequest{Pause: &pause})
+	if err != nil {
+		t.Fatalf("CommitContainer(untagged) error = %v", err)
+	}
+	if img, ok := engine.Image(untagged.ID); !ok || img.Paused || untagged.Reference != "" {
+		t.Fatalf("untagged = %+v, image = %+v", untagged, img)
+	}
+}
+
+func TestContainerService_CommitContainerRejectsInvalidRequests(t *testing.T) {
+	svc, _, _, id := newTestRecreate(t)
+	ctx := context.Background()
+
+	var verr *ValidationError
+	_, err := svc.CommitContainer(ctx, id, &models.CommitRequest{Reference: "Bad Name", Changes: []string{"RUN rm -rf /", "ENV"}})
+	if !errors.As(err, &verr) || len(verr.Fields) != 3 {
+		t.Fatalf("CommitContainer() error = %v, want 3 field errors", err)
+	}
+
+	digest := "snap@sha256:" + strings.Repeat("a", 64)
+	if _, err := svc.CommitContainer(ctx, id, &models.CommitRequest{Reference: digest}); !errors.As(err, &verr) {
+		t.Fatalf("CommitContainer(digest) error = %v, want ValidationError", err)
+	}
+	if _, err := svc.CommitContainer(ctx, "missing", &models.CommitRequest{}); !errdefs.IsNotFound(err) {
+		t.Fatalf("CommitContainer(missing) error = %v, want not found", err)
+	}
+}
+
+func TestContainerService_ExportImportRoundTrip(t *testing.T) {
+	svc, _, engine, id := newTestRecreate(t)
+	engine.WriteFile(id, "/srv/app/config.yml", []byte("debug: true"), 0o600)
+	ctx := context.Background()
+
+	rc, name, err := svc.ExportContainer(ctx, id)
+	if err != nil {
+		t.Fatalf("ExportContainer() error = %v", err)
+	}
+	archive, _ := io.ReadAll(rc)
+	rc.Close()
+	if name != "web" {
+		t.Fatalf("name = %s", name)
+	}
+
+	snapshot, err := svc.ImportImage(ctx, bytes.NewReader(archive), &models.ImportRequest{
+		Reference: "web-restored:1",
+		Message:   "from export",
+		Changes:   []string{"WORKDIR /srv/app"},
+		Platform:  "linux/arm64",
+	})
+	if err != nil {
+		t.Fatalf("ImportImage() error = %v", err)
+	}
+	img, ok := engine.Image("docker.io/library/web-restored:1")
+	if !ok || img.ID != snapshot.ID || img.Architecture != "arm64" || img.Comment != "from export" {
+		t.Fatalf("image = %+v, %v, snapshot = %+v", img, ok, snapshot)
+	}
+
+	resp, err := engine.ContainerCreate(ctx, &container.Config{Image: snapshot.Reference}, nil, nil, nil, "restored")
+	if err != nil {
+		t.Fatalf("ContainerCreate() error = %v", err)
+	}
+	if data, ok := engine.ReadFile(resp.ID, "/srv/app/config.yml"); !ok || string(data) != "debug: true" {
+		t.Fatalf("config.yml = %q, %v", data, ok)
+	}
+
+	if _, err := svc.ImportImage(ctx, strings.NewReader("not a tarball"), &models.ImportRequest{}); !errdefs.IsInvalidParameter(err) {
+		t.Fatalf("ImportImage(invalid) error = %v, want invalid parameter", err)
+	}
+	var verr *ValidationError
+	if _, err := svc.ImportImage(ctx, bytes.NewReader(archive), &models.ImportRequest{Platform: "arm64"}); !errors.As(err, &verr) {
+		t.Fatalf("ImportImage(platform) error = %v, want ValidationError", err)
+	}
+}
@@ -0,0 +1,523 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"mineServers/internal/database"
+	"mineServers/internal/models"
+
+	"github.com/charmbracelet/log"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/errdefs"
+)
+
+// serviceMissing is the state of a service without a container.
+const serviceMissing = "missing"
+
+// dependencyTimeout bounds how long a service waits for its dependencies
+// to become healthy or to complete, checking them every dependencyPollInterval.
+const (
+	dependencyTimeout      = 2 * time.Minute
+	dependencyPollInterval = 500 * time.Millisecond
+)
+
+var ErrStackNotFound = errors.New("stack not found")
+
+// StackManager deploys compose files as stacks: compose projects whose
+// networks, volumes and containers are created in dependency order and
+// labelled with the stack name.
+type StackManager struct {
+	store database.StackStore
+	hosts *HostManager
+	pulls *PullManager
+}
+
+func NewStackManager(store database.StackStore, hosts *HostManager, pulls *PullManager) *StackManager {
+	return &StackManager{
+		store: store,
+		hosts: hosts,
+		pulls: pulls,
+	}
+}
+
+func (m *StackManager) ListStacks(ctx context.Context) ([]models.Stack, error) {
+	stacks, err := m.store.ListStacks(ctx)
+	if err != nil {
+		log.Warnf("STACKS: Unable to list stacks due: %s", err)
+		return nil, err
+	}
+
+	return stacks, nil
+}
+
+func (m *StackManager) GetStack(ctx context.Context, name string) (*models.Stack, error) {
+	stack, err := m.store.GetStack(ctx, name)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return nil, ErrStackNotFound
+		}
+		log.Warnf("STACKS: Unable to get stack '%s' due: %s", name, err)
+		return nil, err
+	}
+
+	return stack, nil
+}
+
+// CreateStack validates and stores the compose file of a stack on host
+// without deploying it. The name defaults to the one of the compose file.
+func (m *StackManager) CreateStack(ctx context.Context, host string, req *models.StackRequest) (*models.Stack, error) {
+	if host == "" {
+		host = LocalHost
+	}
+	name := req.Name
+	if name == "" {
+		name = ProjectName([]byte(req.Compose))
+	}
+	if _, err := parseCompose(name, []byte(req.Compose)); err != nil {
+		return nil, err
+	}
+	if _, err := m.hosts.Resolve(ctx, host); err != nil {
+		return nil, err
+	}
+
+	stack := &models.Stack{Name: name, Host: host, Compose: req.Compose}
+	if err := m.store.CreateStack(ctx, stack); err != nil {
+		return nil, err
+	}
+
+	return stack, nil
+}
+
+// UpdateStack replaces the compose file of a stack. Running containers are
+// only changed on the next Up.
+func (m *StackManager) UpdateStack(ctx context.Context, name, compose string) (*models.Stack, error) {
+	if _, err := parseCompose(name, []byte(compose)); err != nil {
+		return nil, err
+	}
+
+	stack := &models.Stack{Name: name, Compose: compose}
+	if err := m.store.UpdateStack(ctx, stack); err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return nil, ErrStackNotFound
+		}
+		return nil, err
+	}
+
+	return m.GetStack(ctx, name)
+}
+
+// DeleteStack takes the stack down and forgets it.
+func (m *StackManager) DeleteStack(ctx context.Context, name string, removeVolumes bool) error {
+	if _, err := m.Down(ctx, name, removeVolumes); err != nil {
+		return err
+	}
+
+	if err := m.store.DeleteStack(ctx, name); err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return ErrStackNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Up deploys the stack: it pulls the images, creates the missing networks
+// and volumes, then creates or starts the container of every service after
+// its dependencies. Containers whose configuration didn't change are kept,
+// the others are recreated, and containers of removed services are removed.
+func (m *StackManager) Up(ctx context.Context, name string) (*models.StackStatus, error) {
+	stack, project, svc, err := m.load(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range project.Services {
+		if err := m.pull(ctx, svc, stack.Host, s); err != nil {
+			return nil, fmt.Errorf("service %s: %w", s.Name, err)
+		}
+	}
+	for _, n := range project.Networks {
+		if err := ensureNetwork(ctx, svc, n); err != nil {
+			return nil, fmt.Errorf("network %s: %w", n.Key, err)
+		}
+	}
+	for _, vol := range project.Volumes {
+		if err := ensureVolume(ctx, svc, vol); err != nil {
+			return nil, fmt.Errorf("volume %s: %w", vol.Key, err)
+		}
+	}
+
+	containers, err := projectContainers(ctx, svc, project.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	started := map[string]string{}
+	for _, s := range project.Services {
+		if err := waitDependencies(ctx, svc, s, started); err != nil {
+			return nil, fmt.Errorf("service %s: %w", s.Name, err)
+		}
+
+		id, err := converge(ctx, svc, s, containers[s.Name])
+		if err != nil {
+			return nil, fmt.Errorf("service %s: %w", s.Name, err)
+		}
+		started[s.Name] = id
+		delete(containers, s.Name)
+	}
+
+	// Whatever is left belongs to services removed from the compose file.
+	for service, c := range containers {
+		log.Infof("STACKS: Removing orphan container of service '%s' of stack '%s'", service, name)
+		if err := svc.RemoveContainer(ctx, c.ID); err != nil {
+			return nil, fmt.Errorf("service %s: %w", service, err)
+		}
+	}
+
+	log.Infof("STACKS: Stack '%s' is up", name)
+	return stackStatus(ctx, svc, stack, project)
+}
+
+// Down stops and removes the containers of the stack in reverse dependency
+// order, then its networks and, when asked to, its volumes. External
+// networks and volumes are left alone.
+func (m *StackManager) Down(ctx context.Context, name string, removeVolumes bool) (*models.StackStatus, error) {
+	stack, project, svc, err := m.load(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := svc.projectContainerList(ctx, projectFilter(project.Name))
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.downProject(ctx, project.Name, orderByDependencies(list), removeVolumes); err != nil {
+		return nil, err
+	}
+
+	log.Infof("STACKS: Stack '%s' is down", name)
+	return stackStatus(ctx, svc, stack, project)
+}
+
+// Restart restarts the existing containers of the stack in dependency order.
+func (m *StackManager) Restart(ctx context.Context, name string) (*models.StackStatus, error) {
+	stack, project, svc, err := m.load(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := svc.projectContainerList(ctx, projectFilter(project.Name))
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.restartContainers(ctx, orderByDependencies(list)); err != nil {
+		return nil, err
+	}
+
+	return stackStatus(ctx, svc, stack, project)
+}
+
+// Status reports the container of every service of the stack.
+func (m *StackManager) Status(ctx context.Context, name string) (*models.StackStatus, error) {
+	stack, project, svc, err := m.load(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return stackStatus(ctx, svc, stack, project)
+}
+
+// Logs follows the output of every container of the stack, prefixed with
+// its service. The channel is closed once every stream ends or ctx is done.
+func (m *StackManager) Logs(ctx context.Context, name string) (<-chan models.StackLogLine, error) {
+	_, project, svc, err := m.load(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	containers, err := projectContainers(ctx, svc, project.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	readers := map[string]io.ReadCloser{}
+	for _, service := range sortedKeys(containers) {
+		reader, err := svc.StreamContainerLogs(ctx, containers[service].ID)
+		if err != nil {
+			for _, r := range readers {
+				r.Close()
+			}
+			return nil, err
+		}
+		readers[service] = reader
+	}
+
+	out := make(chan models.StackLogLine)
+	var wg sync.WaitGroup
+	for service, reader := range readers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer reader.Close()
+
+			scanner := bufio.NewScanner(reader)
+			for scanner.Scan() {
+				select {
+				case out <- models.StackLogLine{Service: service, Line: scanner.Text()}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out, nil
+}
+
+// load returns a stack with its parsed compose file and the service of its host.
+func (m *StackManager) load(ctx context.Context, name string) (*models.Stack, *composeProject, *ContainerService, error) {
+	stack, err := m.GetStack(ctx, name)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	project, err := parseCompose(stack.Name, []byte(stack.Compose))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	svc, err := m.hosts.Resolve(ctx, stack.Host)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return stack, project, svc, nil
+}
+
+// pull makes the image of the service available through a pull job, so it
+// can be followed like any other pull and uses the registry credentials.
+func (m *StackManager) pull(ctx context.Context, svc *ContainerService, host string, s *projectService) error {
+	job := m.pulls.Start(svc, host, s.Image, s.Options.PullPolicy, image.PullOptions{Platform: s.Options.Platform}, nil)
+	job, err := m.pulls.Wait(ctx, job.ID)
+	if err != nil {
+		return err
+	}
+	if job.Status == PullFailed {
+		return errors.New(job.Error)
+	}
+
+	return nil
+}
+
+func ensureNetwork(ctx context.Context, svc *ContainerService, n projectResource) error {
+	_, err := svc.cli.NetworkInspect(ctx, n.Name, network.InspectOptions{})
+	switch {
+	case err == nil:
+		return nil
+	case !errdefs.IsNotFound(err):
+		log.Warnf("STACKS: Unable to inspect network '%s' due: %s", n.Name, err)
+		return err
+	case n.External:
+		return fmt.Errorf("external network %s does not exist", n.Name)
+	}
+
+	if _, err := svc.cli.NetworkCreate(ctx, n.Name, network.CreateOptions{Driver: n.Driver, Labels: n.Labels}); err != nil {
+		log.Warnf("STACKS: Unable to create network '%s' due: %s", n.Name, err)
+		return err
+	}
+
+	return nil
+}
+
+func ensureVolume(ctx context.Context, svc *ContainerService, vol projectResource) error {
+	_, err := svc.cli.VolumeInspect(ctx, vol.Name)
+	switch {
+	case err == nil:
+		return nil
+	case !errdefs.IsNotFound(err):
+		log.Warnf("STACKS: Unable to inspect volume '%s' due: %s", vol.Name, err)
+		return err
+	case vol.External:
+		return fmt.Errorf("external volume %s does not exist", vol.Name)
+	}
+
+	if _, err := svc.cli.VolumeCreate(ctx, volume.CreateOptions{Name: vol.Name, Driver: vol.Driver, Labels: vol.Labels}); err != nil {
+		log.Warnf("STACKS: Unable to create volume '%s' due: %s", vol.Name, err)
+		return err
+	}
+
+	return nil
+}
+
+// converge makes the container of the service match its spec and run,
+// recreating it when its configuration changed. It returns its ID.
+func converge(ctx context.Context, svc *ContainerService, s *projectService, current *container.Summary) (string, error) {
+	if current != nil && current.Labels[LabelConfigHash] == s.Spec.Config.Labels[LabelConfigHash] {
+		if current.State != "running" {
+			if err := svc.StartContainer(ctx, current.ID); err != nil {
+				return "", err
+			}
+		}
+		return current.ID, nil
+	}
+
+	if current != nil {
+		log.Infof("STACKS: Recreating container of service '%s'", s.Name)
+		if err := svc.RemoveContainer(ctx, current.ID); err != nil {
+			return "", err
+		}
+	}
+
+	spec := s.Spec
+	resp, err := svc.cli.ContainerCreate(ctx, spec.Config, spec.HostConfig, spec.Networking, spec.Platform, spec.Name)
+	if err != nil {
+		log.Warnf("STACKS: Unable to create container of service '%s' due: %s", s.Name, err)
+		return "", err
+	}
+	if err := svc.StartContainer(ctx, resp.ID); err != nil {
+		return resp.ID, err
+	}
+
+	return resp.ID, nil
+}
+
+// waitDependencies blocks until every dependency of the service satisfies
+// its condition. Dependencies come first in the project so they are
+// already started.
+func waitDependencies(ctx context.Context, svc *ContainerService, s *projectService, started map[string]string) error {
+	ctx, cancel := context.WithTimeout(ctx, dependencyTimeout)
+	defer cancel()
+
+	for _, dep := range sortedKeys(s.DependsOn) {
+		condition := s.DependsOn[dep]
+		if condition == ConditionStarted {
+			continue
+		}
+
+		for {
+			info, err := svc.InspectContainer(ctx, started[dep])
+			if err != nil {
+				return err
+			}
+
+			done, err := dependencyMet(dep, condition, info.State)
+			if err != nil {
+				return err
+			}
+			if done {
+				break
+			}
+
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("timed out waiting for %s to be %s", dep, strings.TrimPrefix(condition, "service_"))
+			case <-time.After(dependencyPollInterval):
+			}
+		}
+	}
+
+	return nil
+}
+
+func dependencyMet(dep, condition string, state *container.State) (bool, error) {
+	if state == nil {
+		return false, nil
+	}
+
+	switch condition {
+	case ConditionHealthy:
+		if state.Health == nil {
+			return false, fmt.Errorf("dependency %s has no healthcheck", dep)
+		}
+		if state.Health.Status == container.Unhealthy {
+			return false, fmt.Errorf("dependency %s is unhealthy", dep)
+		}
+		return state.Health.Status == container.Healthy, nil
+	case ConditionCompleted:
+		if state.Running || state.Status == "created" {
+			return false, nil
+		}
+		if state.ExitCode != 0 {
+			return false, fmt.Errorf("dependency %s exited with code %d", dep, state.ExitCode)
+		}
+		return true, nil
+	}
+
+	return true, nil
+}
+
+// projectContainers returns the containers of a compose project by service.
+func projectContainers(ctx context.Context, svc *ContainerService, project string) (map[string]*container.Summary, error) {
+	list, err := svc.cli.ContainerList(ctx, container.ListOptions{All: true, Filters: projectFilter(project)})
+	if err != nil {
+		log.Warnf("STACKS: Unable to list containers of project '%s' due: %s", project, err)
+		return nil, err
+	}
+
+	out := make(map[string]*container.Summary, len(list))
+	for i := range list {
+		out[list[i].Labels[LabelService]] = &list[i]
+	}
+
+	return out, nil
+}
+
+func stackStatus(ctx context.Context, svc *ContainerService, stack *models.Stack, project *composeProject) (*models.StackStatus, error) {
+	containers, err := projectContainers(ctx, svc, project.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.StackStatus{
+		Name:     stack.Name,
+		Host:     stack.Host,
+		Services: []models.StackService{},
+	}
+	states := []string{}
+	for _, s := range project.Services {
+		service := models.StackService{Service: s.Name, Image: s.Image, State: serviceMissing}
+		if c, ok := containers[s.Name]; ok {
+			service = stackService(s.Name, c)
+			states = append(states, c.State)
+			delete(containers, s.Name)
+		}
+		status.Services = append(status.Services, service)
+	}
+	for _, name := range sortedKeys(containers) {
+		status.Services = append(status.Services, stackService(name, containers[name]))
+		states = append(states, containers[name].State)
+	}
+	status.State = aggregateState(states)
+
+	return status, nil
+}
+
+func stackService(name string, c *container.Summary) models.StackService {
+	out := models.StackService{
+		Service:     name,
+		ContainerID: c.ID,
+		Image:       c.Image,
+		State:       c.State,
+		Status:      c.Status,
+	}
+	if len(c.Names) > 0 {
+		out.ContainerName = strings.TrimPrefix(c.Names[0], "/")
+	}
+
+	return out
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"mineServers/internal/fakedocker"
+	"mineServers/internal/models"
+)
+
+func newTestStacks(t *testing.T) (*StackManager, *fakedocker.Engine) {
+	t.Helper()
+
+	db := openTestDB(t)
+
+	hosts, engine := newTestHostManager(t)
+	ctx := context.Background()
+
+	return NewStackManager(db, hosts, NewPullManager(ctx, nil)), engine
+}
+
+func TestStackManager_UpIsIdempotentAndConverges(t *testing.T) {
+	stacks, engine := newTestStacks(t)
+	ctx := context.Background()
+
+	if _, err := stacks.CreateStack(ctx, "", &models.StackRequest{Name: "shop", Compose: testCompose}); err != nil {
+		t.Fatalf("CreateStack() error = %v", err)
+	}
+
+	status, err := stacks.Up(ctx, "shop")
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if status.State != ProjectRunning || len(status.Services) != 3 || status.Services[0].Service != "db" {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if len(engine.Networks()) != 2 || len(engine.Volumes()) != 1 || engine.Volumes()[0].Labels[LabelProject] != "shop" {
+		t.Errorf("expected the project networks and volume, got %+v %+v", engine.Networks(), engine.Volumes())
+	}
+	web, _ := engine.Container("shop-web-1")
+
+	// Nothing changed: containers are kept.
+	if _, err := stacks.Up(ctx, "shop"); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if again, _ := engine.Container("shop-web-1"); again.ID != web.ID {
+		t.Errorf("expected the unchanged web container to be kept")
+	}
+
+	// web changes and api is dropped along with the dependency on it.
+	compose := strings.Replace(testCompose, `"8080:80"`, `"8081:80"`, 1)
+	compose = strings.Replace(compose, "    depends_on:\n      api:\n        condition: service_healthy\n", "", 1)
+	compose = compose[:strings.Index(compose, "  api:")] + compose[strings.Index(compose, "  db:"):]
+	if _, err := stacks.UpdateStack(ctx, "shop", compose); err != nil {
+		t.Fatalf("UpdateStack() error = %v", err)
+	}
+	db, _ := engine.Container("shop-db-1")
+
+	status, err = stacks.Up(ctx, "shop")
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if again, _ := engine.Container("shop-web-1"); again.ID == web.ID {
+		t.Errorf("expected the changed web container to be recreated")
+	}
+	if again, _ := engine.Container("shop-db-1"); again.ID != db.ID {
+		t.Errorf("expected the unchanged db container to be kept")
+	}
+	if _, ok := engine.Container("shop-api-1"); ok || len(status.Services) != 2 {
+		t.Errorf("expected the orphan api container to be removed, got %+v", status.Services)
+	}
+}
+
+func TestStackManager_DownKeepsVolumesUnlessAsked(t *testing.T) {
+	stacks, engine := newTestStacks(t)
+	ctx := context.Background()
+
+	stacks.CreateStack(ctx, "", &models.StackRequest{Name: "shop", Compose: testCompose})
+	if _, err := stacks.Up(ctx, "shop"); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	status, err := stacks.Down(ctx, "shop", false)
+	if err != nil {
+		t.Fatalf("Down() error = %v", err)
+	}
+	if status.State != ProjectDown || status.Services[0].State != serviceMissing {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if engine.ContainerCount() != 0 || len(engine.Networks()) != 0 || len(engine.Volumes()) != 1 {
+		t.Errorf("expected only the volume to remain, got %d containers, %d networks, %d volumes",
+			engine.ContainerCount(), len(engine.Networks()), len(engine.Volumes()))
+	}
+
+	if err := stacks.DeleteStack(ctx, "shop", true); err != nil {
+		t.Fatalf("DeleteStack() error = %v", err)
+	}
+	if len(engine.Volumes()) != 0 {
+		t.Errorf("expected the volume to be removed")
+	}
+	if _, err := stacks.Status(ctx, "shop"); !errors.Is(err, ErrStackNotFound) {
+		t.Errorf("expected ErrStackNotFound, got %v", err)
+	}
+}
+
+func TestStackManager_UpRequiresHealthcheckForHealthyCondition(t *testing.T) {
+	stacks, engine := newTestStacks(t)
+	ctx := context.Background()
+
+	compose := `
+services:
+  app:
+    image: app
+    depends_on:
+      db:
+        condition: service_healthy
+  db:
+    image: postgres
+`
+	stacks.CreateStack(ctx, "", &models.StackRequest{Name: "app", Compose: compose})
+
+	_, err := stacks.Up(ctx, "app")
+	if err == nil || !strings.Contains(err.Error(), "db has no healthcheck") {
+		t.Fatalf("expected the missing healthcheck to be reported, got %v", err)
+	}
+	if _, ok := engine.Container("app-app-1"); ok {
+		t.Errorf("expected app not to be created before its dependency is healthy")
+	}
+}
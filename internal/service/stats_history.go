@@ -0,0 +1,342 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"mineServers/internal/database"
+	"mineServers/internal/models"
+
+	"github.com/charmbracelet/log"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/errdefs"
+)
+
+// Resolutions of the stats history, the raw samples and their rollups.
+const (
+	StatsRaw    time.Duration = 0
+	StatsMinute               = time.Minute
+	StatsHour                 = time.Hour
+)
+
+const (
+	// defaultStatsPoints is how many points a stats history without a step
+	// has at most.
+	defaultStatsPoints = 300
+	maxStatsPoints     = 10000
+	defaultStatsRange  = time.Hour
+	// statsRollupInterval is how often the samples are rolled up and the
+	// rows past retention deleted.
+	statsRollupInterval = time.Minute
+)
+
+// StatsHistoryConfig sets how often the stats are sampled and how long
+// every resolution is kept. A zero retention keeps the rows forever.
+type StatsHistoryConfig struct {
+	Interval        time.Duration
+	RawRetention    time.Duration
+	MinuteRetention time.Duration
+	HourRetention   time.Duration
+}
+
+// DefaultStatsHistoryConfig samples every 10 seconds and keeps the raw
+// samples a day, the minutes a week and the hours 90 days.
+var DefaultStatsHistoryConfig = StatsHistoryConfig{
+	Interval:        10 * time.Second,
+	RawRetention:    24 * time.Hour,
+	MinuteRetention: 7 * 24 * time.Hour,
+	HourRetention:   90 * 24 * time.Hour,
+}
+
+// StatsCollector samples the stats of the running containers of every host
+// in the background, rolls them up per minute and per hour and serves them
+// as time series.
+type StatsCollector struct {
+	store  database.StatsStore
+	hosts  *HostManager
+	config StatsHistoryConfig
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewStatsCollector(store database.StatsStore, hosts *HostManager, config StatsHistoryConfig) *StatsCollector {
+	return &StatsCollector{
+		store:  store,
+		hosts:  hosts,
+		config: config,
+	}
+}
+
+// Start samples the stats in the background until Close.
+func (s *StatsCollector) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		sample := time.NewTicker(s.config.Interval)
+		defer sample.Stop()
+		rollup := time.NewTicker(statsRollupInterval)
+		defer rollup.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sample.C:
+				s.Sample(ctx)
+			case <-rollup.C:
+				s.Rollup(ctx, time.Now())
+			}
+		}
+	}()
+}
+
+// Close stops sampling, waiting for the samples being stored.
+func (s *StatsCollector) Close() error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+	return nil
+}
+
+// Sample stores a stats sample of every running container of every host.
+func (s *StatsCollector) Sample(ctx context.Context) {
+	var (
+		mu      sync.Mutex
+		samples []models.StatsSample
+		wg      sync.WaitGroup
+	)
+	for host, svc := range s.hosts.Services(ctx) {
+		containers, err := svc.cli.ContainerList(ctx, container.ListOptions{})
+		if err != nil {
+			log.Warnf("STATS: Unable to list the containers of host %s due: %s", host, err)
+			continue
+		}
+
+		// Reading the stats of a container takes the daemon a second or so.
+		for _, c := range containers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				sample, err := sampleStats(ctx, svc, c.ID)
+				if err != nil {
+					if !errdefs.IsNotFound(err) && ctx.Err() == nil {
+						log.Warnf("STATS: Unable to sample container %s of host %s due: %s", c.ID, host, err)
+					}
+					return
+				}
+				sample.Host = host
+				if len(c.Names) > 0 {
+					sample.Name = strings.TrimPrefix(c.Names[0], "/")
+				}
+
+				mu.Lock()
+				samples = append(samples, *sample)
+				mu.Unlock()
+			}()
+		}
+	}
+	wg.Wait()
+
+	if len(samples) == 0 {
+		return
+	}
+	if err := s.store.RecordStatsSamples(ctx, samples); err != nil && ctx.Err() == nil {
+		log.Warnf("STATS: Unable to record %d samples due: %s", len(samples), err)
+	}
+}
+
+// Rollup aggregates the recent samples into the minute and hour
+// resolutions, including the current partial buckets, and deletes the rows
+// past retention.
+func (s *StatsCollector) Rollup(ctx context.Context, now time.Time) {
+	if err := s.store.RollupStats(ctx, StatsRaw, StatsMinute, now.Add(-10*time.Minute), now.Add(time.Second)); err != nil {
+		log.Warnf("STATS: Unable to roll up samples per minute due: %s", err)
+		return
+	}
+	if err := s.store.RollupStats(ctx, StatsMinute, StatsHour, now.Add(-time.Hour), now.Add(time.Second)); err != nil {
+		log.Warnf("STATS: Unable to roll up samples per hour due: %s", err)
+		return
+	}
+
+	for resolution, retention := range map[time.Duration]time.Duration{
+		StatsRaw:    s.config.RawRetention,
+		StatsMinute: s.config.MinuteRetention,
+		StatsHour:   s.config.HourRetention,
+	} {
+		if retention <= 0 {
+			continue
+		}
+		if _, err := s.store.PruneStats(ctx, resolution, now.Add(-retention)); err != nil {
+			log.Warnf("STATS: Unable to prune samples due: %s", err)
+		}
+	}
+}
+
+// History returns the stats of a container as points of the query step,
+// computed from the finest resolution still kept at from. Containers that
+// no longer exist are looked up by the name or ID they had.
+func (s *StatsCollector) History(ctx context.Context, host, ref string, q *models.StatsHistoryQuery) (*models.StatsHistory, error) {
+	now := time.Now().UTC()
+	history := &models.StatsHistory{From: now.Add(-defaultStatsRange), To: now}
+
+	v := &ValidationError{}
+	if q.From != "" {
+		from, err := time.Parse(time.RFC3339, q.From)
+		if err != nil {
+			v.add("from", "must be an RFC 3339 time")
+		}
+		history.From = from.UTC()
+	}
+	if q.To != "" {
+		to, err := time.Parse(time.RFC3339, q.To)
+		if err != nil {
+			v.add("to", "must be an RFC 3339 time")
+		}
+		history.To = to.UTC()
+	}
+	if !history.From.Before(history.To) {
+		v.add("to", "must be after from")
+	}
+	span := history.To.Sub(history.From)
+	step := max(s.config.Interval, span/defaultStatsPoints)
+	for _, unit := range []time.Duration{StatsHour, StatsMinute, time.Second} {
+		if step >= unit {
+			step = (step + unit - 1) / unit * unit
+			break
+		}
+	}
+	if q.Step != "" {
+		d, err := time.ParseDuration(q.Step)
+		switch {
+		case err != nil:
+			v.add("step", "%s", err)
+		case d < time.Second || d%time.Second != 0:
+			v.add("step", "must be a whole number of seconds")
+		case span/d > maxStatsPoints:
+			v.add("step", "must return at most %d points", maxStatsPoints)
+		default:
+			step = d
+		}
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	query := &database.StatsQuery{Host: host, ContainerID: ref, Name: ref, From: history.From, To: history.To, Step: step}
+	if host == "" {
+		query.Host = LocalHost
+	}
+	query.Resolution, history.Resolution = s.resolution(step, history.From, now)
+	history.Step = step.String()
+
+	svc, err := s.hosts.Resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	info, err := svc.cli.ContainerInspect(ctx, ref)
+	switch {
+	case err == nil:
+		query.ContainerID = info.ID
+		query.Name = strings.TrimPrefix(info.Name, "/")
+	case !errdefs.IsNotFound(err):
+		return nil, err
+	}
+
+	history.Points, err = s.store.ListStatsPoints(ctx, query)
+	if err != nil {
+		log.Warnf("STATS: Unable to list the stats of container %s due: %s", ref, err)
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// resolution returns the finest resolution still kept at from, or the
+// coarsest one when none is, never coarser than the step.
+func (s *StatsCollector) resolution(step time.Duration, from, now time.Time) (time.Duration, string) {
+	resolutions := []struct {
+		resolution time.Duration
+		retention  time.Duration
+		name       string
+	}{
+		{StatsRaw, s.config.RawRetention, "raw"},
+		{StatsMinute, s.config.MinuteRetention, "1m"},
+		{StatsHour, s.config.HourRetention, "1h"},
+	}
+
+	best := resolutions[0]
+	for _, r := range resolutions {
+		if r.resolution > step {
+			break
+		}
+		best = r
+		if r.retention <= 0 || !from.Before(now.Add(-r.retention)) {
+			break
+		}
+	}
+
+	return best.resolution, best.name
+}
+
+// sampleStats reads a stats sample of the container.
+func sampleStats(ctx context.Context, svc *ContainerService, id string) (*models.StatsSample, error) {
+	reader, err := svc.cli.ContainerStats(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Body.Close()
+
+	var stats container.StatsResponse
+	if err := json.NewDecoder(reader.Body).Decode(&stats); err != nil {
+		return nil, err
+	}
+
+	sample := &models.StatsSample{
+		ContainerID: id,
+		Time:        stats.Read,
+		CPUPercent:  cpuPercent(&stats),
+		MemUsage:    stats.MemoryStats.Usage,
+		MemLimit:    stats.MemoryStats.Limit,
+	}
+	if sample.Time.IsZero() {
+		sample.Time = time.Now()
+	}
+	for _, network := range stats.Networks {
+		sample.NetRx += network.RxBytes
+		sample.NetTx += network.TxBytes
+	}
+	for _, entry := range stats.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			sample.BlockRead += entry.Value
+		case "write":
+			sample.BlockWrite += entry.Value
+		}
+	}
+
+	return sample, nil
+}
+
+// cpuPercent computes the CPU usage of the sample like the docker CLI, 100%
+// being a whole CPU.
+func cpuPercent(stats *container.StatsResponse) float64 {
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemUsage) - float64(stats.PreCPUStats.SystemUsage)
+	cpus := float64(stats.CPUStats.OnlineCPUs)
+	if cpus == 0 {
+		cpus = float64(len(stats.CPUStats.CPUUsage.PercpuUsage))
+	}
+	if systemDelta <= 0 || cpuDelta <= 0 {
+		return 0
+	}
+
+	return cpuDelta / systemDelta * cpus * 100
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"mineServers/internal/models"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestStatsCollector_SamplesAndServesHistory(t *testing.T) {
+	db := openTestDB(t)
+
+	hosts, engine := newTestHostManager(t)
+	ctx := context.Background()
+	stats := NewStatsCollector(db, hosts, DefaultStatsHistoryConfig)
+
+	engine.AddImage("docker.io/library/alpine:latest")
+	for _, name := range []string{"mc", "idle"} {
+		resp, err := engine.ContainerCreate(ctx, &container.Config{Image: "docker.io/library/alpine:latest"}, nil, nil, nil, name)
+		if err != nil {
+			t.Fatalf("ContainerCreate(%s) error = %v", name, err)
+		}
+		if name == "mc" {
+			if err := engine.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+				t.Fatalf("ContainerStart() error = %v", err)
+			}
+		}
+	}
+
+	now := time.Now().UTC().Truncate(time.Minute)
+	for i, cpu := range []uint64{100_000_000, 300_000_000} {
+		sample := container.StatsResponse{
+			Read: now.Add(-time.Minute + time.Duration(i)*10*time.Second),
+			CPUStats: container.CPUStats{
+				CPUUsage:    container.CPUUsage{TotalUsage: cpu},
+				SystemUsage: 1_000_000_000,
+				OnlineCPUs:  2,
+			},
+			MemoryStats: container.MemoryStats{Usage: 64 << 20, Limit: 1 << 30},
+			Networks:    map[string]container.NetworkStats{"eth0": {RxBytes: 100, TxBytes: 50}},
+		}
+		if err := engine.AppendStats("mc", sample); err != nil {
+			t.Fatalf("AppendStats() error = %v", err)
+		}
+		stats.Sample(ctx)
+	}
+	stats.Rollup(ctx, now)
+
+	history, err := stats.History(ctx, "", "mc", &models.StatsHistoryQuery{})
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if history.Resolution != "raw" || history.Step != "12s" || len(history.Points) == 0 {
+		t.Fatalf("history = %+v", history)
+	}
+
+	history, err = stats.History(ctx, "", "mc", &models.StatsHistoryQuery{Step: "1m"})
+	if err != nil {
+		t.Fatalf("History(1m) error = %v", err)
+	}
+	if history.Resolution != "raw" || len(history.Points) != 1 {
+		t.Fatalf("history(1m) = %+v", history)
+	}
+	point := history.Points[0]
+	if point.CPUPercent != 40 || point.CPUMax != 60 || point.MemUsage != 64<<20 || point.NetRx != 100 || !point.Time.Equal(now.Add(-time.Minute)) {
+		t.Fatalf("point = %+v", point)
+	}
+
+	// Raw samples are only kept a day, the minutes cover older ranges.
+	from := now.Add(-48 * time.Hour).Format(time.RFC3339)
+	history, err = stats.History(ctx, "", "mc", &models.StatsHistoryQuery{From: from})
+	if err != nil || history.Resolution != "1m" || history.Step != "10m0s" || len(history.Points) != 1 || history.Points[0].CPUPercent != 40 {
+		t.Fatalf("History(2 days) = %+v, %v", history, err)
+	}
+	if history, err := stats.History(ctx, "", "mc", &models.StatsHistoryQuery{From: now.Add(-30 * 24 * time.Hour).Format(time.RFC3339), Step: "1h"}); err != nil || history.Resolution != "1h" {
+		t.Fatalf("History(30 days) = %+v, %v", history, err)
+	}
+
+	var verr *ValidationError
+	for _, q := range []models.StatsHistoryQuery{{Step: "500ms"}, {Step: "soon"}, {From: "yesterday"}, {From: from, Step: "1s"}, {From: now.Format(time.RFC3339), To: from}} {
+		if _, err := stats.History(ctx, "", "mc", &q); !errors.As(err, &verr) {
+			t.Errorf("History(%+v) error = %v, want ValidationError", q, err)
+		}
+	}
+}
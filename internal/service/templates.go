@@ -0,0 +1,436 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"mineServers/internal/database"
+	"mineServers/internal/models"
+
+	"github.com/charmbracelet/log"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats templates can be exported to and imported from.
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+var (
+	ErrTemplateNotFound = errors.New("template not found")
+
+	placeholderRegex   = regexp.MustCompile(`\$\{([^}]*)\}`)
+	parameterNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	templateNameRegex  = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+	createOptionsType = reflect.TypeOf(models.CreateOptions{})
+)
+
+// TemplateManager stores container templates and renders them into
+// creation options.
+type TemplateManager struct {
+	store database.TemplateStore
+}
+
+func NewTemplateManager(store database.TemplateStore) *TemplateManager {
+	return &TemplateManager{
+		store: store,
+	}
+}
+
+func (m *TemplateManager) ListTemplates(ctx context.Context) ([]models.Template, error) {
+	templates, err := m.store.ListTemplates(ctx)
+	if err != nil {
+		log.Warnf("TEMPLATES: Unable to list templates due: %s", err)
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+func (m *TemplateManager) GetTemplate(ctx context.Context, id int64) (*models.Template, error) {
+	template, err := m.store.GetTemplate(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return nil, ErrTemplateNotFound
+		}
+		log.Warnf("TEMPLATES: Unable to get template %d due: %s", id, err)
+		return nil, err
+	}
+
+	return template, nil
+}
+
+func (m *TemplateManager) CreateTemplate(ctx context.Context, template *models.Template) error {
+	if err := ValidateTemplate(&template.TemplateDefinition); err != nil {
+		return err
+	}
+
+	return m.store.CreateTemplate(ctx, template)
+}
+
+func (m *TemplateManager) UpdateTemplate(ctx context.Context, template *models.Template) error {
+	if err := ValidateTemplate(&template.TemplateDefinition); err != nil {
+		return err
+	}
+
+	if err := m.store.UpdateTemplate(ctx, template); err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return ErrTemplateNotFound
+		}
+		return err
+	}
+
+	stored, err := m.store.GetTemplate(ctx, template.ID)
+	if err != nil {
+		return err
+	}
+	*template = *stored
+
+	return nil
+}
+
+func (m *TemplateManager) DeleteTemplate(ctx context.Context, id int64) error {
+	if err := m.store.DeleteTemplate(ctx, id); err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return ErrTemplateNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ImportTemplates stores every definition in one transaction, failing
+// before writing anything when one is invalid or, unless overwrite is set,
+// already exists. Existing templates are matched by name.
+func (m *TemplateManager) ImportTemplates(ctx context.Context, defs []models.TemplateDefinition, overwrite bool) ([]models.Template, error) {
+	v := &ValidationError{}
+	existing := make([]*models.Template, len(defs))
+	names := map[string]int{}
+
+	for i := range defs {
+		prefix := fmt.Sprintf("templates[%d]", i)
+		if err := ValidateTemplate(&defs[i]); err != nil {
+			var verr *ValidationError
+			errors.As(err, &verr)
+			for _, f := range verr.Fields {
+				v.add(prefix+"."+f.Field, "%s", f.Message)
+			}
+			continue
+		}
+		if prev, ok := names[defs[i].Name]; ok {
+			v.add(prefix+".name", "%s is already imported by templates[%d]", defs[i].Name, prev)
+			continue
+		}
+		names[defs[i].Name] = i
+
+		stored, err := m.store.GetTemplateByName(ctx, defs[i].Name)
+		switch {
+		case err == nil && !overwrite:
+			return nil, database.ErrConflict
+		case err == nil:
+			existing[i] = stored
+		case !errors.Is(err, database.ErrNotFound):
+			return nil, err
+		}
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	templates := make([]*models.Template, len(defs))
+	for i, def := range defs {
+		templates[i] = &models.Template{TemplateDefinition: def}
+		if existing[i] != nil {
+			templates[i].ID = existing[i].ID
+			templates[i].CreatedAt = existing[i].CreatedAt
+		}
+	}
+	if err := m.store.SaveTemplates(ctx, templates); err != nil {
+		log.Warnf("TEMPLATES: Unable to import %d templates due: %s", len(templates), err)
+		return nil, err
+	}
+
+	out := make([]models.Template, 0, len(templates))
+	for _, template := range templates {
+		out = append(out, *template)
+	}
+
+	return out, nil
+}
+
+// ValidateTemplate checks the parameters of a template and that its spec
+// only uses declared placeholders in known fields.
+func ValidateTemplate(def *models.TemplateDefinition) error {
+	v := &ValidationError{}
+
+	if !templateNameRegex.MatchString(def.Name) {
+		v.add("name", "must match %s", templateNameRegex)
+	}
+	if def.Parameters == nil {
+		def.Parameters = []models.TemplateParameter{}
+	}
+
+	declared := map[string]bool{}
+	for i, p := range def.Parameters {
+		field := fmt.Sprintf("parameters[%d]", i)
+		switch {
+		case !parameterNameRegex.MatchString(p.Name):
+			v.add(field+".name", "must match %s", parameterNameRegex)
+		case declared[p.Name]:
+			v.add(field+".name", "%s is declared twice", p.Name)
+		case p.Required && p.Default != "":
+			v.add(field+".default", "required parameters cannot have a default")
+		}
+		declared[p.Name] = true
+	}
+
+	if def.Spec == nil {
+		v.add("spec", "is required")
+	} else {
+		r := &renderer{declared: declared, v: v}
+		r.walk(def.Spec, createOptionsType, "spec")
+	}
+
+	return v.err()
+}
+
+// RenderTemplate substitutes the parameters into the spec of template and
+// decodes it into creation options. Omitted parameters take their default.
+func RenderTemplate(template *models.Template, req *models.InstantiateRequest) (*models.CreateOptions, error) {
+	v := &ValidationError{}
+
+	declared := map[string]bool{}
+	values := map[string]string{}
+	for _, p := range template.Parameters {
+		declared[p.Name] = true
+		value, ok := req.Parameters[p.Name]
+		switch {
+		case ok:
+			values[p.Name] = value
+		case p.Required:
+			v.add("parameters."+p.Name, "is required")
+		default:
+			values[p.Name] = p.Default
+		}
+	}
+	for name := range req.Parameters {
+		if !declared[name] {
+			v.add("parameters."+name, "is not a parameter of the template")
+		}
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	r := &renderer{declared: declared, values: values, v: v}
+	spec := r.walk(template.Spec, createOptionsType, "spec")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	opts := new(models.CreateOptions)
+	if err := json.Unmarshal(data, opts); err != nil {
+		v.add("spec", "%s", err)
+		return nil, v
+	}
+	if req.Name != "" {
+		opts.Name = req.Name
+	}
+
+	return opts, nil
+}
+
+// renderer walks a spec alongside the type it decodes into. Without values
+// it only checks the spec, leaving the placeholders in place.
+type renderer struct {
+	declared map[string]bool
+	values   map[string]string
+	v        *ValidationError
+}
+
+func (r *renderer) walk(value any, t reflect.Type, path string) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch val := value.(type) {
+	case map[string]any:
+		switch t.Kind() {
+		case reflect.Struct:
+			fields := jsonFields(t)
+			out := make(map[string]any, len(val))
+			for key, item := range val {
+				field, ok := fields[key]
+				if !ok {
+					r.v.add(path+"."+key, "unknown field")
+					continue
+				}
+				out[key] = r.walk(item, field, path+"."+key)
+			}
+			return out
+		case reflect.Map:
+			out := make(map[string]any, len(val))
+			for key, item := range val {
+				out[r.substitute(key, path)] = r.walk(item, t.Elem(), path+"."+key)
+			}
+			return out
+		}
+	case []any:
+		if t.Kind() == reflect.Slice {
+			out := make([]any, len(val))
+			for i, item := range val {
+				out[i] = r.walk(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+			}
+			return out
+		}
+	case string:
+		return r.scalar(val, t, path)
+	default:
+		// Numbers, booleans and nulls are checked when decoding the result.
+		return value
+	}
+
+	r.v.add(path, "must be a %s", kindName(t))
+	return value
+}
+
+// scalar substitutes the placeholders of s and converts the result to the
+// kind of the field, so "${PORT}" can fill a numeric field.
+func (r *renderer) scalar(s string, t reflect.Type, path string) any {
+	out := r.substitute(s, path)
+	if r.values == nil && placeholderRegex.MatchString(s) {
+		return s
+	}
+
+	var err error
+	var converted any
+	switch t.Kind() {
+	case reflect.String:
+		return out
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		converted, err = strconv.ParseInt(out, 10, t.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		converted, err = strconv.ParseUint(out, 10, t.Bits())
+	case reflect.Float32, reflect.Float64:
+		converted, err = strconv.ParseFloat(out, t.Bits())
+	case reflect.Bool:
+		converted, err = strconv.ParseBool(out)
+	default:
+		r.v.add(path, "must be a %s", kindName(t))
+		return out
+	}
+	if err != nil {
+		r.v.add(path, "%q is not a valid %s", out, kindName(t))
+		return out
+	}
+
+	return converted
+}
+
+func (r *renderer) substitute(s, path string) string {
+	return placeholderRegex.ReplaceAllStringFunc(s, func(match string) string {
+		name := placeholderRegex.FindStringSubmatch(match)[1]
+		if !r.declared[name] {
+			r.v.add(path, "uses undeclared parameter %q", name)
+			return match
+		}
+		if r.values == nil {
+			return match
+		}
+		return r.values[name]
+	})
+}
+
+// jsonFields maps the JSON names of the fields of t to their type.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+
+	return fields
+}
+
+func kindName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice:
+		return "list"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	default:
+		return t.Kind().String()
+	}
+}
+
+// EncodeTemplates serializes a template definition or a list of them in format.
+func EncodeTemplates(value any, format string) ([]byte, error) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatJSON:
+		return data, nil
+	case FormatYAML:
+		// Going through JSON keeps the field names of the API.
+		var doc any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		return yaml.Marshal(doc)
+	default:
+		return nil, fmt.Errorf("unsupported format %q, use %s or %s", format, FormatJSON, FormatYAML)
+	}
+}
+
+// DecodeTemplates parses a JSON or YAML document holding a template
+// definition or a list of them.
+func DecodeTemplates(data []byte) ([]models.TemplateDefinition, error) {
+	// JSON documents are valid YAML, a single parser handles both.
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if _, ok := doc.([]any); !ok {
+		doc = []any{doc}
+	}
+
+	normalized, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported document: %w", err)
+	}
+	var defs []models.TemplateDefinition
+	if err := json.Unmarshal(normalized, &defs); err != nil {
+		return nil, err
+	}
+
+	return defs, nil
+}
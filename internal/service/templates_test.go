@@ -0,0 +1,123 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"mineServers/internal/models"
+)
+
+func testTemplate() *models.Template {
+	return &models.Template{TemplateDefinition: models.TemplateDefinition{
+		Name: "paper",
+		Parameters: []models.TemplateParameter{
+			{Name: "NAME", Required: true},
+			{Name: "MEMORY", Default: "2G"},
+			{Name: "PORT", Default: "25565"},
+		},
+		Spec: map[string]any{
+			"name":      "mc-${NAME}",
+			"image":     "itzg/minecraft-server",
+			"env":       map[string]any{"MEMORY": "${MEMORY}", "EULA": "TRUE"},
+			"ports":     []any{map[string]any{"container_port": 25565, "host_port": "${PORT}"}},
+			"resources": map[string]any{"memory": "${MEMORY}"},
+		},
+	}}
+}
+
+func TestRenderTemplate_SubstitutesAndConverts(t *testing.T) {
+	opts, err := RenderTemplate(testTemplate(), &models.InstantiateRequest{
+		Parameters: map[string]string{"NAME": "survival", "PORT": "25570"},
+	})
+	if err != nil {
+		t.Fatalf("RenderTemplate() error = %v", err)
+	}
+
+	if opts.Name != "mc-survival" || opts.Env["MEMORY"] != "2G" || opts.Resources.Memory != "2G" {
+		t.Errorf("unexpected options %+v", opts)
+	}
+	if len(opts.Ports) != 1 || opts.Ports[0].HostPort != 25570 || opts.Ports[0].ContainerPort != 25565 {
+		t.Errorf("expected host port 25570, got %+v", opts.Ports)
+	}
+
+	opts, err = RenderTemplate(testTemplate(), &models.InstantiateRequest{
+		Name:       "override",
+		Parameters: map[string]string{"NAME": "x"},
+	})
+	if err != nil || opts.Name != "override" {
+		t.Errorf("expected the name override to win, got %+v, %v", opts, err)
+	}
+}
+
+func TestRenderTemplate_ReportsParameterErrors(t *testing.T) {
+	_, err := RenderTemplate(testTemplate(), &models.InstantiateRequest{
+		Parameters: map[string]string{"PORT": "http", "OTHER": "1"},
+	})
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+	got := verr.Error()
+	for _, want := range []string{"parameters.NAME: is required", "parameters.OTHER: is not a parameter"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in %q", want, got)
+		}
+	}
+
+	_, err = RenderTemplate(testTemplate(), &models.InstantiateRequest{
+		Parameters: map[string]string{"NAME": "x", "PORT": "http"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "spec.ports[0].host_port") {
+		t.Errorf("expected an invalid host port error, got %v", err)
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	if err := ValidateTemplate(&testTemplate().TemplateDefinition); err != nil {
+		t.Fatalf("ValidateTemplate() error = %v", err)
+	}
+
+	def := testTemplate().TemplateDefinition
+	def.Spec["hostname"] = "${UNKNOWN}"
+	def.Spec["volumes"] = []any{"/data"}
+	def.Parameters = append(def.Parameters, models.TemplateParameter{Name: "MEMORY"})
+
+	err := ValidateTemplate(&def)
+	if err == nil {
+		t.Fatalf("expected validation errors")
+	}
+	for _, want := range []string{`spec.hostname: uses undeclared parameter "UNKNOWN"`, "spec.volumes: unknown field", "parameters[3].name: MEMORY is declared twice"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %q", want, err)
+		}
+	}
+}
+
+func TestEncodeDecodeTemplates_YAMLRoundTrip(t *testing.T) {
+	def := testTemplate().TemplateDefinition
+
+	data, err := EncodeTemplates(def, FormatYAML)
+	if err != nil {
+		t.Fatalf("EncodeTemplates() error = %v", err)
+	}
+	if !strings.Contains(string(data), "host_port: ${PORT}") {
+		t.Errorf("expected API field names in YAML, got:\n%s", data)
+	}
+
+	defs, err := DecodeTemplates(data)
+	if err != nil {
+		t.Fatalf("DecodeTemplates() error = %v", err)
+	}
+	if len(defs) != 1 || defs[0].Name != "paper" || len(defs[0].Parameters) != 3 {
+		t.Fatalf("unexpected definitions %+v", defs)
+	}
+	if err := ValidateTemplate(&defs[0]); err != nil {
+		t.Errorf("expected the imported template to be valid, got %v", err)
+	}
+
+	list, _ := EncodeTemplates([]models.TemplateDefinition{def, def}, FormatJSON)
+	if defs, err := DecodeTemplates(list); err != nil || len(defs) != 2 {
+		t.Errorf("expected 2 definitions from a JSON list, got %d, %v", len(defs), err)
+	}
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"mineServers/internal/models"
+
+	"github.com/charmbracelet/log"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+// maxTerminalSize bounds the rows and columns of a terminal.
+const maxTerminalSize = 1000
+
+var defaultTerminalCmd = []string{"/bin/sh"}
+
+// ExecSession is an interactive process with a TTY running inside a
+// container. Reads return its output and writes go to its input.
+type ExecSession struct {
+	ID string
+
+	svc      *ContainerService
+	hijacked types.HijackedResponse
+}
+
+// OpenTerminal starts an interactive shell with a TTY in the running
+// container, /bin/sh unless opts names another command.
+func (c *ContainerService) OpenTerminal(ctx context.Context, id string, opts *models.TerminalOptions) (*ExecSession, error) {
+	v := &ValidationError{}
+	if opts.Rows > maxTerminalSize || opts.Cols > maxTerminalSize {
+		v.add("size", "rows and cols must be at most %d", maxTerminalSize)
+	}
+	if (opts.Rows == 0) != (opts.Cols == 0) {
+		v.add("size", "rows and cols must be set together")
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	cmd := opts.Cmd
+	if len(cmd) == 0 {
+		cmd = defaultTerminalCmd
+	}
+	var size *[2]uint
+	if opts.Rows > 0 {
+		size = &[2]uint{opts.Rows, opts.Cols}
+	}
+
+	exec, err := c.cli.ContainerExecCreate(ctx, id, container.ExecOptions{
+		Cmd:          cmd,
+		User:         opts.User,
+		WorkingDir:   opts.WorkingDir,
+		Tty:          true,
+		ConsoleSize:  size,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		log.Warnf("CONTAINER-TERMINAL: Unable to create terminal in container '%s' due: %s", id, err)
+		return nil, err
+	}
+
+	hijacked, err := c.cli.ContainerExecAttach(ctx, exec.ID, container.ExecAttachOptions{Tty: true, ConsoleSize: size})
+	if err != nil {
+		log.Warnf("CONTAINER-TERMINAL: Unable to attach terminal in container '%s' due: %s", id, err)
+		return nil, err
+	}
+	log.Infof("CONTAINER-TERMINAL: Terminal %s opened in container '%s' running '%s'", shortID(exec.ID), id, strings.Join(cmd, " "))
+
+	return &ExecSession{ID: exec.ID, svc: c, hijacked: hijacked}, nil
+}
+
+func (s *ExecSession) Read(p []byte) (int, error) {
+	return s.hijacked.Reader.Read(p)
+}
+
+func (s *ExecSession) Write(p []byte) (int, error) {
+	return s.hijacked.Conn.Write(p)
+}
+
+// Resize changes the size of the TTY.
+func (s *ExecSession) Resize(ctx context.Context, rows, cols uint) error {
+	if rows == 0 || cols == 0 || rows > maxTerminalSize || cols > maxTerminalSize {
+		v := &ValidationError{}
+		v.add("size", "rows and cols must be between 1 and %d", maxTerminalSize)
+		return v
+	}
+
+	return s.svc.cli.ContainerExecResize(ctx, s.ID, container.ResizeOptions{Height: rows, Width: cols})
+}
+
+// ExitCode returns the exit code of the finished process, -1 if it is still
+// running.
+func (s *ExecSession) ExitCode(ctx context.Context) (int, error) {
+	return s.svc.execExitCode(ctx, s.ID)
+}
+
+// Close closes the input and the connection to the process. A shell exits
+// once its input is closed.
+func (s *ExecSession) Close() error {
+	s.hijacked.CloseWrite()
+	s.hijacked.Close()
+	log.Infof("CONTAINER-TERMINAL: Terminal %s closed", shortID(s.ID))
+	return nil
+}
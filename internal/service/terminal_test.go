@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"mineServers/internal/models"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestContainerService_OpenTerminal(t *testing.T) {
+	svc, _, engine, id := newTestRecreate(t)
+	engine.OnExec(func(ctx context.Context, opts container.ExecOptions, stdin io.Reader, stdout, stderr io.Writer) int {
+		io.WriteString(stdout, opts.Cmd[0])
+		<-ctx.Done()
+		return 0
+	})
+	ctx := context.Background()
+
+	var verr *ValidationError
+	if _, err := svc.OpenTerminal(ctx, id, &models.TerminalOptions{Rows: 24}); !errors.As(err, &verr) {
+		t.Errorf("expected rows without cols to be rejected, got %v", err)
+	}
+
+	session, err := svc.OpenTerminal(ctx, id, &models.TerminalOptions{})
+	if err != nil {
+		t.Fatalf("OpenTerminal() error = %v", err)
+	}
+	defer session.Close()
+
+	buf := make([]byte, 16)
+	if n, _ := session.Read(buf); string(buf[:n]) != "/bin/sh" {
+		t.Errorf("expected /bin/sh by default, got %q", buf[:n])
+	}
+	if err := session.Resize(ctx, 50, 200); err != nil || engine.ExecSize(session.ID) != [2]uint{50, 200} {
+		t.Errorf("expected the TTY to be resized, got %v", err)
+	}
+	if err := session.Resize(ctx, 0, 80); !errors.As(err, &verr) {
+		t.Errorf("expected an empty size to be rejected, got %v", err)
+	}
+}
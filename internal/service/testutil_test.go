@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"mineServers/internal/database"
+	"mineServers/internal/fakedocker"
+)
+
+// openTestDB opens a database in a temporary directory, closed with the test.
+func openTestDB(t *testing.T) database.Service {
+	t.Helper()
+
+	db, err := database.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("unable to open database: %s", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+// newTestHostManager returns a HostManager serving only the local host,
+// backed by a fake engine.
+func newTestHostManager(t *testing.T) (*HostManager, *fakedocker.Engine) {
+	t.Helper()
+
+	engine := fakedocker.New()
+	ctx := context.Background()
+	return NewHostManager(ctx, NewContainerService(ctx, engine), nil, nil), engine
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"mineServers/internal/models"
+
+	"github.com/charmbracelet/log"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/system"
+	"github.com/docker/go-units"
+)
+
+// Bounds accepted by the daemon for the CPU limits.
+const (
+	minCPUShares = 2
+	maxCPUShares = 262144
+	minCPUPeriod = 1000
+	maxCPUPeriod = 1000000
+	minCPUQuota  = 1000
+	// defaultCPUPeriod is the period used by the kernel when none is set.
+	defaultCPUPeriod = 100000
+)
+
+// UpdateContainerResources changes the limits and restart policy of the
+// container without recreating it and returns its effective limits. The
+// values are checked against the capacity of the host and the limits the
+// container already has.
+func (c *ContainerService) UpdateContainerResources(ctx context.Context, id string, req *models.ResourceUpdate) (*models.ContainerResources, error) {
+	info, err := c.InspectContainer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	host, err := c.cli.Info(ctx)
+	if err != nil {
+		log.Warnf("CONTAINER-UPDATE: Unable to get host capacity due: %s", err)
+		return nil, err
+	}
+
+	update, err := buildResourceUpdate(req, info.HostConfig, host)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.cli.ContainerUpdate(ctx, info.ID, update)
+	if err != nil {
+		log.Warnf("CONTAINER-UPDATE: Unable to update container '%s' due: %s", id, err)
+		return nil, err
+	}
+	log.Infof("CONTAINER-UPDATE: Container '%s' limits updated", strings.TrimPrefix(info.Name, "/"))
+
+	info, err = c.InspectContainer(ctx, info.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := containerResources(info.HostConfig)
+	resources.Warnings = resp.Warnings
+	return resources, nil
+}
+
+// buildResourceUpdate validates req against the capacity of host and the
+// current limits of the container, reporting every invalid field at once.
+func buildResourceUpdate(req *models.ResourceUpdate, current *container.HostConfig, host system.Info) (container.UpdateConfig, error) {
+	v := &ValidationError{}
+	var out container.UpdateConfig
+	res := &out.Resources
+
+	memory, swap := current.Memory, current.MemorySwap
+	if req.Memory != nil {
+		value, err := units.RAMInBytes(*req.Memory)
+		switch {
+		case err != nil:
+			v.add("memory", "%s", err)
+		case value < minMemory:
+			v.add("memory", "must be at least 6MB")
+		case host.MemTotal > 0 && value > host.MemTotal:
+			v.add("memory", "cannot exceed the %s of the host", units.BytesSize(float64(host.MemTotal)))
+		default:
+			res.Memory, memory = value, value
+		}
+	}
+
+	if req.MemorySwap != nil {
+		value, err := parseMemorySwap(*req.MemorySwap)
+		switch {
+		case err != nil:
+			v.add("memory_swap", "%s", err)
+		case memory == 0:
+			v.add("memory_swap", "requires a memory limit")
+		case value != -1 && value < memory:
+			v.add("memory_swap", "must be -1 or greater than or equal to memory")
+		default:
+			res.MemorySwap, swap = value, value
+		}
+	} else if req.Memory != nil && swap > 0 && memory > swap {
+		v.add("memory", "exceeds the current memory_swap of %s, raise memory_swap as well", units.BytesSize(float64(swap)))
+	}
+
+	if req.CPUs != nil {
+		switch {
+		case *req.CPUs <= 0:
+			v.add("cpus", "must be positive")
+		case host.NCPU > 0 && *req.CPUs > float64(host.NCPU):
+			v.add("cpus", "cannot exceed the %d CPUs of the host", host.NCPU)
+		case req.CPUQuota != nil || req.CPUPeriod != nil:
+			v.add("cpus", "cannot be combined with cpu_quota or cpu_period")
+		case current.CPUQuota > 0 || current.CPUPeriod > 0:
+			v.add("cpus", "cannot be set on a container limited by cpu_quota")
+		default:
+			res.NanoCPUs = int64(*req.CPUs * 1e9)
+		}
+	}
+
+	if req.CPUShares != nil {
+		if *req.CPUShares < minCPUShares || *req.CPUShares > maxCPUShares {
+			v.add("cpu_shares", "must be between %d and %d", minCPUShares, maxCPUShares)
+		}
+		res.CPUShares = *req.CPUShares
+	}
+
+	if req.CPUQuota != nil || req.CPUPeriod != nil {
+		if current.NanoCPUs > 0 && req.CPUs == nil {
+			v.add("cpu_quota", "cannot be set on a container limited by cpus")
+		}
+
+		period := current.CPUPeriod
+		if req.CPUPeriod != nil {
+			period = *req.CPUPeriod
+			if period < minCPUPeriod || period > maxCPUPeriod {
+				v.add("cpu_period", "must be between %d and %d microseconds", minCPUPeriod, maxCPUPeriod)
+			}
+			res.CPUPeriod = period
+		}
+		if period == 0 {
+			period = defaultCPUPeriod
+		}
+
+		if req.CPUQuota != nil {
+			quota := *req.CPUQuota
+			switch {
+			case quota != -1 && quota < minCPUQuota:
+				v.add("cpu_quota", "must be -1 (unlimited) or at least %d microseconds", minCPUQuota)
+			case quota > 0 && float64(quota)/float64(period) > float64(host.NCPU):
+				v.add("cpu_quota", "cannot exceed the %d CPUs of the host for a period of %d", host.NCPU, period)
+			}
+			res.CPUQuota = quota
+		}
+	}
+
+	if req.CpusetCpus != nil {
+		if err := validateCPUSet(*req.CpusetCpus, host.NCPU); err != nil {
+			v.add("cpuset_cpus", "%s", err)
+		}
+		res.CpusetCpus = *req.CpusetCpus
+	}
+
+	if req.PidsLimit != nil {
+		if *req.PidsLimit < -1 {
+			v.add("pids_limit", "must be -1 (unlimited) or positive")
+		}
+		limit := *req.PidsLimit
+		res.PidsLimit = &limit
+	}
+
+	if req.RestartPolicy != nil {
+		out.RestartPolicy = buildRestartPolicy(v, req.RestartPolicy)
+		if out.RestartPolicy.Name == "" {
+			v.add("restart_policy", "name is required")
+		}
+		if current.AutoRemove && out.RestartPolicy.Name != container.RestartPolicyDisabled {
+			v.add("restart_policy", "cannot be set on a container that is removed when it stops")
+		}
+	}
+
+	if len(v.Fields) == 0 && out.RestartPolicy.Name == "" && res.Memory == 0 && res.MemorySwap == 0 &&
+		res.NanoCPUs == 0 && res.CPUShares == 0 && res.CPUQuota == 0 && res.CPUPeriod == 0 &&
+		res.CpusetCpus == "" && res.PidsLimit == nil {
+		v.add("resources", "at least one limit or the restart policy is required")
+	}
+
+	return out, v.err()
+}
+
+var errCPUSetFormat = errors.New("must be a list of CPU numbers or ranges such as 0-2,5")
+
+// validateCPUSet checks a cpuset such as "0-2,5" only uses CPUs of the host,
+// when their number is known.
+func validateCPUSet(set string, ncpu int) error {
+	if strings.TrimSpace(set) == "" {
+		return errors.New("cannot be empty")
+	}
+
+	for _, part := range strings.Split(set, ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		low, err := strconv.Atoi(first)
+		if err != nil || low < 0 {
+			return errCPUSetFormat
+		}
+		high := low
+		if isRange {
+			if high, err = strconv.Atoi(last); err != nil || high < low {
+				return errCPUSetFormat
+			}
+		}
+		if ncpu > 0 && high >= ncpu {
+			return fmt.Errorf("CPU %d does not exist, the host has %d CPUs", high, ncpu)
+		}
+	}
+
+	return nil
+}
+
+// containerResources converts the limits of a host configuration into the
+// API model.
+func containerResources(hc *container.HostConfig) *models.ContainerResources {
+	out := &models.ContainerResources{
+		Memory:     hc.Memory,
+		MemorySwap: hc.MemorySwap,
+		CPUs:       float64(hc.NanoCPUs) / 1e9,
+		CPUShares:  hc.CPUShares,
+		CPUQuota:   hc.CPUQuota,
+		CPUPeriod:  hc.CPUPeriod,
+		CpusetCpus: hc.CpusetCpus,
+		RestartPolicy: models.RestartPolicy{
+			Name:              string(hc.RestartPolicy.Name),
+			MaximumRetryCount: hc.RestartPolicy.MaximumRetryCount,
+		},
+	}
+	if hc.PidsLimit != nil {
+		out.PidsLimit = *hc.PidsLimit
+	}
+
+	return out
+}
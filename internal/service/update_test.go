@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"mineServers/internal/models"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/system"
+)
+
+func TestContainerService_UpdateContainerResources(t *testing.T) {
+	svc, _, engine, id := newTestRecreate(t)
+	ctx := context.Background()
+
+	memory, swap, shares, pids := "512m", "1g", int64(512), int64(256)
+	resources, err := svc.UpdateContainerResources(ctx, "web", &models.ResourceUpdate{
+		Memory:        &memory,
+		MemorySwap:    &swap,
+		CPUShares:     &shares,
+		PidsLimit:     &pids,
+		RestartPolicy: &models.RestartPolicy{Name: "on-failure", MaximumRetryCount: 3},
+	})
+	if err != nil {
+		t.Fatalf("UpdateContainerResources() error = %v", err)
+	}
+	if resources.Memory != 512<<20 || resources.MemorySwap != 1<<30 || resources.CPUShares != 512 || resources.PidsLimit != 256 {
+		t.Errorf("unexpected limits %+v", resources)
+	}
+	if resources.RestartPolicy.Name != "on-failure" || resources.RestartPolicy.MaximumRetryCount != 3 {
+		t.Errorf("unexpected restart policy %+v", resources.RestartPolicy)
+	}
+
+	// Only the given fields change.
+	cpuset := "0-1"
+	resources, err = svc.UpdateContainerResources(ctx, id, &models.ResourceUpdate{CpusetCpus: &cpuset})
+	if err != nil || resources.CpusetCpus != "0-1" || resources.Memory != 512<<20 {
+		t.Errorf("expected the cpuset to be added to the limits, got %+v, %v", resources, err)
+	}
+	if web, _ := engine.Container("web"); web.HostConfig.CpusetCpus != "0-1" {
+		t.Errorf("expected the engine to have the new cpuset")
+	}
+
+	memory = "2g"
+	_, err = svc.UpdateContainerResources(ctx, id, &models.ResourceUpdate{Memory: &memory})
+	var verr *ValidationError
+	if !errors.As(err, &verr) || len(verr.Fields) != 1 || verr.Fields[0].Field != "memory" {
+		t.Errorf("expected memory above the current swap to be rejected, got %v", err)
+	}
+}
+
+func TestBuildResourceUpdate_ChecksHostCapacity(t *testing.T) {
+	host := system.Info{NCPU: 2, MemTotal: 1 << 30}
+	memory, cpus, quota, cpuset, pids := "2g", 1.0, int64(300000), "0,2", int64(-2)
+
+	_, err := buildResourceUpdate(&models.ResourceUpdate{
+		Memory:     &memory,
+		CPUs:       &cpus,
+		CPUQuota:   &quota,
+		CpusetCpus: &cpuset,
+		PidsLimit:  &pids,
+	}, &container.HostConfig{}, host)
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+	fields := map[string]bool{}
+	for _, f := range verr.Fields {
+		fields[f.Field] = true
+	}
+	for _, field := range []string{"memory", "cpus", "cpu_quota", "cpuset_cpus", "pids_limit"} {
+		if !fields[field] {
+			t.Errorf("expected %s to be reported, got %v", field, verr.Fields)
+		}
+	}
+
+	if _, err := buildResourceUpdate(&models.ResourceUpdate{}, &container.HostConfig{}, host); err == nil {
+		t.Errorf("expected an empty update to be rejected")
+	}
+
+	// Daemons that do not report their capacity are not checked against it.
+	cpus, cpuset = 8, "0-7"
+	if _, err := buildResourceUpdate(&models.ResourceUpdate{CPUs: &cpus, CpusetCpus: &cpuset}, &container.HostConfig{}, system.Info{}); err != nil {
+		t.Errorf("expected an unknown capacity to allow any CPUs, got %v", err)
+	}
+}
+
+func TestValidateCPUSet(t *testing.T) {
+	for set, valid := range map[string]bool{"0": true, "0-3": true, "0,2-3": true, "4": false, "1-0": false, "a": false, "": false} {
+		if err := validateCPUSet(set, 4); (err == nil) != valid {
+			t.Errorf("validateCPUSet(%q) = %v, want valid %v", set, err, valid)
+		}
+	}
+	if err := validateCPUSet("0-63", 0); err != nil {
+		t.Errorf("validateCPUSet() with an unknown CPU count = %v, want valid", err)
+	}
+}